@@ -8,10 +8,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"time"
 
 	"image-gallery/internal/config"
+	"image-gallery/internal/domain/image"
 	"image-gallery/internal/observability"
 	"image-gallery/internal/platform/database"
 	"image-gallery/internal/platform/server"
@@ -131,6 +134,16 @@ func main() {
 		}
 	}
 
+	// Process queued jobs, and those left from an earlier run, in the background
+	container.StartJobWorkers()
+	logger.GetZerolog().Info().Int("workers", cfg.Jobs.Workers).Msg("Processing job workers started")
+
+	// Fill in dimensions, hashes and derivatives of images stored without them, such as
+	// the synced ones, continuing from the last checkpoint
+	if cfg.Storage.BackfillOnStartup {
+		container.BackfillJob().Start(false)
+	}
+
 	handler := handlers.NewWithContainer(container)
 
 	srv := server.New(cfg.Port, handler.Routes())
@@ -186,6 +199,12 @@ func syncExistingImages(ctx context.Context, container *services.Container, logg
 	skipped := 0
 
 	for _, obj := range objects {
+		// Derivatives belong to the image they were rendered from, and resumable uploads
+		// become images once they are complete
+		if strings.HasPrefix(obj.Key, image.DerivativePrefix) || strings.HasPrefix(obj.Key, image.UploadPrefix) {
+			continue
+		}
+
 		// Check if image already exists in database by storage path
 		var existingImage struct{ ID int }
 		err := dbRepo.QueryRowContext(ctx, "SELECT id FROM images WHERE storage_path = $1 LIMIT 1", obj.Key).Scan(&existingImage.ID)
@@ -203,12 +222,12 @@ func syncExistingImages(ctx context.Context, container *services.Container, logg
 
 		// Create image record in database
 		// Note: We don't have the actual file data, so dimensions will be NULL
-		// This is acceptable for existing images as they can be updated later
+		// until the backfill job reads them from the stored object
 		_, err = dbRepo.ExecContext(ctx, `
-			INSERT INTO images (original_filename, storage_path, content_type, file_size, uploaded_at, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, NOW(), NOW(), NOW())
+			INSERT INTO images (filename, original_filename, storage_path, content_type, file_size, uploaded_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), NOW())
 			ON CONFLICT (storage_path) DO NOTHING
-		`, originalFilename, obj.Key, obj.ContentType, obj.Size)
+		`, path.Base(obj.Key), originalFilename, obj.Key, obj.ContentType, obj.Size)
 
 		if err != nil {
 			logger.GetZerolog().Error().Err(err).Str("path", obj.Key).Msg("Failed to create image record")
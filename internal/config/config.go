@@ -17,6 +17,8 @@ type Config struct {
 	DatabaseURL   string
 	Storage       StorageConfig
 	Cache         CacheConfig
+	Tags          TagsConfig
+	Jobs          JobsConfig
 	Logging       *LoggingConfig
 	Server        *ServerConfig
 	Observability ObservabilityConfig
@@ -24,15 +26,27 @@ type Config struct {
 
 // StorageConfig holds object storage configuration
 type StorageConfig struct {
-	Endpoint        string
-	AccessKeyID     string
-	SecretAccessKey string
-	BucketName      string
-	UseSSL          bool
-	Region          string
-	MaxUploadSize   int64
-	AllowedTypes    []string
-	SyncOnStartup   bool // Sync existing S3 objects to database on startup
+	Endpoint             string
+	AccessKeyID          string
+	SecretAccessKey      string
+	BucketName           string
+	UseSSL               bool
+	Region               string
+	MaxUploadSize        int64
+	AllowedTypes         []string
+	SyncOnStartup        bool          // Sync existing S3 objects to database on startup
+	MetadataPolicy       string        // Embedded metadata removed before storing: keep, strip_gps or strip_all
+	VariantWidths        []int         // Widths of the responsive variants generated at upload; empty disables them
+	AnimatedThumbnails   string        // Thumbnails of animated GIFs: animate keeps every frame, poster shows the first
+	MaxImagePixels       int64         // Largest width x height an image may have to be decoded
+	DecodeMemoryBudget   int64         // Largest decoded size in bytes of an image, all frames included
+	MaxConcurrentDecodes int           // Images decoded at once
+	BackfillOnStartup    bool          // Fill in the derived data of images stored without it on startup
+	BackfillInterval     time.Duration // Least time between two images backfilled; zero disables the rate limit
+	UploadExpiry         time.Duration // How long a resumable upload stays open after its last chunk
+	PresignExpiry        time.Duration // How long a presigned direct upload policy can be used for
+	UploadConcurrency    int           // Files of one form upload processed at once
+	UploadBytesInFlight  int64         // Largest total size of the files being processed across form uploads
 }
 
 // CacheConfig holds Redis cache configuration
@@ -56,6 +70,22 @@ type CacheConfig struct {
 	DefaultTTL      time.Duration
 }
 
+// TagsConfig holds tag curation configuration
+type TagsConfig struct {
+	// Policy decides which tags uploads may apply:
+	// "free" creates any tag, "predefined" rejects unknown tags,
+	// "approval" queues unknown tags for admin approval
+	Policy string
+}
+
+// JobsConfig holds background processing job configuration. Zero values use the defaults.
+type JobsConfig struct {
+	Workers      int           // Jobs run at once by the server process
+	MaxAttempts  int           // Attempts before a job is dead-lettered
+	PollInterval time.Duration // Wait between claims while the queue is empty
+	RetryBackoff time.Duration // Wait before the first retry, doubled on each after
+}
+
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level  string
@@ -116,15 +146,27 @@ func Load() (*Config, error) {
 		Host:        getEnv("HOST", "localhost"),
 		DatabaseURL: getEnv("DATABASE_URL", ""),
 		Storage: StorageConfig{
-			Endpoint:        getEnv("STORAGE_ENDPOINT", "localhost:9000"),
-			AccessKeyID:     getEnv("STORAGE_ACCESS_KEY", ""),
-			SecretAccessKey: getEnv("STORAGE_SECRET_KEY", ""),
-			BucketName:      getEnv("STORAGE_BUCKET", "images"),
-			UseSSL:          useSSL,
-			Region:          getEnv("STORAGE_REGION", "us-east-1"),
-			MaxUploadSize:   maxUploadSize,
-			AllowedTypes:    allowedTypes,
-			SyncOnStartup:   parseBoolOrDefault(getEnv("STORAGE_SYNC_ON_STARTUP", "false"), false),
+			Endpoint:             getEnv("STORAGE_ENDPOINT", "localhost:9000"),
+			AccessKeyID:          getEnv("STORAGE_ACCESS_KEY", ""),
+			SecretAccessKey:      getEnv("STORAGE_SECRET_KEY", ""),
+			BucketName:           getEnv("STORAGE_BUCKET", "images"),
+			UseSSL:               useSSL,
+			Region:               getEnv("STORAGE_REGION", "us-east-1"),
+			MaxUploadSize:        maxUploadSize,
+			AllowedTypes:         allowedTypes,
+			SyncOnStartup:        parseBoolOrDefault(getEnv("STORAGE_SYNC_ON_STARTUP", "false"), false),
+			MetadataPolicy:       strings.ToLower(getEnv("METADATA_POLICY", "keep")),
+			VariantWidths:        parseIntList(getEnv("VARIANT_WIDTHS", "320,640,1280,2048")),
+			AnimatedThumbnails:   strings.ToLower(getEnv("ANIMATED_THUMBNAILS", "animate")),
+			MaxImagePixels:       int64(parseIntOrDefault(getEnv("MAX_IMAGE_PIXELS", "100000000"), 100000000)),
+			DecodeMemoryBudget:   parseSize(getEnv("DECODE_MEMORY_BUDGET", "512MB")),
+			MaxConcurrentDecodes: parseIntOrDefault(getEnv("MAX_CONCURRENT_DECODES", "4"), 4),
+			BackfillOnStartup:    parseBoolOrDefault(getEnv("BACKFILL_ON_STARTUP", "false"), false),
+			BackfillInterval:     parseDurationOrDefault(getEnv("BACKFILL_INTERVAL", "200ms"), 200*time.Millisecond),
+			UploadExpiry:         parseDurationOrDefault(getEnv("RESUMABLE_UPLOAD_EXPIRY", "24h"), 24*time.Hour),
+			PresignExpiry:        parseDurationOrDefault(getEnv("PRESIGNED_UPLOAD_EXPIRY", "15m"), 15*time.Minute),
+			UploadConcurrency:    parseIntOrDefault(getEnv("UPLOAD_CONCURRENCY", "4"), 4),
+			UploadBytesInFlight:  parseSize(getEnv("UPLOAD_BYTES_IN_FLIGHT", "64MB")),
 		},
 		Cache: CacheConfig{
 			Enabled:         cacheEnabled,
@@ -145,6 +187,15 @@ func Load() (*Config, error) {
 			IdleTimeout:     cacheIdleTimeout,
 			DefaultTTL:      cacheDefaultTTL,
 		},
+		Tags: TagsConfig{
+			Policy: strings.ToLower(getEnv("TAG_POLICY", "free")),
+		},
+		Jobs: JobsConfig{
+			Workers:      parseIntOrDefault(getEnv("JOB_WORKERS", "4"), 4),
+			MaxAttempts:  parseIntOrDefault(getEnv("JOB_MAX_ATTEMPTS", "5"), 5),
+			PollInterval: parseDurationOrDefault(getEnv("JOB_POLL_INTERVAL", "1s"), time.Second),
+			RetryBackoff: parseDurationOrDefault(getEnv("JOB_RETRY_BACKOFF", "10s"), 10*time.Second),
+		},
 		Logging: &LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
@@ -222,6 +273,22 @@ func parseList(listStr string) []string {
 	return result
 }
 
+// parseIntList parses comma-separated integers; "none" gives an empty list and entries
+// that are not integers become 0 so that validation reports them
+func parseIntList(listStr string) []int {
+	if strings.EqualFold(strings.TrimSpace(listStr), "none") {
+		return []int{}
+	}
+
+	items := parseList(listStr)
+	result := make([]int, len(items))
+	for i, item := range items {
+		result[i] = parseIntOrDefault(item, 0)
+	}
+
+	return result
+}
+
 // MustLoad loads configuration and panics on error
 // Useful for startup scenarios where invalid config should crash the application
 func MustLoad() *Config {
@@ -15,6 +15,12 @@ const (
 	minioadminCredential = "minioadmin"
 )
 
+// Bounds of the configurable responsive variant widths, in pixels
+const (
+	minVariantWidth = 16
+	maxVariantWidth = 4096
+)
+
 // ValidationError represents a configuration validation error
 type ValidationError struct {
 	Field   string
@@ -66,6 +72,16 @@ func (c *Config) Validate() error {
 		validationErrors = append(validationErrors, err...)
 	}
 
+	// Validate tag policy
+	if err := c.validateTags(); err != nil {
+		validationErrors = append(validationErrors, err...)
+	}
+
+	// Validate background job settings
+	if err := c.validateJobs(); err != nil {
+		validationErrors = append(validationErrors, err...)
+	}
+
 	// Validate logging configuration (if present)
 	if c.Logging != nil {
 		if err := c.validateLogging(); err != nil {
@@ -253,6 +269,150 @@ func (c *Config) validateStorage() ValidationErrors {
 		}
 	}
 
+	// An empty policy (e.g. in hand-built test configs) keeps metadata
+	switch c.Storage.MetadataPolicy {
+	case "", "keep", "strip_gps", "strip_all":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "storage.metadata_policy",
+			Value:   c.Storage.MetadataPolicy,
+			Message: "metadata policy must be one of: keep, strip_gps, strip_all",
+		})
+	}
+
+	// An empty setting (e.g. in hand-built test configs) animates thumbnails
+	switch c.Storage.AnimatedThumbnails {
+	case "", "animate", "poster":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "storage.animated_thumbnails",
+			Value:   c.Storage.AnimatedThumbnails,
+			Message: "animated thumbnails must be one of: animate, poster",
+		})
+	}
+
+	// Zero decode limits (e.g. in hand-built test configs) keep the processor defaults
+	if c.Storage.MaxImagePixels < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.max_image_pixels",
+			Value:   c.Storage.MaxImagePixels,
+			Message: "max image pixels cannot be negative",
+		})
+	}
+	if c.Storage.DecodeMemoryBudget < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.decode_memory_budget",
+			Value:   c.Storage.DecodeMemoryBudget,
+			Message: "decode memory budget cannot be negative",
+		})
+	}
+	if c.Storage.MaxConcurrentDecodes < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.max_concurrent_decodes",
+			Value:   c.Storage.MaxConcurrentDecodes,
+			Message: "max concurrent decodes cannot be negative",
+		})
+	}
+	if c.Storage.BackfillInterval < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.backfill_interval",
+			Value:   c.Storage.BackfillInterval,
+			Message: "backfill interval cannot be negative",
+		})
+	}
+	if c.Storage.UploadExpiry < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.upload_expiry",
+			Value:   c.Storage.UploadExpiry,
+			Message: "resumable upload expiry cannot be negative",
+		})
+	}
+	if c.Storage.PresignExpiry < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.presign_expiry",
+			Value:   c.Storage.PresignExpiry,
+			Message: "presigned upload expiry cannot be negative",
+		})
+	}
+	if c.Storage.UploadConcurrency < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.upload_concurrency",
+			Value:   c.Storage.UploadConcurrency,
+			Message: "upload concurrency cannot be negative",
+		})
+	}
+	if c.Storage.UploadBytesInFlight < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "storage.upload_bytes_in_flight",
+			Value:   c.Storage.UploadBytesInFlight,
+			Message: "upload bytes in flight cannot be negative",
+		})
+	}
+
+	for _, width := range c.Storage.VariantWidths {
+		if width < minVariantWidth || width > maxVariantWidth {
+			errors = append(errors, ValidationError{
+				Field:   "storage.variant_widths",
+				Value:   width,
+				Message: fmt.Sprintf("variant widths must be between %d and %d pixels", minVariantWidth, maxVariantWidth),
+			})
+			break
+		}
+	}
+
+	return errors
+}
+
+func (c *Config) validateTags() ValidationErrors {
+	var errors ValidationErrors
+
+	// An empty policy (e.g. in hand-built test configs) means free tagging
+	switch c.Tags.Policy {
+	case "", "free", "predefined", "approval":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "tags.policy",
+			Value:   c.Tags.Policy,
+			Message: "tag policy must be one of: free, predefined, approval",
+		})
+	}
+
+	return errors
+}
+
+func (c *Config) validateJobs() ValidationErrors {
+	var errors ValidationErrors
+
+	// Zero values (e.g. in hand-built test configs) mean the defaults
+	if c.Jobs.Workers < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "jobs.workers",
+			Value:   c.Jobs.Workers,
+			Message: "job workers cannot be negative",
+		})
+	}
+	if c.Jobs.MaxAttempts < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "jobs.max_attempts",
+			Value:   c.Jobs.MaxAttempts,
+			Message: "job max attempts cannot be negative",
+		})
+	}
+	if c.Jobs.PollInterval < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "jobs.poll_interval",
+			Value:   c.Jobs.PollInterval,
+			Message: "job poll interval cannot be negative",
+		})
+	}
+	if c.Jobs.RetryBackoff < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "jobs.retry_backoff",
+			Value:   c.Jobs.RetryBackoff,
+			Message: "job retry backoff cannot be negative",
+		})
+	}
+
 	return errors
 }
 
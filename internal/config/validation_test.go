@@ -60,6 +60,111 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "unknown tag policy",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:   "localhost:9000",
+					BucketName: "test-images",
+				},
+				Tags: TagsConfig{Policy: "moderated"},
+			},
+			expectError: true,
+			errorCount:  1,
+		},
+		{
+			name: "unknown metadata policy",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:       "localhost:9000",
+					BucketName:     "test-images",
+					MetadataPolicy: "strip_serials",
+				},
+			},
+			expectError: true,
+			errorCount:  1,
+		},
+		{
+			name: "variant width out of range",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:      "localhost:9000",
+					BucketName:    "test-images",
+					VariantWidths: []int{320, 0, 10000},
+				},
+			},
+			expectError: true,
+			errorCount:  1,
+		},
+		{
+			name: "unknown animated thumbnail mode",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:           "localhost:9000",
+					BucketName:         "test-images",
+					AnimatedThumbnails: "loop",
+				},
+			},
+			expectError: true,
+			errorCount:  1,
+		},
+		{
+			name: "negative decode limits",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:             "localhost:9000",
+					BucketName:           "test-images",
+					MaxImagePixels:       -1,
+					DecodeMemoryBudget:   -1,
+					MaxConcurrentDecodes: -1,
+				},
+			},
+			expectError: true,
+			errorCount:  3,
+		},
+		{
+			name: "negative backfill interval",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:         "localhost:9000",
+					BucketName:       "test-images",
+					BackfillInterval: -time.Second,
+				},
+			},
+			expectError: true,
+			errorCount:  1,
+		},
+		{
+			name: "negative job settings",
+			config: &Config{
+				Environment: "test",
+				Port:        "8080",
+				Storage: StorageConfig{
+					Endpoint:   "localhost:9000",
+					BucketName: "test-images",
+				},
+				Jobs: JobsConfig{
+					Workers:      -1,
+					MaxAttempts:  -1,
+					PollInterval: -time.Second,
+					RetryBackoff: -time.Second,
+				},
+			},
+			expectError: true,
+			errorCount:  4,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,39 @@
+package image
+
+import (
+	"context"
+	"time"
+)
+
+// BackfillJobName names the checkpoint of the job that fills in the derived data of
+// images stored without it, such as those synced from the bucket
+const BackfillJobName = "derived_data"
+
+// BackfillCheckpoint records how far a backfill job has got. Images are visited in ID
+// order, so a restarted job continues after LastImageID.
+type BackfillCheckpoint struct {
+	Job         string     `json:"job"`
+	LastImageID int        `json:"last_image_id"`
+	Processed   int        `json:"processed"`
+	Failed      int        `json:"failed"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // Set when a pass found no images left
+}
+
+// BackfillProgress reports a backfill job's checkpoint along with what is left to do
+type BackfillProgress struct {
+	BackfillCheckpoint
+	Running   bool `json:"running"`
+	Remaining int  `json:"remaining"` // Images after the checkpoint still missing derived data
+}
+
+// BackfillRunner runs the backfill job in the background
+type BackfillRunner interface {
+	// Start runs the job unless it is already running, continuing from its checkpoint or,
+	// with restart, from the first image. It reports whether the job was started.
+	Start(restart bool) bool
+
+	// Progress reports the job's checkpoint and the images remaining
+	Progress(ctx context.Context) (*BackfillProgress, error)
+}
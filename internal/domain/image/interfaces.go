@@ -3,6 +3,7 @@ package image
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // Repository defines the interface for image data persistence
@@ -19,6 +20,10 @@ type Repository interface {
 	// Update modifies an existing image
 	Update(ctx context.Context, image *Image) error
 
+	// Patch updates only the derived data set in patch, leaving the rest of the image as
+	// it is stored
+	Patch(ctx context.Context, id int, patch *ImagePatch) error
+
 	// Delete removes an image from the repository
 	Delete(ctx context.Context, id int) error
 
@@ -30,6 +35,28 @@ type Repository interface {
 
 	// CountByTag returns the number of images with a specific tag
 	CountByTag(ctx context.Context, tagName string) (int, error)
+
+	// Timeline counts images per capture year, month or day, newest period first
+	Timeline(ctx context.Context, granularity TimelineGranularity) ([]TimelineBucket, error)
+
+	// GeoClusters groups the geotagged images inside bounds by proximity at a map zoom level
+	GeoClusters(ctx context.Context, bounds GeoBounds, zoom int) ([]GeoCluster, error)
+
+	// SaveVariants records the responsive variants stored for an image
+	SaveVariants(ctx context.Context, imageID int, variants []ImageVariant) error
+
+	// ListBackfillCandidates returns the IDs of up to limit images missing derived data
+	// (dimensions or content hash) with IDs above afterID, in ID order
+	ListBackfillCandidates(ctx context.Context, afterID int, limit int) ([]int, error)
+
+	// CountBackfillCandidates counts the images missing derived data with IDs above afterID
+	CountBackfillCandidates(ctx context.Context, afterID int) (int, error)
+
+	// GetBackfillCheckpoint returns a backfill job's checkpoint, at the start if it never ran
+	GetBackfillCheckpoint(ctx context.Context, job string) (*BackfillCheckpoint, error)
+
+	// SaveBackfillCheckpoint records a backfill job's progress
+	SaveBackfillCheckpoint(ctx context.Context, checkpoint *BackfillCheckpoint) error
 }
 
 // TagRepository defines the interface for tag data persistence
@@ -46,12 +73,49 @@ type TagRepository interface {
 	// List retrieves all tags or tags matching criteria
 	List(ctx context.Context, limit, offset int) ([]*Tag, error)
 
+	// Update modifies an existing tag
+	Update(ctx context.Context, tag *Tag) error
+
 	// Delete removes a tag from the repository
 	Delete(ctx context.Context, id int) error
 
-	// GetOrCreate gets an existing tag or creates a new one
+	// Merge moves all images from the source tag to the target tag and removes the source
+	Merge(ctx context.Context, sourceID, targetID int) error
+
+	// GetAll returns every active tag, used to build the tag hierarchy
+	GetAll(ctx context.Context) ([]*Tag, error)
+
+	// SetParent moves a tag and its subtree under a new parent (nil for the root)
+	SetParent(ctx context.Context, tagID int, parentID *int) error
+
+	// GetOrCreate gets an existing tag (by name or alias) or creates a new one
 	GetOrCreate(ctx context.Context, name string) (*Tag, error)
 
+	// ResolveAlias returns the canonical tag for an alias, or nil if the alias is unknown
+	ResolveAlias(ctx context.Context, alias string) (*Tag, error)
+
+	// ListAliases returns every alias, or only those of one tag when tagID is positive
+	ListAliases(ctx context.Context, tagID int) ([]*TagAlias, error)
+
+	// CreateAlias stores a new alias for a tag
+	CreateAlias(ctx context.Context, alias *TagAlias) error
+
+	// DeleteAlias removes an alias
+	DeleteAlias(ctx context.Context, alias string) error
+
+	// AddPendingTag records that an image requested a tag that awaits approval
+	AddPendingTag(ctx context.Context, name string, imageID int) error
+
+	// ListPendingTags returns the tags awaiting approval, oldest request first
+	ListPendingTags(ctx context.Context) ([]*PendingTag, error)
+
+	// ApprovePendingTag creates the tag as a predefined tag and attaches it to the
+	// requesting images, returning the tag and the IDs of the images it was attached to
+	ApprovePendingTag(ctx context.Context, name string) (*Tag, []int, error)
+
+	// RejectPendingTag discards a pending tag
+	RejectPendingTag(ctx context.Context, name string) error
+
 	// GetPopularTags returns the most frequently used tags
 	GetPopularTags(ctx context.Context, limit int) ([]*Tag, error)
 
@@ -60,6 +124,24 @@ type TagRepository interface {
 
 	// ExistsByName checks if a tag with the given name exists
 	ExistsByName(ctx context.Context, name string) (bool, error)
+
+	// AddToImage attaches a tag to an image, reporting whether a new association was created
+	AddToImage(ctx context.Context, imageID, tagID int) (bool, error)
+
+	// RemoveFromImage detaches a tag from an image, reporting whether an association was removed
+	RemoveFromImage(ctx context.Context, imageID, tagID int) (bool, error)
+
+	// GetImageTags returns all tags attached to an image
+	GetImageTags(ctx context.Context, imageID int) ([]*Tag, error)
+
+	// GetImageIDs returns the IDs of all images carrying a tag
+	GetImageIDs(ctx context.Context, tagID int) ([]int, error)
+
+	// GetCategories returns active predefined tags grouped by category
+	GetCategories(ctx context.Context) (map[string][]*Tag, error)
+
+	// RenameCategory moves all tags from one category to another
+	RenameCategory(ctx context.Context, from, to string) (int, error)
 }
 
 // StorageService defines the interface for file storage operations
@@ -68,12 +150,18 @@ type StorageService interface {
 	// size is the known file size in bytes - passing this prevents MinIO SDK from buffering entire file
 	Store(ctx context.Context, filename string, contentType string, data io.Reader, size int64) (string, error)
 
+	// StoreAt saves a file under a fixed path, replacing any file already stored there
+	StoreAt(ctx context.Context, path string, contentType string, data io.Reader, size int64) error
+
 	// Retrieve gets a file from storage
 	Retrieve(ctx context.Context, path string) (io.ReadCloser, error)
 
 	// Delete removes a file from storage
 	Delete(ctx context.Context, path string) error
 
+	// DeletePrefix removes every file whose path starts with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+
 	// Exists checks if a file exists in storage
 	Exists(ctx context.Context, path string) (bool, error)
 
@@ -91,6 +179,7 @@ type FileInfo struct {
 	ContentType  string
 	LastModified int64
 	ETag         string
+	Metadata     map[string]string // User metadata stored with the file, keys in lower case
 }
 
 // ImageProcessor defines the interface for image processing operations
@@ -109,6 +198,24 @@ type ImageProcessor interface {
 
 	// OptimizeImage compresses and optimizes an image
 	OptimizeImage(ctx context.Context, data io.Reader, quality int) (io.Reader, error)
+
+	// Render scales and crops an image into the options' box and encodes it in their format
+	Render(ctx context.Context, data io.Reader, opts RenderOptions) (io.Reader, error)
+
+	// GenerateVariants decodes an image once and encodes a copy at each width no wider than
+	// the image, narrowest first
+	GenerateVariants(ctx context.Context, data io.Reader, widths []int) ([]RenderedVariant, error)
+
+	// SanitizeSVG returns an SVG document with scripts, event handlers and external
+	// references removed, or an error when the document is not well-formed SVG
+	SanitizeSVG(ctx context.Context, data io.Reader) ([]byte, error)
+
+	// ExtractMetadata reads capture details (EXIF, XMP, IPTC) from an image stream
+	ExtractMetadata(ctx context.Context, data io.Reader) (*PhotoMetadata, error)
+
+	// StripMetadata returns the image stream with metadata removed according to the policy.
+	// The result has the same length as the input and must be closed by the caller.
+	StripMetadata(ctx context.Context, data io.Reader, policy MetadataPolicy) io.ReadCloser
 }
 
 // ImageInfo represents metadata extracted from an image
@@ -119,6 +226,16 @@ type ImageInfo struct {
 	ColorSpace  string
 	HasAlpha    bool
 	Orientation int
+	FrameCount  int           // 1 for still images
+	Duration    time.Duration // One loop of an animation, as encoded in its frame delays
+}
+
+// Animation returns the animation details of an animated image, or nil for still images
+func (i *ImageInfo) Animation() *Animation {
+	if i.FrameCount <= 1 {
+		return nil
+	}
+	return &Animation{FrameCount: i.FrameCount, DurationMS: int(i.Duration.Milliseconds())}
 }
 
 // EventPublisher defines the interface for publishing domain events
@@ -134,6 +251,22 @@ type EventPublisher interface {
 
 	// PublishTagCreated publishes an event when a tag is created
 	PublishTagCreated(ctx context.Context, tag *Tag) error
+
+	// PublishTagDeleted publishes an event when a tag is deleted
+	PublishTagDeleted(ctx context.Context, tagID int) error
+
+	// PublishTagAttached publishes an event when a tag is attached to an image
+	PublishTagAttached(ctx context.Context, event *TagAttachedEvent) error
+
+	// PublishTagDetached publishes an event when a tag is detached from an image
+	PublishTagDetached(ctx context.Context, event *TagDetachedEvent) error
+
+	// PublishImageProcessingFailed publishes an event when a processing job has failed
+	// on every attempt
+	PublishImageProcessingFailed(ctx context.Context, event *ImageProcessingFailedEvent) error
+
+	// PublishThumbnailGenerated publishes an event when a thumbnail has been stored
+	PublishThumbnailGenerated(ctx context.Context, event *ThumbnailGeneratedEvent) error
 }
 
 // ImageService defines the high-level business operations for images
@@ -150,6 +283,13 @@ type ImageService interface {
 	// UpdateImage modifies an existing image
 	UpdateImage(ctx context.Context, id int, req *UpdateImageRequest) (*Image, error)
 
+	// AttachTag adds a single tag to an image without touching its other tags.
+	// When the tag policy queues the tag for approval it is reported in PendingTags instead.
+	AttachTag(ctx context.Context, id int, tagName string) (*Image, error)
+
+	// DetachTag removes a single tag from an image without touching its other tags
+	DetachTag(ctx context.Context, id int, tagName string) (*Image, error)
+
 	// DeleteImage removes an image and its associated files
 	DeleteImage(ctx context.Context, id int) error
 
@@ -159,14 +299,66 @@ type ImageService interface {
 	// GenerateImageURL creates a URL for accessing an image
 	GenerateImageURL(ctx context.Context, id int, expiry int64) (string, error)
 
+	// RenderImage returns a resized derivative of an image, rendering and caching it on first use
+	RenderImage(ctx context.Context, id int, opts RenderOptions) (io.ReadCloser, error)
+
 	// GetImageStats returns statistics about images
 	GetImageStats(ctx context.Context) (*ImageStats, error)
+
+	// GetTimeline counts images per capture year, month or day, newest period first
+	GetTimeline(ctx context.Context, granularity TimelineGranularity) ([]TimelineBucket, error)
+
+	// GetGeoClusters groups the geotagged images inside bounds by proximity at a map zoom level
+	GetGeoClusters(ctx context.Context, bounds GeoBounds, zoom int) ([]GeoCluster, error)
+
+	// BackfillImage fills in the derived data an image was stored without: dimensions,
+	// content hash, capture details, variants and animated thumbnail
+	BackfillImage(ctx context.Context, id int) (*Image, error)
+
+	// ProcessImage runs one processing step of an image, as a worker does for a queued job
+	ProcessImage(ctx context.Context, id int, step JobType) error
+
+	// ReprocessImage regenerates an image's derived data from its stored file
+	ReprocessImage(ctx context.Context, id int) error
 }
 
 // TagService defines the high-level business operations for tags
 type TagService interface {
 	// CreateTag creates a new tag
-	CreateTag(ctx context.Context, name string) (*Tag, error)
+	CreateTag(ctx context.Context, req *CreateTagRequest) (*Tag, error)
+
+	// UpdateTag renames or re-describes an existing tag
+	UpdateTag(ctx context.Context, id int, req *UpdateTagRequest) (*Tag, error)
+
+	// MergeTags folds the source tag into the target tag
+	MergeTags(ctx context.Context, sourceID, targetID int) (*Tag, error)
+
+	// MoveTag re-parents a tag and its subtree (nil parent moves it to the root)
+	MoveTag(ctx context.Context, id int, parentID *int) (*Tag, error)
+
+	// GetTagTree returns the active tags arranged as a hierarchy
+	GetTagTree(ctx context.Context) ([]*TagNode, error)
+
+	// ResolveTagNames replaces aliases with canonical tag names and drops duplicates
+	ResolveTagNames(ctx context.Context, names []string) ([]string, error)
+
+	// ListAliases returns every alias, or only those of one tag when tagID is positive
+	ListAliases(ctx context.Context, tagID int) ([]*TagAlias, error)
+
+	// AddAlias makes alias resolve to the given tag
+	AddAlias(ctx context.Context, tagID int, alias string) (*TagAlias, error)
+
+	// RemoveAlias deletes an alias
+	RemoveAlias(ctx context.Context, alias string) error
+
+	// ListPendingTags returns the tags awaiting approval
+	ListPendingTags(ctx context.Context) ([]*PendingTag, error)
+
+	// ApprovePendingTag turns a pending tag into a predefined tag on the requesting images
+	ApprovePendingTag(ctx context.Context, name string) (*Tag, error)
+
+	// RejectPendingTag discards a pending tag
+	RejectPendingTag(ctx context.Context, name string) error
 
 	// GetTag retrieves a tag by ID
 	GetTag(ctx context.Context, id int) (*Tag, error)
@@ -183,6 +375,12 @@ type TagService interface {
 	// DeleteTag removes a tag
 	DeleteTag(ctx context.Context, id int) error
 
+	// GetCategories returns predefined tags grouped by category
+	GetCategories(ctx context.Context) (map[string][]*Tag, error)
+
+	// RenameCategory moves all tags from one category to another
+	RenameCategory(ctx context.Context, from, to string) (int, error)
+
 	// GetTagStats returns statistics about tags
 	GetTagStats(ctx context.Context) (*TagStats, error)
 }
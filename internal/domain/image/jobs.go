@@ -0,0 +1,131 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// JobType names an image processing step run by the background workers
+type JobType string
+
+const (
+	// JobThumbnail stores the thumbnails the gallery shows: the animated thumbnail of
+	// an animated GIF, or the thumb and card renditions of a still image
+	JobThumbnail JobType = "thumbnail"
+	// JobVariants stores the responsive variants
+	JobVariants JobType = "variants"
+	// JobMetadata reads the capture details (EXIF, XMP, IPTC) from the stored file
+	JobMetadata JobType = "metadata"
+	// JobHash computes the content hash of the stored file
+	JobHash JobType = "hash"
+	// JobReprocess reads the dimensions again and queues every other step
+	JobReprocess JobType = "reprocess"
+)
+
+// JobTypes lists the job types in the order an image's steps are shown
+var JobTypes = []JobType{JobHash, JobMetadata, JobVariants, JobThumbnail, JobReprocess}
+
+// IsValid checks if the job type is one of the defined types
+func (t JobType) IsValid() bool {
+	for _, jobType := range JobTypes {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
+
+// JobStatus is the state of a queued job
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending" // Waiting to run, possibly after a failed attempt
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobDead    JobStatus = "dead" // Failed on every attempt
+)
+
+// ProcessingJob is an image processing step in the job queue
+type ProcessingJob struct {
+	ID          int64     `json:"id"`
+	ImageID     int       `json:"image_id"`
+	Type        JobType   `json:"type"`
+	Status      JobStatus `json:"status"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	RunAt       time.Time `json:"run_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	OriginalFilename string `json:"original_filename,omitempty"` // Set on failed job listings
+}
+
+// ProcessingStatus sums up the background processing of an image
+type ProcessingStatus string
+
+const (
+	ProcessingPending    ProcessingStatus = "pending"    // Queued, no step started yet
+	ProcessingInProgress ProcessingStatus = "processing" // Steps running, retrying or left to run
+	ProcessingReady      ProcessingStatus = "ready"      // Every step done, or none queued
+	ProcessingFailed     ProcessingStatus = "failed"     // A step failed on every attempt
+)
+
+// ProcessingStatus sums up the image's processing steps, as loaded in Processing, and
+// returns the error of the step that failed when the status is ProcessingFailed
+func (img *Image) ProcessingStatus() (ProcessingStatus, string) {
+	started, finished := false, true
+	for i := range img.Processing {
+		job := &img.Processing[i]
+		switch {
+		case job.Status == JobDead:
+			return ProcessingFailed, fmt.Sprintf("%s: %s", job.Type, job.LastError)
+		case job.Status == JobDone:
+			started = true
+		default:
+			started = started || job.Status == JobRunning || job.Attempts > 0
+			finished = false
+		}
+	}
+
+	switch {
+	case finished:
+		return ProcessingReady, ""
+	case started:
+		return ProcessingInProgress, ""
+	default:
+		return ProcessingPending, ""
+	}
+}
+
+// JobQueue is the durable queue of image processing jobs. Jobs survive restarts, and
+// each is claimed by one worker at a time.
+type JobQueue interface {
+	// Enqueue queues jobs of the given types for an image. A type already queued or
+	// running for the image is not queued twice.
+	Enqueue(ctx context.Context, imageID int, types ...JobType) error
+
+	// Claim takes up to limit due jobs for a worker, which must finish each within the
+	// lease or have it claimed again
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*ProcessingJob, error)
+
+	// Complete records that a job succeeded. Like Retry and Bury, it takes the attempt
+	// of the worker's claim, and returns ErrJobLeaseLost once the job has been claimed
+	// again, so a worker whose lease ran out cannot overwrite the job's state.
+	Complete(ctx context.Context, id int64, attempt int) error
+
+	// Retry records a failed attempt and queues the job again at runAt
+	Retry(ctx context.Context, id int64, attempt int, lastError string, runAt time.Time) error
+
+	// Bury records the last failed attempt and moves the job to the dead-letter state
+	Bury(ctx context.Context, id int64, attempt int, lastError string) error
+
+	// ListFailed returns a page of the dead-lettered jobs no later job of the same image
+	// and type has replaced, most recently failed first, with their total count
+	ListFailed(ctx context.Context, limit, offset int) ([]*ProcessingJob, int, error)
+
+	// Requeue queues a failed job listed by ListFailed again, with all its attempts.
+	// It returns ErrJobNotFound for any other job.
+	Requeue(ctx context.Context, id int64) error
+}
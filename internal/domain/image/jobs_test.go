@@ -0,0 +1,50 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImage_ProcessingStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		jobs      []ProcessingJob
+		wantState ProcessingStatus
+		wantError string
+	}{
+		{"no jobs", nil, ProcessingReady, ""},
+		{"all done", []ProcessingJob{
+			{Type: JobVariants, Status: JobDone},
+			{Type: JobThumbnail, Status: JobDone},
+		}, ProcessingReady, ""},
+		{"queued", []ProcessingJob{
+			{Type: JobVariants, Status: JobPending},
+			{Type: JobThumbnail, Status: JobPending},
+		}, ProcessingPending, ""},
+		{"running", []ProcessingJob{
+			{Type: JobVariants, Status: JobRunning, Attempts: 1},
+			{Type: JobThumbnail, Status: JobPending},
+		}, ProcessingInProgress, ""},
+		{"some done", []ProcessingJob{
+			{Type: JobVariants, Status: JobDone},
+			{Type: JobThumbnail, Status: JobPending},
+		}, ProcessingInProgress, ""},
+		{"waiting to retry", []ProcessingJob{
+			{Type: JobVariants, Status: JobPending, Attempts: 2, LastError: "timeout"},
+		}, ProcessingInProgress, ""},
+		{"failed", []ProcessingJob{
+			{Type: JobVariants, Status: JobDead, LastError: "corrupt image"},
+			{Type: JobThumbnail, Status: JobPending},
+		}, ProcessingFailed, "variants: corrupt image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := &Image{Processing: tt.jobs}
+			status, err := img.ProcessingStatus()
+			assert.Equal(t, tt.wantState, status)
+			assert.Equal(t, tt.wantError, err)
+		})
+	}
+}
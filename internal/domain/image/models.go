@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -22,19 +23,197 @@ type Image struct {
 	Width            *int            `json:"width" db:"width"`
 	Height           *int            `json:"height" db:"height"`
 	UploadedAt       time.Time       `json:"uploaded_at" db:"uploaded_at"`
+	TakenAt          time.Time       `json:"taken_at" db:"taken_at"` // Capture time; the upload time when unknown
 	Metadata         json.RawMessage `json:"metadata" db:"metadata"`
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
 	Tags             []Tag           `json:"tags,omitempty"`
+	PendingTags      []string        `json:"pending_tags,omitempty"` // Requested tags awaiting approval (not persisted on the image)
+	Variants         []ImageVariant  `json:"variants,omitempty"`     // Responsive widths, narrowest first
+	Processing       []ProcessingJob `json:"processing,omitempty"`   // Latest job of each processing step
 }
 
 // Tag represents a tag that can be associated with images
 type Tag struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
+	ID           int       `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Description  string    `json:"description,omitempty" db:"description"`
+	Color        string    `json:"color,omitempty" db:"color"`
+	Category     string    `json:"category,omitempty" db:"category"`
+	IsPredefined bool      `json:"is_predefined,omitempty" db:"is_predefined"`
+	IsActive     bool      `json:"is_active,omitempty" db:"is_active"`
+	DisplayOrder int       `json:"display_order,omitempty" db:"display_order"`
+	ParentID     *int      `json:"parent_id,omitempty" db:"parent_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// TagNode is a tag positioned in the tag hierarchy
+type TagNode struct {
+	Tag      *Tag       `json:"tag"`
+	Path     string     `json:"path"` // Slash-separated names from the root, e.g. wildlife/birds/raptors
+	Children []*TagNode `json:"children,omitempty"`
+}
+
+// PendingTag is a tag requested by uploaders that awaits admin approval
+type PendingTag struct {
+	Name             string    `json:"name"`
+	ImageIDs         []int     `json:"image_ids"`
+	FirstRequestedAt time.Time `json:"first_requested_at"`
+	LastRequestedAt  time.Time `json:"last_requested_at"`
+}
+
+// TagPolicy controls which tags uploads and tag edits may apply
+type TagPolicy string
+
+// Tag policies
+const (
+	TagPolicyFree       TagPolicy = "free"       // Any valid tag is created on demand
+	TagPolicyPredefined TagPolicy = "predefined" // Only active predefined tags; unknown tags are rejected
+	TagPolicyApproval   TagPolicy = "approval"   // Only active predefined tags; unknown tags are queued for approval
+)
+
+// IsValid reports whether the policy is one of the known policies
+func (p TagPolicy) IsValid() bool {
+	switch p {
+	case TagPolicyFree, TagPolicyPredefined, TagPolicyApproval:
+		return true
+	}
+	return false
+}
+
+// Allows reports whether an existing tag may be applied under the policy.
+// An empty policy behaves like TagPolicyFree.
+func (p TagPolicy) Allows(tag *Tag) bool {
+	if p == TagPolicyFree || p == "" {
+		return true
+	}
+	return tag != nil && tag.IsPredefined && tag.IsActive
+}
+
+// CreatesTags reports whether unknown tags are created on demand
+func (p TagPolicy) CreatesTags() bool {
+	return p == TagPolicyFree || p == ""
+}
+
+// QueuesTags reports whether unknown tags are parked for approval instead of rejected
+func (p TagPolicy) QueuesTags() bool {
+	return p == TagPolicyApproval
+}
+
+// MetadataPolicy controls which embedded metadata is removed from uploads before they are stored
+type MetadataPolicy string
+
+// Metadata policies, from least to most strict
+const (
+	MetadataPolicyKeep     MetadataPolicy = "keep"      // Store files and capture details as uploaded
+	MetadataPolicyStripGPS MetadataPolicy = "strip_gps" // Remove GPS positions, keep camera details
+	MetadataPolicyStripAll MetadataPolicy = "strip_all" // Remove all EXIF but the orientation, XMP, IPTC and text metadata
+)
+
+// MetadataPolicyKey is the key under which the applied policy is recorded in Image.Metadata
+const MetadataPolicyKey = "metadata_policy"
+
+// IsValid reports whether the policy is one of the known policies
+func (p MetadataPolicy) IsValid() bool {
+	switch p {
+	case MetadataPolicyKeep, MetadataPolicyStripGPS, MetadataPolicyStripAll:
+		return true
+	}
+	return false
+}
+
+// Stricter returns whichever of the two policies removes more metadata.
+// An empty policy behaves like MetadataPolicyKeep.
+func (p MetadataPolicy) Stricter(other MetadataPolicy) MetadataPolicy {
+	if other.strictness() > p.strictness() {
+		return other
+	}
+	if p == "" {
+		return MetadataPolicyKeep
+	}
+	return p
+}
+
+func (p MetadataPolicy) strictness() int {
+	switch p {
+	case MetadataPolicyStripGPS:
+		return 1
+	case MetadataPolicyStripAll:
+		return 2
+	}
+	return 0
+}
+
+// Apply returns the capture details that may be kept in the database under the policy
+func (p MetadataPolicy) Apply(photo *PhotoMetadata) *PhotoMetadata {
+	switch {
+	case photo == nil || p == MetadataPolicyStripAll:
+		return nil
+	case p == MetadataPolicyStripGPS && photo.GPS != nil:
+		stripped := *photo
+		stripped.GPS = nil
+		return &stripped
+	}
+	return photo
+}
+
+// Enforceable reports whether the policy can be applied to files of the content type.
+// Metadata is stripped in place from JPEG, PNG, WebP and GIF; BMP has none and SVG
+// metadata is removed when the document is sanitized, but TIFF and HEIC files can only
+// be kept.
+func (p MetadataPolicy) Enforceable(contentType string) bool {
+	switch contentType {
+	case "image/tiff", "image/heic", "image/heif":
+		return p.strictness() == 0
+	}
+	return true
+}
+
+// TagAlias maps an alternative spelling such as "b&w" to a canonical tag
+type TagAlias struct {
+	Alias     string    `json:"alias" db:"alias"`
+	TagID     int       `json:"tag_id" db:"tag_id"`
+	TagName   string    `json:"tag_name,omitempty"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// PhotoMetadataKey is the key under which capture details are stored in Image.Metadata
+const PhotoMetadataKey = "photo"
+
+// PhotoMetadata holds the capture details read from an image's EXIF, XMP and IPTC data
+type PhotoMetadata struct {
+	CameraMake   string          `json:"camera_make,omitempty"`
+	CameraModel  string          `json:"camera_model,omitempty"`
+	Lens         string          `json:"lens,omitempty"`
+	ExposureTime string          `json:"exposure_time,omitempty"` // e.g. "1/250"
+	FNumber      float64         `json:"f_number,omitempty"`
+	ISO          int             `json:"iso,omitempty"`
+	FocalLength  float64         `json:"focal_length,omitempty"` // Millimetres
+	TakenAt      *time.Time      `json:"taken_at,omitempty"`
+	GPS          *GPSCoordinates `json:"gps,omitempty"`
+	Orientation  int             `json:"orientation,omitempty"` // EXIF orientation 1-8
+}
+
+// ContentHashMetadataKey is the key under which the hex SHA-256 of the stored file is kept
+// in Image.Metadata
+const ContentHashMetadataKey = "content_hash"
+
+// AnimationMetadataKey is the key under which animation details are stored in Image.Metadata
+const AnimationMetadataKey = "animation"
+
+// Animation describes the frames of an animated GIF or WebP
+type Animation struct {
+	FrameCount int `json:"frame_count"`
+	DurationMS int `json:"duration_ms"` // One loop, as encoded in the frame delays
+}
+
+// GPSCoordinates is a capture location in decimal degrees
+type GPSCoordinates struct {
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude,omitempty"` // Metres above sea level
+}
+
 // CreateImageRequest represents a request to create a new image
 type CreateImageRequest struct {
 	OriginalFilename string          `json:"original_filename" validate:"required,max=255"`
@@ -44,6 +223,18 @@ type CreateImageRequest struct {
 	Height           *int            `json:"height" validate:"omitempty,min=1,max=50000"`
 	Metadata         json.RawMessage `json:"metadata,omitempty"`
 	Tags             []string        `json:"tags,omitempty" validate:"max=20,dive,min=1,max=100"`
+	MetadataPolicy   MetadataPolicy  `json:"metadata_policy,omitempty"` // Can only tighten the server policy
+}
+
+// ImagePatch is a targeted update of an image's derived data. Fields left nil, and
+// metadata keys not listed, keep their stored value, so processing steps updating
+// different parts of the same image do not overwrite each other.
+type ImagePatch struct {
+	Width         *int
+	Height        *int
+	TakenAt       *time.Time
+	ThumbnailPath *string
+	Metadata      map[string]json.RawMessage // Keys to set; a nil value removes the key
 }
 
 // UpdateImageRequest represents a request to update an image
@@ -51,13 +242,142 @@ type UpdateImageRequest struct {
 	Tags []string `json:"tags" validate:"max=20,dive,min=1,max=100"`
 }
 
+// CreateTagRequest represents a request to create a new tag
+type CreateTagRequest struct {
+	Name         string `json:"name" validate:"required,max=100"`
+	Description  string `json:"description,omitempty"`
+	Color        string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Category     string `json:"category,omitempty" validate:"omitempty,max=50"`
+	IsPredefined bool   `json:"is_predefined"`
+	IsActive     *bool  `json:"is_active,omitempty"`
+	DisplayOrder int    `json:"display_order"`
+	ParentID     *int   `json:"parent_id,omitempty"`
+}
+
+// UpdateTagRequest represents a partial update of a tag; nil fields are left unchanged
+type UpdateTagRequest struct {
+	Name         *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Description  *string `json:"description,omitempty"`
+	Color        *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Category     *string `json:"category,omitempty" validate:"omitempty,max=50"`
+	IsPredefined *bool   `json:"is_predefined,omitempty"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+	DisplayOrder *int    `json:"display_order,omitempty"`
+}
+
 // ListImagesRequest represents a request to list images
 type ListImagesRequest struct {
 	Page     int      `json:"page" form:"page" validate:"min=1"`
 	PageSize int      `json:"page_size" form:"page_size" validate:"min=1,max=100"`
-	Tag      string   `json:"tag" form:"tag" validate:"omitempty,max=100"` // Single tag filter (deprecated, use Tags)
-	Tags     []string `json:"tags" form:"tags"`                            // Multiple tag filters
-	MatchAll bool     `json:"match_all" form:"match_all"`                  // true = AND logic, false = OR logic (default)
+	Tag      string   `json:"tag" form:"tag" validate:"omitempty,max=100"`       // Single tag filter (deprecated, use Tags)
+	Tags     []string `json:"tags" form:"tags"`                                  // Multiple tag filters
+	MatchAll bool     `json:"match_all" form:"match_all"`                        // true = AND logic, false = OR logic (default)
+	Camera   string   `json:"camera" form:"camera" validate:"omitempty,max=100"` // Substring of the camera make and model
+	Lens     string   `json:"lens" form:"lens" validate:"omitempty,max=100"`     // Substring of the lens name
+
+	SortBy      string     `json:"sort" form:"sort"`                 // SortByUploadedAt (default) or SortByTakenAt, newest first
+	TakenAfter  *time.Time `json:"taken_after" form:"taken_after"`   // Inclusive lower bound on the capture time
+	TakenBefore *time.Time `json:"taken_before" form:"taken_before"` // Exclusive upper bound on the capture time
+}
+
+// Image list orderings
+const (
+	SortByUploadedAt = "uploaded_at"
+	SortByTakenAt    = "taken_at"
+)
+
+// TimelineGranularity is the period length of timeline buckets
+type TimelineGranularity string
+
+// Timeline granularities
+const (
+	TimelineYear  TimelineGranularity = "year"
+	TimelineMonth TimelineGranularity = "month"
+	TimelineDay   TimelineGranularity = "day"
+)
+
+// IsValid reports whether the granularity is one of the known granularities
+func (g TimelineGranularity) IsValid() bool {
+	switch g {
+	case TimelineYear, TimelineMonth, TimelineDay:
+		return true
+	}
+	return false
+}
+
+// Label formats the start of a period, e.g. "2024", "2024-05" or "2024-05-17"
+func (g TimelineGranularity) Label(start time.Time) string {
+	switch g {
+	case TimelineYear:
+		return start.Format("2006")
+	case TimelineDay:
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01")
+}
+
+// End returns the exclusive end of the period starting at start
+func (g TimelineGranularity) End(start time.Time) time.Time {
+	switch g {
+	case TimelineYear:
+		return start.AddDate(1, 0, 0)
+	case TimelineDay:
+		return start.AddDate(0, 0, 1)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// TimelineBucket counts the images captured in one year, month or day
+type TimelineBucket struct {
+	Period string    `json:"period"` // Label of the period, e.g. "2024-05"
+	Start  time.Time `json:"start"`  // Inclusive start of the period in UTC
+	End    time.Time `json:"end"`    // Exclusive end of the period in UTC
+	Count  int       `json:"count"`
+}
+
+// Map clustering limits
+const (
+	MaxGeoZoom             = 22 // Deepest web map zoom level accepted for clustering
+	GeoClusterCellsPerTile = 4  // Grid cells per 256px map tile edge, i.e. one cluster per 64px
+)
+
+// GeoBounds is a map viewport in decimal degrees
+type GeoBounds struct {
+	MinLon float64 `json:"min_lon"`
+	MinLat float64 `json:"min_lat"`
+	MaxLon float64 `json:"max_lon"`
+	MaxLat float64 `json:"max_lat"`
+}
+
+// Validate checks that the box lies within valid coordinates and does not cross the antimeridian
+func (b GeoBounds) Validate() error {
+	for _, v := range []float64{b.MinLon, b.MinLat, b.MaxLon, b.MaxLat} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%w: bbox coordinates must be finite", ErrInvalidListFilter)
+		}
+	}
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLon < -180 || b.MaxLon > 180 {
+		return fmt.Errorf("%w: bbox must lie within -180,-90,180,90", ErrInvalidListFilter)
+	}
+	if b.MinLon > b.MaxLon || b.MinLat > b.MaxLat {
+		return fmt.Errorf("%w: bbox minimums must not exceed its maximums", ErrInvalidListFilter)
+	}
+	return nil
+}
+
+// GeoClusterCellSize returns the clustering grid cell edge in degrees for a web map zoom
+// level, so clusters stay roughly the same size on screen as the map zooms
+func GeoClusterCellSize(zoom int) float64 {
+	return 360 / math.Exp2(float64(zoom)) / GeoClusterCellsPerTile
+}
+
+// GeoCluster is one or more geotagged images close together at the requested zoom
+type GeoCluster struct {
+	Latitude  float64 `json:"latitude"`  // Mean position of the images
+	Longitude float64 `json:"longitude"` // Mean position of the images
+	Count     int     `json:"count"`
+	ImageID   int     `json:"image_id"` // Most recently captured image, used as the cluster cover
+	Filename  string  `json:"filename"` // Original filename of ImageID
 }
 
 // ListImagesResponse represents the response for listing images
@@ -71,17 +391,31 @@ type ListImagesResponse struct {
 
 // Domain errors
 var (
-	ErrInvalidImageData   = errors.New("invalid image data")
-	ErrInvalidContentType = errors.New("invalid content type")
-	ErrInvalidFileSize    = errors.New("invalid file size")
-	ErrInvalidFilename    = errors.New("invalid filename")
-	ErrInvalidDimensions  = errors.New("invalid image dimensions")
-	ErrInvalidTagName     = errors.New("invalid tag name")
-	ErrInvalidPagination  = errors.New("invalid pagination parameters")
-	ErrImageNotFound      = errors.New("image not found")
-	ErrTagNotFound        = errors.New("tag not found")
-	ErrDuplicateTag       = errors.New("duplicate tag")
-	ErrCacheUnavailable   = errors.New("cache service unavailable")
+	ErrInvalidImageData     = errors.New("invalid image data")
+	ErrInvalidContentType   = errors.New("invalid content type")
+	ErrInvalidFileSize      = errors.New("invalid file size")
+	ErrInvalidFilename      = errors.New("invalid filename")
+	ErrInvalidDimensions    = errors.New("invalid image dimensions")
+	ErrInvalidTagName       = errors.New("invalid tag name")
+	ErrInvalidPagination    = errors.New("invalid pagination parameters")
+	ErrImageNotFound        = errors.New("image not found")
+	ErrTagNotFound          = errors.New("tag not found")
+	ErrDuplicateTag         = errors.New("duplicate tag")
+	ErrTagLimitExceeded     = errors.New("tag limit exceeded")
+	ErrInvalidTagData       = errors.New("invalid tag data")
+	ErrAliasNotFound        = errors.New("tag alias not found")
+	ErrAliasConflict        = errors.New("tag alias conflicts with an existing tag or alias")
+	ErrTagNotAllowed        = errors.New("tag not allowed by tag policy")
+	ErrPendingTagNotFound   = errors.New("pending tag not found")
+	ErrInvalidListFilter    = errors.New("invalid list filter")
+	ErrInvalidRenderOptions = errors.New("invalid render options")
+	ErrCacheUnavailable     = errors.New("cache service unavailable")
+	ErrJobNotFound          = errors.New("processing job not found")
+	ErrJobLeaseLost         = errors.New("processing job lease lost")
+	ErrUploadNotFound       = errors.New("upload not found")
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+	ErrUploadLocked         = errors.New("upload is being written by another request")
+	ErrUploadTooLarge       = errors.New("upload exceeds its length or the size limit")
 )
 
 // Constants for validation
@@ -91,6 +425,7 @@ const (
 	MaxFilenameLen  = 255
 	MaxTagNameLen   = 100
 	MinTagNameLen   = 1
+	MaxCategoryLen  = 50
 	MaxTagsPerImage = 20
 	DefaultPageSize = 20
 	MaxPageSize     = 100
@@ -103,13 +438,22 @@ const (
 
 // Supported content types
 var SupportedContentTypes = map[string]bool{
-	"image/jpeg": true,
-	"image/jpg":  true,
-	"image/png":  true,
-	"image/gif":  true,
-	"image/webp": true,
+	"image/jpeg":   true,
+	"image/jpg":    true,
+	"image/png":    true,
+	"image/gif":    true,
+	"image/webp":   true,
+	"image/bmp":    true,
+	"image/tiff":   true,
+	"image/heic":   true,
+	"image/heif":   true,
+	ContentTypeSVG: true,
 }
 
+// ContentTypeSVG is the content type of vector images, which are sanitized at upload
+// and served as they are instead of being decoded and re-encoded
+const ContentTypeSVG = "image/svg+xml"
+
 // Business logic methods for Image
 
 // Validate validates the image data
@@ -207,6 +551,11 @@ func (i *Image) IsImage() bool {
 	return SupportedContentTypes[i.ContentType]
 }
 
+// IsVector returns true for SVG images, which have no pixels to scale or re-encode
+func (i *Image) IsVector() bool {
+	return i.ContentType == ContentTypeSVG
+}
+
 // GetAspectRatio returns the aspect ratio of the image if dimensions are available
 func (i *Image) GetAspectRatio() *float64 {
 	if i.Width != nil && i.Height != nil && *i.Height != 0 {
@@ -240,6 +589,164 @@ func (i *Image) HasTag(tagName string) bool {
 	return false
 }
 
+// Photo returns the capture details stored in the image metadata, or nil if there are none
+func (i *Image) Photo() *PhotoMetadata {
+	raw := i.metadataField(PhotoMetadataKey)
+	if raw == nil {
+		return nil
+	}
+	photo := &PhotoMetadata{}
+	if err := json.Unmarshal(raw, photo); err != nil || photo.IsEmpty() {
+		return nil
+	}
+	return photo
+}
+
+// SetPhoto stores capture details in the image metadata, keeping any other metadata keys
+func (i *Image) SetPhoto(photo *PhotoMetadata) error {
+	if photo.IsEmpty() {
+		return i.setMetadataField(PhotoMetadataKey, nil)
+	}
+	return i.setMetadataField(PhotoMetadataKey, photo)
+}
+
+// Animation returns the animation details stored in the image metadata, or nil for still images
+func (i *Image) Animation() *Animation {
+	raw := i.metadataField(AnimationMetadataKey)
+	if raw == nil {
+		return nil
+	}
+	animation := &Animation{}
+	if err := json.Unmarshal(raw, animation); err != nil || animation.FrameCount <= 1 {
+		return nil
+	}
+	return animation
+}
+
+// SetAnimation stores animation details in the image metadata; nil marks a still image
+func (i *Image) SetAnimation(animation *Animation) error {
+	if animation == nil || animation.FrameCount <= 1 {
+		return i.setMetadataField(AnimationMetadataKey, nil)
+	}
+	return i.setMetadataField(AnimationMetadataKey, animation)
+}
+
+// IsAnimated returns true for animated GIFs and WebPs
+func (i *Image) IsAnimated() bool {
+	return i.Animation() != nil
+}
+
+// ContentHash returns the hex SHA-256 of the stored file, or "" if it was never computed
+func (i *Image) ContentHash() string {
+	var hash string
+	if raw := i.metadataField(ContentHashMetadataKey); raw != nil {
+		_ = json.Unmarshal(raw, &hash)
+	}
+	return hash
+}
+
+// SetContentHash records the hex SHA-256 of the stored file in the image metadata
+func (i *Image) SetContentHash(hash string) error {
+	if hash == "" {
+		return i.setMetadataField(ContentHashMetadataKey, nil)
+	}
+	return i.setMetadataField(ContentHashMetadataKey, hash)
+}
+
+// MetadataPolicy returns the policy applied when the image was uploaded, or "" if none was recorded
+func (i *Image) MetadataPolicy() MetadataPolicy {
+	var policy MetadataPolicy
+	if raw := i.metadataField(MetadataPolicyKey); raw != nil {
+		_ = json.Unmarshal(raw, &policy)
+	}
+	return policy
+}
+
+// SetMetadataPolicy records the policy applied to the uploaded file in the image metadata
+func (i *Image) SetMetadataPolicy(policy MetadataPolicy) error {
+	if policy == "" {
+		return i.setMetadataField(MetadataPolicyKey, nil)
+	}
+	return i.setMetadataField(MetadataPolicyKey, policy)
+}
+
+// MetadataPatch returns the current value of the given metadata keys, nil for those
+// not set, for an ImagePatch writing them
+func (i *Image) MetadataPatch(keys ...string) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		fields[key] = i.metadataField(key)
+	}
+	return fields
+}
+
+// metadataField returns one key of the metadata JSON object, or nil if it is not set
+func (i *Image) metadataField(key string) json.RawMessage {
+	if len(i.Metadata) == 0 {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(i.Metadata, &fields); err != nil {
+		return nil
+	}
+	return fields[key]
+}
+
+// setMetadataField sets one key of the metadata JSON object, or removes it when value is nil
+func (i *Image) setMetadataField(key string, value interface{}) error {
+	if value == nil && len(i.Metadata) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if len(i.Metadata) > 0 {
+		if err := json.Unmarshal(i.Metadata, &fields); err != nil {
+			return fmt.Errorf("%w: metadata is not a JSON object", ErrInvalidImageData)
+		}
+		if fields == nil { // Metadata was JSON null
+			fields = make(map[string]json.RawMessage)
+		}
+	}
+
+	if value == nil {
+		delete(fields, key)
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s metadata: %w", key, err)
+		}
+		fields[key] = raw
+	}
+
+	metadata, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	i.Metadata = metadata
+	return nil
+}
+
+// Business logic methods for PhotoMetadata
+
+// IsEmpty reports whether no capture details are set
+func (p *PhotoMetadata) IsEmpty() bool {
+	return p == nil || (p.CameraMake == "" && p.CameraModel == "" && p.Lens == "" &&
+		p.ExposureTime == "" && p.FNumber == 0 && p.ISO == 0 && p.FocalLength == 0 &&
+		p.TakenAt == nil && p.GPS == nil && p.Orientation == 0)
+}
+
+// Camera returns the camera name, leaving out the make when the model already starts with it
+// (e.g. "Canon EOS R5" rather than "Canon Canon EOS R5")
+func (p *PhotoMetadata) Camera() string {
+	if p == nil {
+		return ""
+	}
+	if p.CameraMake == "" || strings.HasPrefix(strings.ToLower(p.CameraModel), strings.ToLower(p.CameraMake)) {
+		return p.CameraModel
+	}
+	return strings.TrimSpace(p.CameraMake + " " + p.CameraModel)
+}
+
 // Business logic methods for Tag
 
 // Validate validates the tag data
@@ -301,6 +808,164 @@ func (t *Tag) NormalizeName() {
 	t.Name = strings.TrimSpace(strings.ToLower(t.Name))
 }
 
+// NormalizeAlias normalizes an alias for lookup (lowercase, trimmed, single spaces).
+// Unlike tag names, aliases may contain any printable characters.
+func NormalizeAlias(alias string) string {
+	return strings.Join(strings.Fields(strings.ToLower(alias)), " ")
+}
+
+// NewTagAlias creates a validated alias pointing at the given tag
+func NewTagAlias(alias string, tagID int) (*TagAlias, error) {
+	a := &TagAlias{
+		Alias:     NormalizeAlias(alias),
+		TagID:     tagID,
+		CreatedAt: time.Now(),
+	}
+	if a.Alias == "" {
+		return nil, fmt.Errorf("%w: alias cannot be empty", ErrInvalidTagName)
+	}
+	if len(a.Alias) > MaxTagNameLen {
+		return nil, fmt.Errorf("%w: alias too long (max %d characters)", ErrInvalidTagName, MaxTagNameLen)
+	}
+	if !utf8.ValidString(a.Alias) {
+		return nil, fmt.Errorf("%w: alias must be valid UTF-8", ErrInvalidTagName)
+	}
+	if tagID <= 0 {
+		return nil, fmt.Errorf("%w: invalid tag ID", ErrInvalidTagData)
+	}
+	return a, nil
+}
+
+// Business logic methods for tag requests
+
+// ToTag builds a normalized, validated tag from the create request
+func (r *CreateTagRequest) ToTag() (*Tag, error) {
+	tag := &Tag{
+		Name:         r.Name,
+		Description:  strings.TrimSpace(r.Description),
+		Color:        strings.TrimSpace(r.Color),
+		Category:     strings.TrimSpace(r.Category),
+		IsPredefined: r.IsPredefined,
+		IsActive:     true,
+		DisplayOrder: r.DisplayOrder,
+		ParentID:     r.ParentID,
+		CreatedAt:    time.Now(),
+	}
+	if r.IsActive != nil {
+		tag.IsActive = *r.IsActive
+	}
+	tag.NormalizeName()
+
+	if err := tag.Validate(); err != nil {
+		return nil, err
+	}
+	if err := tag.validateAttributes(); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// ApplyTo updates tag with the non-nil fields of the request and validates the result
+func (r *UpdateTagRequest) ApplyTo(tag *Tag) error {
+	if r.Name != nil {
+		tag.Name = *r.Name
+		tag.NormalizeName()
+	}
+	if r.Description != nil {
+		tag.Description = strings.TrimSpace(*r.Description)
+	}
+	if r.Color != nil {
+		tag.Color = strings.TrimSpace(*r.Color)
+	}
+	if r.Category != nil {
+		tag.Category = strings.TrimSpace(*r.Category)
+	}
+	if r.IsPredefined != nil {
+		tag.IsPredefined = *r.IsPredefined
+	}
+	if r.IsActive != nil {
+		tag.IsActive = *r.IsActive
+	}
+	if r.DisplayOrder != nil {
+		tag.DisplayOrder = *r.DisplayOrder
+	}
+
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+	return tag.validateAttributes()
+}
+
+// validateAttributes checks the optional presentation fields of a tag
+func (t *Tag) validateAttributes() error {
+	if t.Color != "" && !isHexColor(t.Color) {
+		return fmt.Errorf("%w: color must be a hex value like #1A2B3C", ErrInvalidTagData)
+	}
+	if len(t.Category) > MaxCategoryLen {
+		return fmt.Errorf("%w: category too long (max %d characters)", ErrInvalidTagData, MaxCategoryLen)
+	}
+	if t.DisplayOrder < 0 {
+		return fmt.Errorf("%w: display order cannot be negative", ErrInvalidTagData)
+	}
+	if t.ParentID != nil && (*t.ParentID <= 0 || *t.ParentID == t.ID) {
+		return fmt.Errorf("%w: invalid parent tag", ErrInvalidTagData)
+	}
+	return nil
+}
+
+// BuildTagTree arranges a flat list of tags into a forest using their ParentID.
+// Tags whose parent is not in the list become roots; input order is kept among siblings.
+func BuildTagTree(tags []*Tag) []*TagNode {
+	nodes := make(map[int]*TagNode, len(tags))
+	for _, tag := range tags {
+		nodes[tag.ID] = &TagNode{Tag: tag}
+	}
+
+	var roots []*TagNode
+	for _, tag := range tags {
+		node := nodes[tag.ID]
+		if tag.ParentID != nil {
+			if parent, ok := nodes[*tag.ParentID]; ok && parent != node {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	// Assign paths top-down; visited guards against malformed (cyclic) input
+	visited := make(map[int]bool, len(tags))
+	var assign func(node *TagNode, prefix string)
+	assign = func(node *TagNode, prefix string) {
+		if visited[node.Tag.ID] {
+			return
+		}
+		visited[node.Tag.ID] = true
+		node.Path = prefix + node.Tag.Name
+		for _, child := range node.Children {
+			assign(child, node.Path+"/")
+		}
+	}
+	for _, root := range roots {
+		assign(root, "")
+	}
+
+	return roots
+}
+
+// isHexColor reports whether s is a #RRGGBB color
+func isHexColor(s string) bool {
+	if len(s) != 7 || s[0] != '#' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
+
 // Business logic methods for CreateImageRequest
 
 // Validate validates the create image request
@@ -323,6 +988,9 @@ func (r *CreateImageRequest) Validate() error {
 	if err := r.validateMetadata(); err != nil {
 		return err
 	}
+	if r.MetadataPolicy != "" && !r.MetadataPolicy.IsValid() {
+		return fmt.Errorf("%w: unknown metadata policy %q", ErrInvalidImageData, r.MetadataPolicy)
+	}
 	return nil
 }
 
@@ -436,6 +1104,12 @@ func (r *ListImagesRequest) Validate() error {
 			return fmt.Errorf("%w: tag name contains invalid UTF-8", ErrInvalidTagName)
 		}
 	}
+	if r.SortBy != "" && r.SortBy != SortByUploadedAt && r.SortBy != SortByTakenAt {
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidListFilter, r.SortBy)
+	}
+	if r.TakenAfter != nil && r.TakenBefore != nil && !r.TakenAfter.Before(*r.TakenBefore) {
+		return fmt.Errorf("%w: taken_after must be before taken_before", ErrInvalidListFilter)
+	}
 	return nil
 }
 
@@ -498,6 +1172,12 @@ func ValidateContentTypeFromExtension(contentType, filename string) error {
 		".png":  {"image/png"},
 		".gif":  {"image/gif"},
 		".webp": {"image/webp"},
+		".bmp":  {"image/bmp"},
+		".tif":  {"image/tiff"},
+		".tiff": {"image/tiff"},
+		".heic": {"image/heic", "image/heif"},
+		".heif": {"image/heif", "image/heic"},
+		".svg":  {ContentTypeSVG},
 	}
 
 	if expectedList, exists := expectedTypes[ext]; exists {
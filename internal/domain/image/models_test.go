@@ -2,6 +2,7 @@ package image
 
 import (
 	"encoding/json"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -571,6 +572,176 @@ func TestListImagesRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestCreateTagRequest_ToTag(t *testing.T) {
+	inactive := false
+	tests := []struct {
+		name          string
+		request       *CreateTagRequest
+		expectedName  string
+		expectActive  bool
+		expectedError error
+	}{
+		{
+			name:         "normalizes name and defaults to active",
+			request:      &CreateTagRequest{Name: "  Wildlife ", Color: "#1A2B3C", Category: "subject"},
+			expectedName: "wildlife",
+			expectActive: true,
+		},
+		{
+			name:         "explicitly inactive",
+			request:      &CreateTagRequest{Name: "archive", IsActive: &inactive},
+			expectedName: "archive",
+			expectActive: false,
+		},
+		{
+			name:          "invalid name",
+			request:       &CreateTagRequest{Name: "b&w"},
+			expectedError: ErrInvalidTagName,
+		},
+		{
+			name:          "invalid color",
+			request:       &CreateTagRequest{Name: "sunset", Color: "orange"},
+			expectedError: ErrInvalidTagData,
+		},
+		{
+			name:          "category too long",
+			request:       &CreateTagRequest{Name: "sunset", Category: strings.Repeat("c", MaxCategoryLen+1)},
+			expectedError: ErrInvalidTagData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := tt.request.ToTag()
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, tag.Name)
+			assert.Equal(t, tt.expectActive, tag.IsActive)
+		})
+	}
+}
+
+func TestUpdateTagRequest_ApplyTo(t *testing.T) {
+	newName := "Black-And-White"
+	newColor := "#000000"
+	badName := "black and white"
+
+	t.Run("applies only provided fields", func(t *testing.T) {
+		tag := &Tag{ID: 1, Name: "bw", Description: "monochrome", Category: "style", IsActive: true}
+		err := (&UpdateTagRequest{Name: &newName, Color: &newColor}).ApplyTo(tag)
+
+		require.NoError(t, err)
+		assert.Equal(t, "black-and-white", tag.Name)
+		assert.Equal(t, "#000000", tag.Color)
+		assert.Equal(t, "monochrome", tag.Description)
+		assert.Equal(t, "style", tag.Category)
+		assert.True(t, tag.IsActive)
+	})
+
+	t.Run("rejects invalid rename", func(t *testing.T) {
+		tag := &Tag{ID: 1, Name: "bw"}
+		err := (&UpdateTagRequest{Name: &badName}).ApplyTo(tag)
+
+		assert.ErrorIs(t, err, ErrInvalidTagName)
+	})
+}
+
+func TestBuildTagTree(t *testing.T) {
+	wildlife := &Tag{ID: 1, Name: "wildlife"}
+	birds := &Tag{ID: 2, Name: "birds", ParentID: intPtr(1)}
+	raptors := &Tag{ID: 3, Name: "raptors", ParentID: intPtr(2)}
+	urban := &Tag{ID: 4, Name: "urban"}
+	orphan := &Tag{ID: 5, Name: "orphan", ParentID: intPtr(99)}
+
+	roots := BuildTagTree([]*Tag{wildlife, birds, raptors, urban, orphan})
+
+	require.Len(t, roots, 3)
+	assert.Equal(t, "wildlife", roots[0].Path)
+	assert.Equal(t, "urban", roots[1].Path)
+	assert.Equal(t, "orphan", roots[2].Path, "tags with an unknown parent become roots")
+
+	require.Len(t, roots[0].Children, 1)
+	require.Len(t, roots[0].Children[0].Children, 1)
+	assert.Equal(t, "wildlife/birds/raptors", roots[0].Children[0].Children[0].Path)
+}
+
+func TestBuildTagTree_CycleDoesNotLoop(t *testing.T) {
+	a := &Tag{ID: 1, Name: "a", ParentID: intPtr(2)}
+	b := &Tag{ID: 2, Name: "b", ParentID: intPtr(1)}
+
+	roots := BuildTagTree([]*Tag{a, b})
+
+	assert.Empty(t, roots)
+}
+
+func TestTagPolicy(t *testing.T) {
+	curated := &Tag{Name: "landscape", IsPredefined: true, IsActive: true}
+	inactive := &Tag{Name: "film", IsPredefined: true}
+	adHoc := &Tag{Name: "my-cat", IsActive: true}
+
+	tests := []struct {
+		policy  TagPolicy
+		valid   bool
+		creates bool
+		queues  bool
+		allowed []*Tag
+		denied  []*Tag
+	}{
+		{"", false, true, false, []*Tag{curated, inactive, adHoc}, nil},
+		{TagPolicyFree, true, true, false, []*Tag{curated, inactive, adHoc}, nil},
+		{TagPolicyPredefined, true, false, false, []*Tag{curated}, []*Tag{inactive, adHoc, nil}},
+		{TagPolicyApproval, true, false, true, []*Tag{curated}, []*Tag{inactive, adHoc, nil}},
+		{TagPolicy("moderated"), false, false, false, []*Tag{curated}, []*Tag{adHoc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			assert.Equal(t, tt.valid, tt.policy.IsValid())
+			assert.Equal(t, tt.creates, tt.policy.CreatesTags())
+			assert.Equal(t, tt.queues, tt.policy.QueuesTags())
+			for _, tag := range tt.allowed {
+				assert.True(t, tt.policy.Allows(tag), "expected %s to be allowed", tag.Name)
+			}
+			for _, tag := range tt.denied {
+				assert.False(t, tt.policy.Allows(tag))
+			}
+		})
+	}
+}
+
+func TestNewTagAlias(t *testing.T) {
+	tests := []struct {
+		name        string
+		alias       string
+		tagID       int
+		expected    string
+		expectedErr error
+	}{
+		{"symbols allowed", "B&W", 3, "b&w", nil},
+		{"whitespace collapsed", "  Black   and White ", 3, "black and white", nil},
+		{"empty", "   ", 3, "", ErrInvalidTagName},
+		{"too long", strings.Repeat("a", MaxTagNameLen+1), 3, "", ErrInvalidTagName},
+		{"missing tag", "bw", 0, "", ErrInvalidTagData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alias, err := NewTagAlias(tt.alias, tt.tagID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, alias.Alias)
+			assert.Equal(t, tt.tagID, alias.TagID)
+		})
+	}
+}
+
 func TestListImagesRequest_SetDefaults(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -607,6 +778,178 @@ func TestListImagesRequest_SetDefaults(t *testing.T) {
 	}
 }
 
+func TestImage_Photo(t *testing.T) {
+	takenAt := time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)
+	altitude := 34.5
+	photo := &PhotoMetadata{
+		CameraMake:   "Canon",
+		CameraModel:  "Canon EOS R5",
+		ExposureTime: "1/250",
+		ISO:          400,
+		TakenAt:      &takenAt,
+		GPS:          &GPSCoordinates{Latitude: 52.5, Longitude: -13.41, Altitude: &altitude},
+	}
+
+	t.Run("round trip keeps other metadata keys", func(t *testing.T) {
+		img := &Image{Metadata: json.RawMessage(`{"source":"import"}`)}
+		require.NoError(t, img.SetPhoto(photo))
+
+		assert.Equal(t, photo, img.Photo())
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(img.Metadata, &fields))
+		assert.JSONEq(t, `"import"`, string(fields["source"]))
+	})
+
+	t.Run("empty photo leaves metadata untouched", func(t *testing.T) {
+		img := &Image{}
+		require.NoError(t, img.SetPhoto(&PhotoMetadata{}))
+		assert.Nil(t, img.Metadata)
+		assert.Nil(t, img.Photo())
+	})
+
+	t.Run("null metadata", func(t *testing.T) {
+		img := &Image{Metadata: json.RawMessage(`null`)}
+		require.NoError(t, img.SetPhoto(photo))
+		assert.Equal(t, "Canon EOS R5", img.Photo().CameraModel)
+	})
+
+	t.Run("non-object metadata", func(t *testing.T) {
+		img := &Image{Metadata: json.RawMessage(`[1,2]`)}
+		assert.ErrorIs(t, img.SetPhoto(photo), ErrInvalidImageData)
+		assert.Nil(t, img.Photo())
+	})
+}
+
+func TestImage_Animation(t *testing.T) {
+	img := &Image{Metadata: json.RawMessage(`{"source":"import"}`)}
+	assert.False(t, img.IsAnimated())
+
+	require.NoError(t, img.SetAnimation(&Animation{FrameCount: 12, DurationMS: 1200}))
+	assert.True(t, img.IsAnimated())
+	assert.Equal(t, &Animation{FrameCount: 12, DurationMS: 1200}, img.Animation())
+	assert.JSONEq(t, `{"source":"import","animation":{"frame_count":12,"duration_ms":1200}}`, string(img.Metadata))
+
+	require.NoError(t, img.SetAnimation(&Animation{FrameCount: 1}))
+	assert.Nil(t, img.Animation(), "a single frame is a still image")
+	assert.JSONEq(t, `{"source":"import"}`, string(img.Metadata))
+
+	info := &ImageInfo{FrameCount: 3, Duration: 450 * time.Millisecond}
+	assert.Equal(t, &Animation{FrameCount: 3, DurationMS: 450}, info.Animation())
+	assert.Nil(t, (&ImageInfo{FrameCount: 1}).Animation())
+}
+
+func TestImage_ContentHash(t *testing.T) {
+	img := &Image{Metadata: json.RawMessage(`{"source":"import"}`)}
+	assert.Empty(t, img.ContentHash())
+
+	require.NoError(t, img.SetContentHash("9f86d081"))
+	assert.Equal(t, "9f86d081", img.ContentHash())
+	assert.JSONEq(t, `{"source":"import","content_hash":"9f86d081"}`, string(img.Metadata))
+
+	require.NoError(t, img.SetContentHash(""))
+	assert.Empty(t, img.ContentHash())
+	assert.JSONEq(t, `{"source":"import"}`, string(img.Metadata))
+}
+
+func TestPhotoMetadata_Camera(t *testing.T) {
+	tests := []struct {
+		name     string
+		photo    *PhotoMetadata
+		expected string
+	}{
+		{"model repeats make", &PhotoMetadata{CameraMake: "Canon", CameraModel: "Canon EOS R5"}, "Canon EOS R5"},
+		{"model without make", &PhotoMetadata{CameraMake: "FUJIFILM", CameraModel: "X-T4"}, "FUJIFILM X-T4"},
+		{"make only", &PhotoMetadata{CameraMake: "Apple"}, "Apple"},
+		{"nothing", &PhotoMetadata{}, ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.photo.Camera())
+		})
+	}
+}
+
+func TestMetadataPolicy(t *testing.T) {
+	t.Run("Stricter", func(t *testing.T) {
+		assert.Equal(t, MetadataPolicyKeep, MetadataPolicy("").Stricter(""))
+		assert.Equal(t, MetadataPolicyStripGPS, MetadataPolicyKeep.Stricter(MetadataPolicyStripGPS))
+		assert.Equal(t, MetadataPolicyStripAll, MetadataPolicyStripAll.Stricter(MetadataPolicyStripGPS))
+		assert.Equal(t, MetadataPolicyStripGPS, MetadataPolicyStripGPS.Stricter(MetadataPolicyKeep), "requests cannot loosen the policy")
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		photo := &PhotoMetadata{CameraModel: "X-T4", GPS: &GPSCoordinates{Latitude: 1, Longitude: 2}}
+
+		assert.Same(t, photo, MetadataPolicyKeep.Apply(photo))
+		assert.Nil(t, MetadataPolicyStripAll.Apply(photo))
+
+		stripped := MetadataPolicyStripGPS.Apply(photo)
+		require.NotNil(t, stripped)
+		assert.Equal(t, "X-T4", stripped.CameraModel)
+		assert.Nil(t, stripped.GPS)
+		assert.NotNil(t, photo.GPS, "the input is not modified")
+	})
+
+	t.Run("recorded on the image", func(t *testing.T) {
+		img := &Image{Metadata: json.RawMessage(`{"source":"scanner"}`)}
+		assert.Equal(t, MetadataPolicy(""), img.MetadataPolicy())
+
+		require.NoError(t, img.SetMetadataPolicy(MetadataPolicyStripGPS))
+		assert.Equal(t, MetadataPolicyStripGPS, img.MetadataPolicy())
+		assert.JSONEq(t, `{"source":"scanner","metadata_policy":"strip_gps"}`, string(img.Metadata))
+	})
+
+	t.Run("validated on create", func(t *testing.T) {
+		req := &CreateImageRequest{OriginalFilename: "a.jpg", ContentType: "image/jpeg", FileSize: 1, MetadataPolicy: "shred"}
+		assert.ErrorIs(t, req.Validate(), ErrInvalidImageData)
+
+		req.MetadataPolicy = MetadataPolicyStripAll
+		assert.NoError(t, req.Validate())
+	})
+
+	t.Run("Enforceable", func(t *testing.T) {
+		assert.True(t, MetadataPolicyStripAll.Enforceable("image/jpeg"))
+		assert.True(t, MetadataPolicyStripGPS.Enforceable("image/gif"))
+		assert.True(t, MetadataPolicyStripGPS.Enforceable(ContentTypeSVG))
+		assert.True(t, MetadataPolicyKeep.Enforceable("image/heic"))
+		assert.False(t, MetadataPolicyStripGPS.Enforceable("image/heic"))
+		assert.False(t, MetadataPolicyStripAll.Enforceable("image/tiff"))
+	})
+}
+
+func TestGeoBounds_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  GeoBounds
+		wantErr bool
+	}{
+		{"whole world", GeoBounds{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}, false},
+		{"single point", GeoBounds{MinLon: 2.35, MinLat: 48.85, MaxLon: 2.35, MaxLat: 48.85}, false},
+		{"latitude out of range", GeoBounds{MinLon: 0, MinLat: -91, MaxLon: 1, MaxLat: 1}, true},
+		{"crosses the antimeridian", GeoBounds{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}, true},
+		{"not a number", GeoBounds{MinLon: math.NaN(), MinLat: 0, MaxLon: 1, MaxLat: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bounds.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidListFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGeoClusterCellSize(t *testing.T) {
+	assert.InDelta(t, 90.0, GeoClusterCellSize(0), 1e-9)
+	assert.InDelta(t, 45.0, GeoClusterCellSize(1), 1e-9)
+	assert.Less(t, GeoClusterCellSize(MaxGeoZoom), 1e-4, "the deepest zoom separates neighbouring buildings")
+}
+
 func TestListImagesRequest_GetOffset(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -720,7 +1063,12 @@ func TestValidateContentTypeFromExtension(t *testing.T) {
 		{"jpeg with jpeg", "image/jpeg", "test.jpeg", false},
 		{"png with png", "image/png", "test.png", false},
 		{"jpg with png - mismatch", "image/png", "test.jpg", true},
-		{"unsupported extension", "image/jpeg", "test.bmp", true},
+		{"unsupported extension", "image/jpeg", "test.pdf", true},
+		{"bmp with bmp", "image/bmp", "test.bmp", false},
+		{"tif with tiff", "image/tiff", "scan.tif", false},
+		{"heic with heif", "image/heif", "IMG_0001.HEIC", false},
+		{"svg with svg", ContentTypeSVG, "logo.svg", false},
+		{"svg with png - mismatch", "image/png", "logo.svg", true},
 		{"case insensitive extension", "image/jpeg", "test.JPG", false},
 		{"no extension", "image/jpeg", "test", true},
 	}
@@ -758,6 +1106,11 @@ func TestSupportedContentTypes(t *testing.T) {
 		"image/png",
 		"image/gif",
 		"image/webp",
+		"image/bmp",
+		"image/tiff",
+		"image/heic",
+		"image/heif",
+		"image/svg+xml",
 	}
 
 	for _, contentType := range expectedTypes {
@@ -767,7 +1120,7 @@ func TestSupportedContentTypes(t *testing.T) {
 	unsupportedTypes := []string{
 		"application/pdf",
 		"text/plain",
-		"image/bmp",
+		"image/x-icon",
 		"video/mp4",
 	}
 
@@ -788,6 +1141,14 @@ func TestDomainErrors(t *testing.T) {
 		ErrImageNotFound,
 		ErrTagNotFound,
 		ErrDuplicateTag,
+		ErrTagLimitExceeded,
+		ErrInvalidTagData,
+		ErrAliasNotFound,
+		ErrAliasConflict,
+		ErrTagNotAllowed,
+		ErrPendingTagNotFound,
+		ErrInvalidListFilter,
+		ErrInvalidRenderOptions,
 	}
 
 	for _, err := range errors {
@@ -0,0 +1,48 @@
+package image
+
+import "context"
+
+// UploadStage names how far an uploaded file has got on its way to becoming an image
+type UploadStage string
+
+const (
+	// UploadStageReceived means the file's bytes have reached the server
+	UploadStageReceived UploadStage = "received"
+	// UploadStageValidating means the file and its tags are being checked
+	UploadStageValidating UploadStage = "validating"
+	// UploadStageStoring means the file is being written to storage
+	UploadStageStoring UploadStage = "storing"
+	// UploadStageProcessing means the image is being recorded and its derivatives queued
+	UploadStageProcessing UploadStage = "processing"
+	// UploadStageDone means the image has been created
+	UploadStageDone UploadStage = "done"
+	// UploadStageError means the file was rejected or could not be stored
+	UploadStageError UploadStage = "error"
+)
+
+// UploadProgress reports the stage an uploaded file has reached. Events without a
+// file report the bytes received of the upload request as a whole.
+type UploadProgress struct {
+	File     *int        `json:"file,omitempty"` // Index of the file in the upload
+	Filename string      `json:"filename,omitempty"`
+	Stage    UploadStage `json:"stage"`
+	Bytes    int64       `json:"bytes,omitempty"` // Bytes received so far
+	Total    int64       `json:"total,omitempty"` // Bytes expected, when known
+	ImageID  int         `json:"image_id,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type uploadStageKey struct{}
+
+// WithUploadStageReporter returns a context whose image creation reports each stage it
+// reaches to report
+func WithUploadStageReporter(ctx context.Context, report func(UploadStage)) context.Context {
+	return context.WithValue(ctx, uploadStageKey{}, report)
+}
+
+// ReportUploadStage reports a stage to the context's reporter, if it has one
+func ReportUploadStage(ctx context.Context, stage UploadStage) {
+	if report, ok := ctx.Value(uploadStageKey{}).(func(UploadStage)); ok && report != nil {
+		report(stage)
+	}
+}
@@ -0,0 +1,182 @@
+package image
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderFit is how an image is fitted into the requested box
+type RenderFit string
+
+// Render fits
+const (
+	RenderFitCover   RenderFit = "cover"   // Fill the box, cropping the overflow around the center
+	RenderFitContain RenderFit = "contain" // Fit inside the box, keeping the whole image
+)
+
+// RenderFormat is the encoding of a rendered derivative
+type RenderFormat string
+
+// Render formats. AVIF is not offered: there is no pure-Go AVIF encoder.
+const (
+	RenderFormatJPEG RenderFormat = "jpeg"
+	RenderFormatPNG  RenderFormat = "png"
+	RenderFormatWebP RenderFormat = "webp" // Lossless
+)
+
+// NegotiableFormats returns the formats a derivative of an image of the content type may
+// be encoded in when the client's Accept header allows them. WebP is only encoded
+// lossless, several times the size of a JPEG for photos, so it is offered for PNG and
+// GIF sources alone; photographic sources stay JPEG.
+func NegotiableFormats(contentType string) []RenderFormat {
+	switch strings.ToLower(contentType) {
+	case "image/png", "image/gif":
+		return []RenderFormat{RenderFormatWebP}
+	}
+	return nil
+}
+
+// DefaultRenderQuality is the JPEG quality used when none is requested
+const DefaultRenderQuality = 80
+
+// DerivativePrefix is the storage prefix under which rendered derivatives are cached
+const DerivativePrefix = "derivatives/"
+
+// RenderOptions describes a derivative of an image: its box, fit, format and quality
+type RenderOptions struct {
+	Width   int          `json:"width"`
+	Height  int          `json:"height"`
+	Fit     RenderFit    `json:"fit"`
+	Format  RenderFormat `json:"format"`
+	Quality int          `json:"quality"` // JPEG only; ignored for PNG and WebP
+}
+
+// RenderPresets are the derivative sizes the frontend uses. Only these boxes can be
+// rendered, so clients cannot fill the derivative cache with arbitrary sizes.
+var RenderPresets = map[string]RenderOptions{
+	"thumb":    {Width: 200, Height: 200, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: DefaultRenderQuality},
+	"card":     {Width: 400, Height: 300, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: DefaultRenderQuality},
+	"lightbox": {Width: 1600, Height: 1600, Fit: RenderFitContain, Format: RenderFormatJPEG, Quality: 85},
+	"social":   {Width: 1200, Height: 630, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: 85},
+}
+
+// RenderQualities are the JPEG qualities a derivative may be encoded with
+var RenderQualities = []int{60, 70, 80, 85, 90}
+
+// Validate checks the options against the preset boxes and the allowed formats and qualities
+func (o RenderOptions) Validate() error {
+	if !o.matchesPreset() {
+		return fmt.Errorf("%w: %dx%d %s is not a render preset (allowed: %s)",
+			ErrInvalidRenderOptions, o.Width, o.Height, o.Fit, presetSizes())
+	}
+	switch o.Format {
+	case RenderFormatJPEG:
+		for _, q := range RenderQualities {
+			if o.Quality == q {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: quality must be one of %v", ErrInvalidRenderOptions, RenderQualities)
+	case RenderFormatPNG, RenderFormatWebP:
+		return nil
+	}
+	return fmt.Errorf("%w: format must be %s, %s or %s",
+		ErrInvalidRenderOptions, RenderFormatJPEG, RenderFormatPNG, RenderFormatWebP)
+}
+
+// matchesPreset reports whether the box and fit are those of a preset
+func (o RenderOptions) matchesPreset() bool {
+	for _, preset := range RenderPresets {
+		if o.Width == preset.Width && o.Height == preset.Height && o.Fit == preset.Fit {
+			return true
+		}
+	}
+	return false
+}
+
+// presetSizes lists the preset boxes for error messages, e.g. "400x300 cover"
+func presetSizes() string {
+	sizes := make([]string, 0, len(RenderPresets))
+	for _, preset := range RenderPresets {
+		sizes = append(sizes, fmt.Sprintf("%dx%d %s", preset.Width, preset.Height, preset.Fit))
+	}
+	sort.Strings(sizes)
+	return fmt.Sprint(sizes)
+}
+
+// ContentType returns the MIME type of the format
+func (f RenderFormat) ContentType() string {
+	switch f {
+	case RenderFormatPNG:
+		return "image/png"
+	case RenderFormatWebP:
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// extension returns the file extension of the format, without the dot
+func (f RenderFormat) extension() string {
+	switch f {
+	case RenderFormatPNG:
+		return "png"
+	case RenderFormatWebP:
+		return "webp"
+	}
+	return "jpg"
+}
+
+// ContentType returns the MIME type of the rendered derivative
+func (o RenderOptions) ContentType() string {
+	return o.Format.ContentType()
+}
+
+// StorageKey returns the deterministic storage path of the derivative of an image
+func (o RenderOptions) StorageKey(imageID int) string {
+	if o.Format == RenderFormatJPEG {
+		return fmt.Sprintf("%s%d/%dx%d-%s-q%d.jpg", DerivativePrefix, imageID, o.Width, o.Height, o.Fit, o.Quality)
+	}
+	return fmt.Sprintf("%s%d/%dx%d-%s.%s", DerivativePrefix, imageID, o.Width, o.Height, o.Fit, o.Format.extension())
+}
+
+// AnimatedThumbnailKey returns the storage path of the GIF thumbnail of an animated GIF
+func AnimatedThumbnailKey(imageID int) string {
+	return fmt.Sprintf("%s%d/thumb.gif", DerivativePrefix, imageID)
+}
+
+// DerivativeKeyPrefix returns the storage prefix holding every derivative of an image
+func DerivativeKeyPrefix(imageID int) string {
+	return fmt.Sprintf("%s%d/", DerivativePrefix, imageID)
+}
+
+// DefaultVariantWidths are the widths of the responsive variants generated at upload
+var DefaultVariantWidths = []int{320, 640, 1280, 2048}
+
+// ImageVariant is a resized copy of an image generated at upload for responsive srcsets
+type ImageVariant struct {
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	Format      RenderFormat `json:"format"`
+	FileSize    int64        `json:"file_size"`
+	StoragePath string       `json:"storage_path"`
+}
+
+// RenderedVariant is an encoded responsive variant that has not been stored yet
+type RenderedVariant struct {
+	Width  int
+	Height int
+	Format RenderFormat
+	Data   []byte
+}
+
+// ContentType returns the MIME type of the variant
+func (v ImageVariant) ContentType() string {
+	return v.Format.ContentType()
+}
+
+// VariantStorageKey returns the storage path of an image's responsive variant. Variants
+// share the derivative prefix, so deleting an image's derivatives removes them too.
+func VariantStorageKey(imageID, width int, format RenderFormat) string {
+	return fmt.Sprintf("%s%d/w%d.%s", DerivativePrefix, imageID, width, format.extension())
+}
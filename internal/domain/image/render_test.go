@@ -0,0 +1,66 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RenderOptions
+		wantErr bool
+	}{
+		{"card preset", RenderPresets["card"], false},
+		{"preset box as PNG", RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: RenderFormatPNG}, false},
+		{"preset box as WebP", RenderOptions{Width: 200, Height: 200, Fit: RenderFitCover, Format: RenderFormatWebP, Quality: 80}, false},
+		{"preset box with another quality", RenderOptions{Width: 1200, Height: 630, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: 60}, false},
+		{"arbitrary size", RenderOptions{Width: 401, Height: 300, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: 80}, true},
+		{"preset size with another fit", RenderOptions{Width: 400, Height: 300, Fit: RenderFitContain, Format: RenderFormatJPEG, Quality: 80}, true},
+		{"quality outside the allowlist", RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: RenderFormatJPEG, Quality: 81}, true},
+		{"unknown format", RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: "bmp", Quality: 80}, true},
+		{"avif is not encoded", RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: "avif", Quality: 80}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRenderOptions)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRenderOptions_StorageKey(t *testing.T) {
+	assert.Equal(t, "derivatives/42/400x300-cover-q80.jpg", RenderPresets["card"].StorageKey(42))
+
+	png := RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: RenderFormatPNG, Quality: 90}
+	assert.Equal(t, "derivatives/42/400x300-cover.png", png.StorageKey(42), "quality does not apply to PNG")
+	assert.Equal(t, "image/png", png.ContentType())
+
+	webp := RenderOptions{Width: 400, Height: 300, Fit: RenderFitCover, Format: RenderFormatWebP, Quality: 80}
+	assert.Equal(t, "derivatives/42/400x300-cover.webp", webp.StorageKey(42))
+	assert.Equal(t, "image/webp", webp.ContentType())
+
+	assert.Contains(t, RenderPresets["card"].StorageKey(42), DerivativeKeyPrefix(42))
+}
+
+func TestNegotiableFormats(t *testing.T) {
+	assert.Equal(t, []RenderFormat{RenderFormatWebP}, NegotiableFormats("image/png"))
+	assert.Equal(t, []RenderFormat{RenderFormatWebP}, NegotiableFormats("image/GIF"))
+	assert.Empty(t, NegotiableFormats("image/jpeg"), "lossless WebP would be several times the JPEG")
+	assert.Empty(t, NegotiableFormats("image/heic"))
+	assert.Empty(t, NegotiableFormats("image/webp"))
+}
+
+func TestVariantStorageKey(t *testing.T) {
+	assert.Equal(t, "derivatives/42/w640.jpg", VariantStorageKey(42, 640, RenderFormatJPEG))
+	assert.Equal(t, "derivatives/42/w640.png", VariantStorageKey(42, 640, RenderFormatPNG))
+	assert.Contains(t, VariantStorageKey(42, 320, RenderFormatJPEG), DerivativeKeyPrefix(42),
+		"variants are deleted with the image's derivatives")
+	assert.Equal(t, "image/png", ImageVariant{Format: RenderFormatPNG}.ContentType())
+}
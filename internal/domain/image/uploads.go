@@ -0,0 +1,163 @@
+package image
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// UploadPrefix is the storage prefix under which resumable uploads are assembled
+// before they are handed off to CreateImage
+const UploadPrefix = "uploads/"
+
+// ResumableUpload is an image uploaded in chunks, which can continue from its offset
+// after the connection drops
+type ResumableUpload struct {
+	ID             string         `json:"id"`
+	Length         int64          `json:"length"` // Size of the whole file
+	Offset         int64          `json:"offset"` // Bytes received so far
+	Filename       string         `json:"filename"`
+	ContentType    string         `json:"content_type"`
+	Tags           []string       `json:"tags,omitempty"`
+	MetadataPolicy MetadataPolicy `json:"metadata_policy,omitempty"`
+	ImageID        *int           `json:"image_id,omitempty"` // Set once the upload has been handed off
+	ExpiresAt      time.Time      `json:"expires_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// IsComplete reports whether every byte of the upload has been received
+func (u *ResumableUpload) IsComplete() bool {
+	return u.Offset == u.Length
+}
+
+// CreateUploadRequest starts a resumable upload
+type CreateUploadRequest struct {
+	Length         int64
+	Filename       string
+	ContentType    string
+	Tags           []string
+	MetadataPolicy MetadataPolicy // Can only tighten the server policy
+}
+
+// Validate checks the upload would make a valid CreateImageRequest once complete
+func (r *CreateUploadRequest) Validate() error {
+	req := CreateImageRequest{
+		OriginalFilename: r.Filename,
+		ContentType:      r.ContentType,
+		FileSize:         r.Length,
+		Tags:             r.Tags,
+		MetadataPolicy:   r.MetadataPolicy,
+	}
+	return req.Validate()
+}
+
+// ResumableUploadService receives images in chunks and hands each one off to
+// ImageService.CreateImage once it is complete
+type ResumableUploadService interface {
+	// CreateUpload starts an upload of the given length
+	CreateUpload(ctx context.Context, req *CreateUploadRequest) (*ResumableUpload, error)
+
+	// GetUpload returns an upload that has not expired
+	GetUpload(ctx context.Context, id string) (*ResumableUpload, error)
+
+	// WriteChunk appends data to an upload at offset, which must be the upload's current
+	// offset. The upload is handed off when its last byte arrives, and the returned
+	// upload then carries the new image's ID.
+	WriteChunk(ctx context.Context, id string, offset int64, data io.Reader) (*ResumableUpload, error)
+
+	// TerminateUpload stops an upload and removes what was received
+	TerminateUpload(ctx context.Context, id string) error
+
+	// MaxSize returns the largest upload accepted
+	MaxSize() int64
+}
+
+// UploadPart is a part of a multipart upload stored so far
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// MultipartStorage stores a file as parts uploaded one after the other, joined into
+// one file once the last has been uploaded
+type MultipartStorage interface {
+	// CreateMultipartUpload starts a multipart upload to path and returns its ID
+	CreateMultipartUpload(ctx context.Context, path string, contentType string) (string, error)
+
+	// UploadPart stores a part and returns its ETag. Every part but the last must be at
+	// least MinPartSize bytes.
+	UploadPart(ctx context.Context, path string, uploadID string, number int, data io.Reader, size int64) (string, error)
+
+	// CompleteMultipartUpload joins the parts into the file at path
+	CompleteMultipartUpload(ctx context.Context, path string, uploadID string, parts []UploadPart) error
+
+	// AbortMultipartUpload discards a multipart upload and its parts
+	AbortMultipartUpload(ctx context.Context, path string, uploadID string) error
+}
+
+// MinPartSize is the smallest part S3 accepts in a multipart upload, the last excepted
+const MinPartSize = 5 << 20
+
+// DirectUploadPrefix is the storage prefix browsers upload to with a presigned POST
+// policy, from which CompleteUpload registers the file as an image
+const DirectUploadPrefix = UploadPrefix + "direct/"
+
+// PresignUploadRequest asks for a policy to upload one image straight to storage
+type PresignUploadRequest struct {
+	Filename       string         `json:"filename"`
+	ContentType    string         `json:"content_type"`
+	Tags           []string       `json:"tags,omitempty"`
+	MetadataPolicy MetadataPolicy `json:"metadata_policy,omitempty"` // Can only tighten the server policy
+}
+
+// Validate checks the upload would make a valid CreateImageRequest, whatever its size
+func (r *PresignUploadRequest) Validate() error {
+	req := CreateImageRequest{
+		OriginalFilename: r.Filename,
+		ContentType:      r.ContentType,
+		FileSize:         MinFileSize,
+		Tags:             r.Tags,
+		MetadataPolicy:   r.MetadataPolicy,
+	}
+	return req.Validate()
+}
+
+// PresignedUpload is a policy letting a browser POST one file to storage. The file
+// goes in a multipart/form-data request to URL carrying every field of Fields
+// followed by the file itself.
+type PresignedUpload struct {
+	Key       string            `json:"key"` // Passed to CompleteUpload once the file is stored
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	MaxSize   int64             `json:"max_size"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// DirectUploadService hands out policies to upload images straight to storage, and
+// registers the files uploaded with them
+type DirectUploadService interface {
+	// PresignUpload returns a policy to upload one image under a new key
+	PresignUpload(ctx context.Context, req *PresignUploadRequest) (*PresignedUpload, error)
+
+	// CompleteUpload checks the file stored under key is the image it was presigned
+	// for and hands it off to ImageService.CreateImage
+	CompleteUpload(ctx context.Context, key string) (*Image, error)
+}
+
+// PostPolicy restricts what may be uploaded with a presigned POST
+type PostPolicy struct {
+	Key         string // The only key it uploads to
+	ContentType string
+	MinSize     int64
+	MaxSize     int64
+	Expires     time.Time
+	Metadata    map[string]string // Stored with the file, as FileInfo.Metadata
+}
+
+// PresignedPostStorage signs policies for browsers to upload to storage directly
+type PresignedPostStorage interface {
+	// PresignPost returns the URL to POST to and the form fields to send with the file
+	PresignPost(ctx context.Context, policy *PostPolicy) (string, map[string]string, error)
+}
@@ -3,13 +3,25 @@ package settings
 import (
 	"fmt"
 	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Text classes chosen by TextClassForColor. Pure white and black guarantee that
+// one of them reaches at least 4.58:1 against any background (WCAG AA is 4.5:1).
+const (
+	lightTextClass = "text-white"
+	darkTextClass  = "text-black"
+	lightTextColor = "#FFFFFF"
+	darkTextColor  = "#000000"
 )
 
 // TagColorPalette is a curated set of accessible, distinguishable colors
 // chosen from TailwindCSS color palette with WCAG AA contrast compliance
 var TagColorPalette = []string{
 	"#3B82F6", // blue-500
-	"#10B981", // green-500
+	"#22C55E", // green-500
 	"#F59E0B", // amber-500
 	"#EF4444", // red-500
 	"#8B5CF6", // violet-500
@@ -22,19 +34,21 @@ var TagColorPalette = []string{
 	"#10B981", // emerald-500
 }
 
-// TagColorPaletteClasses maps hex colors to TailwindCSS background classes
+// TagColorPaletteClasses maps hex colors to TailwindCSS background classes.
+// The text class is derived from the color by TextClassForColor.
 var TagColorPaletteClasses = map[string]string{
-	"#3B82F6": "bg-blue-500 text-white",
-	"#10B981": "bg-green-500 text-white",
-	"#F59E0B": "bg-amber-500 text-white",
-	"#EF4444": "bg-red-500 text-white",
-	"#8B5CF6": "bg-violet-500 text-white",
-	"#EC4899": "bg-pink-500 text-white",
-	"#06B6D4": "bg-cyan-500 text-white",
-	"#F97316": "bg-orange-500 text-white",
-	"#14B8A6": "bg-teal-500 text-white",
-	"#A855F7": "bg-purple-500 text-white",
-	"#6366F1": "bg-indigo-500 text-white",
+	"#3B82F6": "bg-blue-500",
+	"#22C55E": "bg-green-500",
+	"#F59E0B": "bg-amber-500",
+	"#EF4444": "bg-red-500",
+	"#8B5CF6": "bg-violet-500",
+	"#EC4899": "bg-pink-500",
+	"#06B6D4": "bg-cyan-500",
+	"#F97316": "bg-orange-500",
+	"#14B8A6": "bg-teal-500",
+	"#A855F7": "bg-purple-500",
+	"#6366F1": "bg-indigo-500",
+	"#10B981": "bg-emerald-500",
 }
 
 // GetTagColor returns a consistent color for a given tag name using FNV-1a hashing
@@ -54,28 +68,126 @@ func GetTagColor(tagName string) string {
 	return TagColorPalette[paletteIndex]
 }
 
+// ResolveTagColor returns the color stored on a tag when it is a valid #RRGGBB value,
+// falling back to the hashed palette color for the tag name
+func ResolveTagColor(tagName, storedColor string) string {
+	if IsValidHexColor(storedColor) {
+		return strings.ToUpper(storedColor)
+	}
+	return GetTagColor(tagName)
+}
+
 // GetTagColorClass returns TailwindCSS classes for a tag based on its color
 func GetTagColorClass(tagName string) string {
 	color := GetTagColor(tagName)
 	if class, exists := TagColorPaletteClasses[color]; exists {
-		return class
+		return class + " " + TextClassForColor(color)
 	}
 	// Fallback to blue if color not in class map
-	return "bg-blue-500 text-white"
+	return "bg-blue-500 " + TextClassForColor(TagColorPalette[0])
 }
 
 // GetTagStyle returns inline style with background color for a tag
 // Useful for HTML rendering where Tailwind classes aren't available
 func GetTagStyle(tagName string) string {
 	color := GetTagColor(tagName)
-	return fmt.Sprintf("background-color: %s; color: white;", color)
+	return fmt.Sprintf("background-color: %s; color: %s;", color, textColorFor(color))
+}
+
+// GetTagBadgeStyle returns the classes and inline style for a tag badge.
+// Tags with a stored color are drawn in that color with a contrasting text class;
+// all other tags use the light palette classes derived from the name hash.
+func GetTagBadgeStyle(tagName, storedColor string) (class, style string) {
+	if !IsValidHexColor(storedColor) {
+		return GetLightTagColorClass(tagName), ""
+	}
+	color := strings.ToUpper(storedColor)
+	return TextClassForColor(color), fmt.Sprintf("background-color: %s;", color)
+}
+
+// TextClassForColor returns the text class (white or black) with the higher
+// WCAG 2.x contrast ratio against the given background color
+func TextClassForColor(hexColor string) string {
+	if textColorFor(hexColor) == lightTextColor {
+		return lightTextClass
+	}
+	return darkTextClass
+}
+
+// textColorFor returns the text color with the higher contrast against the background
+func textColorFor(hexColor string) string {
+	light, err := ContrastRatio(hexColor, lightTextColor)
+	if err != nil {
+		return lightTextColor
+	}
+	dark, _ := ContrastRatio(hexColor, darkTextColor) //nolint:errcheck // Same color parsed above
+	if dark > light {
+		return darkTextColor
+	}
+	return lightTextColor
+}
+
+// ContrastRatio returns the WCAG 2.x contrast ratio (1 to 21) between two #RRGGBB colors
+func ContrastRatio(a, b string) (float64, error) {
+	la, err := relativeLuminance(a)
+	if err != nil {
+		return 0, err
+	}
+	lb, err := relativeLuminance(b)
+	if err != nil {
+		return 0, err
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), nil
+}
+
+// IsValidHexColor reports whether s is a #RRGGBB color
+func IsValidHexColor(s string) bool {
+	_, err := parseHexColor(s)
+	return err == nil
+}
+
+// relativeLuminance computes the WCAG relative luminance of a #RRGGBB color
+func relativeLuminance(hexColor string) (float64, error) {
+	rgb, err := parseHexColor(hexColor)
+	if err != nil {
+		return 0, err
+	}
+
+	channel := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(rgb[0]) + 0.7152*channel(rgb[1]) + 0.0722*channel(rgb[2]), nil
+}
+
+// parseHexColor parses a #RRGGBB color into its red, green and blue components
+func parseHexColor(s string) ([3]uint8, error) {
+	var rgb [3]uint8
+	if len(s) != 7 || s[0] != '#' {
+		return rgb, fmt.Errorf("invalid hex color %q: expected #RRGGBB", s)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(s[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("invalid hex color %q: %w", s, err)
+		}
+		rgb[i] = uint8(v)
+	}
+	return rgb, nil
 }
 
 // GetLightTagColorClass returns a lighter version of the tag color for non-selected states
 // Uses Tailwind's 100-weight colors for backgrounds with darker text
 var LightTagColorClasses = map[string]string{
 	"#3B82F6": "bg-blue-100 text-blue-800",
-	"#10B981": "bg-green-100 text-green-800",
+	"#22C55E": "bg-green-100 text-green-800",
 	"#F59E0B": "bg-amber-100 text-amber-800",
 	"#EF4444": "bg-red-100 text-red-800",
 	"#8B5CF6": "bg-violet-100 text-violet-800",
@@ -85,6 +197,7 @@ var LightTagColorClasses = map[string]string{
 	"#14B8A6": "bg-teal-100 text-teal-800",
 	"#A855F7": "bg-purple-100 text-purple-800",
 	"#6366F1": "bg-indigo-100 text-indigo-800",
+	"#10B981": "bg-emerald-100 text-emerald-800",
 }
 
 // GetLightTagColorClass returns light background classes for tag display
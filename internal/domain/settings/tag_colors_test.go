@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagColorPalette_Distinct(t *testing.T) {
+	seen := make(map[string]bool, len(TagColorPalette))
+	for _, color := range TagColorPalette {
+		assert.False(t, seen[color], "duplicate palette color %s", color)
+		seen[color] = true
+
+		assert.Contains(t, TagColorPaletteClasses, color)
+		assert.Contains(t, LightTagColorClasses, color)
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	ratio, err := ContrastRatio("#FFFFFF", "#000000")
+	require.NoError(t, err)
+	assert.InDelta(t, 21.0, ratio, 0.01)
+
+	ratio, err = ContrastRatio("#777777", "#777777")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, ratio, 0.01)
+
+	_, err = ContrastRatio("blue", "#000000")
+	assert.Error(t, err)
+}
+
+func TestTextClassForColor(t *testing.T) {
+	tests := []struct {
+		color    string
+		expected string
+	}{
+		{"#000000", "text-white"},
+		{"#1E3A8A", "text-white"}, // blue-900
+		{"#FFFFFF", "text-black"},
+		{"#F59E0B", "text-black"}, // amber-500
+		{"#FDE68A", "text-black"}, // amber-200
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TextClassForColor(tt.color))
+		})
+	}
+}
+
+func TestTextClassForColor_MeetsWCAGAA(t *testing.T) {
+	for _, color := range append([]string{"#808080", "#EF4444", "#3B82F6"}, TagColorPalette...) {
+		text := lightTextColor
+		if TextClassForColor(color) == darkTextClass {
+			text = darkTextColor
+		}
+		ratio, err := ContrastRatio(color, text)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, ratio, 4.5, "contrast for %s", color)
+	}
+}
+
+func TestResolveTagColor(t *testing.T) {
+	assert.Equal(t, "#ABCDEF", ResolveTagColor("sunset", "#abcdef"))
+	assert.Equal(t, GetTagColor("sunset"), ResolveTagColor("sunset", ""))
+	assert.Equal(t, GetTagColor("sunset"), ResolveTagColor("sunset", "#12345"))
+}
+
+func TestGetTagBadgeStyle(t *testing.T) {
+	class, style := GetTagBadgeStyle("sunset", "#1E3A8A")
+	assert.Equal(t, "text-white", class)
+	assert.Equal(t, "background-color: #1E3A8A;", style)
+
+	class, style = GetTagBadgeStyle("sunset", "")
+	assert.Equal(t, GetLightTagColorClass("sunset"), class)
+	assert.Empty(t, style)
+}
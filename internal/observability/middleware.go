@@ -90,6 +90,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap returns the wrapped writer, so http.ResponseController can flush streamed
+// responses and change their deadlines
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // MetricsMiddleware returns a middleware that records HTTP metrics
 func MetricsMiddleware(metrics *HTTPMetrics) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
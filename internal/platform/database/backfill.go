@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// backfillCondition selects the images missing derived data that uploads record:
+// dimensions, which SVGs do not have, and the content hash kept in the metadata
+const backfillCondition = `
+	id > $1 AND (
+		((width IS NULL OR height IS NULL) AND content_type <> 'image/svg+xml')
+		OR NOT (COALESCE(metadata, '{}'::jsonb) ? 'content_hash')
+	)`
+
+// ListBackfillCandidates returns the IDs of up to limit images missing derived data
+// with IDs above afterID, in ID order
+func (r *imageRepository) ListBackfillCandidates(ctx context.Context, afterID int, limit int) ([]int, error) {
+	query := `SELECT id FROM images WHERE ` + backfillCondition + ` ORDER BY id LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CountBackfillCandidates counts the images missing derived data with IDs above afterID
+func (r *imageRepository) CountBackfillCandidates(ctx context.Context, afterID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM images WHERE `+backfillCondition, afterID).Scan(&count)
+	return count, err
+}
+
+// GetBackfillCheckpoint returns the checkpoint of a backfill job, or a checkpoint at the
+// start for a job that has not run yet
+func (r *imageRepository) GetBackfillCheckpoint(ctx context.Context, job string) (*BackfillCheckpoint, error) {
+	query := `
+		SELECT job, last_image_id, processed, failed, started_at, updated_at, completed_at
+		FROM backfill_checkpoints
+		WHERE job = $1
+	`
+
+	checkpoint := &BackfillCheckpoint{}
+	err := r.db.QueryRowContext(ctx, query, job).Scan(
+		&checkpoint.Job,
+		&checkpoint.LastImageID,
+		&checkpoint.Processed,
+		&checkpoint.Failed,
+		&checkpoint.StartedAt,
+		&checkpoint.UpdatedAt,
+		&checkpoint.CompletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &BackfillCheckpoint{Job: job}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// SaveBackfillCheckpoint records the progress of a backfill job
+func (r *imageRepository) SaveBackfillCheckpoint(ctx context.Context, checkpoint *BackfillCheckpoint) error {
+	query := `
+		INSERT INTO backfill_checkpoints (job, last_image_id, processed, failed, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, NOW()), $6)
+		ON CONFLICT (job) DO UPDATE
+		SET last_image_id = EXCLUDED.last_image_id, processed = EXCLUDED.processed,
+			failed = EXCLUDED.failed, started_at = EXCLUDED.started_at,
+			completed_at = EXCLUDED.completed_at, updated_at = NOW()
+		RETURNING started_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		checkpoint.Job,
+		checkpoint.LastImageID,
+		checkpoint.Processed,
+		checkpoint.Failed,
+		nullTime(checkpoint.StartedAt),
+		checkpoint.CompletedAt,
+	).Scan(&checkpoint.StartedAt, &checkpoint.UpdatedAt)
+}
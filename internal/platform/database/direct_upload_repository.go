@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// directUploadRepository implements DirectUploadRepository interface
+type directUploadRepository struct {
+	db *sql.DB
+}
+
+// NewDirectUploadRepository creates a new DirectUploadRepository
+func NewDirectUploadRepository(db *sql.DB) DirectUploadRepository {
+	return &directUploadRepository{db: db}
+}
+
+// Claim takes a direct upload for the calling request to complete. It returns
+// ErrUploadClaimed while another request holds the claim, until its lease runs
+// out, and once the upload's image has been created.
+func (r *directUploadRepository) Claim(ctx context.Context, key string, lease time.Duration) error {
+	query := `
+		INSERT INTO direct_upload_claims (upload_key)
+		VALUES ($1)
+		ON CONFLICT (upload_key) DO UPDATE SET claimed_at = NOW()
+		WHERE direct_upload_claims.image_id IS NULL
+			AND direct_upload_claims.claimed_at < NOW() - make_interval(secs => $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, key, lease.Seconds())
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUploadClaimed
+	}
+	return nil
+}
+
+// Complete records the image created from a claimed upload, keeping the claim for good
+func (r *directUploadRepository) Complete(ctx context.Context, key string, imageID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE direct_upload_claims SET image_id = $2 WHERE upload_key = $1`, key, imageID)
+	return err
+}
+
+// Release removes a claim, so the upload can be claimed again
+func (r *directUploadRepository) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM direct_upload_claims WHERE upload_key = $1`, key)
+	return err
+}
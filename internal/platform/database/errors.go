@@ -5,4 +5,14 @@ import "errors"
 var (
 	ErrMissingDatabaseURL = errors.New("database URL is required")
 	ErrMigrationFailed    = errors.New("migration failed")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagCycle           = errors.New("tag hierarchy would contain a cycle")
+	ErrAliasNotFound      = errors.New("tag alias not found")
+	ErrAliasConflict      = errors.New("tag alias already in use")
+	ErrPendingTagNotFound = errors.New("pending tag not found")
+	ErrJobNotFound        = errors.New("processing job not found")
+	ErrJobLeaseLost       = errors.New("processing job lease lost")
+	ErrUploadNotFound     = errors.New("resumable upload not found")
+	ErrUploadLocked       = errors.New("resumable upload is being written")
+	ErrUploadClaimed      = errors.New("direct upload is being completed")
 )
@@ -3,8 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
@@ -13,6 +15,10 @@ import (
 // imageRepository implements ImageRepository interface
 type imageRepository struct {
 	db *sql.DB
+
+	postgisMu      sync.Mutex
+	postgisChecked bool
+	postgis        bool // PostGIS is installed, so geo queries can use its spatial index
 }
 
 // NewImageRepository creates a new ImageRepository
@@ -25,9 +31,9 @@ func (r *imageRepository) Create(ctx context.Context, image *Image) error {
 	query := `
 		INSERT INTO images (
 			filename, original_filename, content_type, file_size, 
-			storage_path, thumbnail_path, width, height, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, uploaded_at, created_at, updated_at
+			storage_path, thumbnail_path, width, height, metadata, taken_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, NOW()))
+		RETURNING id, uploaded_at, taken_at, created_at, updated_at
 	`
 
 	err := r.db.QueryRowContext(
@@ -41,9 +47,11 @@ func (r *imageRepository) Create(ctx context.Context, image *Image) error {
 		image.Width,
 		image.Height,
 		image.Metadata,
+		nullTime(image.TakenAt),
 	).Scan(
 		&image.ID,
 		&image.UploadedAt,
+		&image.TakenAt,
 		&image.CreatedAt,
 		&image.UpdatedAt,
 	)
@@ -65,6 +73,7 @@ func (r *imageRepository) scanSingleImage(ctx context.Context, query string, not
 		&image.Width,
 		&image.Height,
 		&image.UploadedAt,
+		&image.TakenAt,
 		&image.Metadata,
 		&image.CreatedAt,
 		&image.UpdatedAt,
@@ -81,7 +90,7 @@ func (r *imageRepository) scanSingleImage(ctx context.Context, query string, not
 func (r *imageRepository) GetByID(ctx context.Context, id int) (*Image, error) {
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images WHERE id = $1
 	`
@@ -93,7 +102,7 @@ func (r *imageRepository) GetByID(ctx context.Context, id int) (*Image, error) {
 func (r *imageRepository) GetByFilename(ctx context.Context, filename string) (*Image, error) {
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images WHERE filename = $1
 	`
@@ -105,7 +114,7 @@ func (r *imageRepository) GetByFilename(ctx context.Context, filename string) (*
 func (r *imageRepository) GetByStoragePath(ctx context.Context, path string) (*Image, error) {
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images WHERE storage_path = $1
 	`
@@ -126,9 +135,10 @@ func (r *imageRepository) Update(ctx context.Context, image *Image) error {
 			width = $8,
 			height = $9,
 			metadata = $10,
+			taken_at = COALESCE($11, taken_at),
 			updated_at = NOW()
 		WHERE id = $1
-		RETURNING updated_at
+		RETURNING taken_at, updated_at
 	`
 
 	err := r.db.QueryRowContext(
@@ -143,11 +153,17 @@ func (r *imageRepository) Update(ctx context.Context, image *Image) error {
 		image.Width,
 		image.Height,
 		image.Metadata,
-	).Scan(&image.UpdatedAt)
+		nullTime(image.TakenAt),
+	).Scan(&image.TakenAt, &image.UpdatedAt)
 
 	return err
 }
 
+// nullTime maps the zero time to NULL so the database default or current value applies
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
 // UpdateThumbnail updates just the thumbnail path for an image
 func (r *imageRepository) UpdateThumbnail(ctx context.Context, id int, thumbnailPath string) error {
 	query := `UPDATE images SET thumbnail_path = $2, updated_at = NOW() WHERE id = $1`
@@ -169,6 +185,52 @@ func (r *imageRepository) UpdateThumbnail(ctx context.Context, id int, thumbnail
 	return nil
 }
 
+// Patch updates the fields set in patch, merging its metadata keys into the stored
+// metadata rather than replacing it
+func (r *imageRepository) Patch(ctx context.Context, id int, patch ImagePatch) error {
+	var removed []string
+	set := make(map[string]json.RawMessage)
+	for key, value := range patch.Metadata {
+		if value == nil {
+			removed = append(removed, key)
+		} else {
+			set[key] = value
+		}
+	}
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	query := `
+		UPDATE images SET
+			width = COALESCE($2, width),
+			height = COALESCE($3, height),
+			taken_at = COALESCE($4, taken_at),
+			thumbnail_path = COALESCE($5, thumbnail_path),
+			metadata = (COALESCE(metadata, '{}'::jsonb) - $6::text[]) || $7::jsonb,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		id, patch.Width, patch.Height, patch.TakenAt, patch.ThumbnailPath, pq.Array(removed), string(setJSON))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("image with ID %d not found", id)
+	}
+
+	return nil
+}
+
 // Delete removes an image record by ID
 func (r *imageRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM images WHERE id = $1`
@@ -220,7 +282,7 @@ func (r *imageRepository) List(ctx context.Context, pagination PaginationParams,
 
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY ` + orderBy + `
@@ -236,7 +298,7 @@ func (r *imageRepository) ListByContentType(ctx context.Context, contentType str
 
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		WHERE content_type = $1
@@ -251,10 +313,51 @@ func (r *imageRepository) ListByContentType(ctx context.Context, contentType str
 func (r *imageRepository) Search(ctx context.Context, filters SearchFilters, pagination PaginationParams, sort SortParams) ([]*Image, error) {
 	pagination.Validate()
 
+	with, whereClause, args := buildSearchConditions(filters)
+	argIndex := len(args) + 1
+
+	// Validate and build ORDER BY clause safely
+	orderBy := buildSimpleOrderByClause(sort)
+
+	query := fmt.Sprintf(`%s
+		SELECT id, filename, original_filename, content_type, file_size,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
+			   metadata, created_at, updated_at
+		FROM images
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, with, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, pagination.Limit, pagination.Offset)
+
+	return r.scanImages(ctx, query, args...)
+}
+
+// CountSearch counts the images matching the search filters
+func (r *imageRepository) CountSearch(ctx context.Context, filters SearchFilters) (int, error) {
+	with, whereClause, args := buildSearchConditions(filters)
+
+	var count int
+	query := fmt.Sprintf(`%s SELECT COUNT(*) FROM images %s`, with, whereClause)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// buildSearchConditions turns search filters into an optional WITH clause (for tag
+// filters), a WHERE clause over the images table and the matching query arguments
+func buildSearchConditions(filters SearchFilters) (with, whereClause string, args []interface{}) {
 	var conditions []string
-	var args []interface{}
 	argIndex := 1
 
+	// tagTreeCTE refers to the tag names as $1, so they must be the first argument
+	if len(filters.Tags) > 0 {
+		with = tagTreeCTE
+		args = append(args, pq.Array(filters.Tags))
+		argIndex++
+		conditions = append(conditions, tagMatchCondition("images.id", filters.MatchAllTags))
+	}
+
 	if len(filters.ContentTypes) > 0 {
 		placeholders := make([]string, len(filters.ContentTypes))
 		for i, contentType := range filters.ContentTypes {
@@ -289,6 +392,18 @@ func (r *imageRepository) Search(ctx context.Context, filters SearchFilters, pag
 		argIndex++
 	}
 
+	if filters.TakenAfter != nil {
+		conditions = append(conditions, fmt.Sprintf("taken_at >= $%d", argIndex))
+		args = append(args, *filters.TakenAfter)
+		argIndex++
+	}
+
+	if filters.TakenBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("taken_at < $%d", argIndex))
+		args = append(args, *filters.TakenBefore)
+		argIndex++
+	}
+
 	if filters.Filename != "" {
 		conditions = append(conditions, fmt.Sprintf("(filename ILIKE $%d OR original_filename ILIKE $%d)", argIndex, argIndex+1))
 		searchTerm := "%" + filters.Filename + "%"
@@ -296,27 +411,28 @@ func (r *imageRepository) Search(ctx context.Context, filters SearchFilters, pag
 		argIndex += 2
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	// Capture details extracted on upload live under metadata->'photo'
+	if filters.Camera != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"CONCAT_WS(' ', metadata->'photo'->>'camera_make', metadata->'photo'->>'camera_model') ILIKE $%d", argIndex))
+		args = append(args, "%"+escapeLike(filters.Camera)+"%")
+		argIndex++
 	}
 
-	// Validate and build ORDER BY clause safely
-	orderBy := buildSimpleOrderByClause(sort)
-
-	query := fmt.Sprintf(`
-		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
-			   metadata, created_at, updated_at
-		FROM images
-		%s
-		ORDER BY %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, argIndex, argIndex+1)
+	if filters.Lens != "" {
+		conditions = append(conditions, fmt.Sprintf("metadata->'photo'->>'lens' ILIKE $%d", argIndex))
+		args = append(args, "%"+escapeLike(filters.Lens)+"%")
+	}
 
-	args = append(args, pagination.Limit, pagination.Offset)
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	return with, whereClause, args
+}
 
-	return r.scanImages(ctx, query, args...)
+// escapeLike escapes the LIKE wildcards in a user-supplied search term
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
 }
 
 // GetByDateRange retrieves images within a date range
@@ -325,7 +441,7 @@ func (r *imageRepository) GetByDateRange(ctx context.Context, start, end time.Ti
 
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		WHERE uploaded_at >= $1 AND uploaded_at <= $2
@@ -344,7 +460,7 @@ func (r *imageRepository) GetRecent(ctx context.Context, since time.Time, limit
 
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		WHERE uploaded_at >= $1
@@ -361,7 +477,7 @@ func (r *imageRepository) GetLargest(ctx context.Context, pagination PaginationP
 
 	query := `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY file_size DESC
@@ -411,6 +527,188 @@ func (r *imageRepository) GetStats(ctx context.Context) (*ImageStats, error) {
 	return stats, err
 }
 
+// GetTimeline counts images per capture year, month or day, newest period first
+func (r *imageRepository) GetTimeline(ctx context.Context, granularity string) ([]TimelineBucket, error) {
+	switch granularity {
+	case TimelineYear, TimelineMonth, TimelineDay:
+	default:
+		return nil, fmt.Errorf("invalid timeline granularity %q", granularity)
+	}
+
+	// Buckets are cut in UTC so they match the RFC 3339 times returned by the API
+	query := `
+		SELECT date_trunc($1, taken_at AT TIME ZONE 'UTC') AS period, COUNT(*) AS count
+		FROM images
+		GROUP BY period
+		ORDER BY period DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, granularity)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var buckets []TimelineBucket
+	for rows.Next() {
+		var bucket TimelineBucket
+		if err := rows.Scan(&bucket.Period, &bucket.Count); err != nil {
+			return nil, err
+		}
+		bucket.Period = bucket.Period.UTC()
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// SaveVariants records the responsive variants of an image, replacing any earlier
+// variant with the same width and format
+func (r *imageRepository) SaveVariants(ctx context.Context, imageID int, variants []ImageVariant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Transaction cleanup
+
+	query := `
+		INSERT INTO image_variants (image_id, width, height, format, file_size, storage_path)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (image_id, width, format) DO UPDATE
+		SET height = EXCLUDED.height, file_size = EXCLUDED.file_size,
+			storage_path = EXCLUDED.storage_path, created_at = NOW()
+		RETURNING id, created_at
+	`
+	for i := range variants {
+		variant := &variants[i]
+		variant.ImageID = imageID
+		err := tx.QueryRowContext(ctx, query,
+			imageID, variant.Width, variant.Height, variant.Format, variant.FileSize, variant.StoragePath,
+		).Scan(&variant.ID, &variant.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadVariants fills in the responsive variants of the given images with one query
+func (r *imageRepository) LoadVariants(ctx context.Context, images []*Image) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*Image, len(images))
+	imageIDs := make([]int, 0, len(images))
+	for _, image := range images {
+		byID[image.ID] = image
+		imageIDs = append(imageIDs, image.ID)
+	}
+
+	query := `
+		SELECT id, image_id, width, height, format, file_size, storage_path, created_at
+		FROM image_variants
+		WHERE image_id = ANY($1)
+		ORDER BY image_id, width, format
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(imageIDs))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	for rows.Next() {
+		var variant ImageVariant
+		err := rows.Scan(
+			&variant.ID,
+			&variant.ImageID,
+			&variant.Width,
+			&variant.Height,
+			&variant.Format,
+			&variant.FileSize,
+			&variant.StoragePath,
+			&variant.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if image, ok := byID[variant.ImageID]; ok {
+			image.Variants = append(image.Variants, variant)
+		}
+	}
+
+	return rows.Err()
+}
+
+// maxGeoClusters caps the clusters returned for one bounding box, largest first
+const maxGeoClusters = 2000
+
+// GetGeoClusters groups the geotagged images inside bounds into square grid cells of
+// cellSize degrees, returning each cell's mean position, image count and newest image
+func (r *imageRepository) GetGeoClusters(ctx context.Context, bounds GeoBounds, cellSize float64) ([]GeoCluster, error) {
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("invalid geo cluster cell size %v", cellSize)
+	}
+
+	// Both filters select the same rows; the PostGIS one can use the GiST index from migration 008
+	inBounds := "latitude BETWEEN $1 AND $3 AND longitude BETWEEN $2 AND $4"
+	if r.hasPostGIS(ctx) {
+		inBounds = "latitude IS NOT NULL AND longitude IS NOT NULL AND " +
+			"ST_SetSRID(ST_MakePoint(longitude, latitude), 4326) && ST_MakeEnvelope($2, $1, $4, $3, 4326)"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT AVG(latitude) AS latitude, AVG(longitude) AS longitude, COUNT(*) AS count,
+			(array_agg(id ORDER BY taken_at DESC, id DESC))[1] AS image_id,
+			(array_agg(original_filename ORDER BY taken_at DESC, id DESC))[1] AS filename
+		FROM images
+		WHERE %s
+		GROUP BY floor(latitude / $5), floor(longitude / $5)
+		ORDER BY count DESC
+		LIMIT %d
+	`, inBounds, maxGeoClusters)
+
+	rows, err := r.db.QueryContext(ctx, query, bounds.MinLat, bounds.MinLon, bounds.MaxLat, bounds.MaxLon, cellSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var clusters []GeoCluster
+	for rows.Next() {
+		var cluster GeoCluster
+		if err := rows.Scan(&cluster.Latitude, &cluster.Longitude, &cluster.Count, &cluster.ImageID, &cluster.Filename); err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters, rows.Err()
+}
+
+// hasPostGIS reports whether the PostGIS extension is installed, checking once per
+// repository. A failed check, such as one cut short by its request's context, is not
+// kept: it reports false and the next call checks again.
+func (r *imageRepository) hasPostGIS(ctx context.Context) bool {
+	r.postgisMu.Lock()
+	defer r.postgisMu.Unlock()
+	if r.postgisChecked {
+		return r.postgis
+	}
+
+	var installed bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')",
+	).Scan(&installed)
+	if err != nil {
+		return false
+	}
+	r.postgis, r.postgisChecked = installed, true
+	return r.postgis
+}
+
 // selectGetImagesOnlyQuery selects the appropriate query for images WITHOUT tags
 // This is more efficient than the old json_agg approach
 func (r *imageRepository) selectGetImagesOnlyQuery(sort SortParams) string {
@@ -435,6 +733,11 @@ func (r *imageRepository) selectGetImagesOnlyQuery(sort SortParams) string {
 			return getImagesOnlyQueryCreatedAtAsc
 		}
 		return getImagesOnlyQueryCreatedAtDesc
+	case SortByTakenAt:
+		if sort.Order == SortAsc {
+			return getImagesOnlyQueryTakenAtAsc
+		}
+		return getImagesOnlyQueryTakenAtDesc
 	default:
 		return getImagesOnlyQueryUploadedAtDesc
 	}
@@ -474,6 +777,7 @@ func (r *imageRepository) GetWithTags(ctx context.Context, pagination Pagination
 			&image.Width,
 			&image.Height,
 			&image.UploadedAt,
+			&image.TakenAt,
 			&image.Metadata,
 			&image.CreatedAt,
 			&image.UpdatedAt,
@@ -547,7 +851,40 @@ func (r *imageRepository) GetWithTags(ctx context.Context, pagination Pagination
 	return images, nil
 }
 
-// GetByTags retrieves images that have specific tags
+// requested maps each filter name through tag_aliases to its canonical tag name.
+// tag_tree expands every requested tag to itself plus all of its descendants,
+// remembering which requested tag (root) each expanded tag came from
+const tagTreeCTE = `
+	WITH RECURSIVE requested AS (
+		SELECT DISTINCT COALESCE(a.name, r.name) AS name
+		FROM unnest($1::text[]) AS r(name)
+		LEFT JOIN tag_aliases ta ON ta.alias = LOWER(TRIM(r.name))
+		LEFT JOIN tags a ON a.id = ta.tag_id
+	),
+	tag_tree AS (
+		SELECT id, name AS root FROM tags WHERE name IN (SELECT name FROM requested)
+		UNION
+		SELECT c.id, tt.root FROM tags c INNER JOIN tag_tree tt ON c.parent_id = tt.id
+	)`
+
+// tagMatchCondition returns the WHERE condition that matches an image (identified by
+// idColumn) against the requested tags of tagTreeCTE
+func tagMatchCondition(idColumn string, matchAll bool) string {
+	if matchAll {
+		return `(
+			SELECT COUNT(DISTINCT tt.root) FROM image_tags it
+			INNER JOIN tag_tree tt ON it.tag_id = tt.id
+			WHERE it.image_id = ` + idColumn + `
+		) = (SELECT COUNT(*) FROM requested)`
+	}
+	return `EXISTS (
+			SELECT 1 FROM image_tags it
+			INNER JOIN tag_tree tt ON it.tag_id = tt.id
+			WHERE it.image_id = ` + idColumn + `
+		)`
+}
+
+// GetByTags retrieves images that have specific tags; a parent tag also matches images tagged with its descendants
 func (r *imageRepository) GetByTags(ctx context.Context, tags []string, matchAll bool, pagination PaginationParams) ([]*Image, error) {
 	if len(tags) == 0 {
 		return []*Image{}, nil
@@ -555,41 +892,19 @@ func (r *imageRepository) GetByTags(ctx context.Context, tags []string, matchAll
 
 	pagination.Validate()
 
-	var query string
 	args := []interface{}{pq.Array(tags), pagination.Limit, pagination.Offset}
 
-	if matchAll {
-		// Images must have ALL specified tags
-		query = `
-			SELECT DISTINCT i.id, i.filename, i.original_filename, i.content_type, i.file_size,
-				   i.storage_path, i.thumbnail_path, i.width, i.height, i.uploaded_at,
-				   i.metadata, i.created_at, i.updated_at
-			FROM images i
-			WHERE EXISTS (
-				SELECT 1 FROM image_tags it 
-				INNER JOIN tags t ON it.tag_id = t.id 
-				WHERE it.image_id = i.id AND t.name = ANY($1::text[])
-				GROUP BY it.image_id 
-				HAVING COUNT(DISTINCT t.name) = $4
-			)
-			ORDER BY i.uploaded_at DESC
-			LIMIT $2 OFFSET $3
-		`
-		args = append(args, len(tags))
-	} else {
-		// Images must have ANY of the specified tags
-		query = `
-			SELECT DISTINCT i.id, i.filename, i.original_filename, i.content_type, i.file_size,
-				   i.storage_path, i.thumbnail_path, i.width, i.height, i.uploaded_at,
-				   i.metadata, i.created_at, i.updated_at
-			FROM images i
-			INNER JOIN image_tags it ON i.id = it.image_id
-			INNER JOIN tags t ON it.tag_id = t.id
-			WHERE t.name = ANY($1::text[])
-			ORDER BY i.uploaded_at DESC
-			LIMIT $2 OFFSET $3
-		`
-	}
+	// Images must match ALL specified tags (each directly or through a descendant)
+	// or ANY of them; an alias and its canonical tag count as one requested tag
+	query := tagTreeCTE + `
+		SELECT i.id, i.filename, i.original_filename, i.content_type, i.file_size,
+			   i.storage_path, i.thumbnail_path, i.width, i.height, i.uploaded_at, i.taken_at,
+			   i.metadata, i.created_at, i.updated_at
+		FROM images i
+		WHERE ` + tagMatchCondition("i.id", matchAll) + `
+		ORDER BY i.uploaded_at DESC
+		LIMIT $2 OFFSET $3
+	`
 
 	return r.scanImages(ctx, query, args...)
 }
@@ -616,6 +931,7 @@ func (r *imageRepository) scanImages(ctx context.Context, query string, args ...
 			&image.Width,
 			&image.Height,
 			&image.UploadedAt,
+			&image.TakenAt,
 			&image.Metadata,
 			&image.CreatedAt,
 			&image.UpdatedAt,
@@ -642,6 +958,8 @@ func buildSimpleOrderByClause(sort SortParams) string {
 		field = string(SortByFileSize)
 	case SortByCreatedAt:
 		field = string(SortByCreatedAt)
+	case SortByTakenAt:
+		field = string(SortByTakenAt)
 	default:
 		// Default to uploaded_at if invalid
 		field = string(SortByUploadedAt)
@@ -667,7 +985,7 @@ func buildSimpleOrderByClause(sort SortParams) string {
 const (
 	getImagesOnlyQueryUploadedAtAsc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY uploaded_at ASC
@@ -675,7 +993,7 @@ const (
 
 	getImagesOnlyQueryUploadedAtDesc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY uploaded_at DESC
@@ -683,7 +1001,7 @@ const (
 
 	getImagesOnlyQueryFilenameAsc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY filename ASC
@@ -691,7 +1009,7 @@ const (
 
 	getImagesOnlyQueryFilenameDesc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY filename DESC
@@ -699,7 +1017,7 @@ const (
 
 	getImagesOnlyQueryFileSizeAsc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY file_size ASC
@@ -707,7 +1025,7 @@ const (
 
 	getImagesOnlyQueryFileSizeDesc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY file_size DESC
@@ -715,7 +1033,7 @@ const (
 
 	getImagesOnlyQueryCreatedAtAsc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY created_at ASC
@@ -723,9 +1041,25 @@ const (
 
 	getImagesOnlyQueryCreatedAtDesc = `
 		SELECT id, filename, original_filename, content_type, file_size,
-			   storage_path, thumbnail_path, width, height, uploaded_at,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
 			   metadata, created_at, updated_at
 		FROM images
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
+
+	getImagesOnlyQueryTakenAtAsc = `
+		SELECT id, filename, original_filename, content_type, file_size,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
+			   metadata, created_at, updated_at
+		FROM images
+		ORDER BY taken_at ASC, id ASC
+		LIMIT $1 OFFSET $2`
+
+	getImagesOnlyQueryTakenAtDesc = `
+		SELECT id, filename, original_filename, content_type, file_size,
+			   storage_path, thumbnail_path, width, height, uploaded_at, taken_at,
+			   metadata, created_at, updated_at
+		FROM images
+		ORDER BY taken_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
 )
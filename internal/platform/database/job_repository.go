@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Job statuses, as stored in processing_jobs.status
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusDead    = "dead"
+)
+
+// jobRepository implements JobRepository interface
+type jobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new JobRepository
+func NewJobRepository(db *sql.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+// Enqueue adds a pending job for an image, unless one of the same type is already
+// queued or running
+func (r *jobRepository) Enqueue(ctx context.Context, imageID int, jobType string, maxAttempts int) error {
+	query := `
+		INSERT INTO processing_jobs (image_id, job_type, max_attempts)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (image_id, job_type) WHERE status IN ('pending', 'running') DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, imageID, jobType, maxAttempts)
+	return err
+}
+
+// Claim marks up to limit due jobs as running and returns them, oldest first. Jobs
+// running for longer than the lease are claimed again, as their worker has gone, unless
+// that was their last attempt: those are dead-lettered, so a job that brings its worker
+// down is not claimed forever. Rows locked by another worker's claim are skipped rather
+// than waited for.
+func (r *jobRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*ProcessingJob, error) {
+	query := `
+		WITH buried AS (
+			UPDATE processing_jobs
+			SET status = 'dead', last_error = 'lease expired on the last attempt', locked_at = NULL, updated_at = NOW()
+			WHERE status = 'running' AND locked_at < NOW() - make_interval(secs => $2)
+				AND attempts >= max_attempts
+		)
+		UPDATE processing_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM processing_jobs
+			WHERE (status = 'pending' AND run_at <= NOW())
+				OR (status = 'running' AND locked_at < NOW() - make_interval(secs => $2)
+					AND attempts < max_attempts)
+			ORDER BY run_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, image_id, job_type, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows, false)
+}
+
+// Complete marks a job as done. The attempt is that of the worker's claim, as for
+// Retry and Bury.
+func (r *jobRepository) Complete(ctx context.Context, id int64, attempt int) error {
+	return r.finish(ctx, id, attempt, JobStatusDone, nil, nil)
+}
+
+// Retry puts a failed job back in the queue, due at runAt
+func (r *jobRepository) Retry(ctx context.Context, id int64, attempt int, lastError string, runAt time.Time) error {
+	return r.finish(ctx, id, attempt, JobStatusPending, &lastError, &runAt)
+}
+
+// Bury moves a job that has used up its attempts to the dead-letter state, where it
+// stays until it is retried by hand
+func (r *jobRepository) Bury(ctx context.Context, id int64, attempt int, lastError string) error {
+	return r.finish(ctx, id, attempt, JobStatusDead, &lastError, nil)
+}
+
+// finish releases a running job with a new status. The last error is kept when none is
+// given, and the due time when no new one is. It returns ErrJobLeaseLost when the job
+// is no longer running under the given attempt, as its lease ran out and it has been
+// claimed again or dead-lettered since.
+func (r *jobRepository) finish(ctx context.Context, id int64, attempt int, status string, lastError *string, runAt *time.Time) error {
+	query := `
+		UPDATE processing_jobs
+		SET status = $3, last_error = COALESCE($4, last_error), run_at = COALESCE($5, run_at),
+			locked_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, attempt, status, lastError, runAt)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+// ListLatest returns the latest job of each type for the given images, which is the
+// current state of that processing step
+func (r *jobRepository) ListLatest(ctx context.Context, imageIDs []int) ([]*ProcessingJob, error) {
+	if len(imageIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (image_id, job_type)
+			id, image_id, job_type, status, attempts, max_attempts, last_error, run_at, created_at, updated_at
+		FROM processing_jobs
+		WHERE image_id = ANY($1)
+		ORDER BY image_id, job_type, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(imageIDs))
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows, false)
+}
+
+// currentDeadJob selects dead jobs no later job of the same image and type has
+// replaced, such as one queued by reprocessing the image
+const currentDeadJob = `
+	j.status = 'dead' AND NOT EXISTS (
+		SELECT 1 FROM processing_jobs later
+		WHERE later.image_id = j.image_id AND later.job_type = j.job_type AND later.id > j.id
+	)
+`
+
+// ListDead returns the dead-lettered jobs still current for their image, most
+// recently failed first
+func (r *jobRepository) ListDead(ctx context.Context, pagination PaginationParams) ([]*ProcessingJob, error) {
+	query := `
+		SELECT j.id, j.image_id, j.job_type, j.status, j.attempts, j.max_attempts, j.last_error,
+			j.run_at, j.created_at, j.updated_at, i.original_filename
+		FROM processing_jobs j
+		JOIN images i ON i.id = j.image_id
+		WHERE ` + currentDeadJob + `
+		ORDER BY j.updated_at DESC, j.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows, true)
+}
+
+// CountDead counts the dead-lettered jobs still current for their image
+func (r *jobRepository) CountDead(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM processing_jobs j WHERE ` + currentDeadJob
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	return count, err
+}
+
+// Requeue queues a dead-lettered job again, due at once and with all its attempts.
+// It returns ErrJobNotFound unless the job is dead and still current for its image.
+func (r *jobRepository) Requeue(ctx context.Context, id int64) error {
+	query := `
+		UPDATE processing_jobs j
+		SET status = 'pending', attempts = 0, run_at = NOW(), locked_at = NULL, updated_at = NOW()
+		WHERE j.id = $1 AND ` + currentDeadJob
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// scanJobs reads processing jobs from rows, each followed by the image's original
+// filename when withFilename is set, and closes the rows
+func scanJobs(rows *sql.Rows, withFilename bool) ([]*ProcessingJob, error) {
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var jobs []*ProcessingJob
+	for rows.Next() {
+		job := &ProcessingJob{}
+		dest := []interface{}{
+			&job.ID,
+			&job.ImageID,
+			&job.Type,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.RunAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		}
+		if withFilename {
+			dest = append(dest, &job.OriginalFilename)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,11 @@
+-- Add optional parent/child relationships between tags
+-- A filter on a parent tag matches images tagged with any of its descendants
+
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES tags(id) ON DELETE SET NULL;
+
+-- A tag can never be its own parent; deeper cycles are rejected by the application
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_parent_not_self;
+ALTER TABLE tags ADD CONSTRAINT tags_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+-- Index for walking the hierarchy from parent to children
+CREATE INDEX IF NOT EXISTS idx_tags_parent_id ON tags(parent_id);
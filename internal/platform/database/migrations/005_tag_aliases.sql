@@ -0,0 +1,22 @@
+-- Create tag_aliases table mapping alternative spellings to a canonical tag
+-- Aliases are stored normalized (trimmed, lowercase) and may contain characters
+-- that are not allowed in tag names, e.g. 'b&w'
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create index for listing the aliases of a tag
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_id ON tag_aliases(tag_id);
+
+-- Seed common aliases for predefined tags
+INSERT INTO tag_aliases (alias, tag_id)
+SELECT a.alias, t.id
+FROM (VALUES
+    ('b&w', 'black-and-white'),
+    ('bw', 'black-and-white'),
+    ('monochrome', 'black-and-white')
+) AS a(alias, tag_name)
+INNER JOIN tags t ON t.name = a.tag_name
+ON CONFLICT (alias) DO NOTHING;
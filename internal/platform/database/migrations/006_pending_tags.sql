@@ -0,0 +1,12 @@
+-- Create pending_tags table for tags suggested by uploaders that await approval
+-- Used when TAG_POLICY=approval: unknown tags are parked here instead of being created,
+-- one row per requested tag and image so approval can attach the tag retroactively
+CREATE TABLE IF NOT EXISTS pending_tags (
+    name VARCHAR(100) NOT NULL,
+    image_id INTEGER NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (name, image_id)
+);
+
+-- Create index for cleaning up suggestions per image
+CREATE INDEX IF NOT EXISTS idx_pending_tags_image_id ON pending_tags(image_id);
@@ -0,0 +1,14 @@
+-- Add taken_at: when the photo was captured, read from EXIF/XMP/IPTC on upload and
+-- falling back to the upload time, so timelines and capture-date sorting cover every image
+ALTER TABLE images ADD COLUMN IF NOT EXISTS taken_at TIMESTAMP WITH TIME ZONE;
+
+-- Backfill from the capture details already extracted into metadata.photo
+UPDATE images
+SET taken_at = COALESCE((metadata->'photo'->>'taken_at')::timestamptz, uploaded_at)
+WHERE taken_at IS NULL;
+
+ALTER TABLE images ALTER COLUMN taken_at SET DEFAULT NOW();
+ALTER TABLE images ALTER COLUMN taken_at SET NOT NULL;
+
+-- Create index for capture-date sorting and timeline buckets
+CREATE INDEX IF NOT EXISTS idx_images_taken_at ON images(taken_at DESC);
@@ -0,0 +1,21 @@
+-- Add latitude/longitude: the capture position from metadata.photo.gps, kept in sync by
+-- PostgreSQL so privacy stripping and metadata edits can never leave a stale location behind
+ALTER TABLE images ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION
+    GENERATED ALWAYS AS ((metadata->'photo'->'gps'->>'latitude')::double precision) STORED;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION
+    GENERATED ALWAYS AS ((metadata->'photo'->'gps'->>'longitude')::double precision) STORED;
+
+-- Create index for bounding box queries over geotagged images
+CREATE INDEX IF NOT EXISTS idx_images_lat_lon ON images(latitude, longitude)
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
+
+-- Use a spatial index instead when PostGIS is installed; the map queries detect it at runtime
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis') THEN
+        EXECUTE 'CREATE INDEX IF NOT EXISTS idx_images_geo_point ON images
+            USING GIST (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326))
+            WHERE latitude IS NOT NULL AND longitude IS NOT NULL';
+    END IF;
+END
+$$;
@@ -0,0 +1,16 @@
+-- Create image_variants table for the resized copies generated at upload for srcset,
+-- one row per image, width and format; the objects live under derivatives/<image_id>/
+CREATE TABLE IF NOT EXISTS image_variants (
+    id SERIAL PRIMARY KEY,
+    image_id INTEGER NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    file_size BIGINT NOT NULL,
+    storage_path VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (image_id, width, format)
+);
+
+-- Create index for loading the variants of listed images
+CREATE INDEX IF NOT EXISTS idx_image_variants_image_id ON image_variants(image_id);
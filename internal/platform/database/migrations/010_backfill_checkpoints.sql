@@ -0,0 +1,11 @@
+-- Create backfill_checkpoints table recording how far each backfill job has got, so a
+-- restarted job continues after the last image it finished instead of starting over
+CREATE TABLE IF NOT EXISTS backfill_checkpoints (
+    job VARCHAR(100) PRIMARY KEY,
+    last_image_id INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
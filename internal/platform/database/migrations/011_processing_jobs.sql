@@ -0,0 +1,26 @@
+-- Create processing_jobs table, the queue of image processing steps run by the workers
+-- in the server process. Workers claim due jobs with FOR UPDATE SKIP LOCKED; a running
+-- job whose lease has expired belongs to a crashed worker and is claimed again.
+CREATE TABLE IF NOT EXISTS processing_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    image_id INTEGER NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    job_type VARCHAR(20) NOT NULL, -- thumbnail, variants, metadata, hash or reprocess
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, done or dead
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create index for claiming due jobs
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_due ON processing_jobs(run_at) WHERE status = 'pending';
+
+-- Create index for finding running jobs with an expired lease
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_locked_at ON processing_jobs(locked_at) WHERE status = 'running';
+
+-- An image has at most one queued or running job of each type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processing_jobs_active
+    ON processing_jobs(image_id, job_type) WHERE status IN ('pending', 'running');
@@ -0,0 +1,5 @@
+-- Create index for the processing status of images: the latest job of each step
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_image ON processing_jobs(image_id, job_type, id DESC);
+
+-- Create index for listing dead-lettered jobs, most recently failed first
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_dead ON processing_jobs(updated_at DESC) WHERE status = 'dead';
@@ -0,0 +1,24 @@
+-- Create resumable_uploads table, the state of uploads sent in chunks over the tus
+-- protocol. Chunks are stored as the parts of a multipart upload to the bucket; bytes
+-- not yet making up a whole part are kept in a tail object until the next chunk.
+CREATE TABLE IF NOT EXISTS resumable_uploads (
+    id VARCHAR(64) PRIMARY KEY,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    metadata_policy VARCHAR(20) NOT NULL DEFAULT '',
+    storage_path TEXT NOT NULL, -- Where the parts are assembled, under uploads/
+    multipart_id TEXT, -- Cleared once the parts have been joined
+    parts JSONB NOT NULL DEFAULT '[]',
+    tail_size BIGINT NOT NULL DEFAULT 0,
+    image_id INTEGER REFERENCES images(id) ON DELETE SET NULL,
+    locked_at TIMESTAMP WITH TIME ZONE, -- Set while a request writes a chunk
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create index for removing expired uploads
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_expires_at ON resumable_uploads(expires_at);
@@ -0,0 +1,8 @@
+-- Create direct_upload_claims table, held by the request completing a file uploaded
+-- straight to the bucket so that concurrent completions register it once. A claim is
+-- kept once its image is created, until the file has been removed.
+CREATE TABLE IF NOT EXISTS direct_upload_claims (
+    upload_key VARCHAR(64) PRIMARY KEY,
+    image_id INTEGER, -- Set once the image is created
+    claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
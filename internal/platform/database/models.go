@@ -9,30 +9,128 @@ import (
 
 // Image represents an image record in the database
 type Image struct {
-	ID               int       `json:"id" db:"id"`
-	Filename         string    `json:"filename" db:"filename"`
-	OriginalFilename string    `json:"original_filename" db:"original_filename"`
-	ContentType      string    `json:"content_type" db:"content_type"`
-	FileSize         int64     `json:"file_size" db:"file_size"`
-	StoragePath      string    `json:"storage_path" db:"storage_path"`
-	ThumbnailPath    *string   `json:"thumbnail_path,omitempty" db:"thumbnail_path"`
-	Width            *int      `json:"width,omitempty" db:"width"`
-	Height           *int      `json:"height,omitempty" db:"height"`
-	UploadedAt       time.Time `json:"uploaded_at" db:"uploaded_at"`
-	Metadata         Metadata  `json:"metadata" db:"metadata"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
-	Tags             []Tag     `json:"tags,omitempty" db:"-"` // Loaded separately
+	ID               int            `json:"id" db:"id"`
+	Filename         string         `json:"filename" db:"filename"`
+	OriginalFilename string         `json:"original_filename" db:"original_filename"`
+	ContentType      string         `json:"content_type" db:"content_type"`
+	FileSize         int64          `json:"file_size" db:"file_size"`
+	StoragePath      string         `json:"storage_path" db:"storage_path"`
+	ThumbnailPath    *string        `json:"thumbnail_path,omitempty" db:"thumbnail_path"`
+	Width            *int           `json:"width,omitempty" db:"width"`
+	Height           *int           `json:"height,omitempty" db:"height"`
+	UploadedAt       time.Time      `json:"uploaded_at" db:"uploaded_at"`
+	TakenAt          time.Time      `json:"taken_at" db:"taken_at"` // Capture time; the upload time when unknown
+	Metadata         Metadata       `json:"metadata" db:"metadata"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+	Tags             []Tag          `json:"tags,omitempty" db:"-"`     // Loaded separately
+	Variants         []ImageVariant `json:"variants,omitempty" db:"-"` // Loaded separately
 }
 
-// Tag represents a tag for categorizing images
-type Tag struct {
+// ImagePatch is a targeted update of an image record. Nil fields keep their value, and
+// only the metadata keys listed are written.
+type ImagePatch struct {
+	Width         *int
+	Height        *int
+	TakenAt       *time.Time
+	ThumbnailPath *string
+	Metadata      map[string]json.RawMessage // Keys to set; a nil value removes the key
+}
+
+// BackfillCheckpoint records how far a backfill job has got through the images
+type BackfillCheckpoint struct {
+	Job         string     `json:"job" db:"job"`
+	LastImageID int        `json:"last_image_id" db:"last_image_id"` // Images up to this ID are done
+	Processed   int        `json:"processed" db:"processed"`
+	Failed      int        `json:"failed" db:"failed"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// ProcessingJob is a queued image processing step
+type ProcessingJob struct {
+	ID          int64     `json:"id" db:"id"`
+	ImageID     int       `json:"image_id" db:"image_id"`
+	Type        string    `json:"job_type" db:"job_type"`
+	Status      string    `json:"status" db:"status"`
+	Attempts    int       `json:"attempts" db:"attempts"` // Claims so far, the current one included
+	MaxAttempts int       `json:"max_attempts" db:"max_attempts"`
+	LastError   *string   `json:"last_error,omitempty" db:"last_error"`
+	RunAt       time.Time `json:"run_at" db:"run_at"` // When a pending job is next due
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	OriginalFilename string `json:"original_filename,omitempty" db:"original_filename"` // Joined from images
+}
+
+// ResumableUpload is an upload received in chunks, assembled as a multipart upload
+type ResumableUpload struct {
+	ID             string      `json:"id" db:"id"`
+	Length         int64       `json:"upload_length" db:"upload_length"`
+	Offset         int64       `json:"upload_offset" db:"upload_offset"`
+	Filename       string      `json:"filename" db:"filename"`
+	ContentType    string      `json:"content_type" db:"content_type"`
+	Tags           []string    `json:"tags" db:"tags"`
+	MetadataPolicy string      `json:"metadata_policy" db:"metadata_policy"`
+	StoragePath    string      `json:"storage_path" db:"storage_path"`
+	MultipartID    *string     `json:"multipart_id,omitempty" db:"multipart_id"` // Nil once the parts are joined
+	Parts          UploadParts `json:"parts" db:"parts"`
+	TailSize       int64       `json:"tail_size" db:"tail_size"` // Bytes received after the last part
+	ImageID        *int        `json:"image_id,omitempty" db:"image_id"`
+	ExpiresAt      time.Time   `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// UploadPart is a stored part of a resumable upload
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// ImageVariant is a resized copy of an image generated at upload for responsive srcsets
+type ImageVariant struct {
 	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description *string   `json:"description,omitempty" db:"description"`
-	Color       *string   `json:"color,omitempty" db:"color"`
+	ImageID     int       `json:"image_id" db:"image_id"`
+	Width       int       `json:"width" db:"width"`
+	Height      int       `json:"height" db:"height"`
+	Format      string    `json:"format" db:"format"`
+	FileSize    int64     `json:"file_size" db:"file_size"`
+	StoragePath string    `json:"storage_path" db:"storage_path"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	ImageCount  int       `json:"image_count,omitempty" db:"image_count"` // For aggregated queries
+}
+
+// Tag represents a tag for categorizing images
+type Tag struct {
+	ID           int       `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Description  *string   `json:"description,omitempty" db:"description"`
+	Color        *string   `json:"color,omitempty" db:"color"`
+	Category     *string   `json:"category,omitempty" db:"category"`
+	IsPredefined bool      `json:"is_predefined" db:"is_predefined"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	DisplayOrder int       `json:"display_order" db:"display_order"`
+	ParentID     *int      `json:"parent_id,omitempty" db:"parent_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ImageCount   int       `json:"image_count,omitempty" db:"image_count"` // For aggregated queries
+}
+
+// TagAlias maps an alternative spelling to a canonical tag
+type TagAlias struct {
+	Alias     string    `json:"alias" db:"alias"`
+	TagID     int       `json:"tag_id" db:"tag_id"`
+	TagName   string    `json:"tag_name" db:"tag_name"` // Joined from tags
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PendingTag groups the images that requested a tag awaiting approval
+type PendingTag struct {
+	Name             string    `json:"name" db:"name"`
+	ImageIDs         []int     `json:"image_ids" db:"image_ids"`
+	FirstRequestedAt time.Time `json:"first_requested_at" db:"first_requested_at"`
+	LastRequestedAt  time.Time `json:"last_requested_at" db:"last_requested_at"`
 }
 
 // Album represents a collection of images
@@ -93,6 +191,37 @@ func (m *Metadata) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, m)
 }
 
+// UploadParts is the list of a resumable upload's parts, stored as JSON
+type UploadParts []UploadPart
+
+// Value implements the driver.Valuer interface for storing to database
+func (p UploadParts) Value() (driver.Value, error) {
+	if p == nil {
+		return json.RawMessage("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for loading from database
+func (p *UploadParts) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into UploadParts", value)
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
 // ImageStats represents aggregate statistics about images
 type ImageStats struct {
 	TotalImages  int     `json:"total_images" db:"total_images"`
@@ -113,6 +242,41 @@ type SearchFilters struct {
 	StartDate    *time.Time `json:"start_date,omitempty"`
 	EndDate      *time.Time `json:"end_date,omitempty"`
 	Filename     string     `json:"filename,omitempty"`
+	MatchAllTags bool       `json:"match_all_tags,omitempty"` // Require every tag instead of any
+	Camera       string     `json:"camera,omitempty"`         // Substring of the camera make and model
+	Lens         string     `json:"lens,omitempty"`           // Substring of the lens name
+	TakenAfter   *time.Time `json:"taken_after,omitempty"`    // Inclusive lower bound on the capture time
+	TakenBefore  *time.Time `json:"taken_before,omitempty"`   // Exclusive upper bound on the capture time
+}
+
+// Timeline granularities, as understood by PostgreSQL date_trunc
+const (
+	TimelineYear  = "year"
+	TimelineMonth = "month"
+	TimelineDay   = "day"
+)
+
+// TimelineBucket counts the images captured in one year, month or day
+type TimelineBucket struct {
+	Period time.Time `json:"period" db:"period"` // Start of the period in UTC
+	Count  int       `json:"count" db:"count"`
+}
+
+// GeoBounds is a bounding box in decimal degrees
+type GeoBounds struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// GeoCluster groups the geotagged images falling into one grid cell
+type GeoCluster struct {
+	Latitude  float64 `json:"latitude" db:"latitude"`   // Mean position of the images
+	Longitude float64 `json:"longitude" db:"longitude"` // Mean position of the images
+	Count     int     `json:"count" db:"count"`
+	ImageID   int     `json:"image_id" db:"image_id"` // Most recently captured image in the cell
+	Filename  string  `json:"filename" db:"filename"` // Original filename of ImageID
 }
 
 // PaginationParams represents pagination parameters
@@ -157,6 +321,7 @@ const (
 	SortByFilename   ImageSortField = "filename"
 	SortByFileSize   ImageSortField = "file_size"
 	SortByCreatedAt  ImageSortField = "created_at"
+	SortByTakenAt    ImageSortField = "taken_at"
 )
 
 // SortParams represents sorting parameters
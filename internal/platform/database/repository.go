@@ -15,6 +15,7 @@ type ImageRepository interface {
 	GetByStoragePath(ctx context.Context, path string) (*Image, error)
 	Update(ctx context.Context, image *Image) error
 	UpdateThumbnail(ctx context.Context, id int, thumbnailPath string) error
+	Patch(ctx context.Context, id int, patch ImagePatch) error
 	Delete(ctx context.Context, id int) error
 	DeleteByStoragePath(ctx context.Context, path string) error
 
@@ -22,6 +23,7 @@ type ImageRepository interface {
 	List(ctx context.Context, pagination PaginationParams, sort SortParams) ([]*Image, error)
 	ListByContentType(ctx context.Context, contentType string, pagination PaginationParams) ([]*Image, error)
 	Search(ctx context.Context, filters SearchFilters, pagination PaginationParams, sort SortParams) ([]*Image, error)
+	CountSearch(ctx context.Context, filters SearchFilters) (int, error)
 	GetByDateRange(ctx context.Context, start, end time.Time, pagination PaginationParams) ([]*Image, error)
 	GetRecent(ctx context.Context, since time.Time, limit int) ([]*Image, error)
 	GetLargest(ctx context.Context, pagination PaginationParams) ([]*Image, error)
@@ -30,6 +32,18 @@ type ImageRepository interface {
 	Count(ctx context.Context) (int, error)
 	CountByContentType(ctx context.Context, contentType string) (int, error)
 	GetStats(ctx context.Context) (*ImageStats, error)
+	GetTimeline(ctx context.Context, granularity string) ([]TimelineBucket, error)
+	GetGeoClusters(ctx context.Context, bounds GeoBounds, cellSize float64) ([]GeoCluster, error)
+
+	// Responsive variants
+	SaveVariants(ctx context.Context, imageID int, variants []ImageVariant) error
+	LoadVariants(ctx context.Context, images []*Image) error
+
+	// Backfill of derived data
+	ListBackfillCandidates(ctx context.Context, afterID int, limit int) ([]int, error)
+	CountBackfillCandidates(ctx context.Context, afterID int) (int, error)
+	GetBackfillCheckpoint(ctx context.Context, job string) (*BackfillCheckpoint, error)
+	SaveBackfillCheckpoint(ctx context.Context, checkpoint *BackfillCheckpoint) error
 
 	// Tag relationships
 	GetWithTags(ctx context.Context, pagination PaginationParams, sort SortParams) ([]*Image, error)
@@ -54,6 +68,24 @@ type TagRepository interface {
 	// Predefined tags
 	GetPredefined(ctx context.Context) ([]*Tag, error)
 	GetPredefinedByCategory(ctx context.Context) (map[string][]*Tag, error)
+	RenameCategory(ctx context.Context, from, to string) (int, error)
+
+	// Taxonomy maintenance
+	Merge(ctx context.Context, sourceID, targetID int) error
+	GetAll(ctx context.Context) ([]*Tag, error)
+	SetParent(ctx context.Context, tagID int, parentID *int) error
+
+	// Aliases
+	ResolveAlias(ctx context.Context, alias string) (*Tag, error)
+	ListAliases(ctx context.Context, tagID int) ([]*TagAlias, error)
+	CreateAlias(ctx context.Context, alias *TagAlias) error
+	DeleteAlias(ctx context.Context, alias string) error
+
+	// Approval queue
+	AddPending(ctx context.Context, name string, imageID int) error
+	ListPending(ctx context.Context) ([]*PendingTag, error)
+	ApprovePending(ctx context.Context, name string, maxTagsPerImage int) (*Tag, []int, error)
+	DeletePending(ctx context.Context, name string) error
 
 	// Statistics
 	Count(ctx context.Context) (int, error)
@@ -64,6 +96,7 @@ type TagRepository interface {
 	RemoveAllFromImage(ctx context.Context, imageID int) error
 	GetImageTags(ctx context.Context, imageID int) ([]*Tag, error)
 	GetTagImages(ctx context.Context, tagID int, pagination PaginationParams) ([]*Image, error)
+	GetTagImageIDs(ctx context.Context, tagID int) ([]int, error)
 	CountImageTags(ctx context.Context, imageID int) (int, error)
 	CountTagImages(ctx context.Context, tagID int) (int, error)
 }
@@ -97,6 +130,43 @@ type AlbumRepository interface {
 	CountAlbumImages(ctx context.Context, albumID int) (int, error)
 }
 
+// JobRepository defines the interface for the image processing job queue
+type JobRepository interface {
+	Enqueue(ctx context.Context, imageID int, jobType string, maxAttempts int) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*ProcessingJob, error)
+	Complete(ctx context.Context, id int64, attempt int) error
+	Retry(ctx context.Context, id int64, attempt int, lastError string, runAt time.Time) error
+	Bury(ctx context.Context, id int64, attempt int, lastError string) error
+
+	// Status of images' processing
+	ListLatest(ctx context.Context, imageIDs []int) ([]*ProcessingJob, error)
+	ListDead(ctx context.Context, pagination PaginationParams) ([]*ProcessingJob, error)
+	CountDead(ctx context.Context) (int, error)
+	Requeue(ctx context.Context, id int64) error
+}
+
+// UploadRepository defines the interface for resumable upload state
+type UploadRepository interface {
+	Create(ctx context.Context, upload *ResumableUpload) error
+	GetByID(ctx context.Context, id string) (*ResumableUpload, error)
+	Save(ctx context.Context, upload *ResumableUpload) error
+	Delete(ctx context.Context, id string) error
+
+	// One chunk is written at a time
+	Lock(ctx context.Context, id string, lease time.Duration) (*ResumableUpload, error)
+	Unlock(ctx context.Context, id string) error
+
+	// Expiry
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*ResumableUpload, error)
+}
+
+// DirectUploadRepository defines the interface for claims on completing direct uploads
+type DirectUploadRepository interface {
+	Claim(ctx context.Context, key string, lease time.Duration) error
+	Complete(ctx context.Context, key string, imageID int) error
+	Release(ctx context.Context, key string) error
+}
+
 // Repositories aggregates all repository interfaces
 type Repositories struct {
 	Images ImageRepository
@@ -131,6 +201,7 @@ func scanImages(ctx context.Context, rows *sql.Rows) ([]*Image, error) {
 			&image.Width,
 			&image.Height,
 			&image.UploadedAt,
+			&image.TakenAt,
 			&image.Metadata,
 			&image.CreatedAt,
 			&image.UpdatedAt,
@@ -4,8 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+
+	"github.com/lib/pq"
 )
 
+// tagColumns is the column list scanned by scanTag; queries must alias tags as t
+const tagColumns = `t.id, t.name, t.description, t.color, t.category,
+		COALESCE(t.is_predefined, false), COALESCE(t.is_active, true), COALESCE(t.display_order, 0),
+		t.parent_id, t.created_at`
+
 // tagRepository implements TagRepository interface
 type tagRepository struct {
 	db *sql.DB
@@ -19,8 +26,8 @@ func NewTagRepository(db *sql.DB) TagRepository {
 // Create inserts a new tag record
 func (r *tagRepository) Create(ctx context.Context, tag *Tag) error {
 	query := `
-		INSERT INTO tags (name, description, color)
-		VALUES ($1, $2, $3)
+		INSERT INTO tags (name, description, color, category, is_predefined, is_active, display_order, parent_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`
 
@@ -29,6 +36,11 @@ func (r *tagRepository) Create(ctx context.Context, tag *Tag) error {
 		tag.Name,
 		tag.Description,
 		tag.Color,
+		tag.Category,
+		tag.IsPredefined,
+		tag.IsActive,
+		tag.DisplayOrder,
+		tag.ParentID,
 	).Scan(&tag.ID, &tag.CreatedAt)
 
 	return err
@@ -36,22 +48,13 @@ func (r *tagRepository) Create(ctx context.Context, tag *Tag) error {
 
 // GetByID retrieves a tag by its ID
 func (r *tagRepository) GetByID(ctx context.Context, id int) (*Tag, error) {
-	query := `
-		SELECT id, name, description, color, created_at
-		FROM tags WHERE id = $1
-	`
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.id = $1`
 
 	tag := &Tag{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&tag.ID,
-		&tag.Name,
-		&tag.Description,
-		&tag.Color,
-		&tag.CreatedAt,
-	)
+	err := scanTag(r.db.QueryRowContext(ctx, query, id), tag)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tag with ID %d not found", id)
+		return nil, fmt.Errorf("%w: tag with ID %d", ErrTagNotFound, id)
 	}
 
 	return tag, err
@@ -59,38 +62,36 @@ func (r *tagRepository) GetByID(ctx context.Context, id int) (*Tag, error) {
 
 // GetByName retrieves a tag by its name
 func (r *tagRepository) GetByName(ctx context.Context, name string) (*Tag, error) {
-	query := `
-		SELECT id, name, description, color, created_at
-		FROM tags WHERE name = $1
-	`
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.name = $1`
 
 	tag := &Tag{}
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
-		&tag.ID,
-		&tag.Name,
-		&tag.Description,
-		&tag.Color,
-		&tag.CreatedAt,
-	)
+	err := scanTag(r.db.QueryRowContext(ctx, query, name), tag)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tag with name %s not found", name)
+		return nil, fmt.Errorf("%w: tag with name %s", ErrTagNotFound, name)
 	}
 
 	return tag, err
 }
 
-// Update updates an existing tag record
+// Update updates an existing tag record; the parent is changed through SetParent only
 func (r *tagRepository) Update(ctx context.Context, tag *Tag) error {
 	query := `
 		UPDATE tags SET
 			name = $2,
 			description = $3,
-			color = $4
+			color = $4,
+			category = $5,
+			is_predefined = $6,
+			is_active = $7,
+			display_order = $8
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, tag.ID, tag.Name, tag.Description, tag.Color)
+	result, err := r.db.ExecContext(ctx, query,
+		tag.ID, tag.Name, tag.Description, tag.Color,
+		tag.Category, tag.IsPredefined, tag.IsActive, tag.DisplayOrder,
+	)
 	if err != nil {
 		return err
 	}
@@ -101,7 +102,7 @@ func (r *tagRepository) Update(ctx context.Context, tag *Tag) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("tag with ID %d not found", tag.ID)
+		return fmt.Errorf("%w: tag with ID %d", ErrTagNotFound, tag.ID)
 	}
 
 	return nil
@@ -122,7 +123,7 @@ func (r *tagRepository) Delete(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("tag with ID %d not found", id)
+		return fmt.Errorf("%w: tag with ID %d", ErrTagNotFound, id)
 	}
 
 	return nil
@@ -133,9 +134,9 @@ func (r *tagRepository) List(ctx context.Context, pagination PaginationParams) (
 	pagination.Validate()
 
 	query := `
-		SELECT id, name, description, color, created_at
-		FROM tags
-		ORDER BY name ASC
+		SELECT ` + tagColumns + `
+		FROM tags t
+		ORDER BY t.name ASC
 		LIMIT $1 OFFSET $2
 	`
 
@@ -147,10 +148,10 @@ func (r *tagRepository) Search(ctx context.Context, query string, pagination Pag
 	pagination.Validate()
 
 	sqlQuery := `
-		SELECT id, name, description, color, created_at
-		FROM tags
-		WHERE name ILIKE $1
-		ORDER BY name ASC
+		SELECT ` + tagColumns + `
+		FROM tags t
+		WHERE t.name ILIKE $1
+		ORDER BY t.name ASC
 		LIMIT $2 OFFSET $3
 	`
 
@@ -165,10 +166,10 @@ func (r *tagRepository) GetPopular(ctx context.Context, limit int) ([]*Tag, erro
 	}
 
 	query := `
-		SELECT t.id, t.name, t.description, t.color, t.created_at, COUNT(it.image_id) as image_count
+		SELECT ` + tagColumns + `, COUNT(it.image_id) as image_count
 		FROM tags t
 		LEFT JOIN image_tags it ON t.id = it.tag_id
-		GROUP BY t.id, t.name, t.description, t.color, t.created_at
+		GROUP BY t.id
 		ORDER BY image_count DESC, t.name ASC
 		LIMIT $1
 	`
@@ -182,14 +183,7 @@ func (r *tagRepository) GetPopular(ctx context.Context, limit int) ([]*Tag, erro
 	var tags []*Tag
 	for rows.Next() {
 		tag := &Tag{}
-		err := rows.Scan(
-			&tag.ID,
-			&tag.Name,
-			&tag.Description,
-			&tag.Color,
-			&tag.CreatedAt,
-			&tag.ImageCount,
-		)
+		err := scanTag(rows, tag, &tag.ImageCount)
 		if err != nil {
 			return nil, err
 		}
@@ -204,10 +198,10 @@ func (r *tagRepository) GetWithImageCount(ctx context.Context, pagination Pagina
 	pagination.Validate()
 
 	query := `
-		SELECT t.id, t.name, t.description, t.color, t.created_at, COUNT(it.image_id) as image_count
+		SELECT ` + tagColumns + `, COUNT(it.image_id) as image_count
 		FROM tags t
 		LEFT JOIN image_tags it ON t.id = it.tag_id
-		GROUP BY t.id, t.name, t.description, t.color, t.created_at
+		GROUP BY t.id
 		ORDER BY t.name ASC
 		LIMIT $1 OFFSET $2
 	`
@@ -221,14 +215,7 @@ func (r *tagRepository) GetWithImageCount(ctx context.Context, pagination Pagina
 	var tags []*Tag
 	for rows.Next() {
 		tag := &Tag{}
-		err := rows.Scan(
-			&tag.ID,
-			&tag.Name,
-			&tag.Description,
-			&tag.Color,
-			&tag.CreatedAt,
-			&tag.ImageCount,
-		)
+		err := scanTag(rows, tag, &tag.ImageCount)
 		if err != nil {
 			return nil, err
 		}
@@ -276,7 +263,7 @@ func (r *tagRepository) RemoveAllFromImage(ctx context.Context, imageID int) err
 // GetImageTags retrieves all tags for a specific image
 func (r *tagRepository) GetImageTags(ctx context.Context, imageID int) ([]*Tag, error) {
 	query := `
-		SELECT t.id, t.name, t.description, t.color, t.created_at
+		SELECT ` + tagColumns + `
 		FROM tags t
 		INNER JOIN image_tags it ON t.id = it.tag_id
 		WHERE it.image_id = $1
@@ -292,7 +279,7 @@ func (r *tagRepository) GetTagImages(ctx context.Context, tagID int, pagination
 
 	query := `
 		SELECT i.id, i.filename, i.original_filename, i.content_type, i.file_size,
-			   i.storage_path, i.thumbnail_path, i.width, i.height, i.uploaded_at,
+			   i.storage_path, i.thumbnail_path, i.width, i.height, i.uploaded_at, i.taken_at,
 			   i.metadata, i.created_at, i.updated_at
 		FROM images i
 		INNER JOIN image_tags it ON i.id = it.image_id
@@ -309,6 +296,26 @@ func (r *tagRepository) GetTagImages(ctx context.Context, tagID int, pagination
 	return scanImages(ctx, rows)
 }
 
+// GetTagImageIDs retrieves the IDs of all images carrying a specific tag
+func (r *tagRepository) GetTagImageIDs(ctx context.Context, tagID int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT image_id FROM image_tags WHERE tag_id = $1`, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 // CountImageTags returns the number of tags for an image
 func (r *tagRepository) CountImageTags(ctx context.Context, imageID int) (int, error) {
 	var count int
@@ -326,10 +333,10 @@ func (r *tagRepository) CountTagImages(ctx context.Context, tagID int) (int, err
 // GetPredefined retrieves all active predefined tags ordered by display_order and name
 func (r *tagRepository) GetPredefined(ctx context.Context) ([]*Tag, error) {
 	query := `
-		SELECT id, name, description, color, created_at
-		FROM tags
-		WHERE is_predefined = true AND is_active = true
-		ORDER BY display_order ASC, name ASC
+		SELECT ` + tagColumns + `
+		FROM tags t
+		WHERE t.is_predefined = true AND t.is_active = true
+		ORDER BY t.display_order ASC, t.name ASC
 	`
 	return r.scanTags(ctx, query)
 }
@@ -337,10 +344,10 @@ func (r *tagRepository) GetPredefined(ctx context.Context) ([]*Tag, error) {
 // GetPredefinedByCategory retrieves predefined tags grouped by category
 func (r *tagRepository) GetPredefinedByCategory(ctx context.Context) (map[string][]*Tag, error) {
 	query := `
-		SELECT id, name, description, color, created_at, category
-		FROM tags
-		WHERE is_predefined = true AND is_active = true
-		ORDER BY category ASC, display_order ASC, name ASC
+		SELECT ` + tagColumns + `
+		FROM tags t
+		WHERE t.is_predefined = true AND t.is_active = true
+		ORDER BY t.category ASC, t.display_order ASC, t.name ASC
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -352,22 +359,13 @@ func (r *tagRepository) GetPredefinedByCategory(ctx context.Context) (map[string
 	tagsByCategory := make(map[string][]*Tag)
 	for rows.Next() {
 		tag := &Tag{}
-		var category sql.NullString
-		err := rows.Scan(
-			&tag.ID,
-			&tag.Name,
-			&tag.Description,
-			&tag.Color,
-			&tag.CreatedAt,
-			&category,
-		)
-		if err != nil {
+		if err := scanTag(rows, tag); err != nil {
 			return nil, err
 		}
 
 		cat := "other"
-		if category.Valid {
-			cat = category.String
+		if tag.Category != nil {
+			cat = *tag.Category
 		}
 
 		tagsByCategory[cat] = append(tagsByCategory[cat], tag)
@@ -376,6 +374,381 @@ func (r *tagRepository) GetPredefinedByCategory(ctx context.Context) (map[string
 	return tagsByCategory, rows.Err()
 }
 
+// GetAll retrieves every active tag ordered for display, used to build the tag hierarchy
+func (r *tagRepository) GetAll(ctx context.Context) ([]*Tag, error) {
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags t
+		WHERE COALESCE(t.is_active, true) = true
+		ORDER BY COALESCE(t.display_order, 0) ASC, t.name ASC
+	`
+	return r.scanTags(ctx, query)
+}
+
+// SetParent moves a tag (and with it its whole subtree) under a new parent, or to the root when parentID is nil.
+// Moves are serialized with an advisory lock so two concurrent moves cannot create a cycle together.
+func (r *tagRepository) SetParent(ctx context.Context, tagID int, parentID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Transaction cleanup
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('tags.parent_id'))`); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		// The new parent must not be the tag itself or one of its descendants
+		var createsCycle bool
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM tags WHERE id = $1
+				UNION
+				SELECT c.id FROM tags c INNER JOIN subtree s ON c.parent_id = s.id
+			)
+			SELECT EXISTS(SELECT 1 FROM subtree WHERE id = $2)
+		`, tagID, *parentID).Scan(&createsCycle)
+		if err != nil {
+			return err
+		}
+		if createsCycle {
+			return fmt.Errorf("%w: tag %d cannot be moved under %d", ErrTagCycle, tagID, *parentID)
+		}
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tags WHERE id = $1)`, *parentID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: tag with ID %d", ErrTagNotFound, *parentID)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE tags SET parent_id = $2 WHERE id = $1`, tagID, parentID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: tag with ID %d", ErrTagNotFound, tagID)
+	}
+
+	return tx.Commit()
+}
+
+// RenameCategory moves every tag in one category to another, returning the number of tags changed
+func (r *tagRepository) RenameCategory(ctx context.Context, from, to string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE tags SET category = $2 WHERE category = $1`, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return int(rowsAffected), err
+}
+
+// Merge moves all image associations from the source tag to the target tag and deletes the source.
+// Images already carrying the target tag keep a single association.
+func (r *tagRepository) Merge(ctx context.Context, sourceID, targetID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Transaction cleanup
+
+	// Lock both tags so a concurrent merge or delete cannot interleave
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM tags WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array([]int{sourceID, targetID}))
+	if err != nil {
+		return err
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	_ = rows.Close() //nolint:errcheck // Closed before issuing further statements on the transaction
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if found != 2 {
+		return fmt.Errorf("%w: tag with ID %d or %d", ErrTagNotFound, sourceID, targetID)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO image_tags (image_id, tag_id)
+		SELECT image_id, $2 FROM image_tags WHERE tag_id = $1
+		ON CONFLICT (image_id, tag_id) DO NOTHING
+	`, sourceID, targetID)
+	if err != nil {
+		return err
+	}
+
+	// Children of the source move up to the source's parent so the hierarchy stays connected
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('tags.parent_id'))`); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tags SET parent_id = (SELECT parent_id FROM tags WHERE id = $1)
+		WHERE parent_id = $1
+	`, sourceID)
+	if err != nil {
+		return err
+	}
+
+	// Aliases follow the source, and the source name itself becomes an alias of the target
+	if _, err := tx.ExecContext(ctx, `UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1`, sourceID, targetID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tag_aliases (alias, tag_id)
+		SELECT name, $2 FROM tags WHERE id = $1
+		ON CONFLICT (alias) DO NOTHING
+	`, sourceID, targetID)
+	if err != nil {
+		return err
+	}
+
+	// Deleting the source tag cascades to its remaining image_tags rows
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResolveAlias returns the tag an alias points to
+func (r *tagRepository) ResolveAlias(ctx context.Context, alias string) (*Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tag_aliases ta INNER JOIN tags t ON t.id = ta.tag_id WHERE ta.alias = $1`
+
+	tag := &Tag{}
+	err := scanTag(r.db.QueryRowContext(ctx, query, alias), tag)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrAliasNotFound, alias)
+	}
+
+	return tag, err
+}
+
+// ListAliases returns all aliases, or only those of a single tag when tagID is positive
+func (r *tagRepository) ListAliases(ctx context.Context, tagID int) ([]*TagAlias, error) {
+	query := `
+		SELECT ta.alias, ta.tag_id, t.name, ta.created_at
+		FROM tag_aliases ta
+		INNER JOIN tags t ON t.id = ta.tag_id
+		WHERE $1 <= 0 OR ta.tag_id = $1
+		ORDER BY t.name, ta.alias
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var aliases []*TagAlias
+	for rows.Next() {
+		alias := &TagAlias{}
+		if err := rows.Scan(&alias.Alias, &alias.TagID, &alias.TagName, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// CreateAlias stores a new alias. An alias may not shadow an existing tag name or alias.
+func (r *tagRepository) CreateAlias(ctx context.Context, alias *TagAlias) error {
+	query := `
+		INSERT INTO tag_aliases (alias, tag_id)
+		SELECT $1, t.id FROM tags t
+		WHERE t.id = $2 AND NOT EXISTS (SELECT 1 FROM tags WHERE name = $1)
+		ON CONFLICT (alias) DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, alias.Alias, alias.TagID).Scan(&alias.CreatedAt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	// Nothing was inserted: work out whether the tag is missing or the alias is taken
+	if _, err := r.GetByID(ctx, alias.TagID); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrAliasConflict, alias.Alias)
+}
+
+// DeleteAlias removes an alias
+func (r *tagRepository) DeleteAlias(ctx context.Context, alias string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tag_aliases WHERE alias = $1`, alias)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrAliasNotFound, alias)
+	}
+
+	return nil
+}
+
+// AddPending records that an image requested a tag awaiting approval
+func (r *tagRepository) AddPending(ctx context.Context, name string, imageID int) error {
+	query := `
+		INSERT INTO pending_tags (name, image_id) VALUES ($1, $2)
+		ON CONFLICT (name, image_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, name, imageID)
+	return err
+}
+
+// ListPending returns the pending tags with their requesting images, oldest request first
+func (r *tagRepository) ListPending(ctx context.Context) ([]*PendingTag, error) {
+	query := `
+		SELECT name, array_agg(image_id ORDER BY image_id), MIN(requested_at), MAX(requested_at)
+		FROM pending_tags
+		GROUP BY name
+		ORDER BY MIN(requested_at), name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var pending []*PendingTag
+	for rows.Next() {
+		tag := &PendingTag{}
+		var imageIDs []int64
+		if err := rows.Scan(&tag.Name, pq.Array(&imageIDs), &tag.FirstRequestedAt, &tag.LastRequestedAt); err != nil {
+			return nil, err
+		}
+		tag.ImageIDs = make([]int, len(imageIDs))
+		for i, id := range imageIDs {
+			tag.ImageIDs[i] = int(id)
+		}
+		pending = append(pending, tag)
+	}
+
+	return pending, rows.Err()
+}
+
+// ApprovePending creates (or promotes) the tag as an active predefined tag, attaches it to
+// every requesting image that is still below maxTagsPerImage and clears the queue entry.
+// It returns the tag and the IDs of the images the tag was attached to.
+func (r *tagRepository) ApprovePending(ctx context.Context, name string, maxTagsPerImage int) (*Tag, []int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Transaction cleanup
+
+	// Lock the requesting images so concurrent tag edits cannot push them past the limit
+	rows, err := tx.QueryContext(ctx, `
+		SELECT i.id FROM images i
+		INNER JOIN pending_tags p ON p.image_id = i.id
+		WHERE p.name = $1
+		ORDER BY i.id
+		FOR UPDATE OF i
+	`, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	_ = rows.Close() //nolint:errcheck // Closed before issuing further statements on the transaction
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if found == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrPendingTagNotFound, name)
+	}
+
+	var tagID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tags (name, is_predefined, is_active) VALUES ($1, true, true)
+		ON CONFLICT (name) DO UPDATE SET is_predefined = true, is_active = true
+		RETURNING id
+	`, name).Scan(&tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tag := &Tag{}
+	if err := scanTag(tx.QueryRowContext(ctx, `SELECT `+tagColumns+` FROM tags t WHERE t.id = $1`, tagID), tag); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		INSERT INTO image_tags (image_id, tag_id)
+		SELECT p.image_id, $2 FROM pending_tags p
+		WHERE p.name = $1
+		  AND (SELECT COUNT(*) FROM image_tags it WHERE it.image_id = p.image_id) < $3
+		ON CONFLICT (image_id, tag_id) DO NOTHING
+		RETURNING image_id
+	`, name, tagID, maxTagsPerImage)
+	if err != nil {
+		return nil, nil, err
+	}
+	var imageIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close() //nolint:errcheck // Already returning error
+			return nil, nil, err
+		}
+		imageIDs = append(imageIDs, id)
+	}
+	_ = rows.Close() //nolint:errcheck // Closed before issuing further statements on the transaction
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pending_tags WHERE name = $1`, name); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return tag, imageIDs, nil
+}
+
+// DeletePending discards a pending tag for all requesting images
+func (r *tagRepository) DeletePending(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM pending_tags WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrPendingTagNotFound, name)
+	}
+
+	return nil
+}
+
 // scanTags is a helper method to scan multiple tag records
 func (r *tagRepository) scanTags(ctx context.Context, query string, args ...interface{}) ([]*Tag, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -387,14 +760,7 @@ func (r *tagRepository) scanTags(ctx context.Context, query string, args ...inte
 	var tags []*Tag
 	for rows.Next() {
 		tag := &Tag{}
-		err := rows.Scan(
-			&tag.ID,
-			&tag.Name,
-			&tag.Description,
-			&tag.Color,
-			&tag.CreatedAt,
-		)
-		if err != nil {
+		if err := scanTag(rows, tag); err != nil {
 			return nil, err
 		}
 		tags = append(tags, tag)
@@ -402,3 +768,25 @@ func (r *tagRepository) scanTags(ctx context.Context, query string, args ...inte
 
 	return tags, rows.Err()
 }
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTag scans the tagColumns selection into tag, followed by any extra destinations
+func scanTag(row rowScanner, tag *Tag, extra ...interface{}) error {
+	dest := []interface{}{
+		&tag.ID,
+		&tag.Name,
+		&tag.Description,
+		&tag.Color,
+		&tag.Category,
+		&tag.IsPredefined,
+		&tag.IsActive,
+		&tag.DisplayOrder,
+		&tag.ParentID,
+		&tag.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
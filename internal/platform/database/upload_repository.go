@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// uploadColumns are the columns of resumable_uploads, in the order scanUpload reads them
+const uploadColumns = `
+	id, upload_length, upload_offset, filename, content_type, tags, metadata_policy,
+	storage_path, multipart_id, parts, tail_size, image_id, expires_at, created_at, updated_at
+`
+
+// uploadRepository implements UploadRepository interface
+type uploadRepository struct {
+	db *sql.DB
+}
+
+// NewUploadRepository creates a new UploadRepository
+func NewUploadRepository(db *sql.DB) UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+// Create records a new upload
+func (r *uploadRepository) Create(ctx context.Context, upload *ResumableUpload) error {
+	query := `
+		INSERT INTO resumable_uploads (id, upload_length, upload_offset, filename, content_type, tags,
+			metadata_policy, storage_path, multipart_id, parts, tail_size, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		upload.ID,
+		upload.Length,
+		upload.Offset,
+		upload.Filename,
+		upload.ContentType,
+		pq.Array(upload.Tags),
+		upload.MetadataPolicy,
+		upload.StoragePath,
+		upload.MultipartID,
+		upload.Parts,
+		upload.TailSize,
+		upload.ExpiresAt,
+	).Scan(&upload.CreatedAt, &upload.UpdatedAt)
+}
+
+// GetByID returns an upload, expired or not
+func (r *uploadRepository) GetByID(ctx context.Context, id string) (*ResumableUpload, error) {
+	query := `SELECT ` + uploadColumns + ` FROM resumable_uploads WHERE id = $1`
+
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUploadNotFound
+	}
+	return upload, err
+}
+
+// Save records the progress of an upload and releases its lock
+func (r *uploadRepository) Save(ctx context.Context, upload *ResumableUpload) error {
+	query := `
+		UPDATE resumable_uploads
+		SET upload_offset = $2, multipart_id = $3, parts = $4, tail_size = $5, image_id = $6,
+			expires_at = $7, locked_at = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		upload.ID,
+		upload.Offset,
+		upload.MultipartID,
+		upload.Parts,
+		upload.TailSize,
+		upload.ImageID,
+		upload.ExpiresAt,
+	).Scan(&upload.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUploadNotFound
+	}
+	return err
+}
+
+// Delete removes an upload's record
+func (r *uploadRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM resumable_uploads WHERE id = $1`, id)
+	return err
+}
+
+// Lock marks an upload as being written and returns it. It returns ErrUploadLocked
+// while another request holds the lock, unless that request has held it for longer
+// than the lease, when it is taken to have gone.
+func (r *uploadRepository) Lock(ctx context.Context, id string, lease time.Duration) (*ResumableUpload, error) {
+	query := `
+		UPDATE resumable_uploads
+		SET locked_at = NOW()
+		WHERE id = $1 AND (locked_at IS NULL OR locked_at < NOW() - make_interval(secs => $2))
+		RETURNING ` + uploadColumns
+
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, query, id, lease.Seconds()))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return upload, err
+	}
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, ErrUploadLocked
+}
+
+// Unlock releases an upload's lock without recording any progress
+func (r *uploadRepository) Unlock(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE resumable_uploads SET locked_at = NULL WHERE id = $1`, id)
+	return err
+}
+
+// ListExpired returns up to limit uploads that expired before the given time, oldest
+// first
+func (r *uploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*ResumableUpload, error) {
+	query := `
+		SELECT ` + uploadColumns + `
+		FROM resumable_uploads
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck // Resource cleanup
+
+	var uploads []*ResumableUpload
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, rows.Err()
+}
+
+// scanUpload reads an upload selected with uploadColumns
+func scanUpload(row rowScanner) (*ResumableUpload, error) {
+	upload := &ResumableUpload{}
+	err := row.Scan(
+		&upload.ID,
+		&upload.Length,
+		&upload.Offset,
+		&upload.Filename,
+		&upload.ContentType,
+		pq.Array(&upload.Tags),
+		&upload.MetadataPolicy,
+		&upload.StoragePath,
+		&upload.MultipartID,
+		&upload.Parts,
+		&upload.TailSize,
+		&upload.ImageID,
+		&upload.ExpiresAt,
+		&upload.CreatedAt,
+		&upload.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
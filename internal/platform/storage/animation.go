@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// gifDelayUnit is the unit of GIF frame delays
+const gifDelayUnit = 10 * time.Millisecond
+
+// readAnimation counts the frames of a GIF or WebP stream and adds up their delays,
+// skipping over the image data without decoding it. Other formats have one frame.
+func readAnimation(data io.Reader, format string) (frames int, duration time.Duration, err error) {
+	switch format {
+	case formatGIF:
+		return readGIFAnimation(bufio.NewReader(data))
+	case formatWebP:
+		return readWebPAnimation(bufio.NewReader(data))
+	}
+	return 1, 0, nil
+}
+
+// readGIFAnimation walks the blocks of a GIF: a graphic control extension carries the
+// delay of the image descriptor that follows it
+func readGIFAnimation(r *bufio.Reader) (int, time.Duration, error) {
+	header := make([]byte, 13) // Signature, version and logical screen descriptor
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, fmt.Errorf("failed to read GIF header: %w", err)
+	}
+	if !bytes.HasPrefix(header, []byte("GIF8")) {
+		return 0, 0, errors.New("not a GIF")
+	}
+	if err := skipColorTable(r, header[10]); err != nil {
+		return 0, 0, err
+	}
+
+	frames := 0
+	var duration, delay time.Duration
+	for {
+		introducer, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read GIF block: %w", err)
+		}
+
+		switch introducer {
+		case 0x21: // Extension
+			label, err := r.ReadByte()
+			if err != nil {
+				return 0, 0, err
+			}
+			if label == 0xF9 { // Graphic control extension
+				gce := make([]byte, 6) // Block size, flags, delay, transparent index, terminator
+				if _, err := io.ReadFull(r, gce); err != nil {
+					return 0, 0, err
+				}
+				delay = time.Duration(binary.LittleEndian.Uint16(gce[2:4])) * gifDelayUnit
+				continue
+			}
+			if err := skipSubBlocks(r); err != nil {
+				return 0, 0, err
+			}
+		case 0x2C: // Image descriptor
+			descriptor := make([]byte, 10) // Position, size, flags and LZW minimum code size
+			if _, err := io.ReadFull(r, descriptor[:9]); err != nil {
+				return 0, 0, err
+			}
+			if err := skipColorTable(r, descriptor[8]); err != nil {
+				return 0, 0, err
+			}
+			if _, err := r.ReadByte(); err != nil {
+				return 0, 0, err
+			}
+			if err := skipSubBlocks(r); err != nil {
+				return 0, 0, err
+			}
+			frames++
+			duration += delay
+			delay = 0
+		case 0x3B: // Trailer
+			return frames, duration, nil
+		default:
+			return 0, 0, fmt.Errorf("invalid GIF block 0x%02x", introducer)
+		}
+	}
+}
+
+// skipColorTable skips the color table that a GIF descriptor's flags announce
+func skipColorTable(r *bufio.Reader, flags byte) error {
+	if flags&0x80 == 0 {
+		return nil
+	}
+	_, err := r.Discard(3 << ((flags & 0x07) + 1))
+	return err
+}
+
+// skipSubBlocks skips a chain of GIF data sub-blocks up to its zero-length terminator
+func skipSubBlocks(r *bufio.Reader) error {
+	for {
+		size, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if _, err := r.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
+
+// readWebPAnimation walks the chunks of a WebP: each ANMF chunk is a frame that starts
+// with its position, size and duration in milliseconds
+func readWebPAnimation(r *bufio.Reader) (int, time.Duration, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, fmt.Errorf("failed to read WebP header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WEBP" {
+		return 0, 0, errors.New("not a WebP")
+	}
+
+	frames := 0
+	var duration time.Duration
+	for {
+		chunk := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, 0, fmt.Errorf("failed to read WebP chunk: %w", err)
+		}
+		size := int(binary.LittleEndian.Uint32(chunk[4:8]))
+		padded := size + size&1
+
+		if string(chunk[0:4]) == "ANMF" && size >= 16 {
+			frame := make([]byte, 16)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return 0, 0, err
+			}
+			frames++
+			duration += time.Duration(uint24(frame[12:15])) * time.Millisecond
+			padded -= len(frame)
+		}
+		if _, err := r.Discard(padded); err != nil {
+			if errors.Is(err, io.EOF) {
+				break // Some encoders leave out the padding of the last chunk
+			}
+			return 0, 0, err
+		}
+	}
+
+	if frames == 0 {
+		return 1, 0, nil
+	}
+	return frames, duration, nil
+}
+
+// uint24 decodes a little-endian 24-bit number
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+// webpFirstFrame rewrites an animated WebP as a still WebP of its first frame, as the
+// WebP decoder does not read animations. It reports false for other files.
+func webpFirstFrame(raw []byte) ([]byte, bool) {
+	if len(raw) < 12 || string(raw[0:4]) != "RIFF" || string(raw[8:12]) != "WEBP" {
+		return nil, false
+	}
+
+	for rest := raw[12:]; len(rest) >= 8; {
+		size := int(binary.LittleEndian.Uint32(rest[4:8]))
+		if size > len(rest)-8 {
+			return nil, false
+		}
+		payload := rest[8 : 8+size]
+		if string(rest[0:4]) == "ANMF" && size >= 16 {
+			return stillWebP(uint24(payload[6:9])+1, uint24(payload[9:12])+1, payload[16:]), true
+		}
+		rest = rest[min(8+size+size&1, len(rest)):]
+	}
+	return nil, false
+}
+
+// stillWebP wraps the ALPH, VP8 or VP8L chunks of a frame in an extended WebP container
+func stillWebP(width, height int, frameChunks []byte) []byte {
+	const alphaFlag = 1 << 4
+
+	vp8x := make([]byte, 18)
+	copy(vp8x, "VP8X")
+	binary.LittleEndian.PutUint32(vp8x[4:8], 10)
+	if bytes.Contains(frameChunks, []byte("ALPH")) {
+		vp8x[8] = alphaFlag
+	}
+	putUint24(vp8x[12:15], width-1)
+	putUint24(vp8x[15:18], height-1)
+
+	out := make([]byte, 0, 12+len(vp8x)+len(frameChunks))
+	out = append(out, "RIFF\x00\x00\x00\x00WEBP"...)
+	out = append(out, vp8x...)
+	out = append(out, frameChunks...)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8)) //nolint:gosec // Bounded by the input size
+	return out
+}
+
+// putUint24 encodes a little-endian 24-bit number
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// scaleAnimatedGIF scales every frame of an animated GIF to the given size. Frames are
+// composed onto a canvas first, as GIF frames may cover only part of the image, and
+// each is dithered back to its own palette so colors do not band.
+func scaleAnimatedGIF(src *gif.GIF, width, height int) *gif.GIF {
+	canvas := image.NewRGBA(image.Rect(0, 0, src.Config.Width, src.Config.Height))
+	bounds := image.Rect(0, 0, width, height)
+	out := &gif.GIF{LoopCount: src.LoopCount}
+
+	for i, frame := range src.Image {
+		disposal := byte(gif.DisposalNone)
+		if i < len(src.Disposal) {
+			disposal = src.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(canvas.Bounds())
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		scaled := image.NewRGBA(bounds)
+		draw.CatmullRom.Scale(scaled, bounds, canvas, canvas.Bounds(), draw.Src, nil)
+		out.Image = append(out.Image, ditherToPalette(scaled, frame.Palette))
+		out.Delay = append(out.Delay, src.Delay[i])
+		// Every output frame is a whole picture, so it replaces the one before
+		out.Disposal = append(out.Disposal, gif.DisposalBackground)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return out
+}
+
+// ditherToPalette converts an image to a paletted one with Floyd-Steinberg dithering
+func ditherToPalette(src image.Image, palette color.Palette) *image.Paletted {
+	dst := image.NewPaletted(src.Bounds(), palette)
+	draw.FloydSteinberg.Draw(dst, dst.Bounds(), src, src.Bounds().Min)
+	return dst
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// animatedGIF encodes a GIF whose frames have the given delays in hundredths of a second
+func animatedGIF(t *testing.T, width, height int, delays ...int) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Transparent, color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	anim := &gif.GIF{LoopCount: 0}
+	for i, delay := range delays {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		for x := 0; x < width; x++ {
+			frame.SetColorIndex(x, (x+i)%height, uint8(1+i%2))
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	return buf.Bytes()
+}
+
+// animatedWebP builds an animated WebP whose frames have the given durations in
+// milliseconds, reusing the bitstream of a still lossless WebP for every frame
+func animatedWebP(t *testing.T, width, height int, durations ...int) []byte {
+	t.Helper()
+
+	var still bytes.Buffer
+	require.NoError(t, nativewebp.Encode(&still, image.NewNRGBA(image.Rect(0, 0, width, height)), nil))
+	bitstream := still.Bytes()[12:] // The VP8L chunk
+
+	chunk := func(fourCC string, payload []byte) []byte {
+		out := append([]byte(fourCC), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:8], uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 1 << 1 // Animation
+	putUint24(vp8x[4:7], width-1)
+	putUint24(vp8x[7:10], height-1)
+
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", vp8x)...)
+	body = append(body, chunk("ANIM", make([]byte, 6))...)
+	for _, duration := range durations {
+		frame := make([]byte, 16)
+		putUint24(frame[6:9], width-1)
+		putUint24(frame[9:12], height-1)
+		putUint24(frame[12:15], duration)
+		body = append(body, chunk("ANMF", append(frame, bitstream...))...)
+	}
+	return chunk("RIFF", body)
+}
+
+func TestReadAnimation(t *testing.T) {
+	var stillPNG, stillWebP bytes.Buffer
+	require.NoError(t, png.Encode(&stillPNG, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	require.NoError(t, nativewebp.Encode(&stillWebP, image.NewNRGBA(image.Rect(0, 0, 4, 4)), nil))
+
+	tests := []struct {
+		name         string
+		data         []byte
+		format       string
+		wantFrames   int
+		wantDuration time.Duration
+	}{
+		{"animated GIF", animatedGIF(t, 8, 8, 10, 20, 30), formatGIF, 3, 600 * time.Millisecond},
+		{"still GIF", animatedGIF(t, 8, 8, 0), formatGIF, 1, 0},
+		{"animated WebP", animatedWebP(t, 8, 8, 100, 250), formatWebP, 2, 350 * time.Millisecond},
+		{"still WebP", stillWebP.Bytes(), formatWebP, 1, 0},
+		{"other formats", stillPNG.Bytes(), formatPNG, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, duration, err := readAnimation(bytes.NewReader(tt.data), tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrames, frames)
+			assert.Equal(t, tt.wantDuration, duration)
+		})
+	}
+
+	t.Run("truncated GIF", func(t *testing.T) {
+		data := animatedGIF(t, 8, 8, 10, 20)
+		_, _, err := readAnimation(bytes.NewReader(data[:len(data)-10]), formatGIF)
+		assert.Error(t, err)
+	})
+}
+
+func TestImageProcessor_GetImageInfo_Animation(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+
+	info, err := processor.GetImageInfo(context.Background(), bytes.NewReader(animatedGIF(t, 16, 12, 5, 5, 5, 5)))
+	require.NoError(t, err)
+	assert.Equal(t, 16, info.Width)
+	assert.Equal(t, 4, info.FrameCount)
+	assert.Equal(t, 200*time.Millisecond, info.Duration)
+
+	info, err = processor.GetImageInfo(context.Background(), bytes.NewReader(animatedWebP(t, 10, 6, 40, 40, 40)))
+	require.NoError(t, err)
+	assert.Equal(t, 10, info.Width)
+	assert.Equal(t, 3, info.FrameCount)
+	assert.Equal(t, 120*time.Millisecond, info.Duration)
+}
+
+func TestImageProcessor_GenerateThumbnail_Animated(t *testing.T) {
+	data := animatedGIF(t, 200, 100, 10, 10, 10)
+
+	t.Run("keeps every frame", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		thumb, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(data), 50, 50)
+		require.NoError(t, err)
+
+		anim, err := gif.DecodeAll(thumb)
+		require.NoError(t, err)
+		assert.Len(t, anim.Image, 3)
+		assert.Equal(t, []int{10, 10, 10}, anim.Delay)
+		assert.Equal(t, image.Rect(0, 0, 50, 25), anim.Image[0].Bounds())
+	})
+
+	t.Run("poster of the first frame", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		processor.SetAnimatedThumbnails(false)
+		thumb, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(data), 50, 50)
+		require.NoError(t, err)
+
+		anim, err := gif.DecodeAll(thumb)
+		require.NoError(t, err)
+		assert.Len(t, anim.Image, 1)
+		// The source palette is kept, padded to a power of two, rather than the encoder's
+		// default web palette
+		assert.Len(t, anim.Image[0].Palette, 4)
+		assert.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 255, A: 255}), color.RGBAModel.Convert(anim.Image[0].Palette[1]))
+	})
+
+	t.Run("animated WebP poster", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		thumb, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(animatedWebP(t, 40, 20, 100, 100)), 20, 20)
+		require.NoError(t, err)
+
+		cfg, format, err := image.DecodeConfig(thumb)
+		require.NoError(t, err)
+		assert.Equal(t, formatWebP, format)
+		assert.Equal(t, 20, cfg.Width)
+		assert.Equal(t, 10, cfg.Height)
+	})
+}
+
+func TestWebPFirstFrame(t *testing.T) {
+	frame, ok := webpFirstFrame(animatedWebP(t, 12, 7, 100, 100))
+	require.True(t, ok)
+
+	img, format, err := image.Decode(bytes.NewReader(frame))
+	require.NoError(t, err)
+	assert.Equal(t, formatWebP, format)
+	assert.Equal(t, image.Rect(0, 0, 12, 7), img.Bounds())
+
+	var still bytes.Buffer
+	require.NoError(t, nativewebp.Encode(&still, image.NewNRGBA(image.Rect(0, 0, 4, 4)), nil))
+	_, ok = webpFirstFrame(still.Bytes())
+	assert.False(t, ok, "still WebPs have no frames")
+	_, ok = webpFirstFrame([]byte("GIF89a"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"image"
+
+	"github.com/gen2brain/heic"
+	_ "golang.org/x/image/bmp"  // Import for BMP decoding support
+	_ "golang.org/x/image/tiff" // Import for TIFF decoding support
+)
+
+// Format names reported by image.DecodeConfig for the formats beyond JPEG, PNG, GIF and WebP
+const (
+	formatBMP  = "bmp"
+	formatTIFF = "tiff"
+	formatHEIC = "heic"
+)
+
+// contentTypeSVG is the content type of SVG uploads, which are sanitized instead of decoded
+const contentTypeSVG = "image/svg+xml"
+
+// heifBrands are the ISO BMFF major brands of HEIF still images. The heic package
+// registers only "heic", but phones and cameras also write these.
+var heifBrands = []string{"heix", "hevc", "heim", "heis", "mif1"}
+
+func init() {
+	for _, brand := range heifBrands {
+		image.RegisterFormat(formatHEIC, "????ftyp"+brand, heic.Decode, heic.DecodeConfig)
+	}
+}
+
+// isHEIFHeader reports whether a file header is an ISO BMFF ftyp box with a HEIF brand
+func isHEIFHeader(header []byte) bool {
+	if len(header) < 12 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+	brand := string(header[8:12])
+	if brand == formatHEIC {
+		return true
+	}
+	for _, b := range heifBrands {
+		if brand == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+func TestImageProcessor_ValidateImage_AdditionalFormats(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+
+	src := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		src.Set(x, x%30, color.RGBA{R: 200, A: 255})
+	}
+	var bmpBuf, tiffBuf bytes.Buffer
+	require.NoError(t, bmp.Encode(&bmpBuf, src))
+	require.NoError(t, tiff.Encode(&tiffBuf, src, nil))
+
+	heicData, err := os.ReadFile("testdata/sample.heic")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		expectError bool
+	}{
+		{"valid BMP", bmpBuf.Bytes(), "image/bmp", false},
+		{"valid TIFF", tiffBuf.Bytes(), "image/tiff", false},
+		{"valid HEIC", heicData, "image/heic", false},
+		{"HEIC as image/heif", heicData, "image/heif", false},
+		{"valid SVG", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="4" height="4"/></svg>`), contentTypeSVG, false},
+		{"malformed SVG", []byte(`<svg><rect></svg>`), contentTypeSVG, true},
+		{"TIFF labelled as BMP", tiffBuf.Bytes(), "image/bmp", true},
+		{"BMP labelled as HEIC", bmpBuf.Bytes(), "image/heic", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := processor.ValidateImage(context.Background(), bytes.NewReader(tt.data), tt.contentType)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestImageProcessor_GetImageInfo_HEIC(t *testing.T) {
+	processor := NewImageProcessor(4000, 4000, 85)
+
+	data, err := os.ReadFile("testdata/sample.heic")
+	require.NoError(t, err)
+
+	info, err := processor.GetImageInfo(context.Background(), bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, formatHEIC, info.Format)
+	assert.Positive(t, info.Width)
+	assert.Positive(t, info.Height)
+}
+
+func TestIsHEIFHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"heic brand", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"), true},
+		{"mif1 brand", []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00"), true},
+		{"avif brand", []byte("\x00\x00\x00\x18ftypavif\x00\x00\x00\x00"), false},
+		{"mp4 brand", []byte("\x00\x00\x00\x18ftypisom\x00\x00\x00\x00"), false},
+		{"no ftyp box", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), false},
+		{"too short", []byte("\x00\x00\x00\x18ftyp"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isHEIFHeader(tt.header))
+		})
+	}
+}
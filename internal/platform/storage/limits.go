@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+)
+
+// Default decode limits: a decode may produce at most 100 megapixels and 512MB of
+// pixel data, and four decodes run at a time
+const (
+	DefaultMaxPixels         = 100_000_000
+	DefaultDecodeMemory      = 512 << 20
+	DefaultConcurrentDecodes = 4
+)
+
+// ErrImageTooLarge is returned for images whose decoded pixels would exceed the pixel
+// count or memory budget of a decode. Compressed formats can declare dimensions far
+// beyond their file size, so the check is made on the header before decoding.
+var ErrImageTooLarge = errors.New("image too large to decode")
+
+// SetDecodeLimits sets the largest pixel count and decoded size in bytes an image may
+// have, and how many images are decoded at once. Zero keeps the default.
+func (p *ImageProcessor) SetDecodeLimits(maxPixels, memoryBudget int64, concurrent int) {
+	if maxPixels > 0 {
+		p.maxPixels = maxPixels
+	}
+	if memoryBudget > 0 {
+		p.decodeMemory = memoryBudget
+	}
+	if concurrent > 0 {
+		p.decodes = make(chan struct{}, concurrent)
+	}
+}
+
+// CheckDecodeLimits reads an image's header and returns ErrImageTooLarge when decoding
+// it would exceed the limits. Data that has no readable header passes: it is rejected
+// when it is decoded instead.
+func (p *ImageProcessor) CheckDecodeLimits(data io.Reader) error {
+	if data == nil {
+		return errors.New("data cannot be nil")
+	}
+
+	// A JPEG's EXIF comes before its frame header, so it is within what was read
+	var head bytes.Buffer
+	config, format, err := image.DecodeConfig(io.TeeReader(data, &head))
+	if err != nil {
+		return nil
+	}
+	return p.checkDecodeConfig(config, 1, reoriented(format, &head))
+}
+
+// reoriented reports whether decodeOriented turns an image of the format upright
+func reoriented(format string, data io.Reader) bool {
+	return format == formatJPEG && readOrientation(data) != OrientationNormal
+}
+
+// checkDecodeConfig checks the pixel count and decoded size of an image with the given
+// number of frames against the limits
+func (p *ImageProcessor) checkDecodeConfig(config image.Config, frames int, reoriented bool) error {
+	pixels := int64(config.Width) * int64(config.Height)
+	if pixels > p.maxPixels {
+		return fmt.Errorf("%w: %dx%d is %d pixels, over the limit of %d",
+			ErrImageTooLarge, config.Width, config.Height, pixels, p.maxPixels)
+	}
+
+	if size := decodedSize(config, frames, reoriented); size > p.decodeMemory {
+		return fmt.Errorf("%w: decoding %dx%d takes %d bytes, over the budget of %d",
+			ErrImageTooLarge, config.Width, config.Height, size, p.decodeMemory)
+	}
+	return nil
+}
+
+// decodedSize estimates the memory a decode takes. Every frame of an animation is kept,
+// along with the canvas and saved copy the frames are composed on, and a still that is
+// turned upright is kept along with its RGBA copy.
+func decodedSize(config image.Config, frames int, reoriented bool) int64 {
+	pixels := int64(config.Width) * int64(config.Height)
+	if frames > 1 {
+		return pixels * (int64(frames) + 8) // Paletted frames, an RGBA canvas and its copy
+	}
+	size := pixels * bytesPerPixel(config.ColorModel)
+	if reoriented {
+		size += pixels * 4
+	}
+	return size
+}
+
+// bytesPerPixel returns the size of a decoded pixel in a color model
+func bytesPerPixel(model color.Model) int64 {
+	switch model {
+	case color.GrayModel, color.AlphaModel:
+		return 1
+	case color.Gray16Model, color.Alpha16Model:
+		return 2
+	case color.YCbCrModel:
+		return 3 // Chroma is often subsampled, but not always
+	case color.RGBA64Model, color.NRGBA64Model:
+		return 8
+	}
+	if _, ok := model.(color.Palette); ok {
+		return 1
+	}
+	return 4
+}
+
+// acquireDecode waits for one of the concurrent decode slots, returning a function that
+// releases it. The slot is held for as long as the decoded image is in use.
+func (p *ImageProcessor) acquireDecode(ctx context.Context) (func(), error) {
+	slots := p.decodes
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting to decode image: %w", ctx.Err())
+	}
+}
+
+// decode reads and decodes an image upright, as decodeOriented does, once its header has
+// passed the decode limits and a decode slot is free. The returned function releases the
+// slot and must be called when the image is no longer used.
+func (p *ImageProcessor) decode(ctx context.Context, data io.Reader) (image.Image, string, func(), error) {
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if config, format, err := image.DecodeConfig(bytes.NewReader(raw)); err == nil {
+		if err := p.checkDecodeConfig(config, 1, reoriented(format, bytes.NewReader(raw))); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	release, err := p.acquireDecode(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	src, format, err := decodeOriented(bytes.NewReader(raw))
+	if err != nil {
+		release()
+		return nil, "", nil, err
+	}
+	return src, format, release, nil
+}
+
+// decodeAnimatedGIF decodes every frame of a GIF once its frame count and size have
+// passed the decode limits. A GIF of a single frame is returned as nil, to be decoded as
+// a still. The returned function releases the decode slot.
+func (p *ImageProcessor) decodeAnimatedGIF(ctx context.Context, raw []byte) (*gif.GIF, func(), error) {
+	frames, _, err := readAnimation(bytes.NewReader(raw), formatGIF)
+	if err != nil || frames <= 1 {
+		return nil, nil, nil
+	}
+	config, err := gif.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, nil
+	}
+	if err := p.checkDecodeConfig(config, frames, false); err != nil {
+		return nil, nil, err
+	}
+
+	release, err := p.acquireDecode(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(raw))
+	if err != nil {
+		release()
+		return nil, nil, nil
+	}
+	return anim, release, nil
+}
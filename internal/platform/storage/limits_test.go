@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader returns the signature and IHDR chunk of an 8-bit RGBA PNG of the given size,
+// which is all DecodeConfig reads. It decompresses to width x height pixels, if it could.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:8], width)
+	binary.BigEndian.PutUint32(ihdr[8:12], height)
+	ihdr[12] = 8 // Bit depth
+	ihdr[13] = 6 // RGBA
+
+	out := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")
+	out = append(out, ihdr...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(ihdr))
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestImageProcessor_DecodeLimits(t *testing.T) {
+	bomb := pngHeader(50000, 50000) // 2.5 gigapixels, each side within the old 50000 limit
+
+	t.Run("rejects the pixel count before decoding", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+
+		_, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(bomb), 100, 100)
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		_, err = processor.Render(context.Background(), bytes.NewReader(bomb), RenderOptions{Width: 100, Height: 100, Format: formatJPEG})
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		_, err = processor.GenerateVariants(context.Background(), bytes.NewReader(bomb), []int{320}, 0)
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		assert.ErrorIs(t, processor.CheckDecodeLimits(bytes.NewReader(bomb)), ErrImageTooLarge)
+	})
+
+	t.Run("rejects the pixel count when validating", func(t *testing.T) {
+		processor := NewImageProcessor(60000, 60000, 85)
+		err := processor.ValidateImage(context.Background(), bytes.NewReader(bomb), "image/png")
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+	})
+
+	t.Run("reports the dimensions without decoding", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		info, err := processor.GetImageInfo(context.Background(), bytes.NewReader(bomb))
+		require.NoError(t, err)
+		assert.Equal(t, 50000, info.Width)
+		assert.Equal(t, "unknown", info.ColorSpace)
+	})
+
+	t.Run("memory budget", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		processor.SetDecodeLimits(0, 10_000, 0)
+
+		// 50x50 RGBA pixels take 10000 bytes, 51x50 take 10200
+		err := processor.CheckDecodeLimits(bytes.NewReader(encodePNG(t, 51, 50)))
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		_, err = processor.GenerateThumbnail(context.Background(), bytes.NewReader(encodePNG(t, 50, 50)), 10, 10)
+		assert.NoError(t, err)
+	})
+
+	t.Run("counts every frame of an animation", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		// 100x100 paletted pixels take 10000 bytes, but four frames and the canvas take 120000
+		processor.SetDecodeLimits(0, 100_000, 0)
+
+		_, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(animatedGIF(t, 100, 100, 5, 5, 5, 5)), 50, 50)
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+	})
+
+	t.Run("counts the upright copy of a rotated JPEG", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		// 80x40 YCbCr pixels take 9600 bytes, and their upright RGBA copy 12800 more
+		processor.SetDecodeLimits(0, 20_000, 0)
+
+		_, err := processor.GenerateThumbnail(context.Background(), bytes.NewReader(rotatedJPEG(t, OrientationNormal)), 10, 10)
+		assert.NoError(t, err)
+		_, err = processor.GenerateThumbnail(context.Background(), bytes.NewReader(rotatedJPEG(t, OrientationRotate90)), 10, 10)
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		assert.ErrorIs(t, processor.CheckDecodeLimits(bytes.NewReader(rotatedJPEG(t, OrientationRotate90))), ErrImageTooLarge)
+	})
+
+	t.Run("unreadable headers are left to the decoder", func(t *testing.T) {
+		processor := NewImageProcessor(2000, 2000, 85)
+		assert.NoError(t, processor.CheckDecodeLimits(bytes.NewReader([]byte("not an image"))))
+		assert.Error(t, processor.CheckDecodeLimits(nil))
+	})
+}
+
+func TestImageProcessor_ConcurrentDecodes(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+	processor.SetDecodeLimits(0, 0, 1)
+
+	release, err := processor.acquireDecode(context.Background())
+	require.NoError(t, err)
+
+	// With the only slot taken, a decode waits until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = processor.GenerateThumbnail(ctx, bytes.NewReader(encodePNG(t, 10, 10)), 5, 5)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+
+	release()
+	_, err = processor.GenerateThumbnail(context.Background(), bytes.NewReader(encodePNG(t, 10, 10)), 5, 5)
+	assert.NoError(t, err)
+}
+
+func TestDecodedSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     image.Config
+		frames     int
+		reoriented bool
+		want       int64
+	}{
+		{"gray", image.Config{Width: 10, Height: 10, ColorModel: image.NewGray(image.Rectangle{}).ColorModel()}, 1, false, 100},
+		{"rgba", image.Config{Width: 10, Height: 10, ColorModel: image.NewRGBA(image.Rectangle{}).ColorModel()}, 1, false, 400},
+		{"rgba64", image.Config{Width: 10, Height: 10, ColorModel: image.NewRGBA64(image.Rectangle{}).ColorModel()}, 1, false, 800},
+		{"paletted", image.Config{Width: 10, Height: 10, ColorModel: image.NewPaletted(image.Rectangle{}, nil).ColorModel()}, 1, false, 100},
+		{"ycbcr", image.Config{Width: 10, Height: 10, ColorModel: color.YCbCrModel}, 1, false, 300},
+		{"reoriented ycbcr", image.Config{Width: 10, Height: 10, ColorModel: color.YCbCrModel}, 1, true, 700},
+		{"animation", image.Config{Width: 10, Height: 10}, 3, false, 1100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, decodedSize(tt.config, tt.frames, tt.reoriented))
+		})
+	}
+}
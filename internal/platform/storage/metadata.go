@@ -0,0 +1,896 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PhotoMetadata holds the capture details embedded in an image file
+type PhotoMetadata struct {
+	Make         string
+	Model        string
+	Lens         string
+	ExposureTime string // Shutter speed as photographers write it, e.g. "1/250" or "2"
+	FNumber      float64
+	ISO          int
+	FocalLength  float64 // Millimetres
+	TakenAt      *time.Time
+	Latitude     *float64
+	Longitude    *float64
+	Altitude     *float64 // Metres above sea level
+	Orientation  int
+}
+
+// IsEmpty reports whether no metadata was found
+func (m *PhotoMetadata) IsEmpty() bool {
+	return m == nil || *m == PhotoMetadata{}
+}
+
+// fill copies the fields of other that are still unset in m
+func (m *PhotoMetadata) fill(other *PhotoMetadata) {
+	if other == nil {
+		return
+	}
+	if m.Make == "" {
+		m.Make = other.Make
+	}
+	if m.Model == "" {
+		m.Model = other.Model
+	}
+	if m.Lens == "" {
+		m.Lens = other.Lens
+	}
+	if m.ExposureTime == "" {
+		m.ExposureTime = other.ExposureTime
+	}
+	if m.FNumber == 0 {
+		m.FNumber = other.FNumber
+	}
+	if m.ISO == 0 {
+		m.ISO = other.ISO
+	}
+	if m.FocalLength == 0 {
+		m.FocalLength = other.FocalLength
+	}
+	if m.TakenAt == nil {
+		m.TakenAt = other.TakenAt
+	}
+	if m.Latitude == nil || m.Longitude == nil {
+		m.Latitude, m.Longitude = other.Latitude, other.Longitude
+	}
+	if m.Altitude == nil {
+		m.Altitude = other.Altitude
+	}
+	if m.Orientation == 0 {
+		m.Orientation = other.Orientation
+	}
+}
+
+// Limits that keep a malformed or hostile file from exhausting memory
+const (
+	maxMetadataBlock = 4 << 20 // Largest EXIF/XMP/IPTC block read into memory
+	maxIFDEntries    = 1000
+)
+
+// Marker prefixes of the metadata blocks inside JPEG APP segments
+var (
+	exifHeader      = []byte("Exif\x00\x00")
+	xmpHeader       = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	photoshopHeader = []byte("Photoshop 3.0\x00")
+	pngSignature    = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// ExtractMetadata reads EXIF, XMP and IPTC metadata from a JPEG, PNG or WebP stream.
+// The stream is read sequentially and never buffered whole. When a value appears in
+// several blocks EXIF wins over XMP, which wins over IPTC. Unsupported formats and
+// files without metadata yield empty metadata rather than an error.
+func ExtractMetadata(data io.Reader) (*PhotoMetadata, error) {
+	if data == nil {
+		return nil, errors.New("data cannot be nil")
+	}
+
+	br := bufio.NewReader(data)
+	head, err := br.Peek(12)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read image header: %w", err)
+	}
+
+	var blocks metadataBlocks
+	switch {
+	case len(head) >= 2 && head[0] == 0xFF && head[1] == 0xD8:
+		err = blocks.readJPEG(br)
+	case bytes.HasPrefix(head, pngSignature):
+		err = blocks.readPNG(br)
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		err = blocks.readWebP(br)
+	default:
+		return &PhotoMetadata{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks.merge()
+}
+
+// metadataBlocks collects the raw metadata blocks found in a file
+type metadataBlocks struct {
+	exif []byte
+	xmp  []byte
+	iptc []byte
+}
+
+// merge parses the collected blocks and combines them by precedence
+func (b *metadataBlocks) merge() (*PhotoMetadata, error) {
+	meta := &PhotoMetadata{}
+	if b.exif != nil {
+		exif, err := parseEXIF(b.exif)
+		if err != nil {
+			return nil, err
+		}
+		meta.fill(exif)
+	}
+	if b.xmp != nil {
+		xmpMeta, err := parseXMP(b.xmp)
+		if err != nil {
+			return nil, err
+		}
+		meta.fill(xmpMeta)
+	}
+	if b.iptc != nil {
+		meta.fill(parseIPTC(b.iptc))
+	}
+	return meta, nil
+}
+
+// readJPEG walks the JPEG segments up to the start of the image data
+func (b *metadataBlocks) readJPEG(r *bufio.Reader) error {
+	if _, err := r.Discard(2); err != nil { // SOI
+		return fmt.Errorf("invalid JPEG: %w", err)
+	}
+
+	for {
+		marker, err := nextJPEGMarker(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid JPEG: %w", err)
+		}
+
+		switch {
+		case marker == 0xDA || marker == 0xD9: // Start of scan or end of image: no metadata follows
+			return nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // Markers without a payload
+			continue
+		}
+
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return fmt.Errorf("invalid JPEG segment: %w", err)
+		}
+		if length < 2 {
+			return fmt.Errorf("invalid JPEG segment length %d", length)
+		}
+		size := int64(length) - 2
+
+		if marker != 0xE1 && marker != 0xED { // Only APP1 (EXIF, XMP) and APP13 (IPTC) matter
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return fmt.Errorf("invalid JPEG segment: %w", err)
+			}
+			continue
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return fmt.Errorf("invalid JPEG segment: %w", err)
+		}
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) && b.exif == nil:
+			b.exif = payload[len(exifHeader):]
+		case marker == 0xE1 && bytes.HasPrefix(payload, xmpHeader) && b.xmp == nil:
+			b.xmp = payload[len(xmpHeader):]
+		case marker == 0xED && bytes.HasPrefix(payload, photoshopHeader) && b.iptc == nil:
+			b.iptc = findPhotoshopIPTC(payload[len(photoshopHeader):])
+		}
+	}
+}
+
+// nextJPEGMarker reads up to and including the next marker byte
+func nextJPEGMarker(r *bufio.Reader) (byte, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if c != 0xFF {
+		return 0, fmt.Errorf("expected marker, found 0x%02X", c)
+	}
+	for c == 0xFF { // Markers may be preceded by any number of fill bytes
+		if c, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+	return c, nil
+}
+
+// findPhotoshopIPTC returns the IPTC record from a Photoshop image resource block
+func findPhotoshopIPTC(data []byte) []byte {
+	for len(data) >= 12 && bytes.HasPrefix(data, []byte("8BIM")) {
+		id := binary.BigEndian.Uint16(data[4:6])
+		// Pascal-string name, padded so that length byte plus name is even
+		nameLen := int(data[6]) + 1
+		nameLen += nameLen % 2
+		pos := 6 + nameLen
+		if pos+4 > len(data) {
+			return nil
+		}
+		size := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		pos += 4
+		if size < 0 || pos+size > len(data) {
+			return nil
+		}
+		if id == 0x0404 {
+			return data[pos : pos+size]
+		}
+		next := pos + size + size%2
+		if next > len(data) {
+			return nil
+		}
+		data = data[next:]
+	}
+	return nil
+}
+
+// readPNG walks the PNG chunks looking for eXIf and the XMP iTXt chunk
+func (b *metadataBlocks) readPNG(r *bufio.Reader) error {
+	if _, err := r.Discard(len(pngSignature)); err != nil {
+		return fmt.Errorf("invalid PNG: %w", err)
+	}
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid PNG chunk: %w", err)
+		}
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		chunkType := string(header[4:8])
+		if chunkType == "IEND" {
+			return nil
+		}
+
+		wanted := (chunkType == "eXIf" && b.exif == nil) || (chunkType == "iTXt" && b.xmp == nil)
+		if !wanted || size > maxMetadataBlock {
+			if _, err := io.CopyN(io.Discard, r, size+4); err != nil { // Payload and CRC
+				return fmt.Errorf("invalid PNG chunk: %w", err)
+			}
+			continue
+		}
+
+		payload := make([]byte, size+4)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return fmt.Errorf("invalid PNG chunk: %w", err)
+		}
+		payload = payload[:size]
+
+		if chunkType == "eXIf" {
+			b.exif = payload
+		} else if xmp, ok := pngXMP(payload); ok {
+			b.xmp = xmp
+		}
+	}
+}
+
+// pngXMP extracts the XMP packet from an iTXt chunk with the XML:com.adobe.xmp keyword
+func pngXMP(chunk []byte) ([]byte, bool) {
+	keyword, rest, ok := bytes.Cut(chunk, []byte{0})
+	if !ok || string(keyword) != "XML:com.adobe.xmp" || len(rest) < 2 {
+		return nil, false
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+	// Skip the language tag and translated keyword
+	for i := 0; i < 2; i++ {
+		if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+			return nil, false
+		}
+	}
+	if !compressed {
+		return rest, true
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = zr.Close() }() //nolint:errcheck // Resource cleanup
+	text, err := io.ReadAll(io.LimitReader(zr, maxMetadataBlock))
+	if err != nil {
+		return nil, false
+	}
+	return text, true
+}
+
+// readWebP walks the RIFF chunks of a WebP file; EXIF and XMP follow the image data
+func (b *metadataBlocks) readWebP(r *bufio.Reader) error {
+	if _, err := r.Discard(12); err != nil {
+		return fmt.Errorf("invalid WebP: %w", err)
+	}
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid WebP chunk: %w", err)
+		}
+		fourCC := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+		padded := size + size%2
+
+		wanted := (fourCC == "EXIF" && b.exif == nil) || (fourCC == "XMP " && b.xmp == nil)
+		if !wanted || size > maxMetadataBlock {
+			if _, err := io.CopyN(io.Discard, r, padded); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil // Some encoders omit the final pad byte
+				}
+				return fmt.Errorf("invalid WebP chunk: %w", err)
+			}
+			continue
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return fmt.Errorf("invalid WebP chunk: %w", err)
+		}
+		if size%2 == 1 {
+			_, _ = r.Discard(1) //nolint:errcheck // Missing pad byte at end of file is harmless
+		}
+
+		if fourCC == "EXIF" {
+			// Some encoders keep the JPEG "Exif\0\0" prefix
+			b.exif = bytes.TrimPrefix(payload, exifHeader)
+		} else {
+			b.xmp = payload
+		}
+	}
+}
+
+// EXIF tags read by parseEXIF
+const (
+	tagMake              = 0x010F
+	tagModel             = 0x0110
+	tagOrientation       = 0x0112
+	tagDateTime          = 0x0132
+	tagExifIFD           = 0x8769
+	tagGPSIFD            = 0x8825
+	tagExposureTime      = 0x829A
+	tagFNumber           = 0x829D
+	tagISO               = 0x8827
+	tagDateTimeOriginal  = 0x9003
+	tagDateTimeDigitized = 0x9004
+	tagOffsetTimeOrig    = 0x9011
+	tagFocalLength       = 0x920A
+	tagLensMake          = 0xA433
+	tagLensModel         = 0xA434
+	tagGPSLatitudeRef    = 0x0001
+	tagGPSLatitude       = 0x0002
+	tagGPSLongitudeRef   = 0x0003
+	tagGPSLongitude      = 0x0004
+	tagGPSAltitudeRef    = 0x0005
+	tagGPSAltitude       = 0x0006
+)
+
+// TIFF field types
+const (
+	tiffByte      = 1
+	tiffASCII     = 2
+	tiffShort     = 3
+	tiffLong      = 4
+	tiffRational  = 5
+	tiffUndefined = 7
+	tiffSLong     = 9
+	tiffSRational = 10
+)
+
+// tiffTypeSizes is the byte size of one value of each TIFF field type
+var tiffTypeSizes = map[uint16]int{
+	tiffByte: 1, tiffASCII: 1, tiffShort: 2, tiffLong: 4, tiffRational: 8,
+	tiffUndefined: 1, tiffSLong: 4, tiffSRational: 8,
+}
+
+// tiffEntry is one decoded IFD field
+type tiffEntry struct {
+	typ   uint16
+	count int
+	value []byte
+	order binary.ByteOrder
+}
+
+// exifTimeLayout is the fixed EXIF date format
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// parseEXIF decodes the TIFF structure of an EXIF block
+func parseEXIF(data []byte) (*PhotoMetadata, error) {
+	if len(data) < 8 {
+		return nil, errors.New("invalid EXIF: header too short")
+	}
+
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid EXIF: unknown byte order")
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return nil, errors.New("invalid EXIF: bad TIFF magic number")
+	}
+
+	ifd0, err := readIFD(data, order, order.Uint32(data[4:8]))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &PhotoMetadata{
+		Make:        ifd0.ascii(tagMake),
+		Model:       ifd0.ascii(tagModel),
+		Orientation: int(ifd0.uint(tagOrientation)),
+	}
+	if meta.Orientation < 1 || meta.Orientation > 8 {
+		meta.Orientation = 0
+	}
+
+	if offset := ifd0.uint(tagExifIFD); offset != 0 {
+		exifIFD, err := readIFD(data, order, offset)
+		if err != nil {
+			return nil, err
+		}
+		meta.ExposureTime = formatExposureTime(exifIFD.rational(tagExposureTime))
+		meta.FNumber = roundTo(exifIFD.rational(tagFNumber), 1)
+		meta.ISO = int(exifIFD.uint(tagISO))
+		meta.FocalLength = roundTo(exifIFD.rational(tagFocalLength), 1)
+		meta.Lens = joinLens(exifIFD.ascii(tagLensMake), exifIFD.ascii(tagLensModel))
+
+		taken := exifIFD.ascii(tagDateTimeOriginal)
+		if taken == "" {
+			taken = exifIFD.ascii(tagDateTimeDigitized)
+		}
+		meta.TakenAt = parseEXIFTime(taken, exifIFD.ascii(tagOffsetTimeOrig))
+	}
+	if meta.TakenAt == nil {
+		meta.TakenAt = parseEXIFTime(ifd0.ascii(tagDateTime), "")
+	}
+
+	if offset := ifd0.uint(tagGPSIFD); offset != 0 {
+		gpsIFD, err := readIFD(data, order, offset)
+		if err != nil {
+			return nil, err
+		}
+		meta.Latitude = gpsIFD.coordinate(tagGPSLatitude, tagGPSLatitudeRef, "S")
+		meta.Longitude = gpsIFD.coordinate(tagGPSLongitude, tagGPSLongitudeRef, "W")
+		if meta.Latitude == nil || meta.Longitude == nil {
+			meta.Latitude, meta.Longitude = nil, nil
+		}
+		if e, ok := gpsIFD[tagGPSAltitude]; ok && e.typ == tiffRational {
+			alt := roundTo(gpsIFD.rational(tagGPSAltitude), 1)
+			if ref, ok := gpsIFD[tagGPSAltitudeRef]; ok && len(ref.value) > 0 && ref.value[0] == 1 {
+				alt = -alt // Below sea level
+			}
+			meta.Altitude = &alt
+		}
+	}
+
+	return meta, nil
+}
+
+// ifd maps tag IDs to their fields
+type ifd map[uint16]tiffEntry
+
+// readIFD decodes the image file directory at offset
+func readIFD(data []byte, order binary.ByteOrder, offset uint32) (ifd, error) {
+	if uint64(offset)+2 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid EXIF: IFD offset %d out of range", offset)
+	}
+	pos := int(offset)
+	count := int(order.Uint16(data[pos : pos+2]))
+	if count > maxIFDEntries {
+		return nil, fmt.Errorf("invalid EXIF: %d IFD entries", count)
+	}
+	pos += 2
+
+	fields := make(ifd, count)
+	for i := 0; i < count; i++ {
+		if pos+12 > len(data) {
+			return nil, errors.New("invalid EXIF: truncated IFD")
+		}
+		raw := data[pos : pos+12]
+		pos += 12
+
+		tag := order.Uint16(raw[0:2])
+		typ := order.Uint16(raw[2:4])
+		n := order.Uint32(raw[4:8])
+		unit, known := tiffTypeSizes[typ]
+		if !known || n == 0 || uint64(n)*uint64(unit) > maxMetadataBlock {
+			continue
+		}
+		size := int(n) * unit
+
+		value := raw[8:12]
+		if size > 4 {
+			valueOffset := order.Uint32(raw[8:12])
+			if uint64(valueOffset)+uint64(size) > uint64(len(data)) {
+				continue // Skip fields pointing outside the block instead of failing the whole file
+			}
+			value = data[valueOffset : int(valueOffset)+size]
+		}
+		fields[tag] = tiffEntry{typ: typ, count: int(n), value: value[:size], order: order}
+	}
+	return fields, nil
+}
+
+// ascii returns a string field with trailing NULs and spaces removed
+func (d ifd) ascii(tag uint16) string {
+	e, ok := d[tag]
+	if !ok || (e.typ != tiffASCII && e.typ != tiffUndefined) {
+		return ""
+	}
+	value := e.value
+	if i := bytes.IndexByte(value, 0); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(string(value))
+}
+
+// uint returns the first value of an integer field
+func (d ifd) uint(tag uint16) uint32 {
+	e, ok := d[tag]
+	if !ok {
+		return 0
+	}
+	switch e.typ {
+	case tiffByte:
+		return uint32(e.value[0])
+	case tiffShort:
+		return uint32(e.order.Uint16(e.value))
+	case tiffLong:
+		return e.order.Uint32(e.value)
+	}
+	return 0
+}
+
+// rationals returns every value of a rational field
+func (d ifd) rationals(tag uint16) []float64 {
+	e, ok := d[tag]
+	if !ok || (e.typ != tiffRational && e.typ != tiffSRational) {
+		return nil
+	}
+	values := make([]float64, 0, e.count)
+	for i := 0; i < e.count; i++ {
+		num := e.order.Uint32(e.value[i*8:])
+		den := e.order.Uint32(e.value[i*8+4:])
+		if den == 0 {
+			return nil
+		}
+		if e.typ == tiffSRational {
+			values = append(values, float64(int32(num))/float64(int32(den)))
+		} else {
+			values = append(values, float64(num)/float64(den))
+		}
+	}
+	return values
+}
+
+// rational returns the first value of a rational field
+func (d ifd) rational(tag uint16) float64 {
+	values := d.rationals(tag)
+	if len(values) == 0 {
+		return 0
+	}
+	return values[0]
+}
+
+// coordinate converts a degrees/minutes/seconds GPS field to signed decimal degrees
+func (d ifd) coordinate(tag, refTag uint16, negativeRef string) *float64 {
+	dms := d.rationals(tag)
+	if len(dms) != 3 {
+		return nil
+	}
+	value := dms[0] + dms[1]/60 + dms[2]/3600
+	if strings.EqualFold(d.ascii(refTag), negativeRef) {
+		value = -value
+	}
+	value = roundTo(value, 6)
+	return &value
+}
+
+// parseEXIFTime parses an EXIF timestamp. Without an offset tag the camera's local
+// time is kept as is and recorded as UTC, since EXIF does not say which zone it was.
+func parseEXIFTime(value, offset string) *time.Time {
+	if value == "" || strings.HasPrefix(value, "0000") {
+		return nil
+	}
+	loc := time.UTC
+	if offset != "" {
+		if t, err := time.Parse("-07:00", offset); err == nil {
+			_, seconds := t.Zone()
+			loc = time.FixedZone("", seconds)
+		}
+	}
+	t, err := time.ParseInLocation(exifTimeLayout, value, loc)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// formatExposureTime renders an exposure in seconds as "1/250" or "2.5"
+func formatExposureTime(seconds float64) string {
+	if seconds <= 0 {
+		return ""
+	}
+	if seconds >= 1 {
+		return strconv.FormatFloat(roundTo(seconds, 1), 'f', -1, 64)
+	}
+	return fmt.Sprintf("1/%d", int(math.Round(1/seconds)))
+}
+
+// joinLens combines the lens maker and model unless the model already names the maker
+func joinLens(lensMake, lensModel string) string {
+	if lensMake == "" || strings.HasPrefix(strings.ToLower(lensModel), strings.ToLower(lensMake)) {
+		return lensModel
+	}
+	if lensModel == "" {
+		return ""
+	}
+	return lensMake + " " + lensModel
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
+
+// XMP namespaces read by parseXMP
+const (
+	nsTIFF      = "http://ns.adobe.com/tiff/1.0/"
+	nsEXIF      = "http://ns.adobe.com/exif/1.0/"
+	nsEXIFAux   = "http://ns.adobe.com/exif/1.0/aux/"
+	nsEXIFEX    = "http://cipa.jp/exif/1.0/"
+	nsXMP       = "http://ns.adobe.com/xap/1.0/"
+	nsPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+)
+
+// xmpProperties lists the XMP properties parseXMP keeps, keyed by namespace and name
+var xmpProperties = map[xml.Name]bool{
+	{Space: nsTIFF, Local: "Make"}:                      true,
+	{Space: nsTIFF, Local: "Model"}:                     true,
+	{Space: nsTIFF, Local: "Orientation"}:               true,
+	{Space: nsEXIF, Local: "ExposureTime"}:              true,
+	{Space: nsEXIF, Local: "FNumber"}:                   true,
+	{Space: nsEXIF, Local: "ISOSpeedRatings"}:           true,
+	{Space: nsEXIF, Local: "FocalLength"}:               true,
+	{Space: nsEXIF, Local: "DateTimeOriginal"}:          true,
+	{Space: nsEXIF, Local: "GPSLatitude"}:               true,
+	{Space: nsEXIF, Local: "GPSLongitude"}:              true,
+	{Space: nsEXIF, Local: "GPSAltitude"}:               true,
+	{Space: nsEXIF, Local: "GPSAltitudeRef"}:            true,
+	{Space: nsEXIFAux, Local: "Lens"}:                   true,
+	{Space: nsEXIFEX, Local: "LensModel"}:               true,
+	{Space: nsEXIFEX, Local: "PhotographicSensitivity"}: true,
+	{Space: nsPhotoshop, Local: "DateCreated"}:          true,
+	{Space: nsXMP, Local: "CreateDate"}:                 true,
+}
+
+// xmpTimeLayouts are the ISO 8601 forms allowed for XMP dates
+var xmpTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseXMP reads the properties of an XMP packet. Properties may be written as
+// attributes of rdf:Description or as elements, optionally wrapped in rdf:Seq/rdf:li.
+func parseXMP(data []byte) (*PhotoMetadata, error) {
+	values := make(map[xml.Name]string)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var stack []xml.Name
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid XMP: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name)
+			for _, attr := range t.Attr {
+				if xmpProperties[attr.Name] {
+					setOnce(values, attr.Name, attr.Value)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			// Attribute the text to the innermost enclosing property (skipping rdf:Seq/rdf:li)
+			for i := len(stack) - 1; i >= 0; i-- {
+				if xmpProperties[stack[i]] {
+					setOnce(values, stack[i], text)
+					break
+				}
+			}
+		}
+	}
+
+	get := func(space, local string) string { return values[xml.Name{Space: space, Local: local}] }
+
+	meta := &PhotoMetadata{
+		Make:         get(nsTIFF, "Make"),
+		Model:        get(nsTIFF, "Model"),
+		Lens:         firstNonEmpty(get(nsEXIFEX, "LensModel"), get(nsEXIFAux, "Lens")),
+		ExposureTime: formatExposureTime(parseXMPRational(get(nsEXIF, "ExposureTime"))),
+		FNumber:      roundTo(parseXMPRational(get(nsEXIF, "FNumber")), 1),
+		FocalLength:  roundTo(parseXMPRational(get(nsEXIF, "FocalLength")), 1),
+		TakenAt: parseXMPTime(firstNonEmpty(
+			get(nsEXIF, "DateTimeOriginal"), get(nsPhotoshop, "DateCreated"), get(nsXMP, "CreateDate"))),
+	}
+	meta.ISO, _ = strconv.Atoi(firstNonEmpty(get(nsEXIFEX, "PhotographicSensitivity"), get(nsEXIF, "ISOSpeedRatings")))
+	if orientation, err := strconv.Atoi(get(nsTIFF, "Orientation")); err == nil && orientation >= 1 && orientation <= 8 {
+		meta.Orientation = orientation
+	}
+
+	meta.Latitude = parseXMPCoordinate(get(nsEXIF, "GPSLatitude"))
+	meta.Longitude = parseXMPCoordinate(get(nsEXIF, "GPSLongitude"))
+	if meta.Latitude == nil || meta.Longitude == nil {
+		meta.Latitude, meta.Longitude = nil, nil
+	}
+	if alt := get(nsEXIF, "GPSAltitude"); alt != "" {
+		altitude := roundTo(parseXMPRational(alt), 1)
+		if get(nsEXIF, "GPSAltitudeRef") == "1" {
+			altitude = -altitude
+		}
+		meta.Altitude = &altitude
+	}
+
+	return meta, nil
+}
+
+// setOnce records the first value seen for a property
+func setOnce(values map[xml.Name]string, name xml.Name, value string) {
+	if _, ok := values[name]; !ok && value != "" {
+		values[name] = value
+	}
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseXMPRational parses "num/den" or a plain decimal
+func parseXMPRational(value string) float64 {
+	num, den, isFraction := strings.Cut(value, "/")
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil {
+		return 0
+	}
+	if !isFraction {
+		return n
+	}
+	d, err := strconv.ParseFloat(strings.TrimSpace(den), 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// parseXMPCoordinate parses the XMP GPS forms "DDD,MM,SSk" and "DDD,MM.mmk"
+// where k is one of N, S, E or W
+func parseXMPCoordinate(value string) *float64 {
+	if len(value) < 2 {
+		return nil
+	}
+	ref := strings.ToUpper(value[len(value)-1:])
+	parts := strings.Split(value[:len(value)-1], ",")
+	if len(parts) < 2 || len(parts) > 3 || !strings.Contains("NSEW", ref) {
+		return nil
+	}
+
+	var result float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil
+		}
+		result += v / math.Pow(60, float64(i))
+	}
+	if ref == "S" || ref == "W" {
+		result = -result
+	}
+	result = roundTo(result, 6)
+	return &result
+}
+
+// parseXMPTime parses an XMP date in any of the allowed ISO 8601 forms
+func parseXMPTime(value string) *time.Time {
+	for _, layout := range xmpTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// parseIPTC reads the capture date from an IPTC-IIM record (datasets 2:55 and 2:60)
+func parseIPTC(data []byte) *PhotoMetadata {
+	var date, clock string
+	for len(data) >= 5 && data[0] == 0x1C {
+		record, dataset := data[1], data[2]
+		size := int(binary.BigEndian.Uint16(data[3:5]))
+		if size&0x8000 != 0 || 5+size > len(data) { // Extended datasets are not used for dates
+			break
+		}
+		value := string(data[5 : 5+size])
+		data = data[5+size:]
+
+		if record != 2 {
+			continue
+		}
+		switch dataset {
+		case 55:
+			date = value
+		case 60:
+			clock = value
+		}
+	}
+
+	meta := &PhotoMetadata{}
+	if len(date) != 8 {
+		return meta
+	}
+	layout, value := "20060102", date
+	if len(clock) >= 6 {
+		layout, value = "20060102150405", date+clock[:6]
+		if len(clock) == 11 {
+			layout, value = "20060102150405-0700", date+clock
+		}
+	}
+	if t, err := time.Parse(layout, value); err == nil {
+		meta.TakenAt = &t
+	}
+	return meta
+}
@@ -0,0 +1,414 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiffField is one IFD entry for building test EXIF blocks
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func asciiField(tag uint16, s string) tiffField {
+	return tiffField{tag: tag, typ: tiffASCII, count: uint32(len(s) + 1), data: append([]byte(s), 0)}
+}
+
+func shortField(tag, v uint16) tiffField {
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, v)
+	return tiffField{tag: tag, typ: tiffShort, count: 1, data: data}
+}
+
+func byteField(tag uint16, v byte) tiffField {
+	return tiffField{tag: tag, typ: tiffByte, count: 1, data: []byte{v}}
+}
+
+func rationalField(tag uint16, pairs ...uint32) tiffField {
+	data := make([]byte, 4*len(pairs))
+	for i, v := range pairs {
+		binary.LittleEndian.PutUint32(data[i*4:], v)
+	}
+	return tiffField{tag: tag, typ: tiffRational, count: uint32(len(pairs) / 2), data: data}
+}
+
+// buildEXIF lays out a little-endian TIFF block with IFD0 and optional EXIF and GPS IFDs
+func buildEXIF(ifd0, exifIFD, gpsIFD []tiffField) []byte {
+	order := binary.LittleEndian
+	if len(exifIFD) > 0 {
+		ifd0 = append(ifd0, tiffField{tag: tagExifIFD, typ: tiffLong, count: 1, data: make([]byte, 4)})
+	}
+	if len(gpsIFD) > 0 {
+		ifd0 = append(ifd0, tiffField{tag: tagGPSIFD, typ: tiffLong, count: 1, data: make([]byte, 4)})
+	}
+
+	ifds := [][]tiffField{ifd0, exifIFD, gpsIFD}
+	offsets := make([]uint32, len(ifds))
+	pos := uint32(8)
+	for i, fields := range ifds {
+		if i > 0 && len(fields) == 0 {
+			continue
+		}
+		offsets[i] = pos
+		pos += uint32(2 + 12*len(fields) + 4)
+	}
+	for i := range ifd0 {
+		switch ifd0[i].tag {
+		case tagExifIFD:
+			order.PutUint32(ifd0[i].data, offsets[1])
+		case tagGPSIFD:
+			order.PutUint32(ifd0[i].data, offsets[2])
+		}
+	}
+
+	var out, extra bytes.Buffer
+	out.WriteString("II")
+	_ = binary.Write(&out, order, uint16(42))
+	_ = binary.Write(&out, order, uint32(8))
+	for i, fields := range ifds {
+		if i > 0 && len(fields) == 0 {
+			continue
+		}
+		_ = binary.Write(&out, order, uint16(len(fields)))
+		for _, f := range fields {
+			_ = binary.Write(&out, order, f.tag)
+			_ = binary.Write(&out, order, f.typ)
+			_ = binary.Write(&out, order, f.count)
+			if len(f.data) <= 4 {
+				value := make([]byte, 4)
+				copy(value, f.data)
+				out.Write(value)
+			} else {
+				_ = binary.Write(&out, order, pos+uint32(extra.Len()))
+				extra.Write(f.data)
+			}
+		}
+		_ = binary.Write(&out, order, uint32(0)) // No next IFD
+	}
+	out.Write(extra.Bytes())
+	return out.Bytes()
+}
+
+// sampleEXIF is a complete camera EXIF block
+func sampleEXIF() []byte {
+	return buildEXIF(
+		[]tiffField{
+			asciiField(tagMake, "Canon"),
+			asciiField(tagModel, "Canon EOS R5"),
+			shortField(tagOrientation, 6),
+		},
+		[]tiffField{
+			rationalField(tagExposureTime, 1, 250),
+			rationalField(tagFNumber, 28, 10),
+			shortField(tagISO, 400),
+			asciiField(tagDateTimeOriginal, "2023:06:01 14:30:00"),
+			asciiField(tagOffsetTimeOrig, "+02:00"),
+			rationalField(tagFocalLength, 50, 1),
+			asciiField(tagLensModel, "RF24-105mm F4 L IS USM"),
+		},
+		[]tiffField{
+			asciiField(tagGPSLatitudeRef, "N"),
+			rationalField(tagGPSLatitude, 52, 1, 30, 1, 0, 1),
+			asciiField(tagGPSLongitudeRef, "W"),
+			rationalField(tagGPSLongitude, 13, 1, 24, 1, 36, 1),
+			byteField(tagGPSAltitudeRef, 0),
+			rationalField(tagGPSAltitude, 345, 10),
+		},
+	)
+}
+
+const sampleXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:tiff="http://ns.adobe.com/tiff/1.0/"
+    xmlns:exif="http://ns.adobe.com/exif/1.0/"
+    xmlns:aux="http://ns.adobe.com/exif/1.0/aux/"
+    tiff:Make="NIKON CORPORATION"
+    tiff:Model="NIKON Z 6"
+    exif:ExposureTime="1/60"
+    exif:FNumber="56/10"
+    exif:FocalLength="35/1"
+    exif:DateTimeOriginal="2022-12-24T18:05:30+01:00"
+    exif:GPSLatitude="48,51.24N"
+    exif:GPSLongitude="2,21,3E">
+   <exif:ISOSpeedRatings>
+    <rdf:Seq><rdf:li>800</rdf:li></rdf:Seq>
+   </exif:ISOSpeedRatings>
+   <aux:Lens>NIKKOR Z 35mm f/1.8 S</aux:Lens>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+// sampleIPTC is a Photoshop APP13 payload holding IPTC date and time created
+func sampleIPTC() []byte {
+	var iptc bytes.Buffer
+	for _, ds := range []struct {
+		id    byte
+		value string
+	}{{55, "20210315"}, {60, "093000+0100"}} {
+		iptc.Write([]byte{0x1C, 2, ds.id})
+		_ = binary.Write(&iptc, binary.BigEndian, uint16(len(ds.value)))
+		iptc.WriteString(ds.value)
+	}
+
+	var irb bytes.Buffer
+	irb.Write(photoshopHeader)
+	irb.WriteString("8BIM")
+	_ = binary.Write(&irb, binary.BigEndian, uint16(0x0404))
+	irb.Write([]byte{0, 0}) // Empty pascal name, padded
+	_ = binary.Write(&irb, binary.BigEndian, uint32(iptc.Len()))
+	irb.Write(iptc.Bytes())
+	return irb.Bytes()
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegWithSegments encodes a small JPEG and inserts the segments right after SOI
+func jpegWithSegments(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(), nil))
+	encoded := buf.Bytes()
+
+	out := append([]byte{}, encoded[:2]...)
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, encoded[2:]...)
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, data...)
+	crc := crc32.ChecksumIEEE(chunk[4:])
+	return binary.BigEndian.AppendUint32(chunk, crc)
+}
+
+// pngWithChunks encodes a small PNG and inserts the chunks after IHDR
+func pngWithChunks(t *testing.T, chunks ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage()))
+	encoded := buf.Bytes()
+	const ihdrEnd = 8 + 25
+
+	out := append([]byte{}, encoded[:ihdrEnd]...)
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	return append(out, encoded[ihdrEnd:]...)
+}
+
+func webpChunk(fourCC string, data []byte) []byte {
+	chunk := make([]byte, 8, 9+len(data))
+	copy(chunk, fourCC)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestExtractMetadata_JPEGWithEXIF(t *testing.T) {
+	data := jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), sampleEXIF()...)))
+
+	meta, err := ExtractMetadata(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Canon", meta.Make)
+	assert.Equal(t, "Canon EOS R5", meta.Model)
+	assert.Equal(t, "RF24-105mm F4 L IS USM", meta.Lens)
+	assert.Equal(t, "1/250", meta.ExposureTime)
+	assert.Equal(t, 2.8, meta.FNumber)
+	assert.Equal(t, 400, meta.ISO)
+	assert.Equal(t, 50.0, meta.FocalLength)
+	assert.Equal(t, 6, meta.Orientation)
+
+	require.NotNil(t, meta.TakenAt)
+	assert.True(t, meta.TakenAt.Equal(time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)))
+
+	require.NotNil(t, meta.Latitude)
+	require.NotNil(t, meta.Longitude)
+	require.NotNil(t, meta.Altitude)
+	assert.InDelta(t, 52.5, *meta.Latitude, 1e-6)
+	assert.InDelta(t, -13.41, *meta.Longitude, 1e-6)
+	assert.InDelta(t, 34.5, *meta.Altitude, 1e-6)
+}
+
+func TestExtractMetadata_JPEGWithXMP(t *testing.T) {
+	data := jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, xmpHeader...), sampleXMP...)))
+
+	meta, err := ExtractMetadata(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "NIKON CORPORATION", meta.Make)
+	assert.Equal(t, "NIKON Z 6", meta.Model)
+	assert.Equal(t, "NIKKOR Z 35mm f/1.8 S", meta.Lens)
+	assert.Equal(t, "1/60", meta.ExposureTime)
+	assert.Equal(t, 5.6, meta.FNumber)
+	assert.Equal(t, 800, meta.ISO)
+	assert.Equal(t, 35.0, meta.FocalLength)
+
+	require.NotNil(t, meta.TakenAt)
+	assert.True(t, meta.TakenAt.Equal(time.Date(2022, 12, 24, 17, 5, 30, 0, time.UTC)))
+
+	require.NotNil(t, meta.Latitude)
+	assert.InDelta(t, 48.854, *meta.Latitude, 1e-6)
+	assert.InDelta(t, 2.350833, *meta.Longitude, 1e-6)
+}
+
+func TestExtractMetadata_JPEGWithIPTC(t *testing.T) {
+	data := jpegWithSegments(t, jpegSegment(0xED, sampleIPTC()))
+
+	meta, err := ExtractMetadata(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.NotNil(t, meta.TakenAt)
+	assert.True(t, meta.TakenAt.Equal(time.Date(2021, 3, 15, 8, 30, 0, 0, time.UTC)))
+	assert.Empty(t, meta.Make)
+}
+
+func TestExtractMetadata_EXIFTakesPrecedence(t *testing.T) {
+	data := jpegWithSegments(t,
+		jpegSegment(0xE1, append(append([]byte{}, xmpHeader...), sampleXMP...)),
+		jpegSegment(0xE1, append(append([]byte{}, exifHeader...), sampleEXIF()...)),
+		jpegSegment(0xED, sampleIPTC()),
+	)
+
+	meta, err := ExtractMetadata(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Canon EOS R5", meta.Model)
+	assert.Equal(t, 2023, meta.TakenAt.Year())
+}
+
+func TestExtractMetadata_PNG(t *testing.T) {
+	t.Run("eXIf chunk", func(t *testing.T) {
+		data := pngWithChunks(t, pngChunk("eXIf", sampleEXIF()))
+
+		meta, err := ExtractMetadata(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "Canon EOS R5", meta.Model)
+		assert.Equal(t, 400, meta.ISO)
+	})
+
+	t.Run("XMP in iTXt chunk", func(t *testing.T) {
+		itxt := append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), sampleXMP...)
+		data := pngWithChunks(t, pngChunk("iTXt", itxt))
+
+		meta, err := ExtractMetadata(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "NIKON Z 6", meta.Model)
+	})
+}
+
+func TestExtractMetadata_WebP(t *testing.T) {
+	// EXIF follows the image data in extended WebP files
+	body := append([]byte("WEBP"), webpChunk("VP8X", make([]byte, 10))...)
+	body = append(body, webpChunk("VP8 ", make([]byte, 33))...)
+	body = append(body, webpChunk("EXIF", sampleEXIF())...)
+
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	meta, err := ExtractMetadata(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Canon", meta.Make)
+	assert.Equal(t, "1/250", meta.ExposureTime)
+}
+
+func TestExtractMetadata_NoMetadata(t *testing.T) {
+	var gifBuf bytes.Buffer
+	require.NoError(t, gif.Encode(&gifBuf, testImage(), nil))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"plain JPEG", jpegWithSegments(t)},
+		{"plain PNG", pngWithChunks(t)},
+		{"unsupported format", gifBuf.Bytes()},
+		{"empty input", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ExtractMetadata(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			assert.True(t, meta.IsEmpty())
+		})
+	}
+}
+
+func TestExtractMetadata_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad byte order", jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), "XX*\x00\x08\x00\x00\x00"...)))},
+		{"IFD out of range", jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), "II*\x00\xff\x00\x00\x00"...)))},
+		{"truncated segment", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10, 0x00, 'E', 'x'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractMetadata(bytes.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("nil reader", func(t *testing.T) {
+		_, err := ExtractMetadata(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatExposureTime(t *testing.T) {
+	assert.Equal(t, "1/250", formatExposureTime(0.004))
+	assert.Equal(t, "1/3", formatExposureTime(1.0/3))
+	assert.Equal(t, "2.5", formatExposureTime(2.5))
+	assert.Equal(t, "30", formatExposureTime(30))
+	assert.Empty(t, formatExposureTime(0))
+}
+
+func TestJoinLens(t *testing.T) {
+	assert.Equal(t, "Sigma 35mm F1.4 DG HSM", joinLens("Sigma", "35mm F1.4 DG HSM"))
+	assert.Equal(t, "Canon RF50mm F1.8 STM", joinLens("Canon", "Canon RF50mm F1.8 STM"))
+	assert.Equal(t, "EF-S18-55mm", joinLens("", "EF-S18-55mm"))
+	assert.Empty(t, joinLens("Canon", ""))
+	assert.True(t, strings.HasPrefix(joinLens("canon", "Canon RF"), "Canon"))
+}
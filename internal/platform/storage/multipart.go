@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// CompletedPart is a part of a multipart upload to be joined into the final object
+type CompletedPart struct {
+	Number int
+	ETag   string
+}
+
+// CreateMultipartUpload starts a multipart upload to path and returns its ID
+func (s *Service) CreateMultipartUpload(ctx context.Context, path string, contentType string) (string, error) {
+	if path == "" {
+		return "", errors.New("path cannot be empty")
+	}
+
+	if !s.isValidContentType(contentType) {
+		return "", fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	core := minio.Core{Client: s.client}
+	uploadID, err := core.NewMultipartUpload(ctx, s.bucketName, path, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart stores a part of a multipart upload and returns its ETag
+func (s *Service) UploadPart(ctx context.Context, path string, uploadID string, number int, data io.Reader, size int64) (string, error) {
+	if data == nil {
+		return "", errors.New("data cannot be nil")
+	}
+
+	core := minio.Core{Client: s.client}
+	part, err := core.PutObjectPart(ctx, s.bucketName, path, uploadID, number, data, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", number, err)
+	}
+
+	return part.ETag, nil
+}
+
+// CompleteMultipartUpload joins the parts of a multipart upload into the object at path
+func (s *Service) CompleteMultipartUpload(ctx context.Context, path string, uploadID string, parts []CompletedPart) error {
+	if len(parts) == 0 {
+		return errors.New("parts cannot be empty")
+	}
+
+	completed := make([]minio.CompletePart, len(parts))
+	for i, part := range parts {
+		completed[i] = minio.CompletePart{PartNumber: part.Number, ETag: part.ETag}
+	}
+
+	core := minio.Core{Client: s.client}
+	if _, err := core.CompleteMultipartUpload(ctx, s.bucketName, path, uploadID, completed, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and the parts stored for it
+func (s *Service) AbortMultipartUpload(ctx context.Context, path string, uploadID string) error {
+	core := minio.Core{Client: s.client}
+	if err := core.AbortMultipartUpload(ctx, s.bucketName, path, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// EXIF orientation values: how the stored pixels must be transformed to display upright
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5 // Mirror across the top-left to bottom-right diagonal
+	OrientationRotate90   = 6 // Rotate 90° clockwise
+	OrientationTransverse = 7 // Mirror across the top-right to bottom-left diagonal
+	OrientationRotate270  = 8 // Rotate 90° counter-clockwise
+)
+
+// OrientationSwapsAxes reports whether displaying an image with the given EXIF
+// orientation swaps its width and height
+func OrientationSwapsAxes(orientation int) bool {
+	return orientation >= OrientationTranspose && orientation <= OrientationRotate270
+}
+
+// decodeOriented decodes an image and turns JPEGs upright according to their EXIF
+// orientation, so derived images match what browsers display
+func decodeOriented(data io.Reader) (image.Image, string, error) {
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(raw))
+	if frame, ok := webpFirstFrame(raw); err != nil && ok {
+		// Animated WebPs are scaled and converted from their first frame
+		src, format, err = image.Decode(bytes.NewReader(frame))
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if format == formatJPEG {
+		src = applyOrientation(src, readOrientation(bytes.NewReader(raw)))
+	}
+	return src, format, nil
+}
+
+// readOrientation returns the EXIF orientation of an image, or OrientationNormal
+// when it has none or its metadata cannot be read
+func readOrientation(data io.Reader) int {
+	meta, err := ExtractMetadata(data)
+	if err != nil || meta.Orientation == 0 {
+		return OrientationNormal
+	}
+	return meta.Orientation
+}
+
+// applyOrientation returns src transformed by the EXIF orientation so it displays upright
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if OrientationSwapsAxes(orientation) {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	// Convert one source row at a time to RGBA, so pixels can be moved as 4-byte groups
+	// without a second full-size copy
+	row := image.NewRGBA(image.Rect(0, 0, w, 1))
+	for sy := 0; sy < h; sy++ {
+		draw.Draw(row, row.Bounds(), src, image.Pt(bounds.Min.X, bounds.Min.Y+sy), draw.Src)
+		for sx := 0; sx < w; sx++ {
+			var x, y int
+			switch orientation {
+			case OrientationFlipH:
+				x, y = w-1-sx, sy
+			case OrientationRotate180:
+				x, y = w-1-sx, h-1-sy
+			case OrientationFlipV:
+				x, y = sx, h-1-sy
+			case OrientationTranspose:
+				x, y = sy, sx
+			case OrientationRotate90:
+				x, y = h-1-sy, sx
+			case OrientationTransverse:
+				x, y = h-1-sy, w-1-sx
+			case OrientationRotate270:
+				x, y = sy, w-1-sx
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):], row.Pix[sx*4:sx*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOrientation(t *testing.T) {
+	// A 3x2 image with a marker at the top-left pixel and another right next to it
+	topLeft := color.RGBA{R: 255, A: 255}
+	next := color.RGBA{G: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, topLeft)
+	src.Set(1, 0, next)
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		topLeftAt   image.Point
+		nextAt      image.Point
+	}{
+		{OrientationNormal, image.Pt(3, 2), image.Pt(0, 0), image.Pt(1, 0)},
+		{OrientationFlipH, image.Pt(3, 2), image.Pt(2, 0), image.Pt(1, 0)},
+		{OrientationRotate180, image.Pt(3, 2), image.Pt(2, 1), image.Pt(1, 1)},
+		{OrientationFlipV, image.Pt(3, 2), image.Pt(0, 1), image.Pt(1, 1)},
+		{OrientationTranspose, image.Pt(2, 3), image.Pt(0, 0), image.Pt(0, 1)},
+		{OrientationRotate90, image.Pt(2, 3), image.Pt(1, 0), image.Pt(1, 1)},
+		{OrientationTransverse, image.Pt(2, 3), image.Pt(1, 2), image.Pt(1, 1)},
+		{OrientationRotate270, image.Pt(2, 3), image.Pt(0, 2), image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		dst := applyOrientation(src, tt.orientation)
+		assert.Equal(t, tt.size, dst.Bounds().Size(), "orientation %d size", tt.orientation)
+		assert.Equal(t, topLeft, color.RGBAModel.Convert(dst.At(tt.topLeftAt.X, tt.topLeftAt.Y)), "orientation %d top-left pixel", tt.orientation)
+		assert.Equal(t, next, color.RGBAModel.Convert(dst.At(tt.nextAt.X, tt.nextAt.Y)), "orientation %d second pixel", tt.orientation)
+	}
+
+	assert.Same(t, src, applyOrientation(src, 0), "unknown orientation is left alone")
+	assert.Same(t, src, applyOrientation(src, 9), "unknown orientation is left alone")
+}
+
+// rotatedJPEG encodes a landscape 80x40 JPEG, red on the left half, with the given EXIF orientation
+func rotatedJPEG(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 80; x++ {
+			if x < 40 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	exif := buildEXIF([]tiffField{shortField(tagOrientation, orientation)}, nil, nil)
+	encoded := buf.Bytes()
+	out := append([]byte{}, encoded[:2]...)
+	out = append(out, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), exif...))...)
+	return append(out, encoded[2:]...)
+}
+
+func TestImageProcessor_AppliesOrientation(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+	ctx := context.Background()
+	data := rotatedJPEG(t, OrientationRotate90)
+
+	t.Run("GetImageInfo reports displayed dimensions", func(t *testing.T) {
+		info, err := processor.GetImageInfo(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, OrientationRotate90, info.Orientation)
+		assert.Equal(t, 40, info.Width)
+		assert.Equal(t, 80, info.Height)
+	})
+
+	t.Run("GenerateThumbnail is upright", func(t *testing.T) {
+		reader, err := processor.GenerateThumbnail(ctx, bytes.NewReader(data), 20, 40)
+		require.NoError(t, err)
+		thumb, _, err := image.Decode(reader)
+		require.NoError(t, err)
+
+		// Rotating clockwise moves the red left half to the top
+		assert.Equal(t, image.Pt(20, 40), thumb.Bounds().Size())
+		r, _, b, _ := thumb.At(10, 5).RGBA()
+		assert.Greater(t, r, b, "top should be red")
+		r, _, b, _ = thumb.At(10, 35).RGBA()
+		assert.Greater(t, b, r, "bottom should be blue")
+	})
+
+	t.Run("Resize is upright", func(t *testing.T) {
+		reader, err := processor.Resize(ctx, bytes.NewReader(data), 20, 40)
+		require.NoError(t, err)
+		resized, _, err := image.Decode(reader)
+		require.NoError(t, err)
+		r, _, b, _ := resized.At(10, 5).RGBA()
+		assert.Greater(t, r, b, "top should be red")
+	})
+
+	t.Run("OptimizeImage is upright", func(t *testing.T) {
+		reader, err := processor.OptimizeImage(ctx, bytes.NewReader(data), 80)
+		require.NoError(t, err)
+		optimized, _, err := image.Decode(reader)
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(40, 80), optimized.Bounds().Size())
+	})
+
+	t.Run("normal orientation", func(t *testing.T) {
+		info, err := processor.GetImageInfo(ctx, bytes.NewReader(rotatedJPEG(t, OrientationNormal)))
+		require.NoError(t, err)
+		assert.Equal(t, OrientationNormal, info.Orientation)
+		assert.Equal(t, 80, info.Width)
+	})
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// PostPolicy restricts what a browser may upload with a presigned POST
+type PostPolicy struct {
+	Key         string
+	ContentType string
+	MinSize     int64
+	MaxSize     int64
+	Expires     time.Time
+	Metadata    map[string]string
+}
+
+// PresignedPostPolicy signs a policy for a browser to POST one file straight to the
+// bucket. It returns the URL to post to and the form fields to send with the file;
+// the policy is signed for the one key, which the key field carries.
+func (s *Service) PresignedPostPolicy(ctx context.Context, policy PostPolicy) (string, map[string]string, error) {
+	if policy.Key == "" {
+		return "", nil, errors.New("key cannot be empty")
+	}
+
+	if !s.isValidContentType(policy.ContentType) {
+		return "", nil, fmt.Errorf("unsupported content type: %s", policy.ContentType)
+	}
+
+	post := minio.NewPostPolicy()
+	conditions := []error{
+		post.SetBucket(s.bucketName),
+		post.SetKey(policy.Key),
+		post.SetContentType(policy.ContentType),
+		post.SetContentLengthRange(policy.MinSize, policy.MaxSize),
+		post.SetExpires(policy.Expires),
+	}
+	for key, value := range policy.Metadata {
+		conditions = append(conditions, post.SetUserMetadata(key, value))
+	}
+	if err := errors.Join(conditions...); err != nil {
+		return "", nil, fmt.Errorf("invalid post policy: %w", err)
+	}
+
+	url, fields, err := s.client.PresignedPostPolicy(ctx, post)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to presign post policy: %w", err)
+	}
+
+	return url.String(), fields, nil
+}
@@ -11,16 +11,21 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/HugoSmits86/nativewebp"
 	"golang.org/x/image/draw"
 	_ "golang.org/x/image/webp" // Import for webp decoding support
 )
 
 // Constants for repeated string literals
 const (
-	formatPNG = "png"
-	formatGIF = "gif"
+	formatJPEG = "jpeg"
+	formatPNG  = "png"
+	formatGIF  = "gif"
+	formatWebP = "webp"
 )
 
 // ImageInfo represents metadata extracted from an image
@@ -31,13 +36,19 @@ type ImageInfo struct {
 	ColorSpace  string
 	HasAlpha    bool
 	Orientation int
+	FrameCount  int           // 1 for still images
+	Duration    time.Duration // One loop of an animation, as encoded in its frame delays
 }
 
 // ImageProcessor implements the domain ImageProcessor interface
 type ImageProcessor struct {
-	maxWidth  int
-	maxHeight int
-	quality   int
+	maxWidth         int
+	maxHeight        int
+	quality          int
+	posterThumbnails bool // Thumbnails of animated GIFs show only the first frame
+	maxPixels        int64
+	decodeMemory     int64         // Budget in bytes for the pixels of one decode
+	decodes          chan struct{} // Slots of the decodes that may run at once
 }
 
 // NewImageProcessor creates a new image processor
@@ -53,12 +64,21 @@ func NewImageProcessor(maxWidth, maxHeight, quality int) *ImageProcessor {
 	}
 
 	return &ImageProcessor{
-		maxWidth:  maxWidth,
-		maxHeight: maxHeight,
-		quality:   quality,
+		maxWidth:     maxWidth,
+		maxHeight:    maxHeight,
+		quality:      quality,
+		maxPixels:    DefaultMaxPixels,
+		decodeMemory: DefaultDecodeMemory,
+		decodes:      make(chan struct{}, DefaultConcurrentDecodes),
 	}
 }
 
+// SetAnimatedThumbnails chooses between animated thumbnails of animated GIFs, the
+// default, and a still poster of their first frame
+func (p *ImageProcessor) SetAnimatedThumbnails(animated bool) {
+	p.posterThumbnails = !animated
+}
+
 // GenerateThumbnail implements ImageProcessor.GenerateThumbnail
 func (p *ImageProcessor) GenerateThumbnail(ctx context.Context, data io.Reader, maxWidth, maxHeight int) (io.Reader, error) {
 	if data == nil {
@@ -69,32 +89,35 @@ func (p *ImageProcessor) GenerateThumbnail(ctx context.Context, data io.Reader,
 		return nil, errors.New("width and height must be positive")
 	}
 
-	// Decode the image
-	src, format, err := image.Decode(data)
+	raw, err := io.ReadAll(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, fmt.Errorf("failed to read image: %w", err)
 	}
 
-	// Calculate thumbnail dimensions maintaining aspect ratio
-	srcBounds := src.Bounds()
-	srcWidth := srcBounds.Dx()
-	srcHeight := srcBounds.Dy()
-
-	// Calculate scaling factor
-	scaleX := float64(maxWidth) / float64(srcWidth)
-	scaleY := float64(maxHeight) / float64(srcHeight)
-	scale := scaleX
-	if scaleY < scaleX {
-		scale = scaleY
+	if !p.posterThumbnails && bytes.HasPrefix(raw, []byte("GIF8")) {
+		anim, release, err := p.decodeAnimatedGIF(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		if anim != nil {
+			defer release()
+			width, height := thumbnailSize(anim.Config.Width, anim.Config.Height, maxWidth, maxHeight)
+			var buf bytes.Buffer
+			if err := gif.EncodeAll(&buf, scaleAnimatedGIF(anim, width, height)); err != nil {
+				return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+			}
+			return bytes.NewReader(buf.Bytes()), nil
+		}
 	}
 
-	// Don't upscale images
-	if scale > 1.0 {
-		scale = 1.0
+	// Decode the image upright, applying any EXIF orientation
+	src, format, release, err := p.decode(ctx, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	dstWidth := int(float64(srcWidth) * scale)
-	dstHeight := int(float64(srcHeight) * scale)
+	dstWidth, dstHeight := thumbnailSize(src.Bounds().Dx(), src.Bounds().Dy(), maxWidth, maxHeight)
 
 	// Create destination image
 	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
@@ -108,9 +131,16 @@ func (p *ImageProcessor) GenerateThumbnail(ctx context.Context, data io.Reader,
 	case formatPNG:
 		err = png.Encode(&buf, dst)
 	case formatGIF:
-		err = gif.Encode(&buf, dst, nil)
+		// Keep the source palette: the encoder's default web palette bands photos
+		var out image.Image = dst
+		if paletted, ok := src.(*image.Paletted); ok {
+			out = ditherToPalette(dst, paletted.Palette)
+		}
+		err = gif.Encode(&buf, out, nil)
+	case formatWebP:
+		err = nativewebp.Encode(&buf, dst, nil)
 	default:
-		// Default to JPEG for all other formats including webp
+		// Default to JPEG for all other formats
 		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: p.quality})
 	}
 
@@ -121,24 +151,68 @@ func (p *ImageProcessor) GenerateThumbnail(ctx context.Context, data io.Reader,
 	return bytes.NewReader(buf.Bytes()), nil
 }
 
+// thumbnailSize fits an image into a box, keeping its aspect ratio and never upscaling
+func thumbnailSize(srcWidth, srcHeight, maxWidth, maxHeight int) (int, int) {
+	// Calculate scaling factor
+	scaleX := float64(maxWidth) / float64(srcWidth)
+	scaleY := float64(maxHeight) / float64(srcHeight)
+	scale := scaleX
+	if scaleY < scaleX {
+		scale = scaleY
+	}
+
+	// Don't upscale images
+	if scale > 1.0 {
+		scale = 1.0
+	}
+
+	return max(1, int(float64(srcWidth)*scale)), max(1, int(float64(srcHeight)*scale))
+}
+
 // GetImageInfo implements ImageProcessor.GetImageInfo
 func (p *ImageProcessor) GetImageInfo(ctx context.Context, data io.Reader) (*ImageInfo, error) {
 	if data == nil {
 		return nil, errors.New("data cannot be nil")
 	}
 
-	// Decode image to get configuration
-	config, format, err := image.DecodeConfig(data)
+	// Decode image to get configuration, keeping the bytes read so the
+	// EXIF orientation can be looked up without another pass over the stream
+	var head bytes.Buffer
+	config, format, err := image.DecodeConfig(io.TeeReader(data, &head))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image config: %w", err)
 	}
 
+	// Report the dimensions as displayed, i.e. after applying the orientation
+	orientation := OrientationNormal
+	if format == formatJPEG {
+		orientation = readOrientation(io.MultiReader(&head, data))
+		if OrientationSwapsAxes(orientation) {
+			config.Width, config.Height = config.Height, config.Width
+		}
+	}
+
+	// Count the frames of animations, skipping over their image data
+	frames, duration := 1, time.Duration(0)
+	if format == formatGIF || format == formatWebP {
+		if n, d, err := readAnimation(io.MultiReader(&head, data), format); err == nil {
+			frames, duration = n, d
+		}
+	}
+
 	// Determine color space and alpha channel
 	colorSpace := "unknown"
 	hasAlpha := false
 
-	// Try to decode the actual image to get more detailed info
-	if seeker, ok := data.(io.Seeker); ok {
+	// Try to decode the actual image to get more detailed info, if it is within the
+	// decode limits; its dimensions and frames are reported either way
+	if seeker, ok := data.(io.Seeker); ok && p.checkDecodeConfig(config, 1, false) == nil {
+		release, err := p.acquireDecode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
 		// Reset reader to beginning
 		_, _ = seeker.Seek(0, io.SeekStart) //nolint:errcheck // Seeker reset for image info extraction
 
@@ -167,7 +241,9 @@ func (p *ImageProcessor) GetImageInfo(ctx context.Context, data io.Reader) (*Ima
 		Format:      format,
 		ColorSpace:  colorSpace,
 		HasAlpha:    hasAlpha,
-		Orientation: 1, // Default orientation
+		Orientation: orientation,
+		FrameCount:  frames,
+		Duration:    duration,
 	}, nil
 }
 
@@ -187,11 +263,12 @@ func (p *ImageProcessor) Resize(ctx context.Context, data io.Reader, width, heig
 			width, height, p.maxWidth, p.maxHeight)
 	}
 
-	// Decode the image
-	src, format, err := image.Decode(data)
+	// Decode the image upright, applying any EXIF orientation
+	src, format, release, err := p.decode(ctx, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	defer release()
 
 	// Create destination image
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -218,6 +295,164 @@ func (p *ImageProcessor) Resize(ctx context.Context, data io.Reader, width, heig
 	return bytes.NewReader(buf.Bytes()), nil
 }
 
+// RenderOptions describes a derivative: the box it must fit, how and its encoding
+type RenderOptions struct {
+	Width   int
+	Height  int
+	Crop    bool   // Fill the box, cropping the overflow around the center; otherwise fit inside it
+	Format  string // formatJPEG, formatPNG or formatWebP
+	Quality int    // JPEG quality; the processor default when zero
+}
+
+// Render implements ImageProcessor.Render. Images are never upscaled: a source smaller
+// than the box is rendered at its own size (cropped to the box's aspect ratio for Crop).
+func (p *ImageProcessor) Render(ctx context.Context, data io.Reader, opts RenderOptions) (io.Reader, error) {
+	if data == nil {
+		return nil, errors.New("data cannot be nil")
+	}
+
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, errors.New("width and height must be positive")
+	}
+
+	if opts.Width > p.maxWidth || opts.Height > p.maxHeight {
+		return nil, fmt.Errorf("requested dimensions %dx%d exceed maximum allowed %dx%d",
+			opts.Width, opts.Height, p.maxWidth, p.maxHeight)
+	}
+
+	// Decode the image upright, applying any EXIF orientation
+	src, _, release, err := p.decode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	srcRect := src.Bounds()
+	if opts.Crop {
+		srcRect = centerCrop(srcRect, opts.Width, opts.Height)
+	}
+	dstWidth, dstHeight := p.CalculateOptimalThumbnailSize(srcRect.Dx(), srcRect.Dy(), opts.Width, opts.Height)
+	if dstWidth < 1 {
+		dstWidth = 1
+	}
+	if dstHeight < 1 {
+		dstHeight = 1
+	}
+
+	encoded, err := p.scaleAndEncode(src, srcRect, dstWidth, dstHeight, opts.Format, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(encoded), nil
+}
+
+// Variant is a responsive copy of an image at a fixed width
+type Variant struct {
+	Width  int
+	Height int
+	Format string // formatJPEG, or formatPNG for PNG sources so transparency is kept
+	Data   []byte
+}
+
+// GenerateVariants decodes an image once and encodes a copy at each requested width,
+// narrowest first. Widths wider than the image are skipped rather than upscaled, and
+// duplicate widths are generated once.
+func (p *ImageProcessor) GenerateVariants(ctx context.Context, data io.Reader, widths []int, quality int) ([]Variant, error) {
+	if data == nil {
+		return nil, errors.New("data cannot be nil")
+	}
+
+	src, format, release, err := p.decode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	outFormat := formatJPEG
+	if format == formatPNG {
+		outFormat = formatPNG
+	}
+
+	sorted := append([]int(nil), widths...)
+	sort.Ints(sorted)
+
+	bounds := src.Bounds()
+	variants := make([]Variant, 0, len(sorted))
+	for i, width := range sorted {
+		if width <= 0 || width > bounds.Dx() || (i > 0 && width == sorted[i-1]) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		encoded, err := p.scaleAndEncode(src, bounds, width, height, outFormat, quality)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{Width: width, Height: height, Format: outFormat, Data: encoded})
+	}
+	return variants, nil
+}
+
+// scaleAndEncode scales the srcRect part of src to width x height and encodes it
+func (p *ImageProcessor) scaleAndEncode(src image.Image, srcRect image.Rectangle, width, height int, format string, quality int) ([]byte, error) {
+	// JPEG has no alpha channel, so flatten transparent sources onto white
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	op := draw.Src
+	if format == formatJPEG {
+		draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+		op = draw.Over
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, op, nil)
+
+	return p.encode(dst, format, quality)
+}
+
+// encode encodes an image as JPEG, PNG or lossless WebP
+func (p *ImageProcessor) encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case formatPNG:
+		err = png.Encode(&buf, img)
+	case formatWebP:
+		err = nativewebp.Encode(&buf, img, nil)
+	case formatJPEG:
+		if quality <= 0 || quality > 100 {
+			quality = p.quality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	default:
+		return nil, fmt.Errorf("unsupported render format: %s", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rendered image: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// centerCrop returns the largest rectangle with the aspect ratio width:height centered in bounds
+func centerCrop(bounds image.Rectangle, width, height int) image.Rectangle {
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if srcWidth*height > srcHeight*width {
+		// Source is wider than the box: trim the sides
+		cropWidth := srcHeight * width / height
+		x0 := bounds.Min.X + (srcWidth-cropWidth)/2
+		return image.Rect(x0, bounds.Min.Y, x0+cropWidth, bounds.Max.Y)
+	}
+	// Source is taller than the box: trim the top and bottom
+	cropHeight := srcWidth * height / width
+	y0 := bounds.Min.Y + (srcHeight-cropHeight)/2
+	return image.Rect(bounds.Min.X, y0, bounds.Max.X, y0+cropHeight)
+}
+
 // ValidateImage implements ImageProcessor.ValidateImage
 func (p *ImageProcessor) ValidateImage(ctx context.Context, data io.Reader, contentType string) error {
 	if err := p.validateInputs(data, contentType); err != nil {
@@ -228,6 +463,12 @@ func (p *ImageProcessor) ValidateImage(ctx context.Context, data io.Reader, cont
 		return err
 	}
 
+	// SVG is XML rather than a raster format: it is valid when it can be sanitized
+	if contentType == contentTypeSVG {
+		_, err := SanitizeSVG(data)
+		return err
+	}
+
 	config, format, err := p.decodeImageConfig(data)
 	if err != nil {
 		return err
@@ -254,11 +495,16 @@ func (p *ImageProcessor) validateInputs(data io.Reader, contentType string) erro
 // validateSupportedContentType checks if the content type is supported
 func (p *ImageProcessor) validateSupportedContentType(contentType string) error {
 	supportedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/gif":  true,
-		"image/webp": true,
+		"image/jpeg":   true,
+		"image/jpg":    true,
+		"image/png":    true,
+		"image/gif":    true,
+		"image/webp":   true,
+		"image/bmp":    true,
+		"image/tiff":   true,
+		"image/heic":   true,
+		"image/heif":   true,
+		contentTypeSVG: true,
 	}
 
 	if !supportedTypes[contentType] {
@@ -286,7 +532,10 @@ func (p *ImageProcessor) validateImageDimensions(config image.Config) error {
 		return fmt.Errorf("image dimensions %dx%d exceed maximum allowed %dx%d",
 			config.Width, config.Height, p.maxWidth, p.maxHeight)
 	}
-	return nil
+
+	// Within the width and height limits, the pixel count and decoded size may still be
+	// too large when the limits are raised
+	return p.checkDecodeConfig(config, 1, false)
 }
 
 // validateFormatMatchesContentType validates that the image format matches the content type
@@ -297,6 +546,10 @@ func (p *ImageProcessor) validateFormatMatchesContentType(format, contentType st
 		"image/png":  {"png"},
 		"image/gif":  {"gif"},
 		"image/webp": {"webp"},
+		"image/bmp":  {formatBMP},
+		"image/tiff": {formatTIFF},
+		"image/heic": {formatHEIC},
+		"image/heif": {formatHEIC},
 	}
 
 	expectedList, ok := expectedFormats[contentType]
@@ -327,11 +580,12 @@ func (p *ImageProcessor) OptimizeImage(ctx context.Context, data io.Reader, qual
 		quality = p.quality
 	}
 
-	// Decode the image
-	src, format, err := image.Decode(data)
+	// Decode the image upright, applying any EXIF orientation
+	src, format, release, err := p.decode(ctx, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	defer release()
 
 	var buf bytes.Buffer
 
@@ -362,9 +616,24 @@ func (p *ImageProcessor) OptimizeImage(ctx context.Context, data io.Reader, qual
 	return bytes.NewReader(buf.Bytes()), nil
 }
 
+// ExtractMetadata reads EXIF, XMP and IPTC capture details from an image
+func (p *ImageProcessor) ExtractMetadata(ctx context.Context, data io.Reader) (*PhotoMetadata, error) {
+	return ExtractMetadata(data)
+}
+
+// SanitizeSVG implements ImageProcessor.SanitizeSVG
+func (p *ImageProcessor) SanitizeSVG(ctx context.Context, data io.Reader) ([]byte, error) {
+	return SanitizeSVG(data)
+}
+
+// StripMetadata removes embedded metadata from an image without changing its length
+func (p *ImageProcessor) StripMetadata(ctx context.Context, data io.Reader, mode StripMode) io.ReadCloser {
+	return StripMetadata(data, mode)
+}
+
 // GetSupportedFormats returns the list of supported image formats
 func (p *ImageProcessor) GetSupportedFormats() []string {
-	return []string{"jpeg", "jpg", formatPNG, formatGIF, "webp"}
+	return []string{"jpeg", "jpg", formatPNG, formatGIF, "webp", formatBMP, formatTIFF, formatHEIC, "svg"}
 }
 
 // GetMaxDimensions returns the maximum allowed dimensions
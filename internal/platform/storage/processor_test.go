@@ -206,6 +206,24 @@ func TestImageProcessor_GetImageInfo(t *testing.T) {
 			expectedHeight: 100,
 			expectedFormat: "png",
 		},
+		{
+			// Uploads pass only the header, as their body is still streaming to storage.
+			// Without a JFIF segment the JPEG decoder reads up to the scan header.
+			name:           "JPEG header only",
+			data:           bytes.NewBuffer(jpegBuf.Bytes()[:600]),
+			expectError:    false,
+			expectedWidth:  100,
+			expectedHeight: 200,
+			expectedFormat: "jpeg",
+		},
+		{
+			name:           "PNG header only",
+			data:           bytes.NewBuffer(pngBuf.Bytes()[:64]),
+			expectError:    false,
+			expectedWidth:  150,
+			expectedHeight: 100,
+			expectedFormat: "png",
+		},
 	}
 
 	for _, tt := range tests {
@@ -320,6 +338,115 @@ func TestImageProcessor_Resize(t *testing.T) {
 	}
 }
 
+func TestImageProcessor_Render(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+
+	// A landscape source with a red left half and a blue right half
+	src := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 400 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	render := func(t *testing.T, opts RenderOptions) image.Image {
+		t.Helper()
+		result, err := processor.Render(context.Background(), bytes.NewReader(buf.Bytes()), opts)
+		require.NoError(t, err)
+		img, _, err := image.Decode(result)
+		require.NoError(t, err)
+		return img
+	}
+
+	t.Run("crop fills the box from the center", func(t *testing.T) {
+		img := render(t, RenderOptions{Width: 200, Height: 200, Crop: true, Format: formatJPEG, Quality: 80})
+		assert.Equal(t, image.Rect(0, 0, 200, 200), img.Bounds())
+
+		// The center square spans both halves
+		r, _, b, _ := img.At(20, 100).RGBA()
+		assert.Greater(t, r, b)
+		r, _, b, _ = img.At(180, 100).RGBA()
+		assert.Greater(t, b, r)
+	})
+
+	t.Run("fit keeps the whole image inside the box", func(t *testing.T) {
+		img := render(t, RenderOptions{Width: 400, Height: 400, Format: formatPNG})
+		assert.Equal(t, image.Rect(0, 0, 400, 200), img.Bounds())
+	})
+
+	t.Run("never upscales", func(t *testing.T) {
+		img := render(t, RenderOptions{Width: 1600, Height: 1600, Format: formatPNG})
+		assert.Equal(t, image.Rect(0, 0, 800, 400), img.Bounds())
+
+		img = render(t, RenderOptions{Width: 1200, Height: 630, Crop: true, Format: formatJPEG})
+		assert.Equal(t, 400, img.Bounds().Dy(), "the crop is rendered at source resolution")
+	})
+
+	t.Run("encodes webp", func(t *testing.T) {
+		result, err := processor.Render(context.Background(), bytes.NewReader(buf.Bytes()), RenderOptions{Width: 200, Height: 200, Crop: true, Format: formatWebP})
+		require.NoError(t, err)
+		img, format, err := image.Decode(result)
+		require.NoError(t, err)
+		assert.Equal(t, formatWebP, format)
+		assert.Equal(t, image.Rect(0, 0, 200, 200), img.Bounds())
+	})
+
+	t.Run("rejects unknown formats and oversized boxes", func(t *testing.T) {
+		_, err := processor.Render(context.Background(), bytes.NewReader(buf.Bytes()), RenderOptions{Width: 100, Height: 100, Format: "bmp"})
+		assert.Error(t, err)
+		_, err = processor.Render(context.Background(), bytes.NewReader(buf.Bytes()), RenderOptions{Width: 3000, Height: 100, Format: formatPNG})
+		assert.Error(t, err)
+	})
+}
+
+func TestImageProcessor_GenerateVariants(t *testing.T) {
+	processor := NewImageProcessor(2000, 2000, 85)
+
+	encode := func(t *testing.T, width, height int, encoder func(io.Writer, image.Image) error) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, encoder(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+		return buf.Bytes()
+	}
+	encodeJPEG := func(w io.Writer, m image.Image) error { return jpeg.Encode(w, m, nil) }
+
+	t.Run("keeps the aspect ratio and skips widths wider than the source", func(t *testing.T) {
+		data := encode(t, 1000, 500, encodeJPEG)
+		variants, err := processor.GenerateVariants(context.Background(), bytes.NewReader(data), []int{2048, 320, 640, 320}, 80)
+		require.NoError(t, err)
+		require.Len(t, variants, 2)
+
+		assert.Equal(t, 320, variants[0].Width)
+		assert.Equal(t, 160, variants[0].Height)
+		assert.Equal(t, formatJPEG, variants[0].Format)
+		assert.Equal(t, 640, variants[1].Width)
+
+		img, format, err := image.Decode(bytes.NewReader(variants[1].Data))
+		require.NoError(t, err)
+		assert.Equal(t, formatJPEG, format)
+		assert.Equal(t, image.Rect(0, 0, 640, 320), img.Bounds())
+	})
+
+	t.Run("png sources stay png", func(t *testing.T) {
+		data := encode(t, 800, 800, png.Encode)
+		variants, err := processor.GenerateVariants(context.Background(), bytes.NewReader(data), []int{320}, 80)
+		require.NoError(t, err)
+		require.Len(t, variants, 1)
+		assert.Equal(t, formatPNG, variants[0].Format)
+	})
+
+	t.Run("rejects invalid images", func(t *testing.T) {
+		_, err := processor.GenerateVariants(context.Background(), strings.NewReader("not an image"), []int{320}, 80)
+		assert.Error(t, err)
+	})
+}
+
 func TestImageProcessor_ValidateImage(t *testing.T) {
 	processor := NewImageProcessor(2000, 2000, 85)
 
@@ -475,7 +602,7 @@ func TestImageProcessor_GetSupportedFormats(t *testing.T) {
 	processor := NewImageProcessor(2000, 2000, 85)
 	formats := processor.GetSupportedFormats()
 
-	expectedFormats := []string{"jpeg", "jpg", "png", "gif", "webp"}
+	expectedFormats := []string{"jpeg", "jpg", "png", "gif", "webp", "bmp", "tiff", "heic", "svg"}
 	assert.ElementsMatch(t, expectedFormats, formats)
 }
 
@@ -135,6 +135,31 @@ func (s *Service) Store(ctx context.Context, filename string, contentType string
 	return storagePath, nil
 }
 
+// StoreAt implements StorageService.StoreAt for files the application generates itself,
+// such as rendered derivatives, which are stored under deterministic paths
+func (s *Service) StoreAt(ctx context.Context, path string, contentType string, data io.Reader, size int64) error {
+	if path == "" {
+		return errors.New("path cannot be empty")
+	}
+
+	if data == nil {
+		return errors.New("data cannot be nil")
+	}
+
+	if !s.isValidContentType(contentType) {
+		return fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucketName, path, data, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return nil
+}
+
 // Retrieve implements StorageService.Retrieve
 func (s *Service) Retrieve(ctx context.Context, path string) (io.ReadCloser, error) {
 	if path == "" {
@@ -183,6 +208,52 @@ func (s *Service) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
+// DeletePrefix implements StorageService.DeletePrefix
+func (s *Service) DeletePrefix(ctx context.Context, prefix string) error {
+	if prefix == "" {
+		return errors.New("prefix cannot be empty")
+	}
+
+	// Feed the listing into a batch delete, keeping listing errors out of the delete requests
+	var listErr error
+	objectCh := make(chan minio.ObjectInfo)
+	listDone := make(chan struct{})
+	go func() {
+		defer close(listDone)
+		defer close(objectCh)
+		for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+			Prefix:    prefix,
+			Recursive: true,
+		}) {
+			if object.Err != nil {
+				listErr = object.Err
+				continue
+			}
+			select {
+			case objectCh <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var removeErr error
+	for result := range s.client.RemoveObjects(ctx, s.bucketName, objectCh, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil && removeErr == nil {
+			removeErr = fmt.Errorf("failed to delete object %s: %w", result.ObjectName, result.Err)
+		}
+	}
+
+	<-listDone
+	if listErr != nil {
+		return fmt.Errorf("error listing objects: %w", listErr)
+	}
+	if removeErr != nil {
+		return removeErr
+	}
+	return ctx.Err()
+}
+
 // Exists implements StorageService.Exists
 func (s *Service) Exists(ctx context.Context, path string) (bool, error) {
 	if path == "" {
@@ -226,6 +297,7 @@ type FileInfo struct {
 	ContentType  string
 	LastModified int64
 	ETag         string
+	Metadata     map[string]string // User metadata, keys in lower case
 }
 
 // GetFileInfo implements StorageService.GetFileInfo
@@ -248,9 +320,23 @@ func (s *Service) GetFileInfo(ctx context.Context, path string) (*FileInfo, erro
 		ContentType:  stat.ContentType,
 		LastModified: stat.LastModified.Unix(),
 		ETag:         stat.ETag,
+		Metadata:     userMetadata(stat.UserMetadata),
 	}, nil
 }
 
+// userMetadata copies an object's user metadata with its keys in lower case, as they
+// are given when the object is stored rather than canonicalized as headers
+func userMetadata(metadata minio.StringMap) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[strings.ToLower(key)] = value
+	}
+	return copied
+}
+
 // Health checks the health of the storage service
 func (s *Service) Health(ctx context.Context) error {
 	// Check if we can list objects (basic connectivity test)
@@ -393,11 +479,16 @@ func (s *Service) sanitizeFilename(filename string) string {
 func (s *Service) isValidContentType(contentType string) bool {
 	// Basic supported content types - this could be moved to config
 	supportedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/gif":  true,
-		"image/webp": true,
+		"image/jpeg":   true,
+		"image/jpg":    true,
+		"image/png":    true,
+		"image/gif":    true,
+		"image/webp":   true,
+		"image/bmp":    true,
+		"image/tiff":   true,
+		"image/heic":   true,
+		"image/heif":   true,
+		contentTypeSVG: true,
 	}
 	return supportedTypes[contentType]
 }
@@ -545,6 +636,12 @@ func (s *Service) validateExtensionContentType(filename, contentType string) err
 		".png":  {"image/png"},
 		".gif":  {"image/gif"},
 		".webp": {"image/webp"},
+		".bmp":  {"image/bmp"},
+		".tif":  {"image/tiff"},
+		".tiff": {"image/tiff"},
+		".heic": {"image/heic", "image/heif"},
+		".heif": {"image/heif", "image/heic"},
+		".svg":  {contentTypeSVG},
 	}
 
 	if expected, exists := expectedTypes[ext]; exists {
@@ -618,6 +715,18 @@ func (s *Service) getMagicNumberPatterns(contentType string) ([][]byte, error) {
 		"image/webp": {
 			{0x52, 0x49, 0x46, 0x46}, // RIFF (WebP container)
 		},
+		"image/bmp": {
+			{0x42, 0x4D}, // BM
+		},
+		"image/tiff": {
+			{0x49, 0x49, 0x2A, 0x00}, // II*\0 (little-endian)
+			{0x4D, 0x4D, 0x00, 0x2A}, // MM\0* (big-endian)
+		},
+		// HEIF files start with a variable-size ftyp box and SVG is text: the empty
+		// pattern matches anything and validateSpecialCases does the actual check
+		"image/heic":   {{}},
+		"image/heif":   {{}},
+		contentTypeSVG: {{}},
 	}
 
 	patterns, exists := magicNumbers[contentType]
@@ -656,8 +765,25 @@ func (s *Service) matchesPattern(header []byte, pattern []byte) bool {
 
 // validateSpecialCases handles content type specific additional validations
 func (s *Service) validateSpecialCases(header []byte, contentType string) error {
-	if contentType == "image/webp" {
+	switch contentType {
+	case "image/webp":
 		return s.validateWebPSignature(header)
+	case "image/heic", "image/heif":
+		if !isHEIFHeader(header) {
+			return errors.New("invalid HEIF signature")
+		}
+	case contentTypeSVG:
+		return s.validateSVGHeader(header)
+	}
+	return nil
+}
+
+// validateSVGHeader checks that a file starts like an XML document and is not binary.
+// The full document is checked when it is sanitized.
+func (s *Service) validateSVGHeader(header []byte) error {
+	text := bytes.TrimLeft(bytes.TrimPrefix(header, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	if len(text) == 0 || text[0] != '<' || bytes.IndexByte(header, 0) >= 0 {
+		return errors.New("invalid SVG header")
 	}
 	return nil
 }
@@ -678,8 +804,11 @@ func (s *Service) validateWebPSignature(header []byte) error {
 	return nil
 }
 
-// getMaxFileSize returns the maximum allowed file size in bytes
+// getMaxFileSize returns the maximum allowed file size in bytes: the configured upload
+// size, 10MB by default
 func (s *Service) getMaxFileSize() int64 {
-	// Default: 10MB, could be made configurable
+	if s.config != nil && s.config.MaxUploadSize > 0 {
+		return s.config.MaxUploadSize
+	}
 	return 10 * 1024 * 1024
 }
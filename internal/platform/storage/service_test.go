@@ -401,6 +401,11 @@ func TestService_isValidContentType(t *testing.T) {
 		{"valid png", "image/png", true},
 		{"valid gif", "image/gif", true},
 		{"valid webp", "image/webp", true},
+		{"valid bmp", "image/bmp", true},
+		{"valid tiff", "image/tiff", true},
+		{"valid heic", "image/heic", true},
+		{"valid heif", "image/heif", true},
+		{"valid svg", "image/svg+xml", true},
 		{"invalid pdf", "application/pdf", false},
 		{"invalid text", "text/plain", false},
 		{"empty", "", false},
@@ -610,6 +615,54 @@ func TestService_MagicNumberValidation(t *testing.T) {
 				contentType: "image/jpeg",
 				expectError: true,
 			},
+			{
+				name:        "valid BMP",
+				data:        []byte("BM\x36\x00\x00\x00\x00\x00"),
+				contentType: "image/bmp",
+				expectError: false,
+			},
+			{
+				name:        "valid little-endian TIFF",
+				data:        []byte("II*\x00\x08\x00\x00\x00"),
+				contentType: "image/tiff",
+				expectError: false,
+			},
+			{
+				name:        "valid big-endian TIFF",
+				data:        []byte("MM\x00*\x00\x00\x00\x08"),
+				contentType: "image/tiff",
+				expectError: false,
+			},
+			{
+				name:        "valid HEIC",
+				data:        []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"),
+				contentType: "image/heic",
+				expectError: false,
+			},
+			{
+				name:        "MP4 declared as HEIF",
+				data:        []byte("\x00\x00\x00\x18ftypisom\x00\x00\x00\x00"),
+				contentType: "image/heif",
+				expectError: true,
+			},
+			{
+				name:        "valid SVG",
+				data:        []byte("\xEF\xBB\xBF\n  <svg xmlns=\"http://www.w3.org/2000/svg\"/>"),
+				contentType: "image/svg+xml",
+				expectError: false,
+			},
+			{
+				name:        "binary declared as SVG",
+				data:        []byte("<svg\x00\x01\x02\x03\x04"),
+				contentType: "image/svg+xml",
+				expectError: true,
+			},
+			{
+				name:        "text declared as SVG",
+				data:        []byte("hello world"),
+				contentType: "image/svg+xml",
+				expectError: true,
+			},
 		}
 
 		for _, tt := range tests {
@@ -650,6 +703,15 @@ func TestService_FileSizeValidation(t *testing.T) {
 		maxSize := service.getMaxFileSize()
 		assert.Equal(t, int64(10*1024*1024), maxSize) // Should be 10MB
 	})
+
+	t.Run("getMaxFileSize uses configured upload size", func(t *testing.T) {
+		service := &Service{
+			bucketName: "test-bucket",
+			config:     &config.StorageConfig{BucketName: "test-bucket", MaxUploadSize: 50 * 1024 * 1024},
+		}
+
+		assert.Equal(t, int64(50*1024*1024), service.getMaxFileSize())
+	})
 }
 
 // Integration tests (require running MinIO)
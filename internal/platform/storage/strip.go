@@ -0,0 +1,715 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"regexp"
+)
+
+// StripMode selects which embedded metadata StripMetadata removes
+type StripMode int
+
+// Strip modes
+const (
+	StripNone StripMode = iota // Pass the file through unchanged
+	StripGPS                   // Blank GPS positions in EXIF and XMP
+	StripAll                   // Blank all EXIF but the orientation, and all XMP, IPTC and text metadata
+)
+
+// Replacement block types for removed metadata. They keep the container valid and
+// are ignored by decoders: a JPEG comment, a private ancillary PNG chunk, a RIFF
+// padding chunk and a GIF comment extension.
+const (
+	jpegComment  = 0xFE
+	pngBlankType = "blNk"
+	webpBlank    = "JUNK"
+	gifComment   = 0xFE
+)
+
+// GIF block introducers and extension labels
+const (
+	gifExtension   = 0x21
+	gifImage       = 0x2C
+	gifApplication = 0xFF
+)
+
+// gifLoopExtensions are the application extensions holding an animation's loop count,
+// the only ones a GIF keeps when it is stripped
+var gifLoopExtensions = map[string]bool{"NETSCAPE2.0": true, "ANIMEXTS1.0": true}
+
+// VP8X feature flags announcing EXIF and XMP chunks
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+var xmpExtensionHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+
+// pngRawProfileKeyword starts the keyword of the PNG text chunks ImageMagick and
+// GraphicsMagick store EXIF, XMP and other profiles in, hex encoded
+var pngRawProfileKeyword = []byte("Raw profile type ")
+
+// StripMetadata returns a reader over data with metadata removed according to mode.
+//
+// Removed metadata is blanked in place rather than cut out, so the output has exactly
+// the length of the input and can be streamed to storage with the size declared
+// by the upload. JPEG, PNG, WebP and GIF are understood; other formats pass through
+// unchanged. When a metadata block cannot be parsed it is blanked entirely. GIF
+// metadata, XMP in an application extension or text in comments, cannot be edited in
+// place, so it is blanked in either mode. StripAll keeps the EXIF orientation alone,
+// so that rotated photos are still displayed, and measured, upright.
+//
+// The returned reader must be closed to release the goroutine producing it.
+func StripMetadata(data io.Reader, mode StripMode) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(stripStream(pw, data, mode)) //nolint:errcheck // Always returns nil
+	}()
+	return pr
+}
+
+// stripStream copies data to w, blanking metadata blocks on the way
+func stripStream(w io.Writer, data io.Reader, mode StripMode) error {
+	br := bufio.NewReader(data)
+	if mode == StripNone {
+		_, err := io.Copy(w, br)
+		return err
+	}
+
+	head, err := br.Peek(12)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	switch {
+	case len(head) >= 2 && head[0] == 0xFF && head[1] == 0xD8:
+		err = stripJPEG(w, br, mode)
+	case bytes.HasPrefix(head, pngSignature):
+		err = stripPNG(w, br, mode)
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		err = stripWebP(w, br, mode)
+	case bytes.HasPrefix(head, []byte("GIF87a")) || bytes.HasPrefix(head, []byte("GIF89a")):
+		err = stripGIF(w, br)
+	default:
+		_, err = io.Copy(w, br)
+	}
+	return err
+}
+
+// stripJPEG rewrites the APP segments before the image data and copies the rest
+func stripJPEG(w io.Writer, r *bufio.Reader, mode StripMode) error {
+	if _, err := io.CopyN(w, r, 2); err != nil { // SOI
+		return err
+	}
+
+	for {
+		// Copy the marker including any fill bytes
+		c, err := r.ReadByte()
+		if err != nil {
+			return eofIsDone(err)
+		}
+		prefix := []byte{c}
+		for c == 0xFF {
+			if c, err = r.ReadByte(); err != nil {
+				return eofIsDone(err)
+			}
+			prefix = append(prefix, c)
+		}
+		marker := c
+
+		if prefix[0] != 0xFF || marker == 0xDA || marker == 0xD9 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			// Image data, end of image, markers without payload, or garbage: copy as is
+			if _, err := w.Write(prefix); err != nil {
+				return err
+			}
+			if marker == 0xDA || marker == 0xD9 || prefix[0] != 0xFF {
+				_, err := io.Copy(w, r)
+				return err
+			}
+			continue
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			_, _ = w.Write(prefix) //nolint:errcheck // Truncated file: keep what there is
+			_, _ = w.Write(length[:])
+			return eofIsDone(err)
+		}
+		size := int64(binary.BigEndian.Uint16(length[:])) - 2
+		if size < 0 || (marker != 0xE1 && marker != 0xED) {
+			if _, err := w.Write(append(prefix, length[:]...)); err != nil {
+				return err
+			}
+			if size > 0 {
+				if _, err := io.CopyN(w, r, size); err != nil {
+					return eofIsDone(err)
+				}
+			}
+			continue
+		}
+
+		payload := make([]byte, size)
+		n, err := io.ReadFull(r, payload)
+		payload = payload[:n]
+		if keep := stripJPEGSegment(marker, payload, mode); !keep {
+			prefix[len(prefix)-1] = jpegComment
+			clear(payload)
+		}
+		if _, werr := w.Write(append(append(prefix, length[:]...), payload...)); werr != nil {
+			return werr
+		}
+		if err != nil {
+			return eofIsDone(err)
+		}
+	}
+}
+
+// stripJPEGSegment blanks GPS inside an APP1/APP13 payload, or all of it but the
+// orientation, or reports false when the whole segment has to be blanked
+func stripJPEGSegment(marker byte, payload []byte, mode StripMode) bool {
+	if mode == StripAll {
+		if marker != 0xE1 || !bytes.HasPrefix(payload, exifHeader) {
+			return false
+		}
+		orientation, err := reduceEXIFToOrientation(payload[len(exifHeader):])
+		return err == nil && orientation != 0
+	}
+	switch {
+	case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+		return blankEXIFGPS(payload[len(exifHeader):]) == nil
+	case marker == 0xE1 && bytes.HasPrefix(payload, xmpHeader):
+		blankXMPGPS(payload[len(xmpHeader):])
+		return true
+	case marker == 0xE1 && bytes.HasPrefix(payload, xmpExtensionHeader):
+		return false // Extended XMP is split across segments and cannot be edited piecewise
+	}
+	return true // IPTC carries no coordinates
+}
+
+// stripPNG rewrites the metadata chunks of a PNG
+func stripPNG(w io.Writer, r *bufio.Reader, mode StripMode) error {
+	if _, err := io.CopyN(w, r, int64(len(pngSignature))); err != nil {
+		return err
+	}
+
+	var header [8]byte
+	for {
+		if n, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			_, _ = w.Write(header[:n]) //nolint:errcheck // Truncated file: keep what there is
+			return eofIsDone(err)
+		}
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		chunkType := string(header[4:8])
+
+		isText := chunkType == "iTXt" || chunkType == "tEXt" || chunkType == "zTXt"
+		keepText := false
+		if isText && mode == StripGPS {
+			// Raw profiles may hold EXIF or XMP, hex encoded and possibly compressed, so
+			// they go whole; other text is kept, bar the XMP iTXt chunk edited below
+			keyword, _ := r.Peek(len(pngRawProfileKeyword)) //nolint:errcheck // A short chunk has no such keyword
+			rawProfile := size >= int64(len(pngRawProfileKeyword)) && bytes.Equal(keyword, pngRawProfileKeyword)
+			if rawProfile {
+				if err := blankPNGChunk(w, r, size); err != nil {
+					return err
+				}
+				continue
+			}
+			keepText = chunkType != "iTXt"
+		}
+		if (chunkType != "eXIf" && !isText) || keepText {
+			if _, err := w.Write(header[:]); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(w, r, size+4); err != nil { // Payload and CRC
+				return eofIsDone(err)
+			}
+			continue
+		}
+
+		if (mode == StripAll && chunkType != "eXIf") || size > maxMetadataBlock {
+			if err := blankPNGChunk(w, r, size); err != nil {
+				return err
+			}
+			continue
+		}
+
+		payload := make([]byte, size+4)
+		if n, err := io.ReadFull(r, payload); err != nil {
+			// Truncated file: keep its length, but none of the partial metadata
+			copy(header[4:8], pngBlankType)
+			_, _ = w.Write(append(header[:], make([]byte, n)...)) //nolint:errcheck // Truncated file: keep what there is
+			return eofIsDone(err)
+		}
+		payload = payload[:size]
+
+		keep := true
+		if chunkType == "eXIf" && mode == StripAll {
+			orientation, err := reduceEXIFToOrientation(payload)
+			keep = err == nil && orientation != 0
+		} else if chunkType == "eXIf" {
+			keep = blankEXIFGPS(payload) == nil
+		} else if text, ok := uncompressedPNGXMP(payload); ok {
+			blankXMPGPS(text)
+		} else if _, isXMP := pngXMP(payload); isXMP {
+			keep = false // Compressed XMP cannot be edited without changing its length
+		}
+
+		if !keep {
+			copy(header[4:8], pngBlankType)
+			clear(payload)
+		}
+		crc := crc32.NewIEEE()
+		_, _ = crc.Write(header[4:8])
+		_, _ = crc.Write(payload)
+		if _, err := w.Write(binary.BigEndian.AppendUint32(append(header[:], payload...), crc.Sum32())); err != nil {
+			return err
+		}
+	}
+}
+
+// blankPNGChunk replaces the next chunk payload with zeros under the blank chunk type.
+// A truncated chunk is replaced with as many zeros as there were bytes.
+func blankPNGChunk(w io.Writer, r io.Reader, size int64) error {
+	n, err := io.CopyN(io.Discard, r, size+4)
+
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(size))
+	copy(header[4:8], pngBlankType)
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(header[4:8])
+
+	if _, werr := w.Write(header[:]); werr != nil {
+		return werr
+	}
+	if err != nil {
+		if _, werr := io.CopyN(w, zeroReader{}, n); werr != nil {
+			return werr
+		}
+		return eofIsDone(err)
+	}
+	if _, err := io.CopyN(io.MultiWriter(w, crc), zeroReader{}, size); err != nil {
+		return err
+	}
+	_, err = w.Write(binary.BigEndian.AppendUint32(nil, crc.Sum32()))
+	return err
+}
+
+// uncompressedPNGXMP returns the XMP text of an uncompressed XMP iTXt chunk as a
+// slice of the chunk, so that edits apply to the chunk itself
+func uncompressedPNGXMP(chunk []byte) ([]byte, bool) {
+	xmp, ok := pngXMP(chunk)
+	if !ok || len(xmp) == 0 || chunk[len("XML:com.adobe.xmp")+1] != 0 {
+		return nil, false
+	}
+	return chunk[len(chunk)-len(xmp):], true
+}
+
+// stripWebP rewrites the metadata chunks of a WebP file
+func stripWebP(w io.Writer, r *bufio.Reader, mode StripMode) error {
+	if _, err := io.CopyN(w, r, 12); err != nil {
+		return err
+	}
+
+	var header [8]byte
+	for {
+		if n, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			_, _ = w.Write(header[:n]) //nolint:errcheck // Truncated file: keep what there is
+			return eofIsDone(err)
+		}
+		fourCC := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+		padded := size + size%2
+
+		switch {
+		case fourCC == "VP8X" && mode == StripAll && size >= 1:
+			// The EXIF chunk is kept, if only for the orientation
+			payload := make([]byte, padded)
+			n, err := io.ReadFull(r, payload)
+			payload[0] &^= vp8xFlagXMP
+			if _, werr := w.Write(append(header[:], payload[:n]...)); werr != nil {
+				return werr
+			}
+			if err != nil {
+				return eofIsDone(err)
+			}
+
+		case (fourCC == "XMP " && mode == StripAll) || ((fourCC == "EXIF" || fourCC == "XMP ") && size > maxMetadataBlock):
+			copy(header[0:4], webpBlank)
+			if _, err := w.Write(header[:]); err != nil {
+				return err
+			}
+			n, err := io.CopyN(io.Discard, r, size)
+			if _, werr := io.CopyN(w, zeroReader{}, n); werr != nil {
+				return werr
+			}
+			if err != nil {
+				return eofIsDone(err)
+			}
+			if size%2 == 1 {
+				if _, err := io.CopyN(w, r, 1); err != nil {
+					return eofIsDone(err)
+				}
+			}
+
+		case fourCC == "EXIF" || fourCC == "XMP ":
+			payload := make([]byte, padded)
+			n, err := io.ReadFull(r, payload)
+			payload = payload[:n]
+			tiff := bytes.TrimPrefix(payload[:min(size, int64(len(payload)))], exifHeader)
+			if fourCC == "XMP " {
+				blankXMPGPS(payload)
+			} else if mode == StripAll {
+				// An empty directory rather than a blank chunk keeps the VP8X flag true
+				if _, err := reduceEXIFToOrientation(tiff); err != nil {
+					copy(header[0:4], webpBlank)
+					clear(payload)
+				}
+			} else if blankEXIFGPS(tiff) != nil {
+				copy(header[0:4], webpBlank)
+				clear(payload)
+			}
+			if _, werr := w.Write(append(header[:], payload...)); werr != nil {
+				return werr
+			}
+			if err != nil {
+				return eofIsDone(err)
+			}
+
+		default:
+			if _, err := w.Write(header[:]); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(w, r, padded); err != nil {
+				return eofIsDone(err)
+			}
+		}
+	}
+}
+
+// stripGIF blanks the comment extensions of a GIF and its application extensions other
+// than the loop count, and copies the rest
+func stripGIF(w io.Writer, r *bufio.Reader) error {
+	// Header and logical screen descriptor
+	screen := make([]byte, 13)
+	n, err := io.ReadFull(r, screen)
+	if _, werr := w.Write(screen[:n]); werr != nil {
+		return werr
+	}
+	if err != nil {
+		return eofIsDone(err)
+	}
+	if flags := screen[10]; flags&0x80 != 0 {
+		if _, err := io.CopyN(w, r, 3<<(flags&0x07+1)); err != nil { // Global color table
+			return eofIsDone(err)
+		}
+	}
+
+	for {
+		introducer, err := r.ReadByte()
+		if err != nil {
+			return eofIsDone(err)
+		}
+
+		switch introducer {
+		case gifExtension:
+			label, err := r.ReadByte()
+			if err != nil {
+				_, _ = w.Write([]byte{introducer}) //nolint:errcheck // Truncated file: keep what there is
+				return eofIsDone(err)
+			}
+			if err := stripGIFExtension(w, r, label); err != nil {
+				return err
+			}
+
+		case gifImage:
+			descriptor := make([]byte, 10) // Introducer, descriptor and LZW code size
+			descriptor[0] = introducer
+			n, err := io.ReadFull(r, descriptor[1:])
+			if _, werr := w.Write(descriptor[:1+n]); werr != nil {
+				return werr
+			}
+			if err != nil {
+				return eofIsDone(err)
+			}
+			if flags := descriptor[9]; flags&0x80 != 0 {
+				if _, err := io.CopyN(w, r, 3<<(flags&0x07+1)+1); err != nil { // Local color table and code size
+					return eofIsDone(err)
+				}
+			} else if _, err := io.CopyN(w, r, 1); err != nil { // Code size
+				return eofIsDone(err)
+			}
+			if err := copyGIFSubBlocks(w, r); err != nil {
+				return err
+			}
+
+		default:
+			// Trailer or garbage: copy as is
+			if _, err := w.Write([]byte{introducer}); err != nil {
+				return err
+			}
+			_, err := io.Copy(w, r)
+			return err
+		}
+	}
+}
+
+// stripGIFExtension copies a GIF extension, or blanks it when it is a comment or an
+// application extension other than the loop count. A blanked extension becomes a
+// comment of as many zero bytes, laid out as sub-blocks of its own: XMP is stored with
+// its text read as sub-block sizes, which would otherwise leave part of it behind.
+func stripGIFExtension(w io.Writer, r *bufio.Reader, label byte) error {
+	var identifier []byte
+	if label == gifApplication {
+		size, err := r.Peek(1)
+		if err == nil {
+			identifier, _ = r.Peek(1 + int(size[0])) //nolint:errcheck // A short identifier is blanked
+		}
+		if len(identifier) > 0 {
+			identifier = identifier[1:]
+		}
+	}
+	if label != gifComment && (label != gifApplication || gifLoopExtensions[string(identifier)]) {
+		if _, err := w.Write([]byte{gifExtension, label}); err != nil {
+			return err
+		}
+		return copyGIFSubBlocks(w, r)
+	}
+
+	// Count the bytes of the sub-blocks and their terminator without keeping them
+	var size int64
+	var err error
+	for {
+		var n byte
+		if n, err = r.ReadByte(); err != nil {
+			break
+		}
+		size++
+		if n == 0 {
+			break
+		}
+		var skipped int
+		skipped, err = r.Discard(int(n))
+		size += int64(skipped)
+		if err != nil {
+			break
+		}
+	}
+
+	if _, werr := w.Write([]byte{gifExtension, gifComment}); werr != nil {
+		return werr
+	}
+	if err != nil {
+		// Truncated file: keep its length with zeros
+		if _, werr := io.CopyN(w, zeroReader{}, size); werr != nil {
+			return werr
+		}
+		return eofIsDone(err)
+	}
+
+	// A well-formed extension has no sub-blocks of a single byte: the size is 1 or at
+	// least 3, which sub-blocks of 1 to 255 zeros and a terminator always fill
+	block := make([]byte, 256)
+	for remaining := size - 1; remaining > 0; {
+		n := min(remaining-1, 255)
+		if remaining-1-n == 1 {
+			n-- // Leave at least two bytes, a size and a zero, for the next sub-block
+		}
+		block[0] = byte(n)
+		if _, err := w.Write(block[:1+n]); err != nil {
+			return err
+		}
+		remaining -= n + 1
+	}
+	_, err = w.Write([]byte{0})
+	return err
+}
+
+// copyGIFSubBlocks copies GIF data sub-blocks up to and including their terminator
+func copyGIFSubBlocks(w io.Writer, r *bufio.Reader) error {
+	for {
+		n, err := r.ReadByte()
+		if err != nil {
+			return eofIsDone(err)
+		}
+		if _, err := w.Write([]byte{n}); err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if _, err := io.CopyN(w, r, int64(n)); err != nil {
+			return eofIsDone(err)
+		}
+	}
+}
+
+// readEXIFIFD0 returns the byte order of a TIFF block and its first directory
+func readEXIFIFD0(data []byte) (binary.ByteOrder, ifd, error) {
+	if len(data) < 8 {
+		return nil, nil, errors.New("invalid EXIF: header too short")
+	}
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, nil, errors.New("invalid EXIF: unknown byte order")
+	}
+
+	ifd0, err := readIFD(data, order, order.Uint32(data[4:8]))
+	return order, ifd0, err
+}
+
+// minimalEXIFSize is the size of a TIFF block with one field: its header, a directory
+// of one entry and the offset of the next directory
+const minimalEXIFSize = 8 + 2 + 12 + 4
+
+// reduceEXIFToOrientation rewrites a TIFF block in place as one holding only its
+// orientation, zeroing everything else, so that a stripped photo is still displayed
+// upright. Without an orientation other than 1 the directory is left empty. It
+// returns the orientation kept, or 0 for none.
+func reduceEXIFToOrientation(data []byte) (int, error) {
+	order, ifd0, err := readEXIFIFD0(data)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < minimalEXIFSize {
+		return 0, errors.New("invalid EXIF: block too short")
+	}
+	orientation := int(ifd0.uint(tagOrientation))
+	if orientation < 2 || orientation > 8 {
+		orientation = 0
+	}
+
+	byteOrder := [2]byte{data[0], data[1]}
+	clear(data)
+	copy(data[0:2], byteOrder[:])
+	order.PutUint16(data[2:4], 42)
+	order.PutUint32(data[4:8], 8)
+	if orientation != 0 {
+		order.PutUint16(data[8:10], 1)
+		entry := data[10:22]
+		order.PutUint16(entry[0:2], tagOrientation)
+		order.PutUint16(entry[2:4], tiffShort)
+		order.PutUint32(entry[4:8], 1)
+		order.PutUint16(entry[8:10], uint16(orientation))
+	}
+	return orientation, nil
+}
+
+// blankEXIFGPS empties the GPS IFD of a TIFF block in place: every GPS value is
+// zeroed and the directory is left with no entries
+func blankEXIFGPS(data []byte) error {
+	order, ifd0, err := readEXIFIFD0(data)
+	if err != nil {
+		return err
+	}
+	offset := ifd0.uint(tagGPSIFD)
+	if offset == 0 {
+		return nil
+	}
+	if uint64(offset)+2 > uint64(len(data)) {
+		return fmt.Errorf("invalid EXIF: GPS IFD offset %d out of range", offset)
+	}
+
+	pos := int(offset)
+	count := int(order.Uint16(data[pos : pos+2]))
+	if pos+2+12*count > len(data) {
+		return errors.New("invalid EXIF: truncated GPS IFD")
+	}
+	for i := 0; i < count; i++ {
+		entry := data[pos+2+12*i : pos+2+12*(i+1)]
+		unit := tiffTypeSizes[order.Uint16(entry[2:4])]
+		size := uint64(order.Uint32(entry[4:8])) * uint64(unit)
+		if size > 4 {
+			valueOffset := uint64(order.Uint32(entry[8:12]))
+			if valueOffset+size <= uint64(len(data)) {
+				clear(data[valueOffset : valueOffset+size])
+			}
+		}
+		clear(entry)
+	}
+	order.PutUint16(data[pos:pos+2], 0)
+	return nil
+}
+
+// XMP GPS handling: the exif namespace prefix is declared per packet
+var (
+	xmpEXIFPrefix  = regexp.MustCompile(`xmlns:([A-Za-z_][\w.-]*)\s*=\s*["']http://ns\.adobe\.com/exif/1\.0/["']`)
+	xmpGPSProperty = `%s:GPS[\w]*`
+)
+
+// blankXMPGPS overwrites every exif:GPS* property of an XMP packet with spaces, whether
+// written as an attribute or as an element. Whitespace is insignificant between XML
+// attributes and RDF elements, so the packet stays valid and keeps its length.
+func blankXMPGPS(xmp []byte) {
+	for _, m := range xmpEXIFPrefix.FindAllSubmatch(xmp, -1) {
+		prefix := regexp.QuoteMeta(string(m[1]))
+		name := fmt.Sprintf(xmpGPSProperty, prefix)
+
+		// Attributes: prefix:GPSLatitude="..."
+		attr := regexp.MustCompile(`\s` + name + `\s*=\s*("[^"]*"|'[^']*')`)
+		for _, loc := range attr.FindAllIndex(xmp, -1) {
+			fillSpaces(xmp[loc[0]:loc[1]])
+		}
+
+		// Elements: <prefix:GPSLatitude ...>...</prefix:GPSLatitude> or self-closing
+		start := regexp.MustCompile(`<(` + name + `)[\s/>]`)
+		for _, loc := range start.FindAllSubmatchIndex(xmp, -1) {
+			if loc[0] < 0 || xmp[loc[0]] != '<' {
+				continue // Already blanked as part of an earlier element
+			}
+			elementName := string(xmp[loc[2]:loc[3]])
+			openEnd := bytes.IndexByte(xmp[loc[0]:], '>')
+			if openEnd < 0 {
+				continue
+			}
+			end := loc[0] + openEnd + 1
+			if xmp[end-2] != '/' {
+				closing := []byte("</" + elementName + ">")
+				i := bytes.Index(xmp[end:], closing)
+				if i < 0 {
+					continue
+				}
+				end += i + len(closing)
+			}
+			fillSpaces(xmp[loc[0]:end])
+		}
+	}
+}
+
+// fillSpaces overwrites b with spaces
+func fillSpaces(b []byte) {
+	for i := range b {
+		b[i] = ' '
+	}
+}
+
+// zeroReader is an endless source of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// eofIsDone treats a truncated file as finished: what could be read was copied through
+func eofIsDone(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
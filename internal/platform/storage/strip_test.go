@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// strip runs StripMetadata and checks the output keeps the input length
+func strip(t *testing.T, data []byte, mode StripMode) []byte {
+	t.Helper()
+	reader := StripMetadata(bytes.NewReader(data), mode)
+	defer func() { _ = reader.Close() }() //nolint:errcheck // Test cleanup
+
+	out, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Len(t, out, len(data), "stripping must preserve the file size")
+	return out
+}
+
+func exifSegment() []byte {
+	return jpegSegment(0xE1, append(append([]byte{}, exifHeader...), sampleEXIF()...))
+}
+
+func xmpSegment(xmp string) []byte {
+	return jpegSegment(0xE1, append(append([]byte{}, xmpHeader...), xmp...))
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+	data := jpegWithSegments(t, exifSegment(), xmpSegment(sampleXMP), jpegSegment(0xED, sampleIPTC()))
+
+	t.Run("none", func(t *testing.T) {
+		assert.Equal(t, data, strip(t, data, StripNone))
+	})
+
+	t.Run("gps", func(t *testing.T) {
+		out := strip(t, data, StripGPS)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, "Canon EOS R5", meta.Model)
+		assert.Equal(t, 400, meta.ISO)
+		assert.Equal(t, 6, meta.Orientation)
+		assert.NotNil(t, meta.TakenAt)
+		assert.Nil(t, meta.Latitude)
+		assert.Nil(t, meta.Longitude)
+		assert.Nil(t, meta.Altitude)
+		assert.NotContains(t, string(out), "GPSLatitude")
+		assert.Contains(t, string(out), `tiff:Model="NIKON Z 6"`)
+
+		_, _, err = image.Decode(bytes.NewReader(out))
+		assert.NoError(t, err)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		out := strip(t, data, StripAll)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, PhotoMetadata{Orientation: 6}, *meta, "only the orientation is kept")
+		assert.NotContains(t, string(out), "Canon")
+		assert.NotContains(t, string(out), "NIKON")
+
+		_, _, err = image.Decode(bytes.NewReader(out))
+		assert.NoError(t, err)
+	})
+
+	t.Run("all upright", func(t *testing.T) {
+		exif := buildEXIF([]tiffField{asciiField(tagMake, "Canon"), shortField(tagOrientation, 1)}, nil, nil)
+		upright := jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), exif...)))
+		out := strip(t, upright, StripAll)
+
+		assert.NotContains(t, string(out), string(exifHeader), "an EXIF block with nothing to keep is blanked")
+		assert.NotContains(t, string(out), "Canon")
+	})
+}
+
+func TestStripMetadata_XMPElements(t *testing.T) {
+	xmp := strings.Replace(sampleXMP, `exif:GPSLongitude="2,21,3E">`,
+		`><exif:GPSLongitude>2,21,3E</exif:GPSLongitude><exif:GPSAltitude/>`, 1)
+	xmp = strings.ReplaceAll(xmp, "exif:", "e:")
+	xmp = strings.Replace(xmp, "xmlns:exif=", "xmlns:e =", 1)
+	data := jpegWithSegments(t, xmpSegment(xmp))
+
+	out := strip(t, data, StripGPS)
+	assert.NotContains(t, string(out), "GPS")
+	assert.Contains(t, string(out), "<e:ISOSpeedRatings>")
+
+	meta, err := ExtractMetadata(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, "NIKON Z 6", meta.Model)
+	assert.Nil(t, meta.Latitude)
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+	itxt := append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), sampleXMP...)
+	data := pngWithChunks(t,
+		pngChunk("eXIf", sampleEXIF()),
+		pngChunk("iTXt", itxt),
+		pngChunk("tEXt", []byte("Comment\x00serial 1234")),
+	)
+
+	t.Run("gps", func(t *testing.T) {
+		out := strip(t, data, StripGPS)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, "Canon EOS R5", meta.Model)
+		assert.Nil(t, meta.Latitude)
+		assert.NotContains(t, string(out), "GPSLatitude")
+
+		_, _, err = image.Decode(bytes.NewReader(out))
+		assert.NoError(t, err, "chunk CRCs must be recomputed")
+	})
+
+	t.Run("all", func(t *testing.T) {
+		out := strip(t, data, StripAll)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, PhotoMetadata{Orientation: 6}, *meta, "only the orientation is kept")
+		assert.NotContains(t, string(out), "Canon")
+		assert.NotContains(t, string(out), "serial")
+
+		_, _, err = image.Decode(bytes.NewReader(out))
+		assert.NoError(t, err)
+	})
+}
+
+func TestStripMetadata_PNGRawProfiles(t *testing.T) {
+	// As ImageMagick writes the profiles of a JPEG it converts to PNG
+	exif := hex.EncodeToString(append(append([]byte{}, exifHeader...), sampleEXIF()...))
+	xmp := hex.EncodeToString([]byte(sampleXMP))
+	data := pngWithChunks(t,
+		pngChunk("tEXt", fmt.Appendf(nil, "Raw profile type exif\x00\nexif\n%8d\n%s\n", len(exif)/2, exif)),
+		pngChunk("iTXt", fmt.Appendf(nil, "Raw profile type xmp\x00\x00\x00\x00\x00\nxmp\n%8d\n%s\n", len(xmp)/2, xmp)),
+		pngChunk("tEXt", []byte("Comment\x00a day at the beach")),
+	)
+
+	out := strip(t, data, StripGPS)
+	assert.NotContains(t, string(out), "Raw profile type")
+	assert.NotContains(t, string(out), exif[:64])
+	assert.NotContains(t, string(out), xmp[:64])
+	assert.Contains(t, string(out), "a day at the beach", "other text is kept")
+
+	_, _, err := image.Decode(bytes.NewReader(out))
+	assert.NoError(t, err)
+}
+
+func TestStripMetadata_TruncatedPNG(t *testing.T) {
+	exif := pngChunk("eXIf", sampleEXIF())
+	data := pngWithChunks(t, exif, pngChunk("tEXt", []byte("Comment\x00serial 1234")))
+
+	tests := []struct {
+		name string
+		cut  int
+	}{
+		{"in a chunk header", 33 + 4},
+		{"in the EXIF", 33 + 8 + 40},
+		{"in the EXIF CRC", 33 + len(exif) - 2},
+		{"in the text", 33 + len(exif) + 12},
+		{"in the image data", len(data) - 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, mode := range []StripMode{StripGPS, StripAll} {
+				out := strip(t, data[:tt.cut], mode)
+				assert.Equal(t, data[:33], out[:33])
+				if mode == StripAll || tt.cut < 33+len(exif) {
+					assert.NotContains(t, string(out), "Canon", "partial metadata is blanked")
+				}
+			}
+		})
+	}
+}
+
+func TestStripMetadata_WebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = vp8xFlagEXIF | vp8xFlagXMP
+	body := append([]byte("WEBP"), webpChunk("VP8X", vp8x)...)
+	body = append(body, webpChunk("VP8 ", make([]byte, 33))...)
+	body = append(body, webpChunk("EXIF", sampleEXIF())...)
+	body = append(body, webpChunk("XMP ", []byte(sampleXMP))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	t.Run("gps", func(t *testing.T) {
+		out := strip(t, data, StripGPS)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, "Canon", meta.Make)
+		assert.Nil(t, meta.Latitude)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		out := strip(t, data, StripAll)
+
+		meta, err := ExtractMetadata(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, PhotoMetadata{Orientation: 6}, *meta, "only the orientation is kept")
+		assert.Equal(t, byte(vp8xFlagEXIF), out[20]&(vp8xFlagEXIF|vp8xFlagXMP), "only the XMP flag is cleared")
+		assert.NotContains(t, string(out), "Canon")
+		assert.NotContains(t, string(out), "GPSLatitude")
+	})
+}
+
+// gifXMPExtension is an XMP application extension: the packet is written raw, its bytes
+// read as sub-block sizes, and a magic trailer leads any of them to the terminator
+func gifXMPExtension(xmp string) []byte {
+	ext := append([]byte("\x21\xFF\x0BXMP DataXMP"), xmp...)
+	ext = append(ext, 0x01)
+	for i := 0xFF; i >= 0; i-- {
+		ext = append(ext, byte(i))
+	}
+	return append(ext, 0x00)
+}
+
+// gifWithExtensions encodes a small animated GIF and inserts the extensions after its
+// global color table
+func gifWithExtensions(t *testing.T, extensions ...[]byte) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, &gif.GIF{
+		Image:     []*image.Paletted{frame, frame},
+		Delay:     []int{10, 10},
+		LoopCount: 3,
+		Config:    image.Config{ColorModel: palette, Width: 4, Height: 4},
+	}))
+	encoded := buf.Bytes()
+	headerEnd := 13 + 3<<(encoded[10]&0x07+1)
+
+	out := append([]byte{}, encoded[:headerEnd]...)
+	for _, ext := range extensions {
+		out = append(out, ext...)
+	}
+	return append(out, encoded[headerEnd:]...)
+}
+
+func TestStripMetadata_GIF(t *testing.T) {
+	comment := []byte("\x21\xFE\x0Dserial 123456\x00")
+	data := gifWithExtensions(t, gifXMPExtension(sampleXMP), comment)
+
+	for _, mode := range []StripMode{StripGPS, StripAll} {
+		out := strip(t, data, mode)
+		assert.NotContains(t, string(out), "GPSLatitude")
+		assert.NotContains(t, string(out), "NIKON")
+		assert.NotContains(t, string(out), "serial")
+
+		decoded, err := gif.DecodeAll(bytes.NewReader(out))
+		require.NoError(t, err, "blanked extensions must still parse")
+		assert.Len(t, decoded.Image, 2)
+		assert.Equal(t, 3, decoded.LoopCount, "the loop count is kept")
+	}
+}
+
+func TestStripMetadata_PassThrough(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown format", []byte("BM not really a bitmap")},
+		{"malformed gif", []byte("GIF89a not really a gif")},
+		{"empty", nil},
+		{"truncated jpeg", exifSegment()[:10]},
+		{"truncated gif", gifWithExtensions(t, gifXMPExtension(sampleXMP))[:200]},
+		{"truncated webp", append([]byte("RIFF\x00\x00\x00\x00WEBP"), webpChunk("XMP ", []byte(sampleXMP))[:40]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strip(t, tt.data, StripAll)
+		})
+	}
+
+	t.Run("malformed EXIF is blanked", func(t *testing.T) {
+		bad := jpegSegment(0xE1, append(append([]byte{}, exifHeader...), "XX garbage"...))
+		out := strip(t, jpegWithSegments(t, bad), StripGPS)
+		assert.NotContains(t, string(out), "garbage")
+	})
+}
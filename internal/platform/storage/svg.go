@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxSVGSize is the largest SVG document accepted for sanitizing
+const MaxSVGSize = 10 * 1024 * 1024
+
+// svgBlockedElements are dropped together with their content: they run scripts, embed
+// other documents or carry authoring metadata
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+	"metadata":      true,
+}
+
+// SanitizeSVG returns an SVG document with scripts, event handlers, external references
+// (links, url() and @import pointing outside the document), comments, processing
+// instructions and DOCTYPEs removed. Documents that are not well-formed SVG are rejected.
+func SanitizeSVG(data io.Reader) ([]byte, error) {
+	if data == nil {
+		return nil, errors.New("data cannot be nil")
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(data, MaxSVGSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SVG: %w", err)
+	}
+	if len(raw) > MaxSVGSize {
+		return nil, fmt.Errorf("SVG exceeds maximum size of %d bytes", MaxSVGSize)
+	}
+
+	// The strict decoder checks that the document is well-formed and rejects undeclared
+	// entities, so the raw pass below only has to filter
+	if err := checkSVGWellFormed(raw); err != nil {
+		return nil, err
+	}
+
+	var out, style bytes.Buffer
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	depth, skipDepth, styleDepth := 0, 0, 0
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SVG: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if skipDepth > 0 {
+				continue
+			}
+			if isBlockedSVGElement(t) {
+				skipDepth = depth
+				continue
+			}
+			if strings.EqualFold(t.Name.Local, "style") {
+				styleDepth = depth
+			}
+			writeSVGStart(&out, t)
+		case xml.EndElement:
+			depth--
+			if styleDepth > 0 && depth < styleDepth {
+				// Style sheets are checked whole, as dropped comments can join their pieces
+				if isSafeCSS(strings.ToLower(style.String())) {
+					_ = xml.EscapeText(&out, style.Bytes()) //nolint:errcheck // bytes.Buffer writes do not fail
+				}
+				style.Reset()
+				styleDepth = 0
+			}
+			if skipDepth > 0 {
+				if depth < skipDepth {
+					skipDepth = 0
+				}
+				continue
+			}
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			switch {
+			case skipDepth > 0:
+			case styleDepth > 0:
+				style.Write(t)
+			default:
+				_ = xml.EscapeText(&out, t) //nolint:errcheck // bytes.Buffer writes do not fail
+			}
+		}
+		// Comments, processing instructions and directives (DOCTYPE, ENTITY) are dropped
+	}
+
+	return out.Bytes(), nil
+}
+
+// checkSVGWellFormed decodes the whole document and checks that its root is <svg>
+func checkSVGWellFormed(raw []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	rootSeen := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid SVG: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && !rootSeen {
+			if !strings.EqualFold(start.Name.Local, "svg") {
+				return fmt.Errorf("invalid SVG: root element is <%s>", start.Name.Local)
+			}
+			rootSeen = true
+		}
+	}
+	if !rootSeen {
+		return errors.New("invalid SVG: no <svg> element")
+	}
+	return nil
+}
+
+// isBlockedSVGElement reports whether an element is dropped with its content. Animations
+// that rewrite links are dropped too, since they can turn a safe href into a script.
+func isBlockedSVGElement(t xml.StartElement) bool {
+	name := strings.ToLower(t.Name.Local)
+	if svgBlockedElements[name] {
+		return true
+	}
+	if name == "style" {
+		return false
+	}
+	for _, attr := range t.Attr {
+		if strings.EqualFold(attr.Name.Local, "attributeName") && strings.HasSuffix(strings.ToLower(attr.Value), "href") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSVGStart writes a start tag with only its safe attributes
+func writeSVGStart(out *bytes.Buffer, t xml.StartElement) {
+	out.WriteString("<" + qualifiedName(t.Name))
+	for _, attr := range t.Attr {
+		if !isSafeSVGAttr(attr) {
+			continue
+		}
+		out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		_ = xml.EscapeText(out, []byte(attr.Value)) //nolint:errcheck // bytes.Buffer writes do not fail
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// isSafeSVGAttr reports whether an attribute may be kept: no event handlers, and links
+// and url() references only to fragments of the same document
+func isSafeSVGAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	value := strings.ToLower(attr.Value)
+
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return false
+	case name == "href" || name == "src" || (name == "base" && attr.Name.Space == "xml"):
+		return isSafeSVGLink(strings.TrimSpace(value))
+	}
+	return isSafeCSS(value)
+}
+
+// isSafeSVGLink reports whether a link stays inside the document or is an embedded
+// raster image, which browsers display without running anything
+func isSafeSVGLink(value string) bool {
+	if strings.HasPrefix(value, "#") {
+		return true
+	}
+	for _, prefix := range []string{"data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unsafeCSS are CSS fragments that run scripts or load resources without url(). Backslashes
+// are refused outright since CSS escapes can spell out any of them, url( included.
+var unsafeCSS = []string{"javascript:", "@import", "expression(", "image-set(", `\`}
+
+// isSafeCSS reports whether a lowercased attribute value or style sheet has no scripts
+// and loads nothing from outside the document
+func isSafeCSS(value string) bool {
+	for _, fragment := range unsafeCSS {
+		if strings.Contains(value, fragment) {
+			return false
+		}
+	}
+	return !hasExternalURL(value)
+}
+
+// hasExternalURL reports whether a value contains a CSS url() that is not a fragment
+func hasExternalURL(value string) bool {
+	for {
+		i := strings.Index(value, "url(")
+		if i < 0 {
+			return false
+		}
+		value = value[i+len("url("):]
+		target := strings.TrimLeft(value, " \t\n\r'\"")
+		if !strings.HasPrefix(target, "#") {
+			return true
+		}
+	}
+}
+
+// qualifiedName writes a raw token name back as prefix:local
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string // fragments the output must contain
+		notWant []string // fragments the output must not contain
+	}{
+		{
+			name:  "keeps shapes and presentation",
+			input: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><rect width="10" height="10" fill="#f00" style="stroke: blue"/></svg>`,
+			want:  []string{`viewBox="0 0 10 10"`, `fill="#f00"`, `style="stroke: blue"`, "<rect"},
+		},
+		{
+			name:    "drops scripts with their content",
+			input:   `<svg><script>alert(1)</script><circle r="1"/></svg>`,
+			want:    []string{"<circle"},
+			notWant: []string{"script", "alert"},
+		},
+		{
+			name:    "drops event handlers",
+			input:   `<svg onload="alert(1)"><rect onclick="alert(2)" width="1"/></svg>`,
+			want:    []string{`width="1"`},
+			notWant: []string{"onload", "onclick", "alert"},
+		},
+		{
+			name:    "drops javascript and external links",
+			input:   `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><a href="javascript:alert(1)"><use xlink:href="https://evil.example/x.svg#a"/></a></svg>`,
+			notWant: []string{"javascript", "evil.example"},
+		},
+		{
+			name:  "keeps fragment links and embedded rasters",
+			input: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><use href="#a"/><image xlink:href="data:image/png;base64,AAAA"/></svg>`,
+			want:  []string{`href="#a"`, `xlink:href="data:image/png;base64,AAAA"`},
+		},
+		{
+			name:    "drops data URLs that are not rasters",
+			input:   `<svg><image href="data:image/svg+xml;base64,PHN2Zz4="/></svg>`,
+			notWant: []string{"data:image/svg+xml"},
+		},
+		{
+			name:    "drops foreignObject",
+			input:   `<svg><foreignObject><iframe src="https://evil.example"/></foreignObject></svg>`,
+			notWant: []string{"foreignObject", "iframe", "evil.example"},
+		},
+		{
+			name:    "drops external url() references",
+			input:   `<svg><rect fill="url(https://evil.example/p)" stroke="url(#grad)"/></svg>`,
+			want:    []string{`stroke="url(#grad)"`},
+			notWant: []string{"evil.example"},
+		},
+		{
+			name:    "drops unsafe style sheets",
+			input:   `<svg><style>@import url(https://evil.example/a.css);</style><style>rect { fill: red }</style></svg>`,
+			want:    []string{"fill: red"},
+			notWant: []string{"@import", "evil.example"},
+		},
+		{
+			name:    "checks style sheets whole across comments",
+			input:   `<svg><style>@imp<!-- -->ort 'https://evil.example/a.css';</style></svg>`,
+			notWant: []string{"ort", "evil.example"},
+		},
+		{
+			name:    "drops CSS escapes",
+			input:   `<svg><rect style="fill: \75 rl(https://evil.example)"/></svg>`,
+			notWant: []string{"evil.example"},
+		},
+		{
+			name:    "drops link-rewriting animations",
+			input:   `<svg><a href="#x"><set attributeName="href" to="javascript:alert(1)"/></a></svg>`,
+			want:    []string{`href="#x"`},
+			notWant: []string{"javascript", "<set"},
+		},
+		{
+			name:    "drops comments and processing instructions",
+			input:   `<?xml version="1.0"?><?xml-stylesheet href="https://evil.example/a.css"?><svg><!-- secret --><g/></svg>`,
+			want:    []string{"<g>"},
+			notWant: []string{"secret", "evil.example", "<?"},
+		},
+		{
+			name:    "escapes text",
+			input:   `<svg><text>a &lt;b&gt; c</text></svg>`,
+			want:    []string{"a &lt;b&gt; c"},
+			notWant: []string{"<b>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SanitizeSVG(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			for _, fragment := range tt.want {
+				assert.Contains(t, string(out), fragment)
+			}
+			for _, fragment := range tt.notWant {
+				assert.NotContains(t, string(out), fragment)
+			}
+
+			// The output is itself a document the sanitizer accepts unchanged
+			again, err := SanitizeSVG(bytes.NewReader(out))
+			require.NoError(t, err)
+			assert.Equal(t, string(out), string(again))
+		})
+	}
+}
+
+func TestSanitizeSVG_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not xml", "hello"},
+		{"unclosed element", "<svg><g></svg>"},
+		{"html root", "<html><body/></html>"},
+		{"no elements", `<?xml version="1.0"?>`},
+		{"undeclared entity", `<svg>&xxe;</svg>`},
+		{"entity declarations", `<!DOCTYPE svg [<!ENTITY a "aaaa">]><svg>&a;</svg>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SanitizeSVG(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("too large", func(t *testing.T) {
+		large := "<svg>" + strings.Repeat(" ", MaxSVGSize) + "</svg>"
+		_, err := SanitizeSVG(strings.NewReader(large))
+		assert.Error(t, err)
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		_, err := SanitizeSVG(nil)
+		assert.Error(t, err)
+	})
+}
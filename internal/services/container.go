@@ -39,6 +39,11 @@ type Container struct {
 	settingsService   settings.SettingsService
 	imageProcessor    image.ImageProcessor
 	validationService image.ValidationService
+	backfillJob       *implementations.BackfillJob
+	jobQueue          image.JobQueue
+	jobWorkers        *implementations.JobWorkerPool
+	uploadService     image.ResumableUploadService
+	directUploads     image.DirectUploadService
 
 	// Infrastructure services (optional - can be nil for now)
 	eventPublisher      image.EventPublisher
@@ -110,6 +115,10 @@ func (c *Container) initializeServices() error {
 	if adapter, ok := imageRepoAdapter.(interface{ SetTagRepository(database.TagRepository) }); ok {
 		adapter.SetTagRepository(dbTagRepo)
 	}
+	// Set job repository on image adapter so images carry their processing status
+	if adapter, ok := imageRepoAdapter.(interface{ SetJobRepository(database.JobRepository) }); ok {
+		adapter.SetJobRepository(database.NewJobRepository(c.db))
+	}
 	c.imageRepository = imageRepoAdapter
 	c.tagRepository = implementations.NewTagRepository(c.db)
 	c.settingsRepository = implementations.NewSettingsRepository(c.db)
@@ -123,6 +132,14 @@ func (c *Container) initializeServices() error {
 		c.storageService = implementations.NewStorageService(c.storageClient)
 	}
 	c.imageProcessor = implementations.NewImageProcessor()
+	if processor, ok := c.imageProcessor.(interface{ SetAnimatedThumbnails(bool) }); ok {
+		processor.SetAnimatedThumbnails(c.config.Storage.AnimatedThumbnails != "poster")
+	}
+	if processor, ok := c.imageProcessor.(interface {
+		SetDecodeLimits(maxPixels, memoryBudget int64, concurrent int)
+	}); ok {
+		processor.SetDecodeLimits(c.config.Storage.MaxImagePixels, c.config.Storage.DecodeMemoryBudget, c.config.Storage.MaxConcurrentDecodes)
+	}
 	c.validationService = implementations.NewValidationService()
 
 	// Initialize cache service (optional)
@@ -159,10 +176,26 @@ func (c *Container) initializeServices() error {
 		c.cacheService,
 	)
 
+	// Apply the configured tag curation policy
+	if svc, ok := c.imageService.(interface{ SetTagPolicy(image.TagPolicy) }); ok {
+		svc.SetTagPolicy(image.TagPolicy(c.config.Tags.Policy))
+	}
+
+	// Apply the configured privacy policy for embedded metadata
+	if svc, ok := c.imageService.(interface{ SetMetadataPolicy(image.MetadataPolicy) }); ok {
+		svc.SetMetadataPolicy(image.MetadataPolicy(c.config.Storage.MetadataPolicy))
+	}
+
+	// Apply the configured responsive variant widths
+	if svc, ok := c.imageService.(interface{ SetVariantWidths([]int) }); ok {
+		svc.SetVariantWidths(c.config.Storage.VariantWidths)
+	}
+
 	c.tagService = implementations.NewTagService(
 		c.tagRepository,
 		c.validationService,
 		c.eventPublisher,
+		c.cacheService,
 	)
 
 	c.settingsService = implementations.NewSettingsService(
@@ -170,6 +203,63 @@ func (c *Container) initializeServices() error {
 		c.redisClient,
 	)
 
+	// Background processing: uploads are queued for the workers once they are started
+	c.jobQueue = implementations.NewJobQueue(c.db, c.config.Jobs.MaxAttempts)
+	c.jobWorkers = implementations.NewJobWorkerPool(
+		c.jobQueue,
+		c.imageService,
+		c.imageRepository,
+		c.eventPublisher,
+		implementations.JobWorkerConfig{
+			Workers:      c.config.Jobs.Workers,
+			PollInterval: c.config.Jobs.PollInterval,
+			RetryBackoff: c.config.Jobs.RetryBackoff,
+		},
+		c.logger,
+	)
+	if c.cacheService != nil {
+		c.jobWorkers.SetCache(c.cacheService)
+	}
+
+	c.backfillJob = implementations.NewBackfillJob(
+		c.imageRepository,
+		c.imageService,
+		c.config.Storage.BackfillInterval,
+		c.logger,
+	)
+
+	// Resumable uploads are assembled as multipart uploads before they are handed off
+	if parts, ok := c.storageService.(image.MultipartStorage); ok {
+		c.uploadService = implementations.NewResumableUploadService(
+			c.db,
+			parts,
+			c.storageService,
+			c.imageService,
+			c.imageProcessor,
+			implementations.ResumableUploadConfig{
+				MaxSize: c.config.Storage.MaxUploadSize,
+				Expiry:  c.config.Storage.UploadExpiry,
+			},
+			c.logger,
+		)
+	}
+
+	// Direct uploads are checked by decoding their header before they are handed off
+	if presigner, ok := c.storageService.(image.PresignedPostStorage); ok {
+		c.directUploads = implementations.NewDirectUploadService(
+			c.db,
+			presigner,
+			c.storageService,
+			c.imageService,
+			c.imageProcessor,
+			implementations.DirectUploadConfig{
+				MaxSize: c.config.Storage.MaxUploadSize,
+				Expiry:  c.config.Storage.PresignExpiry,
+			},
+			c.logger,
+		)
+	}
+
 	log.Println("Dependency injection container initialized successfully")
 	return nil
 }
@@ -244,12 +334,50 @@ func (c *Container) NotificationService() image.NotificationService {
 	return c.notificationService
 }
 
+func (c *Container) JobQueue() image.JobQueue {
+	return c.jobQueue
+}
+
+func (c *Container) ResumableUploadService() image.ResumableUploadService {
+	return c.uploadService
+}
+
+func (c *Container) DirectUploadService() image.DirectUploadService {
+	return c.directUploads
+}
+
+// StartJobWorkers starts the background processing workers and has the image service
+// queue the variants and thumbnails of new uploads for them instead of generating them
+// during the upload. It must be called before requests are served.
+func (c *Container) StartJobWorkers() {
+	if c.jobWorkers == nil {
+		return
+	}
+	if svc, ok := c.imageService.(interface{ SetJobQueue(image.JobQueue) }); ok {
+		svc.SetJobQueue(c.jobQueue)
+	}
+	c.jobWorkers.Start()
+}
+
+func (c *Container) BackfillJob() image.BackfillRunner {
+	if c.backfillJob == nil {
+		return nil
+	}
+	return c.backfillJob
+}
+
 func (c *Container) Logger() *observability.Logger {
 	return c.logger
 }
 
 // Close cleans up resources
 func (c *Container) Close() error {
+	if c.jobWorkers != nil {
+		c.jobWorkers.Stop()
+	}
+	if c.backfillJob != nil {
+		c.backfillJob.Stop()
+	}
 	if c.db != nil {
 		return c.db.Close()
 	}
@@ -0,0 +1,217 @@
+package implementations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/observability"
+)
+
+const (
+	// backfillBatchSize is how many image IDs are read from the database at a time
+	backfillBatchSize = 100
+	// backfillLogEvery is how many images are backfilled between progress log lines
+	backfillLogEvery = 50
+)
+
+// backfillStore is the part of the image repository the backfill job uses
+type backfillStore interface {
+	ListBackfillCandidates(ctx context.Context, afterID, limit int) ([]int, error)
+	CountBackfillCandidates(ctx context.Context, afterID int) (int, error)
+	GetBackfillCheckpoint(ctx context.Context, job string) (*image.BackfillCheckpoint, error)
+	SaveBackfillCheckpoint(ctx context.Context, checkpoint *image.BackfillCheckpoint) error
+}
+
+// imageBackfiller computes the derived data of a single image
+type imageBackfiller interface {
+	BackfillImage(ctx context.Context, id int) (*image.Image, error)
+}
+
+// BackfillJob fills in the derived data of images stored without it, one image at a
+// time at most once per interval. The checkpoint is saved after every image, so a job
+// stopped by a restart continues after the last image it finished.
+type BackfillJob struct {
+	store    backfillStore
+	service  imageBackfiller
+	interval time.Duration
+	logger   *observability.Logger // nil disables progress logging
+
+	mu      sync.Mutex
+	base    context.Context
+	stop    context.CancelFunc
+	running bool
+	done    chan struct{}
+}
+
+// NewBackfillJob creates a backfill job over the repository's images. An interval of
+// zero backfills images back to back.
+func NewBackfillJob(repo image.Repository, service image.ImageService, interval time.Duration, logger *observability.Logger) *BackfillJob {
+	return newBackfillJob(repo, service, interval, logger)
+}
+
+func newBackfillJob(store backfillStore, service imageBackfiller, interval time.Duration, logger *observability.Logger) *BackfillJob {
+	base, stop := context.WithCancel(context.Background())
+	return &BackfillJob{
+		store:    store,
+		service:  service,
+		interval: interval,
+		logger:   logger,
+		base:     base,
+		stop:     stop,
+	}
+}
+
+// Start runs the job in the background unless it is already running or has been
+// stopped, continuing from its checkpoint or, with restart, from the first image
+func (j *BackfillJob) Start(restart bool) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running || j.base.Err() != nil {
+		return false
+	}
+
+	j.running = true
+	j.done = make(chan struct{})
+	go func() {
+		defer close(j.done)
+		err := j.run(j.base, restart)
+
+		j.mu.Lock()
+		j.running = false
+		j.mu.Unlock()
+
+		if err != nil && j.base.Err() == nil && j.logger != nil {
+			j.logger.Error(j.base).Err(err).Msg("Backfill job failed")
+		}
+	}()
+	return true
+}
+
+// Stop cancels the job and waits for the image in progress to finish. The job cannot
+// be started again.
+func (j *BackfillJob) Stop() {
+	j.mu.Lock()
+	j.stop()
+	done := j.done
+	j.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// Progress reports the job's checkpoint and the images after it still missing data
+func (j *BackfillJob) Progress(ctx context.Context) (*image.BackfillProgress, error) {
+	checkpoint, err := j.store.GetBackfillCheckpoint(ctx, image.BackfillJobName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill checkpoint: %w", err)
+	}
+	remaining, err := j.store.CountBackfillCandidates(ctx, checkpoint.LastImageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count backfill candidates: %w", err)
+	}
+
+	j.mu.Lock()
+	running := j.running
+	j.mu.Unlock()
+
+	return &image.BackfillProgress{
+		BackfillCheckpoint: *checkpoint,
+		Running:            running,
+		Remaining:          remaining,
+	}, nil
+}
+
+// run backfills images in ID order from the checkpoint until none are left. An image
+// that fails is counted and skipped; it is retried by a restarted pass.
+func (j *BackfillJob) run(ctx context.Context, restart bool) error {
+	checkpoint, err := j.store.GetBackfillCheckpoint(ctx, image.BackfillJobName)
+	if err != nil {
+		return fmt.Errorf("failed to get backfill checkpoint: %w", err)
+	}
+	if restart || checkpoint.CompletedAt != nil {
+		// A finished pass starts over, to pick up images added since
+		checkpoint = &image.BackfillCheckpoint{Job: image.BackfillJobName}
+	}
+	if err := j.store.SaveBackfillCheckpoint(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to save backfill checkpoint: %w", err)
+	}
+
+	remaining, err := j.store.CountBackfillCandidates(ctx, checkpoint.LastImageID)
+	if err != nil {
+		return fmt.Errorf("failed to count backfill candidates: %w", err)
+	}
+	j.logProgress(ctx, "Backfill job started", checkpoint, remaining)
+
+	var limit <-chan time.Time
+	if j.interval > 0 {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+
+	for {
+		ids, err := j.store.ListBackfillCandidates(ctx, checkpoint.LastImageID, backfillBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list backfill candidates: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if limit != nil {
+				select {
+				case <-limit:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			if _, err := j.service.BackfillImage(ctx, id); err != nil {
+				if ctx.Err() != nil {
+					// Interrupted, not failed: the image is tried again on resume
+					return ctx.Err()
+				}
+				checkpoint.Failed++
+				if j.logger != nil {
+					j.logger.Warn(ctx).Err(err).Int("image_id", id).Msg("Failed to backfill image")
+				}
+			} else {
+				checkpoint.Processed++
+			}
+			remaining--
+
+			checkpoint.LastImageID = id
+			if err := j.store.SaveBackfillCheckpoint(ctx, checkpoint); err != nil {
+				return fmt.Errorf("failed to save backfill checkpoint: %w", err)
+			}
+			if (checkpoint.Processed+checkpoint.Failed)%backfillLogEvery == 0 {
+				j.logProgress(ctx, "Backfill job progress", checkpoint, remaining)
+			}
+		}
+	}
+
+	now := time.Now()
+	checkpoint.CompletedAt = &now
+	if err := j.store.SaveBackfillCheckpoint(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to save backfill checkpoint: %w", err)
+	}
+	j.logProgress(ctx, "Backfill job completed", checkpoint, 0)
+	return nil
+}
+
+func (j *BackfillJob) logProgress(ctx context.Context, msg string, checkpoint *image.BackfillCheckpoint, remaining int) {
+	if j.logger == nil {
+		return
+	}
+	j.logger.Info(ctx).
+		Int("last_image_id", checkpoint.LastImageID).
+		Int("processed", checkpoint.Processed).
+		Int("failed", checkpoint.Failed).
+		Int("remaining", max(remaining, 0)).
+		Msg(msg)
+}
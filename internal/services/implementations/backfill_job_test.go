@@ -0,0 +1,186 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+)
+
+// fakeBackfillStore keeps the images missing derived data and the checkpoint in memory
+type fakeBackfillStore struct {
+	mu         sync.Mutex
+	candidates map[int]bool
+	checkpoint image.BackfillCheckpoint
+}
+
+func newFakeBackfillStore(ids ...int) *fakeBackfillStore {
+	store := &fakeBackfillStore{candidates: make(map[int]bool)}
+	for _, id := range ids {
+		store.candidates[id] = true
+	}
+	store.checkpoint.Job = image.BackfillJobName
+	return store
+}
+
+func (s *fakeBackfillStore) ListBackfillCandidates(_ context.Context, afterID, limit int) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []int
+	for id := range s.candidates {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (s *fakeBackfillStore) CountBackfillCandidates(ctx context.Context, afterID int) (int, error) {
+	ids, err := s.ListBackfillCandidates(ctx, afterID, math.MaxInt)
+	return len(ids), err
+}
+
+func (s *fakeBackfillStore) GetBackfillCheckpoint(context.Context, string) (*image.BackfillCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint := s.checkpoint
+	return &checkpoint, nil
+}
+
+func (s *fakeBackfillStore) SaveBackfillCheckpoint(_ context.Context, checkpoint *image.BackfillCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoint = *checkpoint
+	return nil
+}
+
+// fakeBackfiller backfills images by removing them from the store's candidates
+type fakeBackfiller struct {
+	store   *fakeBackfillStore
+	fail    map[int]bool
+	visited []int
+	block   chan struct{} // When set, each image waits for it or for its context
+}
+
+func (b *fakeBackfiller) BackfillImage(ctx context.Context, id int) (*image.Image, error) {
+	if b.block != nil {
+		select {
+		case <-b.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+	b.visited = append(b.visited, id)
+	if b.fail[id] {
+		return nil, errors.New("object not found")
+	}
+	delete(b.store.candidates, id)
+	return &image.Image{ID: id}, nil
+}
+
+func TestBackfillJob_Run(t *testing.T) {
+	t.Run("backfills every image and counts failures", func(t *testing.T) {
+		store := newFakeBackfillStore(3, 1, 2, 5)
+		backfiller := &fakeBackfiller{store: store, fail: map[int]bool{2: true}}
+		job := newBackfillJob(store, backfiller, 0, nil)
+
+		require.NoError(t, job.run(context.Background(), false))
+
+		assert.Equal(t, []int{1, 2, 3, 5}, backfiller.visited)
+		assert.Equal(t, 3, store.checkpoint.Processed)
+		assert.Equal(t, 1, store.checkpoint.Failed)
+		assert.Equal(t, 5, store.checkpoint.LastImageID)
+		assert.NotNil(t, store.checkpoint.CompletedAt)
+	})
+
+	t.Run("continues after the checkpoint", func(t *testing.T) {
+		store := newFakeBackfillStore(1, 2, 3, 4)
+		store.checkpoint.LastImageID = 2
+		store.checkpoint.Processed = 2
+		backfiller := &fakeBackfiller{store: store}
+		job := newBackfillJob(store, backfiller, 0, nil)
+
+		require.NoError(t, job.run(context.Background(), false))
+
+		assert.Equal(t, []int{3, 4}, backfiller.visited)
+		assert.Equal(t, 4, store.checkpoint.Processed)
+	})
+
+	t.Run("restart and a completed pass start from the first image", func(t *testing.T) {
+		for _, completed := range []bool{false, true} {
+			store := newFakeBackfillStore(1, 2)
+			store.checkpoint.LastImageID = 2
+			store.checkpoint.Failed = 2
+			restart := true
+			if completed {
+				now := time.Now()
+				store.checkpoint.CompletedAt = &now
+				restart = false
+			}
+			backfiller := &fakeBackfiller{store: store}
+			job := newBackfillJob(store, backfiller, 0, nil)
+
+			require.NoError(t, job.run(context.Background(), restart))
+
+			assert.Equal(t, []int{1, 2}, backfiller.visited)
+			assert.Equal(t, 2, store.checkpoint.Processed)
+			assert.Zero(t, store.checkpoint.Failed)
+		}
+	})
+
+	t.Run("a cancelled image is left for the next run", func(t *testing.T) {
+		store := newFakeBackfillStore(1, 2)
+		backfiller := &fakeBackfiller{store: store, block: make(chan struct{})}
+		job := newBackfillJob(store, backfiller, 0, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, job.run(ctx, false), context.Canceled)
+
+		assert.Zero(t, store.checkpoint.LastImageID)
+		assert.Zero(t, store.checkpoint.Failed)
+	})
+}
+
+func TestBackfillJob_StartStop(t *testing.T) {
+	store := newFakeBackfillStore(1, 2, 3)
+	backfiller := &fakeBackfiller{store: store, block: make(chan struct{})}
+	job := newBackfillJob(store, backfiller, 0, nil)
+
+	require.True(t, job.Start(false))
+	assert.False(t, job.Start(false), "already running")
+
+	backfiller.block <- struct{}{} // Let the first image finish
+	require.Eventually(t, func() bool {
+		progress, err := job.Progress(context.Background())
+		return err == nil && progress.LastImageID == 1
+	}, time.Second, time.Millisecond)
+
+	progress, err := job.Progress(context.Background())
+	require.NoError(t, err)
+	assert.True(t, progress.Running)
+	assert.Equal(t, 1, progress.Processed)
+	assert.Equal(t, 2, progress.Remaining)
+
+	job.Stop()
+	progress, err = job.Progress(context.Background())
+	require.NoError(t, err)
+	assert.False(t, progress.Running)
+	assert.Equal(t, 1, progress.LastImageID, "the checkpoint stays at the last finished image")
+	assert.False(t, job.Start(false), "a stopped job cannot be started")
+}
@@ -0,0 +1,299 @@
+package implementations
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/observability"
+	"image-gallery/internal/platform/database"
+)
+
+// DefaultPresignExpiry is how long a presigned upload policy can be used for
+const DefaultPresignExpiry = 15 * time.Minute
+
+// directUploadClaimLease is how long a request may hold an upload while completing it,
+// after which the request is taken to have gone
+const directUploadClaimLease = 10 * time.Minute
+
+// User metadata stored with a direct upload, holding what the request gave for it
+const (
+	directUploadFilenameKey = "filename"
+	directUploadTagsKey     = "tags"
+	directUploadPolicyKey   = "metadata-policy"
+)
+
+// decodedContentTypes are the content types a file may have been presigned for, by the
+// format its header decodes as
+var decodedContentTypes = map[string][]string{
+	"jpeg": {"image/jpeg", "image/jpg"},
+	"png":  {"image/png"},
+	"gif":  {"image/gif"},
+	"webp": {"image/webp"},
+	"bmp":  {"image/bmp"},
+	"tiff": {"image/tiff"},
+	"heic": {"image/heic", "image/heif"},
+}
+
+// DirectUploadConfig configures a DirectUploadServiceImpl. Zero values use the
+// defaults.
+type DirectUploadConfig struct {
+	MaxSize int64         // Largest upload accepted, capped at image.MaxFileSize
+	Expiry  time.Duration // How long a presigned policy can be used for
+}
+
+// directUploadFiles reads back and removes the files browsers uploaded
+type directUploadFiles interface {
+	GetFileInfo(ctx context.Context, path string) (*image.FileInfo, error)
+	Retrieve(ctx context.Context, path string) (io.ReadCloser, error)
+	Delete(ctx context.Context, path string) error
+}
+
+// DirectUploadServiceImpl implements the image.DirectUploadService interface. Browsers
+// upload straight to storage under image.DirectUploadPrefix with a presigned POST
+// policy; the policy stores the request as the file's user metadata, so nothing is
+// recorded until the file is completed.
+type DirectUploadServiceImpl struct {
+	claims    database.DirectUploadRepository
+	presigner image.PresignedPostStorage
+	files     directUploadFiles
+	images    imageCreator
+	processor imageInfoReader
+	logger    *observability.Logger // can be nil
+
+	maxSize int64
+	expiry  time.Duration
+}
+
+// NewDirectUploadService creates a direct upload service claiming completions in db
+func NewDirectUploadService(
+	db *sql.DB,
+	presigner image.PresignedPostStorage,
+	files image.StorageService,
+	images image.ImageService,
+	processor image.ImageProcessor,
+	cfg DirectUploadConfig,
+	logger *observability.Logger,
+) image.DirectUploadService {
+	return newDirectUploadService(database.NewDirectUploadRepository(db), presigner, files, images, processor, cfg, logger)
+}
+
+func newDirectUploadService(
+	claims database.DirectUploadRepository,
+	presigner image.PresignedPostStorage,
+	files directUploadFiles,
+	images imageCreator,
+	processor imageInfoReader,
+	cfg DirectUploadConfig,
+	logger *observability.Logger,
+) *DirectUploadServiceImpl {
+	if cfg.MaxSize <= 0 || cfg.MaxSize > image.MaxFileSize {
+		cfg.MaxSize = image.MaxFileSize
+	}
+	if cfg.Expiry <= 0 {
+		cfg.Expiry = DefaultPresignExpiry
+	}
+	return &DirectUploadServiceImpl{
+		claims:    claims,
+		presigner: presigner,
+		files:     files,
+		images:    images,
+		processor: processor,
+		logger:    logger,
+		maxSize:   cfg.MaxSize,
+		expiry:    cfg.Expiry,
+	}
+}
+
+// PresignUpload returns a policy to upload one file of the requested content type, up
+// to the largest upload accepted, under a new key
+func (s *DirectUploadServiceImpl) PresignUpload(ctx context.Context, req *image.PresignUploadRequest) (*image.PresignedUpload, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty values cannot be signed; they are left out and read back as empty
+	metadata := make(map[string]string)
+	for name, value := range map[string]string{
+		directUploadFilenameKey: url.QueryEscape(req.Filename),
+		directUploadTagsKey:     url.QueryEscape(strings.Join(req.Tags, ",")),
+		directUploadPolicyKey:   string(req.MetadataPolicy),
+	} {
+		if value != "" {
+			metadata[name] = value
+		}
+	}
+
+	expiresAt := time.Now().Add(s.expiry)
+	postURL, fields, err := s.presigner.PresignPost(ctx, &image.PostPolicy{
+		Key:         image.DirectUploadPrefix + key,
+		ContentType: req.ContentType,
+		MinSize:     image.MinFileSize,
+		MaxSize:     s.maxSize,
+		Expires:     expiresAt,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	return &image.PresignedUpload{
+		Key:       key,
+		URL:       postURL,
+		Fields:    fields,
+		MaxSize:   s.maxSize,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteUpload checks the file uploaded under key: its size, and that its header
+// decodes as an image of the format it was presigned for. The file is then handed off
+// to CreateImage, which decodes it in full. A file rejected by either is removed, as is
+// one registered. One request completes a file at a time; it returns
+// image.ErrUploadLocked to the others, and keeps doing so once the file is registered
+// until it has been removed.
+func (s *DirectUploadServiceImpl) CompleteUpload(ctx context.Context, key string) (*image.Image, error) {
+	if !isUploadID(key) {
+		return nil, image.ErrUploadNotFound
+	}
+	path := image.DirectUploadPrefix + key
+
+	info, err := s.files.GetFileInfo(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", image.ErrUploadNotFound, err)
+	}
+
+	if err := s.claims.Claim(ctx, key, directUploadClaimLease); err != nil {
+		if errors.Is(err, database.ErrUploadClaimed) {
+			return nil, image.ErrUploadLocked
+		}
+		return nil, fmt.Errorf("failed to claim upload: %w", err)
+	}
+
+	img, err := s.register(ctx, path, info)
+	if err != nil {
+		if isRejectedUpload(err) {
+			s.discard(ctx, key, path)
+		}
+		s.release(ctx, key)
+		return nil, err
+	}
+
+	// The claim outlives the request until the file is gone, so the file cannot be
+	// registered again
+	if err := s.claims.Complete(ctx, key, img.ID); err != nil && s.logger != nil {
+		s.logger.Warn(ctx).Err(err).Str("upload_key", key).Int("image_id", img.ID).Msg("Failed to record completed direct upload")
+	}
+	if s.discard(ctx, key, path) {
+		s.release(ctx, key)
+	}
+	return img, nil
+}
+
+// register verifies an uploaded file and creates its image
+func (s *DirectUploadServiceImpl) register(ctx context.Context, path string, info *image.FileInfo) (*image.Image, error) {
+	if info.Size > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, maximum allowed: %d bytes", image.ErrUploadTooLarge, info.Size, s.maxSize)
+	}
+
+	filename, _ := url.QueryUnescape(info.Metadata[directUploadFilenameKey]) //nolint:errcheck // Validated as the original filename below
+	tags, _ := url.QueryUnescape(info.Metadata[directUploadTagsKey])         //nolint:errcheck // Validated as tag names below
+	req := &image.CreateImageRequest{
+		OriginalFilename: filename,
+		ContentType:      info.ContentType,
+		FileSize:         info.Size,
+		MetadataPolicy:   image.MetadataPolicy(info.Metadata[directUploadPolicyKey]),
+	}
+	if tags != "" {
+		req.Tags = strings.Split(tags, ",")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	file, err := s.files.Retrieve(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // Resource cleanup
+
+	// SVGs are not decoded; CreateImage sanitizes them instead
+	var body io.Reader = file
+	if req.ContentType != image.ContentTypeSVG {
+		var decoded *image.ImageInfo
+		body, decoded, err = DecodeUploadHeader(ctx, s.processor, file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: file cannot be decoded as an image: %v", image.ErrInvalidImageData, err)
+		}
+		if !slices.Contains(decodedContentTypes[decoded.Format], req.ContentType) {
+			return nil, fmt.Errorf("%w: file is %s, not %s", image.ErrInvalidContentType, decoded.Format, req.ContentType)
+		}
+		req.Width, req.Height = &decoded.Width, &decoded.Height
+	}
+
+	return s.images.CreateImage(ctx, req, body)
+}
+
+// discard removes an uploaded file and reports whether it did. A failure leaves it to
+// the bucket's lifecycle rules.
+func (s *DirectUploadServiceImpl) discard(ctx context.Context, key, path string) bool {
+	if err := s.files.Delete(ctx, path); err != nil {
+		if s.logger != nil {
+			s.logger.Warn(ctx).Err(err).Str("upload_key", key).Msg("Failed to delete direct upload")
+		}
+		return false
+	}
+	return true
+}
+
+// release gives up the claim on an upload. A failure only delays completing it again
+// until the claim's lease runs out.
+func (s *DirectUploadServiceImpl) release(ctx context.Context, key string) {
+	if err := s.claims.Release(ctx, key); err != nil && s.logger != nil {
+		s.logger.Warn(ctx).Err(err).Str("upload_key", key).Msg("Failed to release direct upload claim")
+	}
+}
+
+// isRejectedUpload reports whether an error rejects an uploaded file rather than
+// reporting a failure to store it, which completing the upload again may get past
+func isRejectedUpload(err error) bool {
+	for _, target := range []error{
+		image.ErrUploadTooLarge,
+		image.ErrInvalidImageData,
+		image.ErrInvalidContentType,
+		image.ErrInvalidFileSize,
+		image.ErrInvalidFilename,
+		image.ErrInvalidDimensions,
+		image.ErrInvalidTagName,
+		image.ErrTagLimitExceeded,
+		image.ErrTagNotAllowed,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUploadID reports whether key could have been returned by newUploadID, so it
+// cannot reach outside image.DirectUploadPrefix
+func isUploadID(key string) bool {
+	if len(key) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
@@ -0,0 +1,273 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/database"
+)
+
+// fakePresigner records the policies it signs
+type fakePresigner struct {
+	policy *image.PostPolicy
+}
+
+func (p *fakePresigner) PresignPost(_ context.Context, policy *image.PostPolicy) (string, map[string]string, error) {
+	p.policy = policy
+	return "http://storage.test/images", map[string]string{"key": policy.Key, "policy": "signed"}, nil
+}
+
+// fakeDirectUploadFiles keeps uploaded files with their content type and user metadata
+type fakeDirectUploadFiles struct {
+	*fakeUploadStorage
+	contentTypes map[string]string
+	metadata     map[string]map[string]string
+}
+
+func (f *fakeDirectUploadFiles) GetFileInfo(_ context.Context, path string) (*image.FileInfo, error) {
+	b, ok := f.files[path]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return &image.FileInfo{Path: path, Size: int64(len(b)), ContentType: f.contentTypes[path], Metadata: f.metadata[path]}, nil
+}
+
+// upload stores a file as a browser would with a presigned policy
+func (f *fakeDirectUploadFiles) upload(policy *image.PostPolicy, data []byte) {
+	f.files[policy.Key] = data
+	f.contentTypes[policy.Key] = policy.ContentType
+	f.metadata[policy.Key] = policy.Metadata
+}
+
+// fakeDirectUploadClaims keeps claims in memory, by the ID of the image each was
+// completed into or 0. A claim's lease never runs out.
+type fakeDirectUploadClaims struct {
+	claims map[string]int
+}
+
+func (f *fakeDirectUploadClaims) Claim(_ context.Context, key string, _ time.Duration) error {
+	if _, ok := f.claims[key]; ok {
+		return database.ErrUploadClaimed
+	}
+	f.claims[key] = 0
+	return nil
+}
+
+func (f *fakeDirectUploadClaims) Complete(_ context.Context, key string, imageID int) error {
+	f.claims[key] = imageID
+	return nil
+}
+
+func (f *fakeDirectUploadClaims) Release(_ context.Context, key string) error {
+	delete(f.claims, key)
+	return nil
+}
+
+// interruptedImageCreator runs interrupt before creating an image, as another request
+// arriving meanwhile would
+type interruptedImageCreator struct {
+	*fakeImageCreator
+	interrupt func()
+	created   int
+}
+
+func (c *interruptedImageCreator) CreateImage(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error) {
+	c.interrupt()
+	c.created++
+	return c.fakeImageCreator.CreateImage(ctx, req, data)
+}
+
+func newTestDirectUploadService(t *testing.T) (*DirectUploadServiceImpl, *fakePresigner, *fakeDirectUploadFiles, *fakeImageCreator) {
+	t.Helper()
+	presigner := &fakePresigner{}
+	files := &fakeDirectUploadFiles{
+		fakeUploadStorage: newFakeUploadStorage(),
+		contentTypes:      make(map[string]string),
+		metadata:          make(map[string]map[string]string),
+	}
+	creator := &fakeImageCreator{}
+	claims := &fakeDirectUploadClaims{claims: make(map[string]int)}
+	service := newDirectUploadService(claims, presigner, files, creator, NewImageProcessor(), DirectUploadConfig{MaxSize: 1 << 20}, nil)
+	return service, presigner, files, creator
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestDirectUploadService_PresignUpload(t *testing.T) {
+	ctx := context.Background()
+	service, presigner, _, _ := newTestDirectUploadService(t)
+
+	upload, err := service.PresignUpload(ctx, &image.PresignUploadRequest{
+		Filename:    "beach day.png",
+		ContentType: "image/png",
+		Tags:        []string{"sea", "sand"},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, upload.Key, 32)
+	assert.Equal(t, "http://storage.test/images", upload.URL)
+	assert.Equal(t, image.DirectUploadPrefix+upload.Key, upload.Fields["key"])
+	assert.Equal(t, int64(1<<20), upload.MaxSize)
+	assert.WithinDuration(t, upload.ExpiresAt, presigner.policy.Expires, 0)
+
+	assert.Equal(t, image.DirectUploadPrefix+upload.Key, presigner.policy.Key)
+	assert.Equal(t, "image/png", presigner.policy.ContentType)
+	assert.Equal(t, int64(image.MinFileSize), presigner.policy.MinSize)
+	assert.Equal(t, int64(1<<20), presigner.policy.MaxSize)
+	assert.Equal(t, map[string]string{"filename": "beach+day.png", "tags": "sea%2Csand"}, presigner.policy.Metadata,
+		"empty values are left out of the policy")
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  image.PresignUploadRequest
+			want error
+		}{
+			{"not an image", image.PresignUploadRequest{Filename: "notes.txt", ContentType: "text/plain"}, image.ErrInvalidContentType},
+			{"no filename", image.PresignUploadRequest{ContentType: "image/png"}, image.ErrInvalidFilename},
+			{"unknown metadata policy", image.PresignUploadRequest{Filename: "a.png", ContentType: "image/png", MetadataPolicy: "blur"}, image.ErrInvalidImageData},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.PresignUpload(ctx, &tt.req)
+				assert.ErrorIs(t, err, tt.want)
+			})
+		}
+	})
+}
+
+func TestDirectUploadService_CompleteUpload(t *testing.T) {
+	ctx := context.Background()
+
+	presign := func(t *testing.T, service *DirectUploadServiceImpl, presigner *fakePresigner, req *image.PresignUploadRequest) (string, *image.PostPolicy) {
+		t.Helper()
+		upload, err := service.PresignUpload(ctx, req)
+		require.NoError(t, err)
+		return upload.Key, presigner.policy
+	}
+
+	t.Run("registers the uploaded image", func(t *testing.T) {
+		service, presigner, files, creator := newTestDirectUploadService(t)
+		key, policy := presign(t, service, presigner, &image.PresignUploadRequest{
+			Filename:       "beach day.png",
+			ContentType:    "image/png",
+			Tags:           []string{"sea", "sand"},
+			MetadataPolicy: image.MetadataPolicyStripAll,
+		})
+		data := testPNG(t, 12, 8)
+		files.upload(policy, data)
+
+		img, err := service.CompleteUpload(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 42, img.ID)
+
+		assert.Equal(t, "beach day.png", creator.req.OriginalFilename)
+		assert.Equal(t, "image/png", creator.req.ContentType)
+		assert.Equal(t, int64(len(data)), creator.req.FileSize)
+		assert.Equal(t, []string{"sea", "sand"}, creator.req.Tags)
+		assert.Equal(t, image.MetadataPolicyStripAll, creator.req.MetadataPolicy)
+		require.NotNil(t, creator.req.Width)
+		assert.Equal(t, 12, *creator.req.Width)
+		assert.Equal(t, 8, *creator.req.Height)
+		assert.Equal(t, data, creator.data)
+		assert.Empty(t, files.paths(), "the uploaded file is removed once registered")
+		assert.Empty(t, service.claims.(*fakeDirectUploadClaims).claims, "the claim goes with the file")
+
+		_, err = service.CompleteUpload(ctx, key)
+		assert.ErrorIs(t, err, image.ErrUploadNotFound, "an upload is completed once")
+	})
+
+	t.Run("completes an upload in one request at a time", func(t *testing.T) {
+		service, presigner, files, creator := newTestDirectUploadService(t)
+		key, policy := presign(t, service, presigner, &image.PresignUploadRequest{Filename: "a.png", ContentType: "image/png"})
+		files.upload(policy, testPNG(t, 4, 4))
+
+		interrupted := &interruptedImageCreator{fakeImageCreator: creator}
+		interrupted.interrupt = func() {
+			interrupted.interrupt = func() {}
+			_, err := service.CompleteUpload(ctx, key)
+			assert.ErrorIs(t, err, image.ErrUploadLocked)
+		}
+		service.images = interrupted
+
+		img, err := service.CompleteUpload(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 42, img.ID)
+		assert.Equal(t, 1, interrupted.created, "the image is created once")
+	})
+
+	t.Run("rejects files that are not what was presigned", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data func(t *testing.T) []byte
+			want error
+		}{
+			{"not an image", func(*testing.T) []byte { return []byte("just some text") }, image.ErrInvalidImageData},
+			{"truncated header", func(t *testing.T) []byte { return testPNG(t, 4, 4)[:12] }, image.ErrInvalidImageData},
+			{"magic number only", func(*testing.T) []byte { return append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...) }, image.ErrInvalidImageData},
+			{"over the size limit", func(*testing.T) []byte { return make([]byte, 2<<20) }, image.ErrUploadTooLarge},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service, presigner, files, creator := newTestDirectUploadService(t)
+				key, policy := presign(t, service, presigner, &image.PresignUploadRequest{Filename: "a.png", ContentType: "image/png"})
+				files.upload(policy, tt.data(t))
+
+				_, err := service.CompleteUpload(ctx, key)
+				assert.ErrorIs(t, err, tt.want)
+				assert.Nil(t, creator.req)
+				assert.Empty(t, files.paths(), "a rejected file is removed")
+			})
+		}
+	})
+
+	t.Run("rejects an image of another format", func(t *testing.T) {
+		service, presigner, files, _ := newTestDirectUploadService(t)
+		key, policy := presign(t, service, presigner, &image.PresignUploadRequest{Filename: "a.jpg", ContentType: "image/jpeg"})
+		files.upload(policy, testPNG(t, 4, 4))
+
+		_, err := service.CompleteUpload(ctx, key)
+		assert.ErrorIs(t, err, image.ErrInvalidContentType)
+	})
+
+	t.Run("keeps the file when it cannot be stored", func(t *testing.T) {
+		service, presigner, files, creator := newTestDirectUploadService(t)
+		key, policy := presign(t, service, presigner, &image.PresignUploadRequest{Filename: "a.png", ContentType: "image/png"})
+		files.upload(policy, testPNG(t, 4, 4))
+		creator.err = errors.New("database unavailable")
+
+		_, err := service.CompleteUpload(ctx, key)
+		require.Error(t, err)
+		assert.Len(t, files.paths(), 1, "completing the upload can be retried")
+
+		creator.err = nil
+		_, err = service.CompleteUpload(ctx, key)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		service, _, _, _ := newTestDirectUploadService(t)
+		for _, key := range []string{"", "../../images/1.png", "0123456789abcdef0123456789abcdef"} {
+			_, err := service.CompleteUpload(ctx, key)
+			assert.ErrorIs(t, err, image.ErrUploadNotFound, key)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"time"
+
+	"image-gallery/internal/domain/image"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// thumbnailPresets are the renditions the gallery grid shows, rendered ahead of the first
+// request for still images in each format the render endpoint negotiates
+var thumbnailPresets = []string{"thumb", "card"}
+
+// generateDerivatives queues the variants and thumbnails of a new image for the workers.
+// Without a queue, or when it cannot be reached, the variants and animated thumbnail
+// are generated before the upload returns instead.
+func (s *ImageServiceImpl) generateDerivatives(ctx context.Context, span trace.Span, img *image.Image) {
+	if s.jobs != nil {
+		steps := s.derivativeSteps(img)
+		if len(steps) == 0 {
+			return
+		}
+		span.AddEvent("queueing_processing_jobs")
+		err := s.jobs.Enqueue(ctx, img.ID, steps...)
+		if err == nil {
+			for _, step := range steps {
+				img.Processing = append(img.Processing, image.ProcessingJob{ImageID: img.ID, Type: step, Status: image.JobPending})
+			}
+			return
+		}
+		span.RecordError(err)
+	}
+
+	s.generateVariants(ctx, span, img)
+	s.generateAnimatedThumbnail(ctx, span, img)
+}
+
+// derivativeSteps returns the steps that store an image's derivatives. Vector images
+// scale in the browser and have none.
+func (s *ImageServiceImpl) derivativeSteps(img *image.Image) []image.JobType {
+	if img.IsVector() {
+		return nil
+	}
+	if len(s.widths()) == 0 {
+		return []image.JobType{image.JobThumbnail}
+	}
+	return []image.JobType{image.JobVariants, image.JobThumbnail}
+}
+
+// ReprocessImage reads an image's dimensions again and regenerates its hash, capture
+// details, variants and thumbnails from the stored file, in the background when there
+// is a job queue
+func (s *ImageServiceImpl) ReprocessImage(ctx context.Context, id int) error {
+	if _, err := s.imageRepo.GetByID(ctx, id); err != nil {
+		return fmt.Errorf("%w: %v", image.ErrImageNotFound, err)
+	}
+
+	if s.jobs == nil {
+		return s.ProcessImage(ctx, id, image.JobReprocess)
+	}
+	if err := s.jobs.Enqueue(ctx, id, image.JobReprocess); err != nil {
+		return err
+	}
+	s.invalidateImage(ctx, id)
+	return nil
+}
+
+// invalidateImage drops the cached copies of an image whose processing status changed
+func (s *ImageServiceImpl) invalidateImage(ctx context.Context, id int) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.DeleteImage(ctx, id); err != nil {
+		_ = err
+	}
+	if err := s.cache.InvalidateImageLists(ctx); err != nil {
+		_ = err
+	}
+}
+
+// ProcessImage runs one processing step of an image. Each step recomputes what it
+// covers from the stored file, replacing what was recorded before.
+func (s *ImageServiceImpl) ProcessImage(ctx context.Context, id int, step image.JobType) error {
+	ctx, span := s.tracer.Start(ctx, "ProcessImage",
+		trace.WithAttributes(
+			attribute.Int("image.id", id),
+			attribute.String("job.type", string(step)),
+		),
+	)
+	defer span.End()
+
+	if !step.IsValid() {
+		err := fmt.Errorf("unknown processing step %q", step)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unknown step")
+		return err
+	}
+
+	img, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get image")
+		return fmt.Errorf("failed to get image: %w", err)
+	}
+
+	switch step {
+	case image.JobHash:
+		err = s.updateFromOriginal(ctx, img, func(data []byte) (*image.ImagePatch, error) {
+			setContentHash(img, data)
+			return &image.ImagePatch{Metadata: img.MetadataPatch(image.ContentHashMetadataKey)}, nil
+		})
+	case image.JobMetadata:
+		err = s.updateFromOriginal(ctx, img, func(data []byte) (*image.ImagePatch, error) {
+			if err := s.applyPhotoMetadata(ctx, img, data); err != nil {
+				return nil, err
+			}
+			return photoPatch(img), nil
+		})
+	case image.JobVariants:
+		err = s.storeVariants(ctx, img)
+	case image.JobThumbnail:
+		err = s.storeThumbnails(ctx, img)
+	case image.JobReprocess:
+		err = s.reprocessImage(ctx, img)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "processing failed")
+		return fmt.Errorf("%s step failed: %w", step, err)
+	}
+
+	s.handlePostUpdate(ctx, id, img)
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// updateFromOriginal reads an image's stored file, lets apply record what it finds on
+// the image and writes the patch apply returns. Steps of the same image may run at
+// once, so each writes only what it covers rather than the whole image.
+func (s *ImageServiceImpl) updateFromOriginal(ctx context.Context, img *image.Image, apply func(data []byte) (*image.ImagePatch, error)) error {
+	data, err := s.retrieveOriginal(ctx, img)
+	if err != nil {
+		return err
+	}
+	patch, err := apply(data)
+	if err != nil {
+		return err
+	}
+
+	img.UpdatedAt = time.Now()
+	if err := s.imageRepo.Patch(ctx, img.ID, patch); err != nil {
+		return fmt.Errorf("failed to update image in database: %w", err)
+	}
+	return nil
+}
+
+// setContentHash records the SHA-256 of an image's stored bytes
+func setContentHash(img *image.Image, data []byte) {
+	sum := sha256.Sum256(data)
+	_ = img.SetContentHash(hex.EncodeToString(sum[:])) //nolint:errcheck // A string always marshals
+}
+
+// applyPhotoMetadata records the capture details read from an image's stored bytes,
+// as far as the metadata policy allows
+func (s *ImageServiceImpl) applyPhotoMetadata(ctx context.Context, img *image.Image, data []byte) error {
+	photo, err := s.processor.ExtractMetadata(ctx, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to extract metadata: %w", err)
+	}
+
+	photo = s.metadataPolicy.Stricter(img.MetadataPolicy()).Apply(photo)
+	if err := img.SetPhoto(photo); err != nil {
+		return err
+	}
+	if photo != nil && photo.TakenAt != nil {
+		img.TakenAt = *photo.TakenAt
+	}
+	return nil
+}
+
+// photoPatch writes the capture details recorded on an image, and its capture time
+// when they have one
+func photoPatch(img *image.Image) *image.ImagePatch {
+	patch := &image.ImagePatch{Metadata: img.MetadataPatch(image.PhotoMetadataKey)}
+	if photo := img.Photo(); photo != nil && photo.TakenAt != nil {
+		patch.TakenAt = photo.TakenAt
+	}
+	return patch
+}
+
+// storeThumbnails stores the thumbnail of an animated GIF, or renders the thumbnail
+// presets of a still image into the derivative cache
+func (s *ImageServiceImpl) storeThumbnails(ctx context.Context, img *image.Image) error {
+	if img.IsVector() {
+		return nil
+	}
+	if img.ContentType == "image/gif" && img.IsAnimated() {
+		return s.storeAnimatedThumbnail(ctx, img)
+	}
+
+	data, err := s.retrieveOriginal(ctx, img)
+	if err != nil {
+		return err
+	}
+	formats := append([]image.RenderFormat{image.RenderFormatJPEG}, image.NegotiableFormats(img.ContentType)...)
+	for _, name := range thumbnailPresets {
+		for _, format := range formats {
+			opts := image.RenderPresets[name]
+			opts.Format = format
+			if err := s.storeRendition(ctx, img.ID, data, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storeRendition renders an image and stores it where the render endpoint caches it
+func (s *ImageServiceImpl) storeRendition(ctx context.Context, id int, data []byte, opts image.RenderOptions) error {
+	start := time.Now()
+	rendered, err := s.processor.Render(ctx, bytes.NewReader(data), opts)
+	if err != nil {
+		return fmt.Errorf("failed to render image: %w", err)
+	}
+	out, err := io.ReadAll(rendered)
+	if err != nil {
+		return fmt.Errorf("failed to render image: %w", err)
+	}
+
+	key := opts.StorageKey(id)
+	if err := s.storage.StoreAt(ctx, key, opts.ContentType(), bytes.NewReader(out), int64(len(out))); err != nil {
+		return fmt.Errorf("failed to store rendition: %w", err)
+	}
+	s.publishThumbnailGenerated(ctx, id, key, int64(len(out)), opts.Width, opts.Height, start)
+	return nil
+}
+
+// reprocessImage reads an image's dimensions and animation again, then has every other
+// step run, queued when there is a job queue so each is retried on its own
+func (s *ImageServiceImpl) reprocessImage(ctx context.Context, img *image.Image) error {
+	err := s.updateFromOriginal(ctx, img, func(data []byte) (*image.ImagePatch, error) {
+		patch := &image.ImagePatch{Metadata: map[string]json.RawMessage{}}
+		if !img.IsVector() {
+			info, err := s.processor.GetImageInfo(ctx, bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read image dimensions: %w", err)
+			}
+			img.Width = &info.Width
+			img.Height = &info.Height
+			if err := img.SetAnimation(info.Animation()); err != nil {
+				return nil, err
+			}
+			patch.Width, patch.Height = img.Width, img.Height
+			maps.Copy(patch.Metadata, img.MetadataPatch(image.AnimationMetadataKey))
+		}
+		if s.jobs != nil {
+			return patch, nil
+		}
+
+		setContentHash(img, data)
+		if err := s.applyPhotoMetadata(ctx, img, data); err != nil {
+			return nil, err
+		}
+		photo := photoPatch(img)
+		maps.Copy(patch.Metadata, img.MetadataPatch(image.ContentHashMetadataKey))
+		maps.Copy(patch.Metadata, photo.Metadata)
+		patch.TakenAt = photo.TakenAt
+		return patch, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.jobs != nil {
+		steps := append([]image.JobType{image.JobHash, image.JobMetadata}, s.derivativeSteps(img)...)
+		return s.jobs.Enqueue(ctx, img.ID, steps...)
+	}
+	return errors.Join(s.storeVariants(ctx, img), s.storeThumbnails(ctx, img))
+}
@@ -1,50 +1,190 @@
 package implementations
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/storage"
 )
 
+// UploadHeaderSize is how much of an upload DecodeUploadHeader reads ahead, enough for
+// the metadata JPEGs carry before their frame header
+const UploadHeaderSize = 256 << 10
+
+// imageInfoReader reads the dimensions of an upload
+type imageInfoReader interface {
+	GetImageInfo(ctx context.Context, data io.Reader) (*image.ImageInfo, error)
+}
+
 // ImageProcessorImpl implements the image.ImageProcessor interface
-type ImageProcessorImpl struct{}
+type ImageProcessorImpl struct {
+	processor *storage.ImageProcessor
+}
 
 // NewImageProcessor creates a new image processor implementation
 func NewImageProcessor() image.ImageProcessor {
-	return &ImageProcessorImpl{}
+	return &ImageProcessorImpl{
+		processor: storage.NewImageProcessor(0, 0, 0), // Default limits and quality
+	}
 }
 
-// GenerateThumbnail creates a thumbnail for an image
+// SetAnimatedThumbnails chooses between animated thumbnails of animated GIFs and a
+// still poster of their first frame
+func (p *ImageProcessorImpl) SetAnimatedThumbnails(animated bool) {
+	p.processor.SetAnimatedThumbnails(animated)
+}
+
+// SetDecodeLimits sets the largest pixel count and decoded size in bytes of an image,
+// and how many images are decoded at once
+func (p *ImageProcessorImpl) SetDecodeLimits(maxPixels, memoryBudget int64, concurrent int) {
+	p.processor.SetDecodeLimits(maxPixels, memoryBudget, concurrent)
+}
+
+// dimensionsError reports images over the decode limits as image.ErrInvalidDimensions
+func dimensionsError(err error) error {
+	if errors.Is(err, storage.ErrImageTooLarge) {
+		return fmt.Errorf("%w: %w", image.ErrInvalidDimensions, err)
+	}
+	return err
+}
+
+// GenerateThumbnail creates a thumbnail for an image; animated GIFs keep their frames
+// unless posters are configured
 func (p *ImageProcessorImpl) GenerateThumbnail(ctx context.Context, data io.Reader, maxWidth, maxHeight int) (io.Reader, error) {
-	// TODO: Implement actual thumbnail generation using golang.org/x/image
-	return nil, nil
+	thumbnail, err := p.processor.GenerateThumbnail(ctx, data, maxWidth, maxHeight)
+	return thumbnail, dimensionsError(err)
 }
 
-// GetImageInfo extracts metadata from an image
+// GetImageInfo extracts dimensions, format and, for animations, frame count and duration
 func (p *ImageProcessorImpl) GetImageInfo(ctx context.Context, data io.Reader) (*image.ImageInfo, error) {
-	// TODO: Implement actual image metadata extraction
+	info, err := p.processor.GetImageInfo(ctx, data)
+	if err != nil {
+		return nil, dimensionsError(err)
+	}
 	return &image.ImageInfo{
-		Width:  800,
-		Height: 600,
-		Format: "JPEG",
+		Width:       info.Width,
+		Height:      info.Height,
+		Format:      info.Format,
+		ColorSpace:  info.ColorSpace,
+		HasAlpha:    info.HasAlpha,
+		Orientation: info.Orientation,
+		FrameCount:  info.FrameCount,
+		Duration:    info.Duration,
 	}, nil
 }
 
+// DecodeUploadHeader decodes the dimensions and format of an upload from its first
+// UploadHeaderSize bytes, which are buffered rather than consumed: the reader returned
+// reads the whole upload whether or not its header decodes. A read error leaves the
+// header short and is returned again when the reader is read.
+func DecodeUploadHeader(ctx context.Context, processor imageInfoReader, file io.Reader) (io.Reader, *image.ImageInfo, error) {
+	body := bufio.NewReaderSize(file, UploadHeaderSize)
+	header, _ := body.Peek(UploadHeaderSize) //nolint:errcheck // Short uploads end before the header size
+
+	// A buffer rather than a bytes.Reader, which GetImageInfo would seek back over to
+	// decode the truncated image in full
+	info, err := processor.GetImageInfo(ctx, bytes.NewBuffer(header))
+	return body, info, err
+}
+
 // Resize resizes an image to specified dimensions
 func (p *ImageProcessorImpl) Resize(ctx context.Context, data io.Reader, width, height int) (io.Reader, error) {
-	// TODO: Implement actual image resizing
-	return nil, nil
+	resized, err := p.processor.Resize(ctx, data, width, height)
+	return resized, dimensionsError(err)
 }
 
-// ValidateImage checks if the provided data is a valid image
+// ValidateImage rejects images whose header declares more pixels than may be decoded.
+// The data need only hold the header; the format itself is checked when it is decoded.
 func (p *ImageProcessorImpl) ValidateImage(ctx context.Context, data io.Reader, contentType string) error {
-	// TODO: Implement actual image validation
-	return nil
+	if contentType == image.ContentTypeSVG {
+		return nil
+	}
+	return dimensionsError(p.processor.CheckDecodeLimits(data))
 }
 
 // OptimizeImage compresses and optimizes an image
 func (p *ImageProcessorImpl) OptimizeImage(ctx context.Context, data io.Reader, quality int) (io.Reader, error) {
-	// TODO: Implement actual image optimization
-	return nil, nil
+	optimized, err := p.processor.OptimizeImage(ctx, data, quality)
+	return optimized, dimensionsError(err)
+}
+
+// Render scales and crops an image into the options' box and encodes it in their format
+func (p *ImageProcessorImpl) Render(ctx context.Context, data io.Reader, opts image.RenderOptions) (io.Reader, error) {
+	rendered, err := p.processor.Render(ctx, data, storage.RenderOptions{
+		Width:   opts.Width,
+		Height:  opts.Height,
+		Crop:    opts.Fit == image.RenderFitCover,
+		Format:  string(opts.Format),
+		Quality: opts.Quality,
+	})
+	return rendered, dimensionsError(err)
+}
+
+// GenerateVariants encodes the responsive variants of an image at the default render quality
+func (p *ImageProcessorImpl) GenerateVariants(ctx context.Context, data io.Reader, widths []int) ([]image.RenderedVariant, error) {
+	variants, err := p.processor.GenerateVariants(ctx, data, widths, image.DefaultRenderQuality)
+	if err != nil {
+		return nil, dimensionsError(err)
+	}
+
+	rendered := make([]image.RenderedVariant, len(variants))
+	for i, v := range variants {
+		rendered[i] = image.RenderedVariant{
+			Width:  v.Width,
+			Height: v.Height,
+			Format: image.RenderFormat(v.Format),
+			Data:   v.Data,
+		}
+	}
+	return rendered, nil
+}
+
+// SanitizeSVG removes scripts, event handlers and external references from an SVG document
+func (p *ImageProcessorImpl) SanitizeSVG(ctx context.Context, data io.Reader) ([]byte, error) {
+	return p.processor.SanitizeSVG(ctx, data)
+}
+
+// ExtractMetadata reads camera, exposure, capture time and GPS details from an image
+func (p *ImageProcessorImpl) ExtractMetadata(ctx context.Context, data io.Reader) (*image.PhotoMetadata, error) {
+	meta, err := storage.ExtractMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract image metadata: %w", err)
+	}
+
+	photo := &image.PhotoMetadata{
+		CameraMake:   meta.Make,
+		CameraModel:  meta.Model,
+		Lens:         meta.Lens,
+		ExposureTime: meta.ExposureTime,
+		FNumber:      meta.FNumber,
+		ISO:          meta.ISO,
+		FocalLength:  meta.FocalLength,
+		TakenAt:      meta.TakenAt,
+		Orientation:  meta.Orientation,
+	}
+	if meta.Latitude != nil && meta.Longitude != nil {
+		photo.GPS = &image.GPSCoordinates{
+			Latitude:  *meta.Latitude,
+			Longitude: *meta.Longitude,
+			Altitude:  meta.Altitude,
+		}
+	}
+	return photo, nil
+}
+
+// StripMetadata removes GPS or all embedded metadata from an image stream as the policy requires
+func (p *ImageProcessorImpl) StripMetadata(ctx context.Context, data io.Reader, policy image.MetadataPolicy) io.ReadCloser {
+	mode := storage.StripNone
+	switch policy {
+	case image.MetadataPolicyStripGPS:
+		mode = storage.StripGPS
+	case image.MetadataPolicyStripAll:
+		mode = storage.StripAll
+	}
+	return storage.StripMetadata(data, mode)
 }
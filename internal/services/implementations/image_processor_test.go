@@ -0,0 +1,77 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	stdimage "image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+)
+
+func TestImageProcessor_ValidateImage_DecodeLimits(t *testing.T) {
+	processor := NewImageProcessor()
+
+	// A PNG header declaring 50000x50000 pixels, with no image data after it
+	ihdr := []byte("IHDR\x00\x00\xc3\x50\x00\x00\xc3\x50\x08\x06\x00\x00\x00")
+	bomb := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), ihdr...)
+	bomb = binary.BigEndian.AppendUint32(bomb, crc32.ChecksumIEEE(ihdr))
+
+	err := processor.ValidateImage(context.Background(), bytes.NewReader(bomb), "image/png")
+	assert.ErrorIs(t, err, image.ErrInvalidDimensions)
+
+	_, err = processor.GenerateThumbnail(context.Background(), bytes.NewReader(bomb), 100, 100)
+	assert.ErrorIs(t, err, image.ErrInvalidDimensions)
+
+	var small bytes.Buffer
+	require.NoError(t, png.Encode(&small, stdimage.NewRGBA(stdimage.Rect(0, 0, 8, 8))))
+	assert.NoError(t, processor.ValidateImage(context.Background(), bytes.NewReader(small.Bytes()), "image/png"))
+
+	// Only the header is needed: truncated data is left for the decoder to reject
+	assert.NoError(t, processor.ValidateImage(context.Background(), bytes.NewReader(small.Bytes()[:20]), "image/png"))
+
+	// The pixel limit can be lowered
+	processor.(interface {
+		SetDecodeLimits(maxPixels, memoryBudget int64, concurrent int)
+	}).SetDecodeLimits(50, 0, 0)
+	err = processor.ValidateImage(context.Background(), bytes.NewReader(small.Bytes()), "image/png")
+	assert.ErrorIs(t, err, image.ErrInvalidDimensions)
+}
+
+func TestDecodeUploadHeader(t *testing.T) {
+	ctx := context.Background()
+	processor := NewImageProcessor()
+
+	t.Run("large upload", func(t *testing.T) {
+		data := append(testPNG(t, 12, 8), make([]byte, 2*UploadHeaderSize)...)
+
+		body, info, err := DecodeUploadHeader(ctx, processor, bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 12, info.Width)
+		assert.Equal(t, 8, info.Height)
+		assert.Equal(t, "png", info.Format)
+
+		// The header is not consumed: the body still reads the whole upload
+		read, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, data, read)
+	})
+
+	t.Run("upload that is not an image", func(t *testing.T) {
+		data := []byte("GIF89a\x01\x00\x01\x00")
+
+		body, _, err := DecodeUploadHeader(ctx, processor, bytes.NewReader(data))
+		assert.Error(t, err)
+
+		read, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, data, read)
+	})
+}
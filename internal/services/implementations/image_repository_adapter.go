@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"image-gallery/internal/domain/image"
 	"image-gallery/internal/platform/database"
@@ -13,6 +14,7 @@ import (
 type ImageRepositoryAdapter struct {
 	dbRepo  database.ImageRepository
 	tagRepo database.TagRepository
+	jobRepo database.JobRepository // Processing status is left out when nil
 }
 
 // NewImageRepositoryAdapter creates a domain repository adapter
@@ -28,6 +30,11 @@ func (a *ImageRepositoryAdapter) SetTagRepository(tagRepo database.TagRepository
 	a.tagRepo = tagRepo
 }
 
+// SetJobRepository sets the job repository the processing status of images is read from
+func (a *ImageRepositoryAdapter) SetJobRepository(jobRepo database.JobRepository) {
+	a.jobRepo = jobRepo
+}
+
 func (a *ImageRepositoryAdapter) Create(ctx context.Context, img *image.Image) error {
 	dbImage := &database.Image{
 		Filename:         img.Filename,
@@ -39,6 +46,7 @@ func (a *ImageRepositoryAdapter) Create(ctx context.Context, img *image.Image) e
 		Width:            img.Width,
 		Height:           img.Height,
 		UploadedAt:       img.UploadedAt,
+		TakenAt:          img.TakenAt,
 		Metadata:         database.Metadata{},
 	}
 
@@ -58,6 +66,7 @@ func (a *ImageRepositoryAdapter) Create(ctx context.Context, img *image.Image) e
 	img.CreatedAt = dbImage.CreatedAt
 	img.UpdatedAt = dbImage.UpdatedAt
 	img.UploadedAt = dbImage.UploadedAt
+	img.TakenAt = dbImage.TakenAt
 
 	// Save tag associations if tags are provided
 	if a.tagRepo != nil && len(img.Tags) > 0 {
@@ -86,7 +95,15 @@ func (a *ImageRepositoryAdapter) GetByID(ctx context.Context, id int) (*image.Im
 	// TODO: This is a design issue - the adapter should have access to both repos
 	// or we should use a different approach
 
-	return a.convertToBaseImage(dbImage), nil
+	if err := a.dbRepo.LoadVariants(ctx, []*database.Image{dbImage}); err != nil {
+		return nil, err
+	}
+
+	img := a.convertToBaseImage(dbImage)
+	if err := a.loadProcessing(ctx, []*image.Image{img}); err != nil {
+		return nil, err
+	}
+	return img, nil
 }
 
 func (a *ImageRepositoryAdapter) List(ctx context.Context, req *image.ListImagesRequest) (*image.ListImagesResponse, error) {
@@ -98,6 +115,18 @@ func (a *ImageRepositoryAdapter) List(ctx context.Context, req *image.ListImages
 		tagFilters = []string{req.Tag}
 	}
 
+	// Photo metadata and capture date filters go through the general search, which also applies tag filters
+	if req.Camera != "" || req.Lens != "" || req.TakenAfter != nil || req.TakenBefore != nil {
+		return a.search(ctx, req, database.SearchFilters{
+			Tags:         tagFilters,
+			MatchAllTags: req.MatchAll,
+			Camera:       req.Camera,
+			Lens:         req.Lens,
+			TakenAfter:   req.TakenAfter,
+			TakenBefore:  req.TakenBefore,
+		})
+	}
+
 	// Handle tag-based filtering
 	if len(tagFilters) > 0 {
 		pagination := database.PaginationParams{
@@ -120,9 +149,9 @@ func (a *ImageRepositoryAdapter) List(ctx context.Context, req *image.ListImages
 			count = pagination.Limit * req.Page
 		}
 
-		images := make([]image.Image, len(dbImages))
-		for i, dbImg := range dbImages {
-			images[i] = *a.convertToBaseImage(dbImg)
+		images, err := a.convertWithVariants(ctx, dbImages)
+		if err != nil {
+			return nil, err
 		}
 
 		response := &image.ListImagesResponse{
@@ -142,10 +171,7 @@ func (a *ImageRepositoryAdapter) List(ctx context.Context, req *image.ListImages
 		Offset: req.GetOffset(),
 	}
 
-	sort := database.SortParams{
-		Field: "uploaded_at",
-		Order: "DESC",
-	}
+	sort := listSort(req)
 
 	// Use GetWithTags to load tags with images
 	dbImages, err := a.dbRepo.GetWithTags(ctx, pagination, sort)
@@ -159,9 +185,43 @@ func (a *ImageRepositoryAdapter) List(ctx context.Context, req *image.ListImages
 		return nil, err
 	}
 
-	images := make([]image.Image, len(dbImages))
-	for i, dbImg := range dbImages {
-		images[i] = *a.convertToBaseImage(dbImg)
+	images, err := a.convertWithVariants(ctx, dbImages)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &image.ListImagesResponse{
+		Images:     images,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	}
+	response.CalculateTotalPages()
+
+	return response, nil
+}
+
+// search lists the images matching the filters, newest first, with an exact total count
+func (a *ImageRepositoryAdapter) search(ctx context.Context, req *image.ListImagesRequest, filters database.SearchFilters) (*image.ListImagesResponse, error) {
+	pagination := database.PaginationParams{
+		Limit:  req.PageSize,
+		Offset: req.GetOffset(),
+	}
+	sort := listSort(req)
+
+	dbImages, err := a.dbRepo.Search(ctx, filters, pagination, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := a.dbRepo.CountSearch(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := a.convertWithVariants(ctx, dbImages)
+	if err != nil {
+		return nil, err
 	}
 
 	response := &image.ListImagesResponse{
@@ -186,6 +246,7 @@ func (a *ImageRepositoryAdapter) Update(ctx context.Context, img *image.Image) e
 		ThumbnailPath:    img.ThumbnailPath,
 		Width:            img.Width,
 		Height:           img.Height,
+		TakenAt:          img.TakenAt,
 		Metadata:         database.Metadata{},
 	}
 
@@ -200,10 +261,22 @@ func (a *ImageRepositoryAdapter) Update(ctx context.Context, img *image.Image) e
 		return err
 	}
 
+	img.TakenAt = dbImage.TakenAt
 	img.UpdatedAt = dbImage.UpdatedAt
 	return nil
 }
 
+// Patch updates only the derived data set in patch
+func (a *ImageRepositoryAdapter) Patch(ctx context.Context, id int, patch *image.ImagePatch) error {
+	return a.dbRepo.Patch(ctx, id, database.ImagePatch{
+		Width:         patch.Width,
+		Height:        patch.Height,
+		TakenAt:       patch.TakenAt,
+		ThumbnailPath: patch.ThumbnailPath,
+		Metadata:      patch.Metadata,
+	})
+}
+
 func (a *ImageRepositoryAdapter) Delete(ctx context.Context, id int) error {
 	return a.dbRepo.Delete(ctx, id)
 }
@@ -245,6 +318,172 @@ func (a *ImageRepositoryAdapter) CountByTag(ctx context.Context, tagName string)
 	return len(dbImages), nil
 }
 
+// Timeline counts images per capture year, month or day, newest period first
+func (a *ImageRepositoryAdapter) Timeline(ctx context.Context, granularity image.TimelineGranularity) ([]image.TimelineBucket, error) {
+	dbBuckets, err := a.dbRepo.GetTimeline(ctx, string(granularity))
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]image.TimelineBucket, len(dbBuckets))
+	for i, dbBucket := range dbBuckets {
+		buckets[i] = image.TimelineBucket{
+			Period: granularity.Label(dbBucket.Period),
+			Start:  dbBucket.Period,
+			End:    granularity.End(dbBucket.Period),
+			Count:  dbBucket.Count,
+		}
+	}
+	return buckets, nil
+}
+
+// GeoClusters groups the geotagged images inside bounds by proximity at a map zoom level
+func (a *ImageRepositoryAdapter) GeoClusters(ctx context.Context, bounds image.GeoBounds, zoom int) ([]image.GeoCluster, error) {
+	dbBounds := database.GeoBounds{
+		MinLat: bounds.MinLat,
+		MinLon: bounds.MinLon,
+		MaxLat: bounds.MaxLat,
+		MaxLon: bounds.MaxLon,
+	}
+	dbClusters, err := a.dbRepo.GetGeoClusters(ctx, dbBounds, image.GeoClusterCellSize(zoom))
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]image.GeoCluster, len(dbClusters))
+	for i, dbCluster := range dbClusters {
+		clusters[i] = image.GeoCluster{
+			Latitude:  dbCluster.Latitude,
+			Longitude: dbCluster.Longitude,
+			Count:     dbCluster.Count,
+			ImageID:   dbCluster.ImageID,
+			Filename:  dbCluster.Filename,
+		}
+	}
+	return clusters, nil
+}
+
+// SaveVariants records the responsive variants stored for an image
+func (a *ImageRepositoryAdapter) SaveVariants(ctx context.Context, imageID int, variants []image.ImageVariant) error {
+	dbVariants := make([]database.ImageVariant, len(variants))
+	for i, v := range variants {
+		dbVariants[i] = database.ImageVariant{
+			Width:       v.Width,
+			Height:      v.Height,
+			Format:      string(v.Format),
+			FileSize:    v.FileSize,
+			StoragePath: v.StoragePath,
+		}
+	}
+	return a.dbRepo.SaveVariants(ctx, imageID, dbVariants)
+}
+
+// ListBackfillCandidates returns the IDs of images missing derived data after afterID
+func (a *ImageRepositoryAdapter) ListBackfillCandidates(ctx context.Context, afterID int, limit int) ([]int, error) {
+	return a.dbRepo.ListBackfillCandidates(ctx, afterID, limit)
+}
+
+// CountBackfillCandidates counts the images missing derived data after afterID
+func (a *ImageRepositoryAdapter) CountBackfillCandidates(ctx context.Context, afterID int) (int, error) {
+	return a.dbRepo.CountBackfillCandidates(ctx, afterID)
+}
+
+// GetBackfillCheckpoint returns a backfill job's checkpoint
+func (a *ImageRepositoryAdapter) GetBackfillCheckpoint(ctx context.Context, job string) (*image.BackfillCheckpoint, error) {
+	checkpoint, err := a.dbRepo.GetBackfillCheckpoint(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	return &image.BackfillCheckpoint{
+		Job:         checkpoint.Job,
+		LastImageID: checkpoint.LastImageID,
+		Processed:   checkpoint.Processed,
+		Failed:      checkpoint.Failed,
+		StartedAt:   checkpoint.StartedAt,
+		UpdatedAt:   checkpoint.UpdatedAt,
+		CompletedAt: checkpoint.CompletedAt,
+	}, nil
+}
+
+// SaveBackfillCheckpoint records a backfill job's progress
+func (a *ImageRepositoryAdapter) SaveBackfillCheckpoint(ctx context.Context, checkpoint *image.BackfillCheckpoint) error {
+	dbCheckpoint := &database.BackfillCheckpoint{
+		Job:         checkpoint.Job,
+		LastImageID: checkpoint.LastImageID,
+		Processed:   checkpoint.Processed,
+		Failed:      checkpoint.Failed,
+		StartedAt:   checkpoint.StartedAt,
+		CompletedAt: checkpoint.CompletedAt,
+	}
+	if err := a.dbRepo.SaveBackfillCheckpoint(ctx, dbCheckpoint); err != nil {
+		return err
+	}
+	checkpoint.StartedAt = dbCheckpoint.StartedAt
+	checkpoint.UpdatedAt = dbCheckpoint.UpdatedAt
+	return nil
+}
+
+// convertWithVariants loads the variants of a page of images and converts them to domain images
+func (a *ImageRepositoryAdapter) convertWithVariants(ctx context.Context, dbImages []*database.Image) ([]image.Image, error) {
+	if err := a.dbRepo.LoadVariants(ctx, dbImages); err != nil {
+		return nil, err
+	}
+
+	images := make([]image.Image, len(dbImages))
+	page := make([]*image.Image, len(dbImages))
+	for i, dbImg := range dbImages {
+		images[i] = *a.convertToBaseImage(dbImg)
+		page[i] = &images[i]
+	}
+	if err := a.loadProcessing(ctx, page); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// loadProcessing fills in the latest job of each processing step of the given images,
+// in the order of image.JobTypes
+func (a *ImageRepositoryAdapter) loadProcessing(ctx context.Context, images []*image.Image) error {
+	if a.jobRepo == nil || len(images) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*image.Image, len(images))
+	imageIDs := make([]int, 0, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+		imageIDs = append(imageIDs, img.ID)
+	}
+
+	dbJobs, err := a.jobRepo.ListLatest(ctx, imageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load processing jobs: %w", err)
+	}
+	for _, dbJob := range dbJobs {
+		if img, ok := byID[dbJob.ImageID]; ok {
+			img.Processing = append(img.Processing, *fromDBJob(dbJob))
+		}
+	}
+	for _, img := range images {
+		slices.SortFunc(img.Processing, func(x, y image.ProcessingJob) int {
+			return slices.Index(image.JobTypes, x.Type) - slices.Index(image.JobTypes, y.Type)
+		})
+	}
+	return nil
+}
+
+// listSort orders a listing by upload time unless the capture time was requested, newest first
+func listSort(req *image.ListImagesRequest) database.SortParams {
+	field := database.SortByUploadedAt
+	if req.SortBy == image.SortByTakenAt {
+		field = database.SortByTakenAt
+	}
+	return database.SortParams{
+		Field: field,
+		Order: database.SortDesc,
+	}
+}
+
 // Helper method to convert database Image to domain Image
 func (a *ImageRepositoryAdapter) convertToBaseImage(dbImg *database.Image) *image.Image {
 	img := &image.Image{
@@ -258,6 +497,7 @@ func (a *ImageRepositoryAdapter) convertToBaseImage(dbImg *database.Image) *imag
 		Width:            dbImg.Width,
 		Height:           dbImg.Height,
 		UploadedAt:       dbImg.UploadedAt,
+		TakenAt:          dbImg.TakenAt,
 		CreatedAt:        dbImg.CreatedAt,
 		UpdatedAt:        dbImg.UpdatedAt,
 	}
@@ -279,9 +519,22 @@ func (a *ImageRepositoryAdapter) convertToBaseImage(dbImg *database.Image) *imag
 				Name:      dbTag.Name,
 				CreatedAt: dbTag.CreatedAt,
 			}
+			if dbTag.Color != nil {
+				domainTags[i].Color = *dbTag.Color
+			}
 		}
 		img.Tags = domainTags
 	}
 
+	for _, v := range dbImg.Variants {
+		img.Variants = append(img.Variants, image.ImageVariant{
+			Width:       v.Width,
+			Height:      v.Height,
+			Format:      image.RenderFormat(v.Format),
+			FileSize:    v.FileSize,
+			StoragePath: v.StoragePath,
+		})
+	}
+
 	return img
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"image-gallery/internal/domain/image"
 	"image-gallery/internal/platform/database"
@@ -62,6 +63,11 @@ func (m *MockDatabaseImageRepository) UpdateThumbnail(ctx context.Context, id in
 	return args.Error(0)
 }
 
+func (m *MockDatabaseImageRepository) Patch(ctx context.Context, id int, patch database.ImagePatch) error {
+	args := m.Called(ctx, id, patch)
+	return args.Error(0)
+}
+
 func (m *MockDatabaseImageRepository) Delete(ctx context.Context, id int) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -87,6 +93,11 @@ func (m *MockDatabaseImageRepository) Search(ctx context.Context, filters databa
 	return args.Get(0).([]*database.Image), args.Error(1)
 }
 
+func (m *MockDatabaseImageRepository) CountSearch(ctx context.Context, filters database.SearchFilters) (int, error) {
+	args := m.Called(ctx, filters)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockDatabaseImageRepository) GetByDateRange(ctx context.Context, start, end time.Time, pagination database.PaginationParams) ([]*database.Image, error) {
 	args := m.Called(ctx, start, end, pagination)
 	return args.Get(0).([]*database.Image), args.Error(1)
@@ -117,6 +128,46 @@ func (m *MockDatabaseImageRepository) GetStats(ctx context.Context) (*database.I
 	return args.Get(0).(*database.ImageStats), args.Error(1)
 }
 
+func (m *MockDatabaseImageRepository) GetTimeline(ctx context.Context, granularity string) ([]database.TimelineBucket, error) {
+	args := m.Called(ctx, granularity)
+	return args.Get(0).([]database.TimelineBucket), args.Error(1)
+}
+
+func (m *MockDatabaseImageRepository) GetGeoClusters(ctx context.Context, bounds database.GeoBounds, cellSize float64) ([]database.GeoCluster, error) {
+	args := m.Called(ctx, bounds, cellSize)
+	return args.Get(0).([]database.GeoCluster), args.Error(1)
+}
+
+func (m *MockDatabaseImageRepository) SaveVariants(ctx context.Context, imageID int, variants []database.ImageVariant) error {
+	args := m.Called(ctx, imageID, variants)
+	return args.Error(0)
+}
+
+func (m *MockDatabaseImageRepository) LoadVariants(ctx context.Context, images []*database.Image) error {
+	args := m.Called(ctx, images)
+	return args.Error(0)
+}
+
+func (m *MockDatabaseImageRepository) ListBackfillCandidates(ctx context.Context, afterID int, limit int) ([]int, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockDatabaseImageRepository) CountBackfillCandidates(ctx context.Context, afterID int) (int, error) {
+	args := m.Called(ctx, afterID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDatabaseImageRepository) GetBackfillCheckpoint(ctx context.Context, job string) (*database.BackfillCheckpoint, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(*database.BackfillCheckpoint), args.Error(1)
+}
+
+func (m *MockDatabaseImageRepository) SaveBackfillCheckpoint(ctx context.Context, checkpoint *database.BackfillCheckpoint) error {
+	args := m.Called(ctx, checkpoint)
+	return args.Error(0)
+}
+
 func (m *MockDatabaseImageRepository) GetWithTags(ctx context.Context, pagination database.PaginationParams, sort database.SortParams) ([]*database.Image, error) {
 	args := m.Called(ctx, pagination, sort)
 	return args.Get(0).([]*database.Image), args.Error(1)
@@ -212,6 +263,12 @@ func TestImageRepositoryAdapter_GetByID(t *testing.T) {
 		}
 
 		mockDB.On("GetByID", ctx, imageID).Return(dbImage, nil)
+		mockDB.On("LoadVariants", ctx, []*database.Image{dbImage}).Run(func(args mock.Arguments) {
+			loaded := args.Get(1).([]*database.Image)
+			loaded[0].Variants = []database.ImageVariant{
+				{ImageID: imageID, Width: 320, Height: 240, Format: "jpeg", FileSize: 2048, StoragePath: "derivatives/1/w320.jpg"},
+			}
+		}).Return(nil)
 
 		// When: Getting an image by ID
 		result, err := adapter.GetByID(ctx, imageID)
@@ -227,9 +284,43 @@ func TestImageRepositoryAdapter_GetByID(t *testing.T) {
 		assert.Equal(t, "images/test-image.jpg", result.StoragePath)
 		assert.Equal(t, 800, *result.Width)
 		assert.Equal(t, 600, *result.Height)
+		assert.Equal(t, []image.ImageVariant{
+			{Width: 320, Height: 240, Format: image.RenderFormatJPEG, FileSize: 2048, StoragePath: "derivatives/1/w320.jpg"},
+		}, result.Variants)
 		mockDB.AssertExpectations(t)
 	})
 
+	t.Run("WithProcessingJobs", func(t *testing.T) {
+		// Given: An adapter with a job repository holding the image's latest jobs
+		mockDB := &MockDatabaseImageRepository{}
+		mockJobs := &MockDatabaseJobRepository{}
+		adapter := &ImageRepositoryAdapter{dbRepo: mockDB}
+		adapter.SetJobRepository(mockJobs)
+		ctx := context.Background()
+
+		dbImage := &database.Image{ID: 1, Filename: "test-image.jpg", Metadata: database.Metadata{}}
+		lastError := "corrupt image"
+		mockDB.On("GetByID", ctx, 1).Return(dbImage, nil)
+		mockDB.On("LoadVariants", ctx, []*database.Image{dbImage}).Return(nil)
+		mockJobs.On("ListLatest", ctx, []int{1}).Return([]*database.ProcessingJob{
+			{ID: 3, ImageID: 1, Type: "thumbnail", Status: "pending"},
+			{ID: 2, ImageID: 1, Type: "variants", Status: "dead", Attempts: 5, MaxAttempts: 5, LastError: &lastError},
+		}, nil)
+
+		// When: Getting an image by ID
+		result, err := adapter.GetByID(ctx, 1)
+
+		// Then: The jobs are attached in step order and sum up to the status
+		require.NoError(t, err)
+		require.Len(t, result.Processing, 2)
+		assert.Equal(t, image.JobVariants, result.Processing[0].Type)
+		assert.Equal(t, image.JobThumbnail, result.Processing[1].Type)
+		status, statusErr := result.ProcessingStatus()
+		assert.Equal(t, image.ProcessingFailed, status)
+		assert.Equal(t, "variants: corrupt image", statusErr)
+		mockJobs.AssertExpectations(t)
+	})
+
 	t.Run("NotFound", func(t *testing.T) {
 		// Given: A mock database repository that returns not found error
 		mockDB := &MockDatabaseImageRepository{}
@@ -296,6 +387,7 @@ func TestImageRepositoryAdapter_List(t *testing.T) {
 
 		mockDB.On("GetWithTags", ctx, expectedPagination, expectedSort).Return(dbImages, nil)
 		mockDB.On("Count", ctx).Return(2, nil)
+		mockDB.On("LoadVariants", ctx, dbImages).Return(nil)
 
 		// When: Listing images without tag filter
 		response, err := adapter.List(ctx, req)
@@ -350,6 +442,7 @@ func TestImageRepositoryAdapter_List(t *testing.T) {
 
 		// Mock GetByTags call (only one call now - count is derived from results)
 		mockDB.On("GetByTags", ctx, expectedTags, false, expectedPagination).Return(dbImages, nil)
+		mockDB.On("LoadVariants", ctx, dbImages).Return(nil)
 
 		// When: Listing images with tag filter
 		response, err := adapter.List(ctx, req)
@@ -363,6 +456,141 @@ func TestImageRepositoryAdapter_List(t *testing.T) {
 
 		mockDB.AssertExpectations(t)
 	})
+
+	t.Run("Success_WithCamera", func(t *testing.T) {
+		// Given: A mock database repository searched by camera and tag
+		mockDB := &MockDatabaseImageRepository{}
+		adapter := NewImageRepositoryAdapter(mockDB)
+		ctx := context.Background()
+
+		req := &image.ListImagesRequest{
+			Page:     2,
+			PageSize: 10,
+			Tags:     []string{"nature"},
+			MatchAll: true,
+			Camera:   "EOS R5",
+		}
+
+		dbImages := []*database.Image{
+			{
+				ID:       11,
+				Filename: "bird.jpg",
+				Metadata: database.Metadata{
+					"photo": map[string]interface{}{"camera_make": "Canon", "camera_model": "Canon EOS R5"},
+				},
+			},
+		}
+
+		expectedFilters := database.SearchFilters{
+			Tags:         []string{"nature"},
+			MatchAllTags: true,
+			Camera:       "EOS R5",
+		}
+		expectedPagination := database.PaginationParams{Limit: 10, Offset: 10}
+		expectedSort := database.SortParams{Field: "uploaded_at", Order: "DESC"}
+
+		mockDB.On("Search", ctx, expectedFilters, expectedPagination, expectedSort).Return(dbImages, nil)
+		mockDB.On("CountSearch", ctx, expectedFilters).Return(11, nil)
+		mockDB.On("LoadVariants", ctx, dbImages).Return(nil)
+
+		// When: Listing images with a camera filter
+		response, err := adapter.List(ctx, req)
+
+		// Then: Should use the search with an exact count
+		require.NoError(t, err)
+		require.Len(t, response.Images, 1)
+		assert.Equal(t, 11, response.TotalCount)
+		assert.Equal(t, 2, response.TotalPages)
+		assert.Equal(t, "Canon EOS R5", response.Images[0].Photo().CameraModel)
+
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("Success_ByCaptureMonth", func(t *testing.T) {
+		// Given: A mock database repository searched by capture date
+		mockDB := &MockDatabaseImageRepository{}
+		adapter := NewImageRepositoryAdapter(mockDB)
+		ctx := context.Background()
+
+		from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0)
+		takenAt := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+		req := &image.ListImagesRequest{
+			Page:        1,
+			PageSize:    20,
+			SortBy:      image.SortByTakenAt,
+			TakenAfter:  &from,
+			TakenBefore: &to,
+		}
+
+		expectedFilters := database.SearchFilters{TakenAfter: &from, TakenBefore: &to}
+		expectedPagination := database.PaginationParams{Limit: 20, Offset: 0}
+		expectedSort := database.SortParams{Field: database.SortByTakenAt, Order: database.SortDesc}
+
+		mockDB.On("Search", ctx, expectedFilters, expectedPagination, expectedSort).
+			Return([]*database.Image{{ID: 3, TakenAt: takenAt}}, nil)
+		mockDB.On("CountSearch", ctx, expectedFilters).Return(1, nil)
+		mockDB.On("LoadVariants", ctx, mock.Anything).Return(nil)
+
+		// When: Listing one capture month newest first
+		response, err := adapter.List(ctx, req)
+
+		// Then: Should search by capture time and sort by it
+		require.NoError(t, err)
+		require.Len(t, response.Images, 1)
+		assert.Equal(t, takenAt, response.Images[0].TakenAt)
+
+		mockDB.AssertExpectations(t)
+	})
+}
+
+func TestImageRepositoryAdapter_Timeline(t *testing.T) {
+	// Given: Monthly buckets from the database
+	mockDB := &MockDatabaseImageRepository{}
+	adapter := NewImageRepositoryAdapter(mockDB)
+	ctx := context.Background()
+
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	mockDB.On("GetTimeline", ctx, "month").Return([]database.TimelineBucket{
+		{Period: may, Count: 4},
+		{Period: december, Count: 1},
+	}, nil)
+
+	// When: Getting the monthly timeline
+	buckets, err := adapter.Timeline(ctx, image.TimelineMonth)
+
+	// Then: Buckets are labelled and bounded
+	require.NoError(t, err)
+	assert.Equal(t, []image.TimelineBucket{
+		{Period: "2024-05", Start: may, End: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Count: 4},
+		{Period: "2023-12", Start: december, End: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 1},
+	}, buckets)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestImageRepositoryAdapter_GeoClusters(t *testing.T) {
+	// Given: One cluster in the requested box
+	mockDB := &MockDatabaseImageRepository{}
+	adapter := NewImageRepositoryAdapter(mockDB)
+	ctx := context.Background()
+
+	bounds := database.GeoBounds{MinLat: 48, MinLon: 2, MaxLat: 49, MaxLon: 3}
+	mockDB.On("GetGeoClusters", ctx, bounds, image.GeoClusterCellSize(10)).Return([]database.GeoCluster{
+		{Latitude: 48.85, Longitude: 2.35, Count: 3, ImageID: 7, Filename: "louvre.jpg"},
+	}, nil)
+
+	// When: Clustering at zoom 10
+	clusters, err := adapter.GeoClusters(ctx, image.GeoBounds{MinLon: 2, MinLat: 48, MaxLon: 3, MaxLat: 49}, 10)
+
+	// Then: The grid cell size follows the zoom level
+	require.NoError(t, err)
+	assert.Equal(t, []image.GeoCluster{
+		{Latitude: 48.85, Longitude: 2.35, Count: 3, ImageID: 7, Filename: "louvre.jpg"},
+	}, clusters)
+
+	mockDB.AssertExpectations(t)
 }
 
 func TestImageRepositoryAdapter_Delete(t *testing.T) {
@@ -1,11 +1,16 @@
 package implementations
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -20,6 +25,14 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// animatedThumbnailSize bounds both sides of animated GIF thumbnails, which cards show
+// at up to 20% of the viewport
+const animatedThumbnailSize = 480
+
+// validationHeaderSize is how much of an upload is read ahead for the processor to check
+// its dimensions, enough for the metadata JPEGs carry before their frame header
+const validationHeaderSize = 256 << 10
+
 // ImageServiceImpl implements the image.ImageService interface
 type ImageServiceImpl struct {
 	imageRepo image.Repository
@@ -29,6 +42,11 @@ type ImageServiceImpl struct {
 	validator image.ValidationService
 	eventPub  image.EventPublisher // can be nil
 	cache     image.CacheService   // can be nil
+	tagPolicy image.TagPolicy      // empty behaves like image.TagPolicyFree
+
+	metadataPolicy image.MetadataPolicy // empty behaves like image.MetadataPolicyKeep
+	variantWidths  []int                // nil uses image.DefaultVariantWidths; empty disables variants
+	jobs           image.JobQueue       // nil generates variants and thumbnails during the upload
 
 	// Observability
 	tracer               trace.Tracer
@@ -126,6 +144,29 @@ func NewImageService(
 	}
 }
 
+// SetTagPolicy sets the policy that decides which tags may be applied to images
+func (s *ImageServiceImpl) SetTagPolicy(policy image.TagPolicy) {
+	s.tagPolicy = policy
+}
+
+// SetMetadataPolicy sets the policy that decides which embedded metadata is removed
+// from uploads. Upload requests may tighten it but never loosen it.
+func (s *ImageServiceImpl) SetMetadataPolicy(policy image.MetadataPolicy) {
+	s.metadataPolicy = policy
+}
+
+// SetVariantWidths sets the widths of the responsive variants generated at upload.
+// An empty list turns variant generation off.
+func (s *ImageServiceImpl) SetVariantWidths(widths []int) {
+	s.variantWidths = append([]int{}, widths...)
+}
+
+// SetJobQueue sets the queue that variants and thumbnails of new images are generated
+// from, after the upload has returned
+func (s *ImageServiceImpl) SetJobQueue(jobs image.JobQueue) {
+	s.jobs = jobs
+}
+
 // CreateImage handles the complete image creation process
 func (s *ImageServiceImpl) CreateImage(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error) {
 	startTime := time.Now()
@@ -154,38 +195,86 @@ func (s *ImageServiceImpl) CreateImage(ctx context.Context, req *image.CreateIma
 		return nil, err
 	}
 
-	span.AddEvent("validating_request")
-	if err := s.validateCreateRequest(ctx, req, data); err != nil {
+	if req.ContentType == image.ContentTypeSVG {
+		addCreateEvent(ctx, span, "sanitizing_svg")
+		sanitized, err := s.sanitizeSVG(ctx, req, data)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "svg sanitizing failed")
+			return nil, err
+		}
+		data = sanitized
+	}
+
+	// The header is peeked rather than read, so the whole upload is still stored
+	buffered := bufio.NewReaderSize(data, validationHeaderSize)
+	header, err := buffered.Peek(validationHeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "read failed")
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	data = buffered
+
+	addCreateEvent(ctx, span, "validating_request")
+	if err := s.validateCreateRequest(ctx, req, bytes.NewReader(header)); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "validation failed")
 		return nil, err
 	}
 
-	span.AddEvent("storing_image_file")
-	storageResp, err := s.storeImageFile(ctx, req, data)
-	if err != nil {
+	policy := s.metadataPolicy.Stricter(req.MetadataPolicy)
+	span.SetAttributes(attribute.String("image.metadata_policy", string(policy)))
+	if !policy.Enforceable(req.ContentType) {
+		err := fmt.Errorf("%w: metadata policy %s cannot be applied to %s files", image.ErrInvalidContentType, policy, req.ContentType)
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "storage failed")
+		span.SetStatus(codes.Error, "metadata policy not enforceable")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("storage.path", storageResp))
 
-	span.AddEvent("processing_tags")
-	tags, err := s.processTags(ctx, req.Tags)
+	// Tags are resolved before storing so that a tag rejected by the policy leaves no orphaned file
+	addCreateEvent(ctx, span, "processing_tags")
+	tags, pendingTags, err := s.processTags(ctx, req.Tags)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "tag processing failed")
 		return nil, err
 	}
 
+	addCreateEvent(ctx, span, "storing_image_file")
+	storageResp, details, err := s.storeAndExtractMetadata(ctx, span, req, policy, data)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "storage failed")
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("storage.path", storageResp))
+
 	img := s.buildImageObject(req, storageResp, tags)
+	if err := img.SetAnimation(details.animation); err != nil {
+		span.RecordError(err)
+	}
+	if err := img.SetContentHash(details.contentHash); err != nil {
+		span.RecordError(err)
+	}
+	if photo := policy.Apply(details.photo); photo != nil {
+		if err := img.SetPhoto(photo); err != nil {
+			span.RecordError(err)
+		}
+		if photo.TakenAt != nil {
+			img.TakenAt = *photo.TakenAt
+		}
+	}
+	if err := img.SetMetadataPolicy(policy); err != nil {
+		span.RecordError(err)
+	}
 	if err := img.Validate(); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "validation failed")
 		return nil, fmt.Errorf("final image validation failed: %w", err)
 	}
 
-	span.AddEvent("saving_to_database")
+	addCreateEvent(ctx, span, "saving_to_database")
 	if err := s.saveImageToDatabase(ctx, img, storageResp); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "database save failed")
@@ -193,6 +282,8 @@ func (s *ImageServiceImpl) CreateImage(ctx context.Context, req *image.CreateIma
 	}
 	span.SetAttributes(attribute.Int("image.id", img.ID))
 
+	s.queuePendingTags(ctx, span, img, pendingTags)
+	s.generateDerivatives(ctx, span, img)
 	s.handlePostCreation(ctx, img)
 
 	// Record metrics
@@ -203,6 +294,24 @@ func (s *ImageServiceImpl) CreateImage(ctx context.Context, req *image.CreateIma
 	return img, nil
 }
 
+// createImageStages are the upload stages begun by the span events of CreateImage
+var createImageStages = map[string]image.UploadStage{
+	"sanitizing_svg":     image.UploadStageValidating,
+	"validating_request": image.UploadStageValidating,
+	"processing_tags":    image.UploadStageValidating,
+	"storing_image_file": image.UploadStageStoring,
+	"saving_to_database": image.UploadStageProcessing,
+}
+
+// addCreateEvent adds a span event of CreateImage and reports the upload stage it
+// begins to the context's reporter
+func addCreateEvent(ctx context.Context, span trace.Span, name string) {
+	span.AddEvent(name)
+	if stage, ok := createImageStages[name]; ok {
+		image.ReportUploadStage(ctx, stage)
+	}
+}
+
 func (s *ImageServiceImpl) recordImageCreationMetrics(ctx context.Context, duration float64, contentType string) {
 	if s.imageProcessingTime != nil {
 		s.imageProcessingTime.Record(ctx, duration,
@@ -232,6 +341,17 @@ func (s *ImageServiceImpl) validateCreateRequest(ctx context.Context, req *image
 	return s.processor.ValidateImage(ctx, data, req.ContentType)
 }
 
+// sanitizeSVG strips scripts and external references from an SVG upload. The sanitized
+// document replaces the upload, so the request's file size is updated to match it.
+func (s *ImageServiceImpl) sanitizeSVG(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (io.Reader, error) {
+	sanitized, err := s.processor.SanitizeSVG(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", image.ErrInvalidContentType, err)
+	}
+	req.FileSize = int64(len(sanitized))
+	return bytes.NewReader(sanitized), nil
+}
+
 func (s *ImageServiceImpl) storeImageFile(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (string, error) {
 	filename := s.generateUniqueFilename(req.OriginalFilename)
 	// Pass actual file size to storage layer to prevent MinIO SDK memory buffering
@@ -242,16 +362,193 @@ func (s *ImageServiceImpl) storeImageFile(ctx context.Context, req *image.Create
 	return storageResp, nil
 }
 
-func (s *ImageServiceImpl) processTags(ctx context.Context, tagNames []string) ([]image.Tag, error) {
+// uploadDetails is what is read from an upload while it is stored
+type uploadDetails struct {
+	photo       *image.PhotoMetadata
+	animation   *image.Animation // nil for still images
+	contentHash string           // SHA-256 of the stored bytes
+}
+
+// storeAndExtractMetadata stores the upload while reading its EXIF/XMP/IPTC metadata
+// and, for GIF and WebP, its animation frames from the same stream, so the file is
+// neither buffered nor read twice. Both are best effort: a file that cannot be parsed
+// is stored without them.
+//
+// Metadata is read from the original bytes, while the stored file has the metadata
+// the policy forbids removed on the way to storage. The content hash is taken of the
+// stored bytes, so it matches what a backfill computes from the object later.
+func (s *ImageServiceImpl) storeAndExtractMetadata(
+	ctx context.Context,
+	span trace.Span,
+	req *image.CreateImageRequest,
+	policy image.MetadataPolicy,
+	data io.Reader,
+) (string, uploadDetails, error) {
+	type extraction struct {
+		photo *image.PhotoMetadata
+		err   error
+	}
+
+	pr, pw := io.Pipe()
+	extracted := make(chan extraction, 1)
+	go func() {
+		photo, err := s.processor.ExtractMetadata(ctx, pr)
+		// Keep draining so the upload never blocks on the metadata reader
+		_, _ = io.Copy(io.Discard, pr) //nolint:errcheck // Pipe closed by the writer
+		extracted <- extraction{photo: photo, err: err}
+	}()
+
+	pipes := []*io.PipeWriter{pw}
+	var animated chan *image.Animation
+	if isAnimatable(req.ContentType) {
+		ar, aw := io.Pipe()
+		pipes = append(pipes, aw)
+		animated = make(chan *image.Animation, 1)
+		go func() {
+			info, err := s.processor.GetImageInfo(ctx, ar)
+			_, _ = io.Copy(io.Discard, ar) //nolint:errcheck // Pipe closed by the writer
+			if err != nil {
+				animated <- nil
+				return
+			}
+			animated <- info.Animation()
+		}()
+	}
+
+	sinks := make([]io.Writer, len(pipes))
+	for i, w := range pipes {
+		sinks[i] = w
+	}
+	var upload io.Reader = io.TeeReader(data, io.MultiWriter(sinks...))
+	if policy != image.MetadataPolicyKeep {
+		stripped := s.processor.StripMetadata(ctx, upload, policy)
+		defer func() { _ = stripped.Close() }() //nolint:errcheck // Resource cleanup
+		upload = stripped
+	}
+	hasher := sha256.New()
+	upload = io.TeeReader(upload, hasher)
+
+	storageResp, err := s.storeImageFile(ctx, req, upload)
+	for _, w := range pipes {
+		_ = w.CloseWithError(err) //nolint:errcheck // Always returns nil
+	}
+	result := <-extracted
+	details := uploadDetails{contentHash: hex.EncodeToString(hasher.Sum(nil))}
+	if animated != nil {
+		details.animation = <-animated
+	}
+	if err != nil {
+		return "", uploadDetails{}, err
+	}
+
+	if details.animation != nil {
+		span.SetAttributes(attribute.Int("image.frame_count", details.animation.FrameCount))
+	}
+	if result.err != nil {
+		span.AddEvent("metadata_extraction_failed", trace.WithAttributes(
+			attribute.String("error", result.err.Error()),
+		))
+		return storageResp, details, nil
+	}
+	if camera := result.photo.Camera(); camera != "" {
+		span.SetAttributes(attribute.String("image.camera", camera))
+	}
+	details.photo = result.photo
+	return storageResp, details, nil
+}
+
+// isAnimatable reports whether files of the content type may hold several frames
+func isAnimatable(contentType string) bool {
+	return contentType == "image/gif" || contentType == "image/webp"
+}
+
+// processTags resolves tag names under the tag policy. Names that the policy parks
+// for approval are returned separately as pending instead of becoming tags.
+func (s *ImageServiceImpl) processTags(ctx context.Context, tagNames []string) ([]image.Tag, []string, error) {
 	tags := make([]image.Tag, 0, len(tagNames))
+	var pending []string
+	seen := make(map[int]bool, len(tagNames))
 	for _, tagName := range tagNames {
-		tag, err := s.tagRepo.GetOrCreate(ctx, tagName)
+		tag, pendingName, err := s.resolveTag(ctx, tagName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create/get tag %s: %w", tagName, err)
+			return nil, nil, err
+		}
+		if tag == nil {
+			pending = append(pending, pendingName)
+			continue
+		}
+		// An alias and its canonical name resolve to the same tag
+		if seen[tag.ID] {
+			continue
 		}
+		seen[tag.ID] = true
 		tags = append(tags, *tag)
 	}
-	return tags, nil
+	return tags, pending, nil
+}
+
+// resolveTag maps a requested tag name to a tag the policy allows. When the policy
+// queues unknown tags instead, it returns a nil tag and the normalized name to queue.
+func (s *ImageServiceImpl) resolveTag(ctx context.Context, tagName string) (*image.Tag, string, error) {
+	tag, err := s.lookupTag(ctx, tagName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tag %s: %w", tagName, err)
+	}
+	if tag != nil && s.tagPolicy.Allows(tag) {
+		return tag, "", nil
+	}
+
+	name := ""
+	if tag != nil {
+		name = tag.Name
+	} else {
+		normalized, err := image.NewTag(tagName)
+		if err != nil {
+			return nil, "", err
+		}
+		name = normalized.Name
+	}
+
+	switch {
+	case s.tagPolicy.CreatesTags():
+		tag, err = s.tagRepo.GetOrCreate(ctx, name)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create/get tag %s: %w", name, err)
+		}
+		return tag, "", nil
+	case s.tagPolicy.QueuesTags():
+		return nil, name, nil
+	default:
+		return nil, "", fmt.Errorf("%w: %s", image.ErrTagNotAllowed, name)
+	}
+}
+
+// lookupTag finds an existing tag by name or alias, returning nil if there is none
+func (s *ImageServiceImpl) lookupTag(ctx context.Context, tagName string) (*image.Tag, error) {
+	tag, err := s.tagRepo.GetByName(ctx, strings.TrimSpace(strings.ToLower(tagName)))
+	if err != nil || tag != nil {
+		return tag, err
+	}
+	return s.tagRepo.ResolveAlias(ctx, tagName)
+}
+
+// queuePendingTags parks tags awaiting approval against the image. Failures are recorded
+// on the span but do not fail the operation, the image itself was saved.
+func (s *ImageServiceImpl) queuePendingTags(ctx context.Context, span trace.Span, img *image.Image, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	span.AddEvent("queueing_pending_tags", trace.WithAttributes(
+		attribute.StringSlice("tags.pending", names),
+	))
+	for _, name := range names {
+		if err := s.tagRepo.AddPendingTag(ctx, name, img.ID); err != nil {
+			span.RecordError(err)
+			continue
+		}
+		img.PendingTags = append(img.PendingTags, name)
+	}
 }
 
 func (s *ImageServiceImpl) buildImageObject(req *image.CreateImageRequest, storageResp string, tags []image.Tag) *image.Image {
@@ -265,6 +562,7 @@ func (s *ImageServiceImpl) buildImageObject(req *image.CreateImageRequest, stora
 		Width:            req.Width,
 		Height:           req.Height,
 		UploadedAt:       now,
+		TakenAt:          now,
 		Metadata:         req.Metadata,
 		CreatedAt:        now,
 		UpdatedAt:        now,
@@ -282,6 +580,137 @@ func (s *ImageServiceImpl) saveImageToDatabase(ctx context.Context, img *image.I
 	return nil
 }
 
+// generateAnimatedThumbnail stores a thumbnail of an animated GIF, which cards show
+// instead of the still renditions. Failures are recorded on the span only.
+func (s *ImageServiceImpl) generateAnimatedThumbnail(ctx context.Context, span trace.Span, img *image.Image) {
+	if img.ContentType != "image/gif" || !img.IsAnimated() {
+		return
+	}
+
+	span.AddEvent("generating_animated_thumbnail")
+	if err := s.storeAnimatedThumbnail(ctx, img); err != nil {
+		span.RecordError(err)
+	}
+}
+
+// storeAnimatedThumbnail stores the thumbnail of an animated GIF and records it on the
+// image. Whether it keeps the frames is up to the processor. Animated WebPs get none,
+// as there is no encoder for animated WebP to keep them in.
+func (s *ImageServiceImpl) storeAnimatedThumbnail(ctx context.Context, img *image.Image) error {
+	start := time.Now()
+	original, err := s.storage.Retrieve(ctx, img.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve image: %w", err)
+	}
+	defer func() { _ = original.Close() }() //nolint:errcheck // Resource cleanup
+
+	thumbnail, err := s.processor.GenerateThumbnail(ctx, original, animatedThumbnailSize, animatedThumbnailSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate thumbnail: %w", err)
+	}
+	data, err := io.ReadAll(thumbnail)
+	if err != nil {
+		return fmt.Errorf("failed to generate thumbnail: %w", err)
+	}
+
+	key := image.AnimatedThumbnailKey(img.ID)
+	if err := s.storage.StoreAt(ctx, key, "image/gif", bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+	if err := s.imageRepo.Patch(ctx, img.ID, &image.ImagePatch{ThumbnailPath: &key}); err != nil {
+		return fmt.Errorf("failed to record thumbnail: %w", err)
+	}
+	img.ThumbnailPath = &key
+
+	var width, height int
+	if info, err := s.processor.GetImageInfo(ctx, bytes.NewReader(data)); err == nil {
+		width, height = info.Width, info.Height
+	}
+	s.publishThumbnailGenerated(ctx, img.ID, key, int64(len(data)), width, height, start)
+	return nil
+}
+
+// publishThumbnailGenerated publishes a ThumbnailGeneratedEvent, when events are published
+func (s *ImageServiceImpl) publishThumbnailGenerated(ctx context.Context, id int, key string, size int64, width, height int, start time.Time) {
+	if s.eventPub == nil {
+		return
+	}
+	event := image.NewThumbnailGeneratedEvent(id, key, size, width, height, time.Since(start).Milliseconds())
+	if err := s.eventPub.PublishThumbnailGenerated(ctx, event); err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+}
+
+// generateVariants stores the responsive variants of a new image and records them on it.
+// Variants are best effort: an image without them is still served through its renditions.
+func (s *ImageServiceImpl) generateVariants(ctx context.Context, span trace.Span, img *image.Image) {
+	if len(s.widths()) == 0 || img.IsVector() {
+		return
+	}
+
+	span.AddEvent("generating_variants")
+	if err := s.storeVariants(ctx, img); err != nil {
+		span.RecordError(err)
+	}
+	span.SetAttributes(attribute.Int("image.variants", len(img.Variants)))
+}
+
+// widths returns the configured variant widths
+func (s *ImageServiceImpl) widths() []int {
+	if s.variantWidths == nil {
+		return image.DefaultVariantWidths
+	}
+	return s.variantWidths
+}
+
+// storeVariants stores the responsive variants of an image and records them on it,
+// replacing any stored before. Vector images scale in the browser and get none. The
+// variants that were stored are recorded even when others fail.
+func (s *ImageServiceImpl) storeVariants(ctx context.Context, img *image.Image) error {
+	widths := s.widths()
+	if len(widths) == 0 || img.IsVector() {
+		return nil
+	}
+
+	original, err := s.storage.Retrieve(ctx, img.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve image: %w", err)
+	}
+	defer func() { _ = original.Close() }() //nolint:errcheck // Resource cleanup
+
+	rendered, err := s.processor.GenerateVariants(ctx, original, widths)
+	if err != nil {
+		return fmt.Errorf("failed to generate variants: %w", err)
+	}
+
+	var storeErr error
+	variants := make([]image.ImageVariant, 0, len(rendered))
+	for _, r := range rendered {
+		variant := image.ImageVariant{
+			Width:       r.Width,
+			Height:      r.Height,
+			Format:      r.Format,
+			FileSize:    int64(len(r.Data)),
+			StoragePath: image.VariantStorageKey(img.ID, r.Width, r.Format),
+		}
+		if err := s.storage.StoreAt(ctx, variant.StoragePath, variant.ContentType(), bytes.NewReader(r.Data), variant.FileSize); err != nil {
+			storeErr = fmt.Errorf("failed to store variant: %w", err)
+			continue
+		}
+		variants = append(variants, variant)
+	}
+	if len(variants) == 0 {
+		return storeErr
+	}
+
+	// Stored objects without a row are still removed with the image's derivatives
+	if err := s.imageRepo.SaveVariants(ctx, img.ID, variants); err != nil {
+		return fmt.Errorf("failed to record variants: %w", err)
+	}
+	img.Variants = variants
+	return storeErr
+}
+
 func (s *ImageServiceImpl) handlePostCreation(ctx context.Context, img *image.Image) {
 	if s.cache != nil {
 		if err := s.cache.InvalidateImageLists(ctx); err != nil {
@@ -424,8 +853,123 @@ func generateListCacheKey(req *image.ListImagesRequest) string {
 		matchAllKey = "all"
 	}
 
-	return fmt.Sprintf("list_page_%d_pagesize_%d_tags_%s_match_%s",
-		req.Page, req.PageSize, tagsKey, matchAllKey)
+	return fmt.Sprintf("list_page_%d_pagesize_%d_tags_%s_match_%s_camera_%s_lens_%s_sort_%s_taken_%s_%s",
+		req.Page, req.PageSize, tagsKey, matchAllKey, req.Camera, req.Lens,
+		req.SortBy, cacheKeyTime(req.TakenAfter), cacheKeyTime(req.TakenBefore))
+}
+
+// cacheKeyTime formats an optional time bound for a cache key
+func cacheKeyTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// BackfillImage fills in the derived data of an image stored without it, as the rows
+// added for objects already in storage are: dimensions, content hash, capture details,
+// variants and the animated thumbnail. Only what is missing is computed, so running it
+// again on the same image does no work.
+func (s *ImageServiceImpl) BackfillImage(ctx context.Context, id int) (*image.Image, error) {
+	ctx, span := s.tracer.Start(ctx, "BackfillImage",
+		trace.WithAttributes(
+			attribute.Int("image.id", id),
+		),
+	)
+	defer span.End()
+
+	img, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get image")
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	data, err := s.retrieveOriginal(ctx, img)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to retrieve image")
+		return nil, err
+	}
+
+	patch := &image.ImagePatch{Metadata: map[string]json.RawMessage{}}
+	if img.ContentHash() == "" {
+		setContentHash(img, data)
+		maps.Copy(patch.Metadata, img.MetadataPatch(image.ContentHashMetadataKey))
+	}
+
+	if !img.IsVector() && (img.Width == nil || img.Height == nil) {
+		info, err := s.processor.GetImageInfo(ctx, bytes.NewReader(data))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to read dimensions")
+			return nil, fmt.Errorf("failed to read image dimensions: %w", err)
+		}
+		img.Width = &info.Width
+		img.Height = &info.Height
+		if err := img.SetAnimation(info.Animation()); err != nil {
+			span.RecordError(err)
+		}
+		patch.Width, patch.Height = img.Width, img.Height
+		maps.Copy(patch.Metadata, img.MetadataPatch(image.AnimationMetadataKey))
+	}
+
+	if img.Photo() == nil {
+		photo, err := s.processor.ExtractMetadata(ctx, bytes.NewReader(data))
+		if err != nil {
+			span.AddEvent("metadata_extraction_failed", trace.WithAttributes(
+				attribute.String("error", err.Error()),
+			))
+		}
+		if photo = s.metadataPolicy.Stricter(img.MetadataPolicy()).Apply(photo); photo != nil {
+			if err := img.SetPhoto(photo); err != nil {
+				span.RecordError(err)
+			}
+			if photo.TakenAt != nil {
+				img.TakenAt = *photo.TakenAt
+			}
+			captured := photoPatch(img)
+			maps.Copy(patch.Metadata, captured.Metadata)
+			patch.TakenAt = captured.TakenAt
+		}
+	}
+
+	img.UpdatedAt = time.Now()
+	if err := s.imageRepo.Patch(ctx, img.ID, patch); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database update failed")
+		return nil, fmt.Errorf("failed to update image in database: %w", err)
+	}
+
+	if len(img.Variants) == 0 {
+		s.generateVariants(ctx, span, img)
+	}
+	if img.ThumbnailPath == nil {
+		s.generateAnimatedThumbnail(ctx, span, img)
+	}
+
+	s.handlePostUpdate(ctx, id, img)
+
+	span.SetStatus(codes.Ok, "")
+	return img, nil
+}
+
+// retrieveOriginal reads an image's stored file, which may be no larger than an upload
+func (s *ImageServiceImpl) retrieveOriginal(ctx context.Context, img *image.Image) ([]byte, error) {
+	original, err := s.storage.Retrieve(ctx, img.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve image: %w", err)
+	}
+	defer func() { _ = original.Close() }() //nolint:errcheck // Resource cleanup
+
+	data, err := io.ReadAll(io.LimitReader(original, image.MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > image.MaxFileSize {
+		return nil, fmt.Errorf("%w: file size too large (max %d bytes)", image.ErrInvalidFileSize, image.MaxFileSize)
+	}
+	return data, nil
 }
 
 // UpdateImage modifies an existing image
@@ -443,7 +987,7 @@ func (s *ImageServiceImpl) UpdateImage(ctx context.Context, id int, req *image.U
 		return nil, fmt.Errorf("failed to get existing image: %w", err)
 	}
 
-	tags, err := s.processTags(ctx, req.Tags)
+	tags, pendingTags, err := s.processTags(ctx, req.Tags)
 	if err != nil {
 		return nil, err
 	}
@@ -459,6 +1003,8 @@ func (s *ImageServiceImpl) UpdateImage(ctx context.Context, id int, req *image.U
 		return nil, fmt.Errorf("failed to update image in database: %w", err)
 	}
 
+	s.queuePendingTags(ctx, trace.SpanFromContext(ctx), existing, pendingTags)
+
 	s.handlePostUpdate(ctx, id, existing)
 
 	return existing, nil
@@ -480,6 +1026,156 @@ func (s *ImageServiceImpl) handlePostUpdate(ctx context.Context, id int, img *im
 	}
 }
 
+// AttachTag adds a single tag to an image without touching its other tags
+func (s *ImageServiceImpl) AttachTag(ctx context.Context, id int, tagName string) (*image.Image, error) {
+	ctx, span := s.tracer.Start(ctx, "AttachTag",
+		trace.WithAttributes(
+			attribute.Int("image.id", id),
+			attribute.String("tag.name", tagName),
+		),
+	)
+	defer span.End()
+
+	img, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image not found")
+		return nil, fmt.Errorf("%w: %v", image.ErrImageNotFound, err)
+	}
+
+	span.AddEvent("resolving_tag")
+	tag, pendingName, err := s.resolveTag(ctx, tagName)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tag processing failed")
+		return nil, err
+	}
+	if tag == nil {
+		// The tag policy parks the tag for approval; the image is returned unchanged
+		span.AddEvent("queueing_pending_tag")
+		if err := s.tagRepo.AddPendingTag(ctx, pendingName, id); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "tag queueing failed")
+			return nil, err
+		}
+		if err := s.loadImageTags(ctx, img); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "tag reload failed")
+			return nil, err
+		}
+		img.PendingTags = []string{pendingName}
+		span.SetStatus(codes.Ok, "")
+		return img, nil
+	}
+
+	span.AddEvent("attaching_tag")
+	attached, err := s.tagRepo.AddToImage(ctx, id, tag.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tag attachment failed")
+		return nil, err
+	}
+	span.SetAttributes(attribute.Bool("tag.attached", attached))
+
+	if err := s.loadImageTags(ctx, img); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tag reload failed")
+		return nil, err
+	}
+
+	if attached {
+		s.handlePostTagChange(ctx, id)
+		if s.eventPub != nil {
+			if err := s.eventPub.PublishTagAttached(ctx, image.NewTagAttachedEvent(id, tag.ID, tag.Name, "")); err != nil {
+				_ = err
+			}
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return img, nil
+}
+
+// DetachTag removes a single tag from an image without touching its other tags
+func (s *ImageServiceImpl) DetachTag(ctx context.Context, id int, tagName string) (*image.Image, error) {
+	ctx, span := s.tracer.Start(ctx, "DetachTag",
+		trace.WithAttributes(
+			attribute.Int("image.id", id),
+			attribute.String("tag.name", tagName),
+		),
+	)
+	defer span.End()
+
+	img, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image not found")
+		return nil, fmt.Errorf("%w: %v", image.ErrImageNotFound, err)
+	}
+
+	tag, err := s.lookupTag(ctx, tagName)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tag lookup failed")
+		return nil, fmt.Errorf("failed to get tag %s: %w", tagName, err)
+	}
+
+	detached := false
+	if tag != nil {
+		span.AddEvent("detaching_tag")
+		detached, err = s.tagRepo.RemoveFromImage(ctx, id, tag.ID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "tag detachment failed")
+			return nil, err
+		}
+	}
+	span.SetAttributes(attribute.Bool("tag.detached", detached))
+
+	if err := s.loadImageTags(ctx, img); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tag reload failed")
+		return nil, err
+	}
+
+	if detached {
+		s.handlePostTagChange(ctx, id)
+		if s.eventPub != nil {
+			if err := s.eventPub.PublishTagDetached(ctx, image.NewTagDetachedEvent(id, tag.ID, tag.Name, "")); err != nil {
+				_ = err
+			}
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return img, nil
+}
+
+// loadImageTags refreshes the tags of an image from the tag repository
+func (s *ImageServiceImpl) loadImageTags(ctx context.Context, img *image.Image) error {
+	tags, err := s.tagRepo.GetImageTags(ctx, img.ID)
+	if err != nil {
+		return err
+	}
+
+	img.Tags = make([]image.Tag, len(tags))
+	for i, tag := range tags {
+		img.Tags[i] = *tag
+	}
+	return nil
+}
+
+func (s *ImageServiceImpl) handlePostTagChange(ctx context.Context, id int) {
+	if s.cache != nil {
+		if err := s.cache.DeleteImage(ctx, id); err != nil {
+			_ = err
+		}
+		if err := s.cache.InvalidateImageLists(ctx); err != nil {
+			_ = err
+		}
+	}
+}
+
 // DeleteImage removes an image and its associated files
 func (s *ImageServiceImpl) DeleteImage(ctx context.Context, id int) error {
 	startTime := time.Now()
@@ -525,7 +1221,7 @@ func (s *ImageServiceImpl) DeleteImage(ctx context.Context, id int) error {
 	}
 
 	span.AddEvent("cleaning_up_storage")
-	s.cleanupStorage(ctx, img.StoragePath)
+	s.cleanupStorage(ctx, img.ID, img.StoragePath)
 
 	span.AddEvent("post_deletion_cleanup")
 	s.handlePostDeletion(ctx, id)
@@ -554,12 +1250,15 @@ func (s *ImageServiceImpl) recordDeletionMetrics(ctx context.Context, duration f
 	}
 }
 
-func (s *ImageServiceImpl) cleanupStorage(ctx context.Context, storagePath string) {
+func (s *ImageServiceImpl) cleanupStorage(ctx context.Context, id int, storagePath string) {
 	if storagePath != "" {
 		if err := s.storage.Delete(ctx, storagePath); err != nil {
 			_ = err
 		}
 	}
+	if err := s.storage.DeletePrefix(ctx, image.DerivativeKeyPrefix(id)); err != nil {
+		_ = err
+	}
 }
 
 func (s *ImageServiceImpl) handlePostDeletion(ctx context.Context, id int) {
@@ -590,11 +1289,149 @@ func (s *ImageServiceImpl) GenerateImageURL(ctx context.Context, id int, expiry
 	return "", nil
 }
 
+// RenderImage returns a resized derivative of an image. Derivatives are rendered from the
+// stored original on first request and cached in the bucket under a deterministic key.
+func (s *ImageServiceImpl) RenderImage(ctx context.Context, id int, opts image.RenderOptions) (io.ReadCloser, error) {
+	ctx, span := s.tracer.Start(ctx, "RenderImage",
+		trace.WithAttributes(
+			attribute.Int("image.id", id),
+			attribute.Int("render.width", opts.Width),
+			attribute.Int("render.height", opts.Height),
+			attribute.String("render.fit", string(opts.Fit)),
+			attribute.String("render.format", string(opts.Format)),
+		),
+	)
+	defer span.End()
+
+	if err := opts.Validate(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid render options")
+		return nil, err
+	}
+
+	img, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image not found")
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	key := opts.StorageKey(img.ID)
+	span.SetAttributes(attribute.String("render.key", key))
+
+	if exists, err := s.storage.Exists(ctx, key); err == nil && exists {
+		if cached, err := s.storage.Retrieve(ctx, key); err == nil {
+			span.AddEvent("derivative_cache_hit")
+			span.SetStatus(codes.Ok, "")
+			return cached, nil
+		}
+	}
+
+	span.AddEvent("rendering_derivative")
+	original, err := s.storage.Retrieve(ctx, img.StoragePath)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to retrieve original")
+		return nil, fmt.Errorf("failed to retrieve image: %w", err)
+	}
+	defer func() { _ = original.Close() }() //nolint:errcheck // Resource cleanup
+
+	rendered, err := s.processor.Render(ctx, original, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "render failed")
+		return nil, fmt.Errorf("failed to render image: %w", err)
+	}
+	data, err := io.ReadAll(rendered)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "render failed")
+		return nil, fmt.Errorf("failed to render image: %w", err)
+	}
+
+	// A failed cache write only costs a re-render on the next request
+	span.AddEvent("caching_derivative")
+	if err := s.storage.StoreAt(ctx, key, opts.ContentType(), bytes.NewReader(data), int64(len(data))); err != nil {
+		span.RecordError(err)
+	}
+
+	span.SetAttributes(attribute.Int("render.size", len(data)))
+	span.SetStatus(codes.Ok, "")
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
 // GetImageStats returns statistics about images
 func (s *ImageServiceImpl) GetImageStats(ctx context.Context) (*image.ImageStats, error) {
-	// TODO: Implement stats calculation
-	return &image.ImageStats{
-		TotalImages: 0,
-		TotalSize:   0,
-	}, nil
+	// Images per month come from the same capture-date buckets as the timeline
+	months, err := s.imageRepo.Timeline(ctx, image.TimelineMonth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images per month: %w", err)
+	}
+
+	// TODO: Implement the remaining stats
+	stats := &image.ImageStats{
+		ImagesPerMonth: make(map[string]int64, len(months)),
+	}
+	for _, month := range months {
+		stats.ImagesPerMonth[month.Period] = int64(month.Count)
+		stats.TotalImages += int64(month.Count)
+	}
+	return stats, nil
+}
+
+// GetTimeline counts images per capture year, month or day, newest period first
+func (s *ImageServiceImpl) GetTimeline(ctx context.Context, granularity image.TimelineGranularity) ([]image.TimelineBucket, error) {
+	ctx, span := s.tracer.Start(ctx, "GetTimeline",
+		trace.WithAttributes(attribute.String("timeline.granularity", string(granularity))),
+	)
+	defer span.End()
+
+	if !granularity.IsValid() {
+		err := fmt.Errorf("%w: granularity must be year, month or day", image.ErrInvalidListFilter)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid granularity")
+		return nil, err
+	}
+
+	buckets, err := s.imageRepo.Timeline(ctx, granularity)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "timeline query failed")
+		return nil, fmt.Errorf("failed to get timeline: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("timeline.buckets", len(buckets)))
+	span.SetStatus(codes.Ok, "")
+	return buckets, nil
+}
+
+// GetGeoClusters groups the geotagged images inside bounds by proximity at a map zoom level
+func (s *ImageServiceImpl) GetGeoClusters(ctx context.Context, bounds image.GeoBounds, zoom int) ([]image.GeoCluster, error) {
+	ctx, span := s.tracer.Start(ctx, "GetGeoClusters",
+		trace.WithAttributes(attribute.Int("geo.zoom", zoom)),
+	)
+	defer span.End()
+
+	if err := bounds.Validate(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid bbox")
+		return nil, err
+	}
+	if zoom < 0 || zoom > image.MaxGeoZoom {
+		err := fmt.Errorf("%w: zoom must be between 0 and %d", image.ErrInvalidListFilter, image.MaxGeoZoom)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid zoom")
+		return nil, err
+	}
+
+	clusters, err := s.imageRepo.GeoClusters(ctx, bounds, zoom)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "geo query failed")
+		return nil, fmt.Errorf("failed to get geo clusters: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("geo.clusters", len(clusters)))
+	span.SetStatus(codes.Ok, "")
+	return clusters, nil
 }
@@ -0,0 +1,272 @@
+package implementations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"image-gallery/internal/domain/image"
+)
+
+// rejectingValidator fails every upload
+type rejectingValidator struct {
+	image.ValidationService
+}
+
+func (rejectingValidator) ValidateImageUpload(context.Context, *image.CreateImageRequest) error {
+	return image.ErrInvalidImageData
+}
+
+// recordStages returns a context reporting upload stages into stages
+func recordStages(stages *[]image.UploadStage) context.Context {
+	return image.WithUploadStageReporter(context.Background(), func(stage image.UploadStage) {
+		*stages = append(*stages, stage)
+	})
+}
+
+func TestImageService_CreateImage_ReportsUploadStages(t *testing.T) {
+	service := NewImageService(nil, nil, nil, nil, rejectingValidator{}, nil, nil)
+
+	var stages []image.UploadStage
+	_, err := service.CreateImage(recordStages(&stages), &image.CreateImageRequest{
+		OriginalFilename: "a.png",
+		ContentType:      "image/png",
+		FileSize:         4,
+	}, strings.NewReader("data"))
+
+	require.ErrorIs(t, err, image.ErrInvalidImageData)
+	assert.Equal(t, []image.UploadStage{image.UploadStageValidating}, stages, "no stage past the one that failed is reported")
+}
+
+func TestAddCreateEvent(t *testing.T) {
+	var stages []image.UploadStage
+	ctx := recordStages(&stages)
+	_, span := noop.NewTracerProvider().Tracer("test").Start(ctx, "test")
+
+	for _, name := range []string{"validating_request", "processing_tags", "storing_image_file", "saving_to_database", "image_created_successfully"} {
+		addCreateEvent(ctx, span, name)
+	}
+
+	assert.Equal(t, []image.UploadStage{
+		image.UploadStageValidating,
+		image.UploadStageValidating,
+		image.UploadStageStoring,
+		image.UploadStageProcessing,
+	}, stages, "events without a stage report nothing")
+
+	t.Run("without a reporter", func(t *testing.T) {
+		assert.NotPanics(t, func() { addCreateEvent(context.Background(), span, "storing_image_file") })
+	})
+}
+
+// patchingRepository records the patches written to the images it returns
+type patchingRepository struct {
+	image.Repository
+	img     *image.Image
+	patches []*image.ImagePatch
+}
+
+func (r *patchingRepository) GetByID(context.Context, int) (*image.Image, error) {
+	img := *r.img
+	return &img, nil
+}
+
+func (r *patchingRepository) Patch(_ context.Context, _ int, patch *image.ImagePatch) error {
+	r.patches = append(r.patches, patch)
+	return nil
+}
+
+// originalStorage serves the same stored file at every path
+type originalStorage struct {
+	image.StorageService
+	data string
+}
+
+func (s originalStorage) Retrieve(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(s.data)), nil
+}
+
+func TestImageService_ProcessImage_HashStepPatchesOnlyTheHash(t *testing.T) {
+	repo := &patchingRepository{img: &image.Image{
+		ID:          7,
+		ContentType: "image/png",
+		StoragePath: "images/a.png",
+		Metadata:    []byte(`{"photo":{"camera_make":"Canon"}}`),
+	}}
+	service := NewImageService(repo, nil, originalStorage{data: "data"}, nil, nil, nil, nil)
+
+	require.NoError(t, service.ProcessImage(context.Background(), 7, image.JobHash))
+
+	require.Len(t, repo.patches, 1)
+	patch := repo.patches[0]
+	assert.Nil(t, patch.Width)
+	assert.Nil(t, patch.TakenAt)
+	assert.Nil(t, patch.ThumbnailPath)
+	assert.Equal(t, []string{image.ContentHashMetadataKey}, slices.Collect(maps.Keys(patch.Metadata)),
+		"the photo written by the metadata step is left alone")
+	assert.JSONEq(t, `"3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"`,
+		string(patch.Metadata[image.ContentHashMetadataKey]))
+}
+
+// MockTagRepository is a mock implementation of the image.TagRepository methods the
+// single tag endpoints use
+type MockTagRepository struct {
+	image.TagRepository
+	mock.Mock
+}
+
+func (m *MockTagRepository) GetByName(ctx context.Context, name string) (*image.Tag, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*image.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) ResolveAlias(ctx context.Context, alias string) (*image.Tag, error) {
+	args := m.Called(ctx, alias)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*image.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) AddToImage(ctx context.Context, imageID, tagID int) (bool, error) {
+	args := m.Called(ctx, imageID, tagID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTagRepository) RemoveFromImage(ctx context.Context, imageID, tagID int) (bool, error) {
+	args := m.Called(ctx, imageID, tagID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTagRepository) GetImageTags(ctx context.Context, imageID int) ([]*image.Tag, error) {
+	args := m.Called(ctx, imageID)
+	return args.Get(0).([]*image.Tag), args.Error(1)
+}
+
+// MockEventPublisher is a mock implementation of the tag events of image.EventPublisher
+type MockEventPublisher struct {
+	image.EventPublisher
+	mock.Mock
+}
+
+func (m *MockEventPublisher) PublishTagAttached(ctx context.Context, event *image.TagAttachedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventPublisher) PublishTagDetached(ctx context.Context, event *image.TagDetachedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func newTagTestService() (image.ImageService, *MockTagRepository, *MockEventPublisher) {
+	tags := &MockTagRepository{}
+	events := &MockEventPublisher{}
+	repo := &patchingRepository{img: &image.Image{ID: 7, ContentType: "image/png"}}
+	return NewImageService(repo, tags, nil, nil, nil, events, nil), tags, events
+}
+
+func TestImageService_AttachTag(t *testing.T) {
+	ctx := context.Background()
+	sunset := &image.Tag{ID: 3, Name: "sunset"}
+
+	t.Run("attaches a tag", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "sunset").Return(sunset, nil)
+		tags.On("AddToImage", mock.Anything, 7, 3).Return(true, nil)
+		tags.On("GetImageTags", mock.Anything, 7).Return([]*image.Tag{sunset}, nil)
+		events.On("PublishTagAttached", mock.Anything, mock.MatchedBy(func(e *image.TagAttachedEvent) bool {
+			return e.ImageID == 7 && e.TagID == 3 && e.TagName == "sunset"
+		})).Return(nil).Once()
+
+		img, err := service.AttachTag(ctx, 7, "Sunset")
+		require.NoError(t, err)
+		assert.Equal(t, []image.Tag{*sunset}, img.Tags)
+		tags.AssertExpectations(t)
+		events.AssertExpectations(t)
+	})
+
+	t.Run("re-attaching a tag changes nothing", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "sunset").Return(sunset, nil)
+		tags.On("AddToImage", mock.Anything, 7, 3).Return(false, nil)
+		tags.On("GetImageTags", mock.Anything, 7).Return([]*image.Tag{sunset}, nil)
+
+		img, err := service.AttachTag(ctx, 7, "sunset")
+		require.NoError(t, err)
+		assert.Equal(t, []image.Tag{*sunset}, img.Tags)
+		events.AssertNotCalled(t, "PublishTagAttached", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses a tag past the limit", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "sunset").Return(sunset, nil)
+		// The repository counts the image's tags with its row locked FOR UPDATE
+		tags.On("AddToImage", mock.Anything, 7, 3).Return(false,
+			fmt.Errorf("%w: image already has %d tags (max %d)", image.ErrTagLimitExceeded, image.MaxTagsPerImage, image.MaxTagsPerImage))
+
+		_, err := service.AttachTag(ctx, 7, "sunset")
+		assert.ErrorIs(t, err, image.ErrTagLimitExceeded)
+		tags.AssertNotCalled(t, "GetImageTags", mock.Anything, mock.Anything)
+		events.AssertNotCalled(t, "PublishTagAttached", mock.Anything, mock.Anything)
+	})
+}
+
+func TestImageService_DetachTag(t *testing.T) {
+	ctx := context.Background()
+	sunset := &image.Tag{ID: 3, Name: "sunset"}
+	beach := &image.Tag{ID: 4, Name: "beach"}
+
+	t.Run("detaches a tag", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "sunset").Return(sunset, nil)
+		tags.On("RemoveFromImage", mock.Anything, 7, 3).Return(true, nil)
+		tags.On("GetImageTags", mock.Anything, 7).Return([]*image.Tag{beach}, nil)
+		events.On("PublishTagDetached", mock.Anything, mock.MatchedBy(func(e *image.TagDetachedEvent) bool {
+			return e.ImageID == 7 && e.TagID == 3 && e.TagName == "sunset"
+		})).Return(nil).Once()
+
+		img, err := service.DetachTag(ctx, 7, "sunset")
+		require.NoError(t, err)
+		assert.Equal(t, []image.Tag{*beach}, img.Tags)
+		tags.AssertExpectations(t)
+		events.AssertExpectations(t)
+	})
+
+	t.Run("detaching a tag that is not attached changes nothing", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "sunset").Return(sunset, nil)
+		tags.On("RemoveFromImage", mock.Anything, 7, 3).Return(false, nil)
+		tags.On("GetImageTags", mock.Anything, 7).Return([]*image.Tag{beach}, nil)
+
+		img, err := service.DetachTag(ctx, 7, "sunset")
+		require.NoError(t, err)
+		assert.Equal(t, []image.Tag{*beach}, img.Tags)
+		events.AssertNotCalled(t, "PublishTagDetached", mock.Anything, mock.Anything)
+	})
+
+	t.Run("detaching an unknown tag changes nothing", func(t *testing.T) {
+		service, tags, events := newTagTestService()
+		tags.On("GetByName", mock.Anything, "dusk").Return(nil, nil)
+		tags.On("ResolveAlias", mock.Anything, "dusk").Return(nil, nil)
+		tags.On("GetImageTags", mock.Anything, 7).Return([]*image.Tag{beach}, nil)
+
+		img, err := service.DetachTag(ctx, 7, "dusk")
+		require.NoError(t, err)
+		assert.Equal(t, []image.Tag{*beach}, img.Tags)
+		tags.AssertNotCalled(t, "RemoveFromImage", mock.Anything, mock.Anything, mock.Anything)
+		events.AssertNotCalled(t, "PublishTagDetached", mock.Anything, mock.Anything)
+	})
+}
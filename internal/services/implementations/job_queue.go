@@ -0,0 +1,176 @@
+package implementations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/database"
+)
+
+// DefaultJobMaxAttempts is how many times a job is tried before it is dead-lettered
+const DefaultJobMaxAttempts = 5
+
+// Page sizes of failed job listings
+const (
+	defaultFailedJobsPage = 50
+	maxFailedJobsPage     = 1000
+)
+
+// JobQueueImpl implements the image.JobQueue interface on the processing_jobs table
+type JobQueueImpl struct {
+	dbJobRepo   database.JobRepository
+	maxAttempts int
+	ready       chan struct{} // Signalled on enqueue, so idle workers need not wait to poll
+}
+
+// NewJobQueue creates a job queue whose jobs are tried up to maxAttempts times, or
+// DefaultJobMaxAttempts when it is zero
+func NewJobQueue(db *sql.DB, maxAttempts int) image.JobQueue {
+	return newJobQueue(database.NewJobRepository(db), maxAttempts)
+}
+
+func newJobQueue(repo database.JobRepository, maxAttempts int) *JobQueueImpl {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultJobMaxAttempts
+	}
+	return &JobQueueImpl{
+		dbJobRepo:   repo,
+		maxAttempts: maxAttempts,
+		ready:       make(chan struct{}, 1),
+	}
+}
+
+// Enqueue queues jobs of the given types for an image
+func (q *JobQueueImpl) Enqueue(ctx context.Context, imageID int, types ...image.JobType) error {
+	for _, jobType := range types {
+		if !jobType.IsValid() {
+			return fmt.Errorf("unknown job type %q", jobType)
+		}
+		if err := q.dbJobRepo.Enqueue(ctx, imageID, string(jobType), q.maxAttempts); err != nil {
+			return fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+		}
+	}
+
+	q.signalReady()
+	return nil
+}
+
+// Ready is signalled when jobs have been queued by this process
+func (q *JobQueueImpl) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Claim takes up to limit due jobs for a worker
+func (q *JobQueueImpl) Claim(ctx context.Context, limit int, lease time.Duration) ([]*image.ProcessingJob, error) {
+	dbJobs, err := q.dbJobRepo.Claim(ctx, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	jobs := make([]*image.ProcessingJob, len(dbJobs))
+	for i, dbJob := range dbJobs {
+		jobs[i] = fromDBJob(dbJob)
+	}
+	return jobs, nil
+}
+
+// Complete records that a job succeeded
+func (q *JobQueueImpl) Complete(ctx context.Context, id int64, attempt int) error {
+	if err := q.dbJobRepo.Complete(ctx, id, attempt); err != nil {
+		return jobOutcomeError("complete", id, err)
+	}
+	return nil
+}
+
+// Retry records a failed attempt and queues the job again at runAt
+func (q *JobQueueImpl) Retry(ctx context.Context, id int64, attempt int, lastError string, runAt time.Time) error {
+	if err := q.dbJobRepo.Retry(ctx, id, attempt, lastError, runAt); err != nil {
+		return jobOutcomeError("retry", id, err)
+	}
+	return nil
+}
+
+// Bury moves a job to the dead-letter state
+func (q *JobQueueImpl) Bury(ctx context.Context, id int64, attempt int, lastError string) error {
+	if err := q.dbJobRepo.Bury(ctx, id, attempt, lastError); err != nil {
+		return jobOutcomeError("bury", id, err)
+	}
+	return nil
+}
+
+// jobOutcomeError wraps an error recording a job's outcome
+func jobOutcomeError(action string, id int64, err error) error {
+	if errors.Is(err, database.ErrJobLeaseLost) {
+		return fmt.Errorf("%w: %d", image.ErrJobLeaseLost, id)
+	}
+	return fmt.Errorf("failed to %s job %d: %w", action, id, err)
+}
+
+// ListFailed returns a page of the dead-lettered jobs, most recently failed first, with
+// their total count
+func (q *JobQueueImpl) ListFailed(ctx context.Context, limit, offset int) ([]*image.ProcessingJob, int, error) {
+	if limit <= 0 || limit > maxFailedJobsPage {
+		limit = defaultFailedJobsPage
+	}
+	offset = max(offset, 0)
+
+	dbJobs, err := q.dbJobRepo.ListDead(ctx, database.PaginationParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list failed jobs: %w", err)
+	}
+	total, err := q.dbJobRepo.CountDead(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+
+	jobs := make([]*image.ProcessingJob, len(dbJobs))
+	for i, dbJob := range dbJobs {
+		jobs[i] = fromDBJob(dbJob)
+	}
+	return jobs, total, nil
+}
+
+// Requeue queues a failed job again and wakes up an idle worker
+func (q *JobQueueImpl) Requeue(ctx context.Context, id int64) error {
+	if err := q.dbJobRepo.Requeue(ctx, id); err != nil {
+		if errors.Is(err, database.ErrJobNotFound) {
+			return fmt.Errorf("%w: %d", image.ErrJobNotFound, id)
+		}
+		return fmt.Errorf("failed to requeue job %d: %w", id, err)
+	}
+
+	q.signalReady()
+	return nil
+}
+
+// signalReady wakes up a worker waiting for jobs
+func (q *JobQueueImpl) signalReady() {
+	select {
+	case q.ready <- struct{}{}:
+	default: // A wakeup is already pending
+	}
+}
+
+func fromDBJob(dbJob *database.ProcessingJob) *image.ProcessingJob {
+	job := &image.ProcessingJob{
+		ID:          dbJob.ID,
+		ImageID:     dbJob.ImageID,
+		Type:        image.JobType(dbJob.Type),
+		Status:      image.JobStatus(dbJob.Status),
+		Attempts:    dbJob.Attempts,
+		MaxAttempts: dbJob.MaxAttempts,
+		RunAt:       dbJob.RunAt,
+		CreatedAt:   dbJob.CreatedAt,
+		UpdatedAt:   dbJob.UpdatedAt,
+
+		OriginalFilename: dbJob.OriginalFilename,
+	}
+	if dbJob.LastError != nil {
+		job.LastError = *dbJob.LastError
+	}
+	return job
+}
@@ -0,0 +1,176 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/database"
+)
+
+// MockDatabaseJobRepository is a mock implementation of database.JobRepository
+type MockDatabaseJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockDatabaseJobRepository) Enqueue(ctx context.Context, imageID int, jobType string, maxAttempts int) error {
+	return m.Called(ctx, imageID, jobType, maxAttempts).Error(0)
+}
+
+func (m *MockDatabaseJobRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*database.ProcessingJob, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*database.ProcessingJob), args.Error(1)
+}
+
+func (m *MockDatabaseJobRepository) Complete(ctx context.Context, id int64, attempt int) error {
+	return m.Called(ctx, id, attempt).Error(0)
+}
+
+func (m *MockDatabaseJobRepository) Retry(ctx context.Context, id int64, attempt int, lastError string, runAt time.Time) error {
+	return m.Called(ctx, id, attempt, lastError, runAt).Error(0)
+}
+
+func (m *MockDatabaseJobRepository) Bury(ctx context.Context, id int64, attempt int, lastError string) error {
+	return m.Called(ctx, id, attempt, lastError).Error(0)
+}
+
+func (m *MockDatabaseJobRepository) ListLatest(ctx context.Context, imageIDs []int) ([]*database.ProcessingJob, error) {
+	args := m.Called(ctx, imageIDs)
+	return args.Get(0).([]*database.ProcessingJob), args.Error(1)
+}
+
+func (m *MockDatabaseJobRepository) ListDead(ctx context.Context, pagination database.PaginationParams) ([]*database.ProcessingJob, error) {
+	args := m.Called(ctx, pagination)
+	return args.Get(0).([]*database.ProcessingJob), args.Error(1)
+}
+
+func (m *MockDatabaseJobRepository) CountDead(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDatabaseJobRepository) Requeue(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestJobQueue_Enqueue(t *testing.T) {
+	t.Run("queues each type and signals the workers", func(t *testing.T) {
+		mockDB := &MockDatabaseJobRepository{}
+		queue := newJobQueue(mockDB, 0)
+		ctx := context.Background()
+
+		mockDB.On("Enqueue", ctx, 7, "variants", DefaultJobMaxAttempts).Return(nil)
+		mockDB.On("Enqueue", ctx, 7, "thumbnail", DefaultJobMaxAttempts).Return(nil)
+
+		require.NoError(t, queue.Enqueue(ctx, 7, image.JobVariants, image.JobThumbnail))
+		require.NoError(t, queue.Enqueue(ctx, 7, image.JobVariants))
+
+		mockDB.AssertExpectations(t)
+		select {
+		case <-queue.Ready():
+		default:
+			t.Fatal("expected a wakeup after enqueueing")
+		}
+	})
+
+	t.Run("rejects unknown types", func(t *testing.T) {
+		mockDB := &MockDatabaseJobRepository{}
+		queue := newJobQueue(mockDB, 3)
+
+		assert.Error(t, queue.Enqueue(context.Background(), 7, image.JobType("sharpen")))
+		mockDB.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestJobQueue_Claim(t *testing.T) {
+	mockDB := &MockDatabaseJobRepository{}
+	queue := newJobQueue(mockDB, 3)
+	ctx := context.Background()
+
+	lastError := "object not found"
+	mockDB.On("Claim", ctx, 2, time.Minute).Return([]*database.ProcessingJob{
+		{ID: 1, ImageID: 7, Type: "hash", Status: "running", Attempts: 1, MaxAttempts: 3},
+		{ID: 2, ImageID: 8, Type: "thumbnail", Status: "running", Attempts: 2, MaxAttempts: 3, LastError: &lastError},
+	}, nil)
+
+	jobs, err := queue.Claim(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, image.JobHash, jobs[0].Type)
+	assert.Equal(t, image.JobRunning, jobs[0].Status)
+	assert.Empty(t, jobs[0].LastError)
+	assert.Equal(t, "object not found", jobs[1].LastError)
+	assert.Equal(t, 2, jobs[1].Attempts)
+}
+
+func TestJobQueue_RecordOutcome(t *testing.T) {
+	mockDB := &MockDatabaseJobRepository{}
+	queue := newJobQueue(mockDB, 3)
+	ctx := context.Background()
+	runAt := time.Now()
+
+	mockDB.On("Complete", ctx, int64(1), 2).Return(nil)
+	mockDB.On("Retry", ctx, int64(2), 1, "timeout", runAt).Return(database.ErrJobLeaseLost)
+	mockDB.On("Bury", ctx, int64(3), 3, "corrupt image").Return(errors.New("connection reset"))
+
+	require.NoError(t, queue.Complete(ctx, 1, 2))
+	assert.ErrorIs(t, queue.Retry(ctx, 2, 1, "timeout", runAt), image.ErrJobLeaseLost)
+	err := queue.Bury(ctx, 3, 3, "corrupt image")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, image.ErrJobLeaseLost)
+	mockDB.AssertExpectations(t)
+}
+
+func TestJobQueue_ListFailed(t *testing.T) {
+	mockDB := &MockDatabaseJobRepository{}
+	queue := newJobQueue(mockDB, 3)
+	ctx := context.Background()
+
+	lastError := "corrupt image"
+	mockDB.On("ListDead", ctx, database.PaginationParams{Limit: defaultFailedJobsPage}).Return([]*database.ProcessingJob{
+		{ID: 4, ImageID: 7, Type: "variants", Status: "dead", Attempts: 3, MaxAttempts: 3, LastError: &lastError, OriginalFilename: "photo.jpg"},
+	}, nil)
+	mockDB.On("CountDead", ctx).Return(1, nil)
+
+	jobs, total, err := queue.ListFailed(ctx, 0, -5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, image.JobDead, jobs[0].Status)
+	assert.Equal(t, "corrupt image", jobs[0].LastError)
+	assert.Equal(t, "photo.jpg", jobs[0].OriginalFilename)
+	mockDB.AssertExpectations(t)
+}
+
+func TestJobQueue_Requeue(t *testing.T) {
+	t.Run("queues the job again and signals the workers", func(t *testing.T) {
+		mockDB := &MockDatabaseJobRepository{}
+		queue := newJobQueue(mockDB, 3)
+		ctx := context.Background()
+
+		mockDB.On("Requeue", ctx, int64(4)).Return(nil)
+
+		require.NoError(t, queue.Requeue(ctx, 4))
+		select {
+		case <-queue.Ready():
+		default:
+			t.Fatal("expected a wakeup after requeueing")
+		}
+	})
+
+	t.Run("reports jobs that are not failed", func(t *testing.T) {
+		mockDB := &MockDatabaseJobRepository{}
+		queue := newJobQueue(mockDB, 3)
+		ctx := context.Background()
+
+		mockDB.On("Requeue", ctx, int64(4)).Return(database.ErrJobNotFound)
+
+		assert.ErrorIs(t, queue.Requeue(ctx, 4), image.ErrJobNotFound)
+	})
+}
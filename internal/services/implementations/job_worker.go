@@ -0,0 +1,277 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/observability"
+)
+
+// Job worker defaults
+const (
+	DefaultJobWorkers      = 4
+	DefaultJobPollInterval = time.Second
+	DefaultJobRetryBackoff = 10 * time.Second
+)
+
+const (
+	// jobLease is how long a worker may hold a job before another may claim it
+	jobLease = 10 * time.Minute
+	// jobTimeout is how long a job may run. It is cancelled well within its lease, so
+	// its outcome is recorded before another worker may claim it.
+	jobTimeout = 8 * time.Minute
+	// maxJobBackoff caps the wait before a failed job is tried again
+	maxJobBackoff = time.Hour
+	// jobRecordTimeout bounds recording a job's outcome, which goes ahead on shutdown
+	jobRecordTimeout = 5 * time.Second
+)
+
+// JobWorkerConfig configures a JobWorkerPool. Zero values use the defaults.
+type JobWorkerConfig struct {
+	Workers      int           // Jobs run at once
+	PollInterval time.Duration // Wait between claims while the queue is empty
+	RetryBackoff time.Duration // Wait before the first retry, doubled on each after
+}
+
+// imageStepRunner runs the processing steps of images
+type imageStepRunner interface {
+	ProcessImage(ctx context.Context, id int, step image.JobType) error
+}
+
+// imageGetter looks up images for the events of failed jobs
+type imageGetter interface {
+	GetByID(ctx context.Context, id int) (*image.Image, error)
+}
+
+// JobWorkerPool runs queued image processing jobs in the background. A failed job is
+// retried with exponential backoff until it has used up its attempts, when it is
+// dead-lettered and an ImageProcessingFailedEvent is published.
+type JobWorkerPool struct {
+	queue  image.JobQueue
+	runner imageStepRunner
+	images imageGetter
+	events image.EventPublisher  // can be nil
+	cache  image.CacheService    // can be nil
+	logger *observability.Logger // can be nil
+
+	workers      int
+	pollInterval time.Duration
+	retryBackoff time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobWorkerPool creates a worker pool running the queue's jobs through the service
+func NewJobWorkerPool(
+	queue image.JobQueue,
+	service image.ImageService,
+	repo image.Repository,
+	events image.EventPublisher,
+	cfg JobWorkerConfig,
+	logger *observability.Logger,
+) *JobWorkerPool {
+	return newJobWorkerPool(queue, service, repo, events, cfg, logger)
+}
+
+func newJobWorkerPool(
+	queue image.JobQueue,
+	runner imageStepRunner,
+	images imageGetter,
+	events image.EventPublisher,
+	cfg JobWorkerConfig,
+	logger *observability.Logger,
+) *JobWorkerPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultJobWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultJobPollInterval
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultJobRetryBackoff
+	}
+	return &JobWorkerPool{
+		queue:        queue,
+		runner:       runner,
+		images:       images,
+		events:       events,
+		logger:       logger,
+		workers:      cfg.Workers,
+		pollInterval: cfg.PollInterval,
+		retryBackoff: cfg.RetryBackoff,
+	}
+}
+
+// SetCache sets the cache whose copies of an image are dropped when its jobs change
+// state, so the processing status it is served with stays current
+func (p *JobWorkerPool) SetCache(cache image.CacheService) {
+	p.cache = cache
+}
+
+// Start starts the workers, unless they are already running
+func (p *JobWorkerPool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(ctx)
+		}()
+	}
+}
+
+// Stop cancels the jobs in progress and waits for the workers to exit. Cancelled jobs
+// are queued again, due at once, rather than failed.
+func (p *JobWorkerPool) Stop() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		p.wg.Wait()
+	}
+}
+
+// work claims and runs one job at a time until the context is done
+func (p *JobWorkerPool) work(ctx context.Context) {
+	var ready <-chan struct{}
+	if queue, ok := p.queue.(interface{ Ready() <-chan struct{} }); ok {
+		ready = queue.Ready()
+	}
+
+	for ctx.Err() == nil {
+		jobs, err := p.queue.Claim(ctx, 1, jobLease)
+		if err != nil && ctx.Err() == nil && p.logger != nil {
+			p.logger.Error(ctx).Err(err).Msg("Failed to claim processing jobs")
+		}
+		if len(jobs) == 0 {
+			select {
+			case <-time.After(p.pollInterval):
+			case <-ready:
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		for _, job := range jobs {
+			p.run(ctx, job)
+		}
+	}
+}
+
+// run runs a claimed job and records its outcome
+func (p *JobWorkerPool) run(ctx context.Context, job *image.ProcessingJob) {
+	p.invalidate(ctx, job.ImageID)
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	err := p.runner.ProcessImage(jobCtx, job.ImageID, job.Type)
+	cancel()
+
+	// The outcome is recorded even when the pool is stopping
+	recordCtx, cancelRecord := context.WithTimeout(context.WithoutCancel(ctx), jobRecordTimeout)
+	defer cancelRecord()
+	defer p.invalidate(recordCtx, job.ImageID)
+
+	switch {
+	case err == nil:
+		err = p.queue.Complete(recordCtx, job.ID, job.Attempts)
+	case ctx.Err() != nil:
+		err = p.queue.Retry(recordCtx, job.ID, job.Attempts, "interrupted by shutdown", time.Now())
+	case job.Attempts >= job.MaxAttempts:
+		p.bury(recordCtx, job, err)
+		return
+	default:
+		if p.logger != nil {
+			p.logger.Warn(ctx).Err(err).
+				Int64("job_id", job.ID).
+				Int("image_id", job.ImageID).
+				Str("job_type", string(job.Type)).
+				Int("attempt", job.Attempts).
+				Msg("Processing job failed, will retry")
+		}
+		err = p.queue.Retry(recordCtx, job.ID, job.Attempts, err.Error(), time.Now().Add(jobBackoff(p.retryBackoff, job.Attempts)))
+	}
+	p.logRecordError(ctx, job, err)
+}
+
+// logRecordError logs a failure to record a job's outcome. A lost lease is only a
+// warning: the job's state belongs to the worker that claimed it since.
+func (p *JobWorkerPool) logRecordError(ctx context.Context, job *image.ProcessingJob, err error) {
+	switch {
+	case err == nil || p.logger == nil:
+	case errors.Is(err, image.ErrJobLeaseLost):
+		p.logger.Warn(ctx).Err(err).Int64("job_id", job.ID).Int("attempt", job.Attempts).
+			Msg("Processing job lease expired, outcome discarded")
+	default:
+		p.logger.Error(ctx).Err(err).Int64("job_id", job.ID).Msg("Failed to record processing job outcome")
+	}
+}
+
+// bury dead-letters a job that failed on its last attempt and publishes the failure
+func (p *JobWorkerPool) bury(ctx context.Context, job *image.ProcessingJob, jobErr error) {
+	if err := p.queue.Bury(ctx, job.ID, job.Attempts, jobErr.Error()); err != nil {
+		p.logRecordError(ctx, job, err)
+		if errors.Is(err, image.ErrJobLeaseLost) {
+			return
+		}
+	}
+	if p.logger != nil {
+		p.logger.Error(ctx).Err(jobErr).
+			Int64("job_id", job.ID).
+			Int("image_id", job.ImageID).
+			Str("job_type", string(job.Type)).
+			Int("attempts", job.Attempts).
+			Msg("Processing job failed on every attempt")
+	}
+
+	if p.events == nil {
+		return
+	}
+	var filename string
+	if img, err := p.images.GetByID(ctx, job.ImageID); err == nil {
+		filename = img.OriginalFilename
+	}
+	event := image.NewImageProcessingFailedEvent(job.ImageID, filename, string(job.Type), jobErr.Error(), map[string]interface{}{
+		"job_id":   job.ID,
+		"attempts": job.Attempts,
+	})
+	if err := p.events.PublishImageProcessingFailed(ctx, event); err != nil && p.logger != nil {
+		p.logger.Error(ctx).Err(err).Int64("job_id", job.ID).Msg("Failed to publish processing failure")
+	}
+}
+
+// invalidate drops the cached copies of an image whose job has changed state
+func (p *JobWorkerPool) invalidate(ctx context.Context, imageID int) {
+	if p.cache == nil {
+		return
+	}
+	if err := p.cache.DeleteImage(ctx, imageID); err != nil {
+		_ = err
+	}
+	if err := p.cache.InvalidateImageLists(ctx); err != nil {
+		_ = err
+	}
+}
+
+// jobBackoff returns the wait before a job that failed its attempt-th attempt is tried
+// again: the base doubled for each attempt after the first, up to maxJobBackoff
+func jobBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < maxJobBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxJobBackoff)
+}
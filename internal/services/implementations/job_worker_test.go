@@ -0,0 +1,248 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+)
+
+// fakeJobQueue records the outcome of each job it hands out
+type fakeJobQueue struct {
+	mu      sync.Mutex
+	pending []*image.ProcessingJob
+	done    []int64
+	retried map[int64]time.Time
+	buried  map[int64]string
+	lost    map[int64]bool // Jobs claimed again since they were handed out
+	ready   chan struct{}
+}
+
+func newFakeJobQueue(jobs ...*image.ProcessingJob) *fakeJobQueue {
+	return &fakeJobQueue{
+		pending: jobs,
+		retried: make(map[int64]time.Time),
+		buried:  make(map[int64]string),
+		lost:    make(map[int64]bool),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+func (q *fakeJobQueue) Enqueue(_ context.Context, imageID int, types ...image.JobType) error {
+	q.mu.Lock()
+	for _, jobType := range types {
+		q.pending = append(q.pending, &image.ProcessingJob{
+			ID: int64(len(q.pending) + 100), ImageID: imageID, Type: jobType, MaxAttempts: 3,
+		})
+	}
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *fakeJobQueue) Ready() <-chan struct{} { return q.ready }
+
+func (q *fakeJobQueue) Claim(_ context.Context, limit int, _ time.Duration) ([]*image.ProcessingJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(limit, len(q.pending))
+	jobs := q.pending[:n]
+	q.pending = q.pending[n:]
+	for _, job := range jobs {
+		job.Attempts++
+		job.Status = image.JobRunning
+	}
+	return jobs, nil
+}
+
+func (q *fakeJobQueue) Complete(_ context.Context, id int64, _ int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.lost[id] {
+		return image.ErrJobLeaseLost
+	}
+	q.done = append(q.done, id)
+	return nil
+}
+
+func (q *fakeJobQueue) Retry(_ context.Context, id int64, _ int, _ string, runAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.lost[id] {
+		return image.ErrJobLeaseLost
+	}
+	q.retried[id] = runAt
+	return nil
+}
+
+func (q *fakeJobQueue) Bury(_ context.Context, id int64, _ int, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.lost[id] {
+		return image.ErrJobLeaseLost
+	}
+	q.buried[id] = lastError
+	return nil
+}
+
+func (q *fakeJobQueue) ListFailed(context.Context, int, int) ([]*image.ProcessingJob, int, error) {
+	return nil, 0, nil
+}
+
+func (q *fakeJobQueue) Requeue(context.Context, int64) error {
+	return nil
+}
+
+func (q *fakeJobQueue) completed() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]int64{}, q.done...)
+}
+
+// fakeStepRunner fails the steps of the images in fail
+type fakeStepRunner struct {
+	fail  map[int]error
+	block chan struct{} // When set, each step waits for it or for its context
+}
+
+func (r *fakeStepRunner) ProcessImage(ctx context.Context, id int, _ image.JobType) error {
+	if r.block != nil {
+		select {
+		case <-r.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return r.fail[id]
+}
+
+// fakeImages returns an image named after its ID
+type fakeImages struct{}
+
+func (fakeImages) GetByID(_ context.Context, id int) (*image.Image, error) {
+	return &image.Image{ID: id, OriginalFilename: "photo.jpg"}, nil
+}
+
+// fakeEventPublisher records the processing failures it publishes
+type fakeEventPublisher struct {
+	image.EventPublisher
+	failed []*image.ImageProcessingFailedEvent
+}
+
+func (p *fakeEventPublisher) PublishImageProcessingFailed(_ context.Context, event *image.ImageProcessingFailedEvent) error {
+	p.failed = append(p.failed, event)
+	return nil
+}
+
+func TestJobWorkerPool_Run(t *testing.T) {
+	cfg := JobWorkerConfig{RetryBackoff: time.Minute}
+
+	t.Run("completes a job that succeeds", func(t *testing.T) {
+		queue := newFakeJobQueue()
+		pool := newJobWorkerPool(queue, &fakeStepRunner{}, fakeImages{}, nil, cfg, nil)
+
+		pool.run(context.Background(), &image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobHash, Attempts: 1, MaxAttempts: 3})
+
+		assert.Equal(t, []int64{1}, queue.done)
+		assert.Empty(t, queue.retried)
+	})
+
+	t.Run("retries a failed job with backoff", func(t *testing.T) {
+		queue := newFakeJobQueue()
+		runner := &fakeStepRunner{fail: map[int]error{7: errors.New("storage unavailable")}}
+		pool := newJobWorkerPool(queue, runner, fakeImages{}, nil, cfg, nil)
+
+		before := time.Now()
+		pool.run(context.Background(), &image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobHash, Attempts: 2, MaxAttempts: 3})
+
+		require.Contains(t, queue.retried, int64(1))
+		assert.WithinDuration(t, before.Add(2*time.Minute), queue.retried[1], time.Second)
+		assert.Empty(t, queue.buried)
+	})
+
+	t.Run("dead-letters a job on its last attempt", func(t *testing.T) {
+		queue := newFakeJobQueue()
+		runner := &fakeStepRunner{fail: map[int]error{7: errors.New("corrupt image")}}
+		events := &fakeEventPublisher{}
+		pool := newJobWorkerPool(queue, runner, fakeImages{}, events, cfg, nil)
+
+		pool.run(context.Background(), &image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobVariants, Attempts: 3, MaxAttempts: 3})
+
+		assert.Equal(t, "corrupt image", queue.buried[1])
+		assert.Empty(t, queue.retried)
+		require.Len(t, events.failed, 1)
+		assert.Equal(t, 7, events.failed[0].ImageID)
+		assert.Equal(t, "photo.jpg", events.failed[0].Filename)
+		assert.Equal(t, "variants", events.failed[0].ProcessType)
+		assert.Equal(t, "corrupt image", events.failed[0].Error)
+	})
+
+	t.Run("publishes nothing for a job whose lease was lost", func(t *testing.T) {
+		queue := newFakeJobQueue()
+		queue.lost[1] = true
+		runner := &fakeStepRunner{fail: map[int]error{7: errors.New("corrupt image")}}
+		events := &fakeEventPublisher{}
+		pool := newJobWorkerPool(queue, runner, fakeImages{}, events, cfg, nil)
+
+		pool.run(context.Background(), &image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobVariants, Attempts: 3, MaxAttempts: 3})
+
+		assert.Empty(t, queue.buried)
+		assert.Empty(t, events.failed, "the worker now holding the job reports its outcome")
+	})
+
+	t.Run("requeues a job interrupted by shutdown", func(t *testing.T) {
+		queue := newFakeJobQueue()
+		runner := &fakeStepRunner{block: make(chan struct{})}
+		pool := newJobWorkerPool(queue, runner, fakeImages{}, nil, cfg, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		before := time.Now()
+		pool.run(ctx, &image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobHash, Attempts: 3, MaxAttempts: 3})
+
+		require.Contains(t, queue.retried, int64(1))
+		assert.WithinDuration(t, before, queue.retried[1], time.Second)
+		assert.Empty(t, queue.buried)
+	})
+}
+
+func TestJobWorkerPool_StartStop(t *testing.T) {
+	queue := newFakeJobQueue(&image.ProcessingJob{ID: 1, ImageID: 7, Type: image.JobHash, MaxAttempts: 3})
+	// A long poll interval: the enqueued job is picked up through the wakeup
+	pool := newJobWorkerPool(queue, &fakeStepRunner{}, fakeImages{}, nil, JobWorkerConfig{Workers: 2, PollInterval: time.Hour}, nil)
+
+	pool.Start()
+	pool.Start() // Already running
+	require.Eventually(t, func() bool { return len(queue.completed()) == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, queue.Enqueue(context.Background(), 8, image.JobThumbnail))
+	require.Eventually(t, func() bool { return len(queue.completed()) == 2 }, time.Second, time.Millisecond)
+
+	pool.Stop()
+	pool.Stop() // Already stopped
+}
+
+func TestJobBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{20, maxJobBackoff},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, jobBackoff(10*time.Second, tt.attempt), "attempt %d", tt.attempt)
+	}
+}
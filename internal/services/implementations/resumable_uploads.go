@@ -0,0 +1,485 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/observability"
+	"image-gallery/internal/platform/database"
+)
+
+// DefaultUploadExpiry is how long a resumable upload stays open after its last chunk
+const DefaultUploadExpiry = 24 * time.Hour
+
+const (
+	// uploadLockLease is how long a request may hold an upload while writing a chunk,
+	// after which the request is taken to have gone
+	uploadLockLease = 10 * time.Minute
+	// uploadPurgeBatch caps the expired uploads removed each time an upload is created
+	uploadPurgeBatch = 10
+)
+
+// ResumableUploadConfig configures a ResumableUploadServiceImpl. Zero values use the
+// defaults.
+type ResumableUploadConfig struct {
+	MaxSize int64         // Largest upload accepted, capped at image.MaxFileSize
+	Expiry  time.Duration // How long an upload stays open after its last chunk
+}
+
+// uploadFiles stores the bytes of an upload that do not yet make up a whole part, and
+// reads back the assembled file
+type uploadFiles interface {
+	StoreAt(ctx context.Context, path string, contentType string, data io.Reader, size int64) error
+	Retrieve(ctx context.Context, path string) (io.ReadCloser, error)
+	Delete(ctx context.Context, path string) error
+}
+
+// imageCreator creates the image of a completed upload
+type imageCreator interface {
+	CreateImage(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error)
+}
+
+// ResumableUploadServiceImpl implements the image.ResumableUploadService interface.
+// Chunks are stored as the parts of a multipart upload as they arrive; bytes short of
+// a whole part are kept in a tail object and sent with the next chunk. The parts are
+// joined when the last byte arrives and the file is handed to CreateImage.
+type ResumableUploadServiceImpl struct {
+	store     database.UploadRepository
+	parts     image.MultipartStorage
+	files     uploadFiles
+	images    imageCreator
+	processor imageInfoReader       // can be nil
+	logger    *observability.Logger // can be nil
+
+	maxSize int64
+	expiry  time.Duration
+}
+
+// NewResumableUploadService creates a resumable upload service storing its state in db
+func NewResumableUploadService(
+	db *sql.DB,
+	parts image.MultipartStorage,
+	files image.StorageService,
+	images image.ImageService,
+	processor image.ImageProcessor,
+	cfg ResumableUploadConfig,
+	logger *observability.Logger,
+) image.ResumableUploadService {
+	var info imageInfoReader
+	if processor != nil {
+		info = processor
+	}
+	return newResumableUploadService(database.NewUploadRepository(db), parts, files, images, info, cfg, logger)
+}
+
+func newResumableUploadService(
+	store database.UploadRepository,
+	parts image.MultipartStorage,
+	files uploadFiles,
+	images imageCreator,
+	processor imageInfoReader,
+	cfg ResumableUploadConfig,
+	logger *observability.Logger,
+) *ResumableUploadServiceImpl {
+	if cfg.MaxSize <= 0 || cfg.MaxSize > image.MaxFileSize {
+		cfg.MaxSize = image.MaxFileSize
+	}
+	if cfg.Expiry <= 0 {
+		cfg.Expiry = DefaultUploadExpiry
+	}
+	return &ResumableUploadServiceImpl{
+		store:     store,
+		parts:     parts,
+		files:     files,
+		images:    images,
+		processor: processor,
+		logger:    logger,
+		maxSize:   cfg.MaxSize,
+		expiry:    cfg.Expiry,
+	}
+}
+
+// MaxSize returns the largest upload accepted
+func (s *ResumableUploadServiceImpl) MaxSize() int64 {
+	return s.maxSize
+}
+
+// CreateUpload starts an upload, first removing a few uploads that have expired
+func (s *ResumableUploadServiceImpl) CreateUpload(ctx context.Context, req *image.CreateUploadRequest) (*image.ResumableUpload, error) {
+	if req.Length > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, maximum allowed: %d bytes", image.ErrUploadTooLarge, req.Length, s.maxSize)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.purgeExpired(ctx)
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+	path := image.UploadPrefix + id
+	multipartID, err := s.parts.CreateMultipartUpload(ctx, path, req.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	upload := &database.ResumableUpload{
+		ID:             id,
+		Length:         req.Length,
+		Filename:       req.Filename,
+		ContentType:    req.ContentType,
+		Tags:           req.Tags,
+		MetadataPolicy: string(req.MetadataPolicy),
+		StoragePath:    path,
+		MultipartID:    &multipartID,
+		ExpiresAt:      time.Now().Add(s.expiry),
+	}
+	if err := s.store.Create(ctx, upload); err != nil {
+		if abortErr := s.parts.AbortMultipartUpload(ctx, path, multipartID); abortErr != nil {
+			_ = abortErr
+		}
+		return nil, fmt.Errorf("failed to record upload: %w", err)
+	}
+	return fromDBUpload(upload), nil
+}
+
+// GetUpload returns an upload that has not expired
+func (s *ResumableUploadServiceImpl) GetUpload(ctx context.Context, id string) (*image.ResumableUpload, error) {
+	upload, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapUploadError(err)
+	}
+	if upload.ExpiresAt.Before(time.Now()) {
+		return nil, image.ErrUploadNotFound
+	}
+	return fromDBUpload(upload), nil
+}
+
+// WriteChunk appends data to an upload at offset. What was received is kept when the
+// data ends early, so the client can resume from the new offset. A chunk reaching the
+// upload's length completes it; a chunk at the full length of an upload whose hand-off
+// failed tries the hand-off again.
+func (s *ResumableUploadServiceImpl) WriteChunk(ctx context.Context, id string, offset int64, data io.Reader) (*image.ResumableUpload, error) {
+	// A client that drops the connection cancels the request, and what it sent is still
+	// to be stored
+	ctx = context.WithoutCancel(ctx)
+
+	upload, err := s.store.Lock(ctx, id, uploadLockLease)
+	if err != nil {
+		return nil, mapUploadError(err)
+	}
+	if upload.ExpiresAt.Before(time.Now()) {
+		s.unlock(ctx, upload.ID)
+		return nil, image.ErrUploadNotFound
+	}
+	if upload.Offset != offset {
+		s.unlock(ctx, upload.ID)
+		return nil, fmt.Errorf("%w: upload is at %d, chunk starts at %d", image.ErrUploadOffsetMismatch, upload.Offset, offset)
+	}
+
+	var writeErr error
+	if upload.Offset < upload.Length {
+		writeErr = s.writeParts(ctx, upload, data)
+		if errors.Is(writeErr, image.ErrUploadTooLarge) {
+			s.unlock(ctx, upload.ID)
+			return nil, writeErr
+		}
+	}
+	if writeErr == nil && upload.Offset == upload.Length && upload.ImageID == nil {
+		writeErr = s.finish(ctx, upload)
+	}
+
+	upload.ExpiresAt = time.Now().Add(s.expiry)
+	if err := s.store.Save(ctx, upload); err != nil {
+		return nil, fmt.Errorf("failed to record upload progress: %w", err)
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return fromDBUpload(upload), nil
+}
+
+// TerminateUpload stops an upload and removes what was received
+func (s *ResumableUploadServiceImpl) TerminateUpload(ctx context.Context, id string) error {
+	upload, err := s.store.Lock(ctx, id, uploadLockLease)
+	if err != nil {
+		return mapUploadError(err)
+	}
+	return s.remove(ctx, upload)
+}
+
+// writeParts reads the tail left by the last chunk followed by data, stores each whole
+// part and keeps the rest as the new tail. The upload records the progress made when
+// reading data fails part way, and none when storing fails, as the parts stored are
+// then overwritten by the next chunk.
+func (s *ResumableUploadServiceImpl) writeParts(ctx context.Context, upload *database.ResumableUpload, data io.Reader) error {
+	saved := len(upload.Parts)
+	discard := func(err error) error {
+		upload.Parts = upload.Parts[:saved]
+		return err
+	}
+
+	tail := &countingReader{reader: bytes.NewReader(nil)}
+	if upload.TailSize > 0 {
+		stored, err := s.files.Retrieve(ctx, tailPath(upload))
+		if err != nil {
+			return fmt.Errorf("failed to read upload tail: %w", err)
+		}
+		defer func() { _ = stored.Close() }() //nolint:errcheck // Resource cleanup
+		tail.reader = io.LimitReader(stored, upload.TailSize)
+	}
+
+	// One byte past the length is read, to tell a chunk that overruns the upload
+	remaining := upload.Length - upload.Offset
+	body := &countingReader{reader: io.LimitReader(data, remaining+1)}
+	in := io.MultiReader(tail, body)
+
+	stored := partsSize(upload.Parts)
+	buf := make([]byte, image.MinPartSize)
+	for {
+		n, readErr := io.ReadFull(in, buf)
+		if tail.err != nil {
+			return discard(fmt.Errorf("failed to read upload tail: %w", tail.err))
+		}
+		if body.count > remaining {
+			return discard(fmt.Errorf("%w: upload is %d bytes", image.ErrUploadTooLarge, upload.Length))
+		}
+		last := stored+int64(n) == upload.Length
+		if n == len(buf) || (last && n > 0) {
+			if err := s.uploadPart(ctx, upload, buf[:n]); err != nil {
+				return discard(err)
+			}
+			stored += int64(n)
+			n = 0
+		}
+		if readErr == nil && !last {
+			continue
+		}
+
+		if err := s.storeTail(ctx, upload, buf[:n]); err != nil {
+			return discard(err)
+		}
+		upload.Offset = stored + upload.TailSize
+		if body.err != nil {
+			return fmt.Errorf("failed to read upload chunk: %w", body.err)
+		}
+		return nil
+	}
+}
+
+// uploadPart stores the next part of an upload
+func (s *ResumableUploadServiceImpl) uploadPart(ctx context.Context, upload *database.ResumableUpload, data []byte) error {
+	number := len(upload.Parts) + 1
+	etag, err := s.parts.UploadPart(ctx, upload.StoragePath, *upload.MultipartID, number, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to store upload part: %w", err)
+	}
+	upload.Parts = append(upload.Parts, database.UploadPart{Number: number, ETag: etag, Size: int64(len(data))})
+	return nil
+}
+
+// storeTail replaces the bytes kept after the last part, removing them when there are
+// none
+func (s *ResumableUploadServiceImpl) storeTail(ctx context.Context, upload *database.ResumableUpload, data []byte) error {
+	if len(data) == 0 {
+		if upload.TailSize > 0 {
+			upload.TailSize = 0
+			if err := s.files.Delete(ctx, tailPath(upload)); err != nil {
+				_ = err
+			}
+		}
+		return nil
+	}
+
+	if err := s.files.StoreAt(ctx, tailPath(upload), upload.ContentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to store upload tail: %w", err)
+	}
+	upload.TailSize = int64(len(data))
+	return nil
+}
+
+// finish joins the parts of a complete upload and hands the file to CreateImage. The
+// joined file is kept when the hand-off fails, so it can be tried again.
+func (s *ResumableUploadServiceImpl) finish(ctx context.Context, upload *database.ResumableUpload) error {
+	if upload.MultipartID != nil {
+		parts := make([]image.UploadPart, len(upload.Parts))
+		for i, part := range upload.Parts {
+			parts[i] = image.UploadPart{Number: part.Number, ETag: part.ETag, Size: part.Size}
+		}
+		if err := s.parts.CompleteMultipartUpload(ctx, upload.StoragePath, *upload.MultipartID, parts); err != nil {
+			return fmt.Errorf("failed to assemble upload: %w", err)
+		}
+		upload.MultipartID = nil
+	}
+
+	file, err := s.files.Retrieve(ctx, upload.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to read assembled upload: %w", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // Resource cleanup
+
+	req := &image.CreateImageRequest{
+		OriginalFilename: upload.Filename,
+		ContentType:      upload.ContentType,
+		FileSize:         upload.Length,
+		Tags:             upload.Tags,
+		MetadataPolicy:   image.MetadataPolicy(upload.MetadataPolicy),
+	}
+	body, width, height := s.readDimensions(ctx, upload, file)
+	req.Width, req.Height = width, height
+
+	img, err := s.images.CreateImage(ctx, req, body)
+	if err != nil {
+		return err
+	}
+	upload.ImageID = &img.ID
+
+	if err := s.files.Delete(ctx, upload.StoragePath); err != nil && s.logger != nil {
+		s.logger.Warn(ctx).Err(err).Str("upload_id", upload.ID).Msg("Failed to delete assembled upload")
+	}
+	return nil
+}
+
+// readDimensions reads the width and height of an upload from its header, returning a
+// reader over the whole upload. They are left out when the header cannot be read, as
+// for uploads through the form.
+func (s *ResumableUploadServiceImpl) readDimensions(ctx context.Context, upload *database.ResumableUpload, file io.Reader) (io.Reader, *int, *int) {
+	if s.processor == nil || upload.ContentType == image.ContentTypeSVG {
+		return file, nil, nil
+	}
+
+	body, info, err := DecodeUploadHeader(ctx, s.processor, file)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn(ctx).Err(err).Str("upload_id", upload.ID).Msg("Failed to extract image dimensions")
+		}
+		return body, nil, nil
+	}
+	return body, &info.Width, &info.Height
+}
+
+// purgeExpired removes a batch of expired uploads and what they stored
+func (s *ResumableUploadServiceImpl) purgeExpired(ctx context.Context) {
+	expired, err := s.store.ListExpired(ctx, time.Now(), uploadPurgeBatch)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn(ctx).Err(err).Msg("Failed to list expired uploads")
+		}
+		return
+	}
+	for _, upload := range expired {
+		if err := s.remove(ctx, upload); err != nil && s.logger != nil {
+			s.logger.Warn(ctx).Err(err).Str("upload_id", upload.ID).Msg("Failed to remove expired upload")
+		}
+	}
+}
+
+// remove discards an upload's parts and files, then its record. The files are removed
+// on a best effort basis; the record is what makes the upload unreachable.
+func (s *ResumableUploadServiceImpl) remove(ctx context.Context, upload *database.ResumableUpload) error {
+	if upload.MultipartID != nil {
+		if err := s.parts.AbortMultipartUpload(ctx, upload.StoragePath, *upload.MultipartID); err != nil {
+			_ = err
+		}
+	} else if upload.ImageID == nil {
+		if err := s.files.Delete(ctx, upload.StoragePath); err != nil {
+			_ = err
+		}
+	}
+	if upload.TailSize > 0 {
+		if err := s.files.Delete(ctx, tailPath(upload)); err != nil {
+			_ = err
+		}
+	}
+
+	if err := s.store.Delete(ctx, upload.ID); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// unlock releases an upload left unchanged. A failure only delays the next chunk until
+// the lock's lease runs out.
+func (s *ResumableUploadServiceImpl) unlock(ctx context.Context, id string) {
+	if err := s.store.Unlock(ctx, id); err != nil && s.logger != nil {
+		s.logger.Warn(ctx).Err(err).Str("upload_id", id).Msg("Failed to unlock upload")
+	}
+}
+
+// countingReader counts the bytes read through it and keeps the error, other than
+// io.EOF, that ended them
+type countingReader struct {
+	reader io.Reader
+	count  int64
+	err    error
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return n, err
+}
+
+// newUploadID returns a random upload ID, which is all a client needs to write to it
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// tailPath returns where the bytes received after an upload's last part are kept
+func tailPath(upload *database.ResumableUpload) string {
+	return upload.StoragePath + ".tail"
+}
+
+// partsSize returns the bytes stored in parts
+func partsSize(parts database.UploadParts) int64 {
+	var size int64
+	for _, part := range parts {
+		size += part.Size
+	}
+	return size
+}
+
+// mapUploadError maps database upload errors to the domain errors
+func mapUploadError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrUploadNotFound):
+		return image.ErrUploadNotFound
+	case errors.Is(err, database.ErrUploadLocked):
+		return image.ErrUploadLocked
+	}
+	return err
+}
+
+// fromDBUpload converts a database upload to a domain upload
+func fromDBUpload(upload *database.ResumableUpload) *image.ResumableUpload {
+	return &image.ResumableUpload{
+		ID:             upload.ID,
+		Length:         upload.Length,
+		Offset:         upload.Offset,
+		Filename:       upload.Filename,
+		ContentType:    upload.ContentType,
+		Tags:           upload.Tags,
+		MetadataPolicy: image.MetadataPolicy(upload.MetadataPolicy),
+		ImageID:        upload.ImageID,
+		ExpiresAt:      upload.ExpiresAt,
+		CreatedAt:      upload.CreatedAt,
+		UpdatedAt:      upload.UpdatedAt,
+	}
+}
@@ -0,0 +1,435 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/database"
+)
+
+// fakeUploadStore keeps uploads in memory, copying them in and out like the database
+type fakeUploadStore struct {
+	uploads map[string]*database.ResumableUpload
+	locked  map[string]bool
+}
+
+func newFakeUploadStore() *fakeUploadStore {
+	return &fakeUploadStore{
+		uploads: make(map[string]*database.ResumableUpload),
+		locked:  make(map[string]bool),
+	}
+}
+
+func (f *fakeUploadStore) Create(_ context.Context, upload *database.ResumableUpload) error {
+	f.uploads[upload.ID] = copyUpload(upload)
+	return nil
+}
+
+func (f *fakeUploadStore) GetByID(_ context.Context, id string) (*database.ResumableUpload, error) {
+	upload, ok := f.uploads[id]
+	if !ok {
+		return nil, database.ErrUploadNotFound
+	}
+	return copyUpload(upload), nil
+}
+
+func (f *fakeUploadStore) Save(_ context.Context, upload *database.ResumableUpload) error {
+	f.uploads[upload.ID] = copyUpload(upload)
+	f.locked[upload.ID] = false
+	return nil
+}
+
+func (f *fakeUploadStore) Delete(_ context.Context, id string) error {
+	delete(f.uploads, id)
+	return nil
+}
+
+func (f *fakeUploadStore) Lock(ctx context.Context, id string, _ time.Duration) (*database.ResumableUpload, error) {
+	upload, err := f.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f.locked[id] {
+		return nil, database.ErrUploadLocked
+	}
+	f.locked[id] = true
+	return upload, nil
+}
+
+func (f *fakeUploadStore) Unlock(_ context.Context, id string) error {
+	f.locked[id] = false
+	return nil
+}
+
+func (f *fakeUploadStore) ListExpired(_ context.Context, before time.Time, limit int) ([]*database.ResumableUpload, error) {
+	var expired []*database.ResumableUpload
+	for _, upload := range f.uploads {
+		if upload.ExpiresAt.Before(before) && len(expired) < limit {
+			expired = append(expired, copyUpload(upload))
+		}
+	}
+	return expired, nil
+}
+
+func copyUpload(upload *database.ResumableUpload) *database.ResumableUpload {
+	c := *upload
+	c.Parts = append(database.UploadParts{}, upload.Parts...)
+	return &c
+}
+
+// fakeUploadStorage is a bucket holding files and the parts of multipart uploads
+type fakeUploadStorage struct {
+	files     map[string][]byte
+	parts     map[string]map[int][]byte // By multipart upload ID
+	aborted   []string
+	failParts bool
+}
+
+func newFakeUploadStorage() *fakeUploadStorage {
+	return &fakeUploadStorage{
+		files: make(map[string][]byte),
+		parts: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeUploadStorage) CreateMultipartUpload(_ context.Context, path string, _ string) (string, error) {
+	id := fmt.Sprintf("mp-%d", len(f.parts)+1)
+	f.parts[id] = make(map[int][]byte)
+	return id, nil
+}
+
+func (f *fakeUploadStorage) UploadPart(_ context.Context, _ string, uploadID string, number int, data io.Reader, size int64) (string, error) {
+	if f.failParts {
+		return "", errors.New("storage unavailable")
+	}
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) != size {
+		return "", fmt.Errorf("part is %d bytes, said to be %d", len(b), size)
+	}
+	f.parts[uploadID][number] = b
+	return fmt.Sprintf("etag-%d", number), nil
+}
+
+func (f *fakeUploadStorage) CompleteMultipartUpload(_ context.Context, path string, uploadID string, parts []image.UploadPart) error {
+	var file []byte
+	for i, part := range parts {
+		data := f.parts[uploadID][part.Number]
+		if i < len(parts)-1 && len(data) < image.MinPartSize {
+			return fmt.Errorf("part %d is too small", part.Number)
+		}
+		file = append(file, data...)
+	}
+	f.files[path] = file
+	delete(f.parts, uploadID)
+	return nil
+}
+
+func (f *fakeUploadStorage) AbortMultipartUpload(_ context.Context, _ string, uploadID string) error {
+	f.aborted = append(f.aborted, uploadID)
+	delete(f.parts, uploadID)
+	return nil
+}
+
+func (f *fakeUploadStorage) StoreAt(_ context.Context, path string, _ string, data io.Reader, _ int64) error {
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	f.files[path] = b
+	return nil
+}
+
+func (f *fakeUploadStorage) Retrieve(_ context.Context, path string) (io.ReadCloser, error) {
+	b, ok := f.files[path]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (f *fakeUploadStorage) Delete(_ context.Context, path string) error {
+	if _, ok := f.files[path]; !ok {
+		return fmt.Errorf("file not found: %s", path)
+	}
+	delete(f.files, path)
+	return nil
+}
+
+func (f *fakeUploadStorage) paths() []string {
+	var paths []string
+	for path := range f.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// fakeImageCreator records the images it is asked to create
+type fakeImageCreator struct {
+	req  *image.CreateImageRequest
+	data []byte
+	err  error
+}
+
+func (c *fakeImageCreator) CreateImage(_ context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	c.req, c.data = req, b
+	return &image.Image{ID: 42, OriginalFilename: req.OriginalFilename}, nil
+}
+
+// failingReader returns its data, then fails as a dropped connection does
+type failingReader struct {
+	data io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func newTestUploadService(t *testing.T) (*ResumableUploadServiceImpl, *fakeUploadStore, *fakeUploadStorage, *fakeImageCreator) {
+	t.Helper()
+	store := newFakeUploadStore()
+	storage := newFakeUploadStorage()
+	creator := &fakeImageCreator{}
+	service := newResumableUploadService(store, storage, storage, creator, nil, ResumableUploadConfig{MaxSize: 20 << 20}, nil)
+	return service, store, storage, creator
+}
+
+func testUploadData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestResumableUploadService_CreateUpload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts a multipart upload", func(t *testing.T) {
+		service, store, storage, _ := newTestUploadService(t)
+
+		upload, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: 1024, Filename: "photo.jpg", ContentType: "image/jpeg", Tags: []string{"beach"},
+		})
+		require.NoError(t, err)
+		assert.Len(t, upload.ID, 32)
+		assert.Equal(t, int64(0), upload.Offset)
+		assert.WithinDuration(t, time.Now().Add(DefaultUploadExpiry), upload.ExpiresAt, time.Minute)
+
+		stored := store.uploads[upload.ID]
+		require.NotNil(t, stored)
+		assert.Equal(t, image.UploadPrefix+upload.ID, stored.StoragePath)
+		require.NotNil(t, stored.MultipartID)
+		assert.Contains(t, storage.parts, *stored.MultipartID)
+	})
+
+	t.Run("rejects uploads over the size limit", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+
+		_, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: 21 << 20, Filename: "photo.jpg", ContentType: "image/jpeg",
+		})
+		assert.ErrorIs(t, err, image.ErrUploadTooLarge)
+		assert.Empty(t, store.uploads)
+	})
+
+	t.Run("rejects uploads that would not make a valid image", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+
+		_, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: 1024, Filename: "notes.txt", ContentType: "text/plain",
+		})
+		assert.ErrorIs(t, err, image.ErrInvalidContentType)
+		assert.Empty(t, store.uploads)
+	})
+
+	t.Run("removes expired uploads", func(t *testing.T) {
+		service, store, storage, _ := newTestUploadService(t)
+		expired, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: 1024, Filename: "old.jpg", ContentType: "image/jpeg",
+		})
+		require.NoError(t, err)
+		multipartID := *store.uploads[expired.ID].MultipartID
+		store.uploads[expired.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+		_, err = service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: 1024, Filename: "new.jpg", ContentType: "image/jpeg",
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, store.uploads, expired.ID)
+		assert.Equal(t, []string{multipartID}, storage.aborted)
+	})
+}
+
+func TestResumableUploadService_WriteChunk(t *testing.T) {
+	ctx := context.Background()
+	create := func(t *testing.T, service *ResumableUploadServiceImpl, length int) *image.ResumableUpload {
+		t.Helper()
+		upload, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+			Length: int64(length), Filename: "photo.jpg", ContentType: "image/jpeg", Tags: []string{"beach"},
+		})
+		require.NoError(t, err)
+		return upload
+	}
+
+	t.Run("assembles chunks into parts and hands off the file", func(t *testing.T) {
+		service, store, storage, creator := newTestUploadService(t)
+		data := testUploadData(12 << 20)
+		upload := create(t, service, len(data))
+
+		// Chunks smaller than a part, so bytes are carried over in the tail
+		chunk := 3 << 20
+		for offset := 0; offset < len(data); offset += chunk {
+			got, err := service.WriteChunk(ctx, upload.ID, int64(offset), bytes.NewReader(data[offset:offset+chunk]))
+			require.NoError(t, err)
+			assert.Equal(t, int64(offset+chunk), got.Offset)
+			upload = got
+		}
+
+		require.NotNil(t, upload.ImageID)
+		assert.Equal(t, 42, *upload.ImageID)
+		assert.True(t, upload.IsComplete())
+		assert.Equal(t, data, creator.data)
+		assert.Equal(t, "photo.jpg", creator.req.OriginalFilename)
+		assert.Equal(t, int64(len(data)), creator.req.FileSize)
+		assert.Equal(t, []string{"beach"}, creator.req.Tags)
+
+		stored := store.uploads[upload.ID]
+		assert.Nil(t, stored.MultipartID)
+		assert.Len(t, stored.Parts, 3)
+		assert.Empty(t, storage.paths(), "the tail and assembled file are removed")
+	})
+
+	t.Run("rejects a chunk at the wrong offset", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+		upload := create(t, service, 1024)
+
+		_, err := service.WriteChunk(ctx, upload.ID, 512, bytes.NewReader(make([]byte, 512)))
+		assert.ErrorIs(t, err, image.ErrUploadOffsetMismatch)
+		assert.False(t, store.locked[upload.ID])
+	})
+
+	t.Run("rejects a chunk while another is being written", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+		upload := create(t, service, 1024)
+		store.locked[upload.ID] = true
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(make([]byte, 512)))
+		assert.ErrorIs(t, err, image.ErrUploadLocked)
+	})
+
+	t.Run("rejects a chunk past the upload length", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+		upload := create(t, service, 1024)
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(make([]byte, 1025)))
+		assert.ErrorIs(t, err, image.ErrUploadTooLarge)
+		assert.Equal(t, int64(0), store.uploads[upload.ID].Offset)
+		assert.False(t, store.locked[upload.ID])
+	})
+
+	t.Run("keeps what was received before the connection dropped", func(t *testing.T) {
+		service, store, _, creator := newTestUploadService(t)
+		data := testUploadData(7 << 20)
+		upload := create(t, service, len(data))
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, &failingReader{data: bytes.NewReader(data[:6<<20])})
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		stored := store.uploads[upload.ID]
+		assert.Equal(t, int64(6<<20), stored.Offset)
+		assert.Equal(t, int64(1<<20), stored.TailSize)
+
+		got, err := service.WriteChunk(ctx, upload.ID, 6<<20, bytes.NewReader(data[6<<20:]))
+		require.NoError(t, err)
+		require.NotNil(t, got.ImageID)
+		assert.Equal(t, data, creator.data)
+	})
+
+	t.Run("keeps no progress when a part cannot be stored", func(t *testing.T) {
+		service, store, storage, _ := newTestUploadService(t)
+		data := testUploadData(7 << 20)
+		upload := create(t, service, len(data))
+		storage.failParts = true
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(data))
+		require.Error(t, err)
+		stored := store.uploads[upload.ID]
+		assert.Equal(t, int64(0), stored.Offset)
+		assert.Empty(t, stored.Parts)
+		assert.False(t, store.locked[upload.ID])
+	})
+
+	t.Run("retries a failed hand-off at the full length", func(t *testing.T) {
+		service, store, storage, creator := newTestUploadService(t)
+		data := testUploadData(2048)
+		upload := create(t, service, len(data))
+		creator.err = fmt.Errorf("%w: corrupt image", image.ErrInvalidImageData)
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(data))
+		require.ErrorIs(t, err, image.ErrInvalidImageData)
+		stored := store.uploads[upload.ID]
+		assert.Equal(t, int64(len(data)), stored.Offset)
+		assert.Nil(t, stored.ImageID)
+		assert.Equal(t, []string{stored.StoragePath}, storage.paths(), "the assembled file is kept")
+
+		creator.err = nil
+		got, err := service.WriteChunk(ctx, upload.ID, int64(len(data)), bytes.NewReader(nil))
+		require.NoError(t, err)
+		require.NotNil(t, got.ImageID)
+		assert.Equal(t, data, creator.data)
+	})
+
+	t.Run("reports expired uploads as not found", func(t *testing.T) {
+		service, store, _, _ := newTestUploadService(t)
+		upload := create(t, service, 1024)
+		store.uploads[upload.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+		_, err := service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(make([]byte, 512)))
+		assert.ErrorIs(t, err, image.ErrUploadNotFound)
+		_, err = service.GetUpload(ctx, upload.ID)
+		assert.ErrorIs(t, err, image.ErrUploadNotFound)
+	})
+}
+
+func TestResumableUploadService_TerminateUpload(t *testing.T) {
+	ctx := context.Background()
+	service, store, storage, _ := newTestUploadService(t)
+	upload, err := service.CreateUpload(ctx, &image.CreateUploadRequest{
+		Length: 8 << 20, Filename: "photo.jpg", ContentType: "image/jpeg",
+	})
+	require.NoError(t, err)
+	_, err = service.WriteChunk(ctx, upload.ID, 0, bytes.NewReader(make([]byte, 1024)))
+	require.NoError(t, err)
+	multipartID := *store.uploads[upload.ID].MultipartID
+
+	require.NoError(t, service.TerminateUpload(ctx, upload.ID))
+	assert.Empty(t, store.uploads)
+	assert.Equal(t, []string{multipartID}, storage.aborted)
+	assert.Empty(t, storage.paths())
+
+	assert.ErrorIs(t, service.TerminateUpload(ctx, upload.ID), image.ErrUploadNotFound)
+}
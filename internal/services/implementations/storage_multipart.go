@@ -0,0 +1,94 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/storage"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errMultipartUnsupported is returned by the multipart and presign methods of a storage
+// service built on the bare MinIOClient
+var errMultipartUnsupported = errors.New("multipart and presigned uploads need the full storage service")
+
+// CreateMultipartUpload starts a multipart upload to path and returns its ID
+func (s *StorageServiceImpl) CreateMultipartUpload(ctx context.Context, path string, contentType string) (string, error) {
+	var uploadID string
+	err := s.multipart(ctx, "CreateMultipartUpload", "create_multipart", path, func(ctx context.Context) error {
+		var err error
+		uploadID, err = s.service.CreateMultipartUpload(ctx, path, contentType)
+		return err
+	})
+	return uploadID, err
+}
+
+// UploadPart stores a part of a multipart upload and returns its ETag
+func (s *StorageServiceImpl) UploadPart(ctx context.Context, path string, uploadID string, number int, data io.Reader, size int64) (string, error) {
+	var etag string
+	err := s.multipart(ctx, "UploadPart", "upload_part", path, func(ctx context.Context) error {
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Int("storage.part_number", number),
+			attribute.Int64("storage.size", size),
+		)
+		var err error
+		etag, err = s.service.UploadPart(ctx, path, uploadID, number, data, size)
+		if err == nil && s.storageBytesTransferred != nil {
+			s.storageBytesTransferred.Add(ctx, size, metric.WithAttributes(attribute.String("operation", "upload_part")))
+		}
+		return err
+	})
+	return etag, err
+}
+
+// CompleteMultipartUpload joins the parts of a multipart upload into the file at path
+func (s *StorageServiceImpl) CompleteMultipartUpload(ctx context.Context, path string, uploadID string, parts []image.UploadPart) error {
+	return s.multipart(ctx, "CompleteMultipartUpload", "complete_multipart", path, func(ctx context.Context) error {
+		completed := make([]storage.CompletedPart, len(parts))
+		for i, part := range parts {
+			completed[i] = storage.CompletedPart{Number: part.Number, ETag: part.ETag}
+		}
+		return s.service.CompleteMultipartUpload(ctx, path, uploadID, completed)
+	})
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts
+func (s *StorageServiceImpl) AbortMultipartUpload(ctx context.Context, path string, uploadID string) error {
+	return s.multipart(ctx, "AbortMultipartUpload", "abort_multipart", path, func(ctx context.Context) error {
+		return s.service.AbortMultipartUpload(ctx, path, uploadID)
+	})
+}
+
+// multipart runs a multipart upload or presign operation in a span and records its
+// metrics
+func (s *StorageServiceImpl) multipart(ctx context.Context, spanName, operation, path string, fn func(ctx context.Context) error) error {
+	startTime := time.Now()
+	ctx, span := s.tracer.Start(ctx, spanName,
+		trace.WithAttributes(
+			attribute.String("storage.path", path),
+		),
+	)
+	defer span.End()
+
+	err := errMultipartUnsupported
+	if s.service != nil {
+		err = fn(ctx)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, operation+" failed")
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	s.recordOperation(ctx, operation, startTime, err)
+
+	return err
+}
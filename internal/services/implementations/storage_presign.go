@@ -0,0 +1,29 @@
+package implementations
+
+import (
+	"context"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/platform/storage"
+)
+
+// PresignPost signs a policy for a browser to upload one file straight to storage
+func (s *StorageServiceImpl) PresignPost(ctx context.Context, policy *image.PostPolicy) (string, map[string]string, error) {
+	var (
+		url    string
+		fields map[string]string
+	)
+	err := s.multipart(ctx, "PresignPost", "presign_post", policy.Key, func(ctx context.Context) error {
+		var err error
+		url, fields, err = s.service.PresignedPostPolicy(ctx, storage.PostPolicy{
+			Key:         policy.Key,
+			ContentType: policy.ContentType,
+			MinSize:     policy.MinSize,
+			MaxSize:     policy.MaxSize,
+			Expires:     policy.Expires,
+			Metadata:    policy.Metadata,
+		})
+		return err
+	})
+	return url, fields, err
+}
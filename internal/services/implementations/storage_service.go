@@ -128,6 +128,39 @@ func (s *StorageServiceImpl) Store(ctx context.Context, filename string, content
 	return path, err
 }
 
+// StoreAt saves a file under a fixed path, replacing any file already stored there
+func (s *StorageServiceImpl) StoreAt(ctx context.Context, path string, contentType string, data io.Reader, size int64) error {
+	startTime := time.Now()
+	ctx, span := s.tracer.Start(ctx, "StoreAt",
+		trace.WithAttributes(
+			attribute.String("storage.path", path),
+			attribute.String("storage.content_type", contentType),
+			attribute.Int64("storage.size", size),
+		),
+	)
+	defer span.End()
+
+	var err error
+	if s.service != nil {
+		err = s.service.StoreAt(ctx, path, contentType, data, size)
+	} else {
+		err = s.client.UploadFile(ctx, path, data, size, contentType)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "store failed")
+	} else {
+		span.SetStatus(codes.Ok, "")
+		if s.storageBytesTransferred != nil {
+			s.storageBytesTransferred.Add(ctx, size, metric.WithAttributes(attribute.String("operation", "store_at")))
+		}
+	}
+	s.recordOperation(ctx, "store_at", startTime, err)
+
+	return err
+}
+
 // Retrieve gets a file from storage
 func (s *StorageServiceImpl) Retrieve(ctx context.Context, path string) (io.ReadCloser, error) {
 	startTime := time.Now()
@@ -215,6 +248,71 @@ func (s *StorageServiceImpl) Delete(ctx context.Context, path string) error {
 	return err
 }
 
+// DeletePrefix removes every file whose path starts with prefix
+func (s *StorageServiceImpl) DeletePrefix(ctx context.Context, prefix string) error {
+	startTime := time.Now()
+	ctx, span := s.tracer.Start(ctx, "DeletePrefix",
+		trace.WithAttributes(
+			attribute.String("storage.prefix", prefix),
+		),
+	)
+	defer span.End()
+
+	var err error
+	if s.service != nil {
+		err = s.service.DeletePrefix(ctx, prefix)
+	} else {
+		err = s.deletePrefixWithClient(ctx, prefix)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete failed")
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	s.recordOperation(ctx, "delete_prefix", startTime, err)
+
+	return err
+}
+
+// deletePrefixWithClient removes the files under prefix one by one through the MinIOClient
+func (s *StorageServiceImpl) deletePrefixWithClient(ctx context.Context, prefix string) error {
+	for {
+		objects, err := s.client.ListObjects(ctx, prefix, 1000)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		for _, obj := range objects {
+			if err := s.client.DeleteFile(ctx, obj.Key); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// recordOperation records the count and duration metrics of a storage operation
+func (s *StorageServiceImpl) recordOperation(ctx context.Context, operation string, startTime time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("status", status),
+	)
+
+	if s.storageOperationsCounter != nil {
+		s.storageOperationsCounter.Add(ctx, 1, attrs)
+	}
+	if s.storageOperationsDuration != nil {
+		s.storageOperationsDuration.Record(ctx, time.Since(startTime).Seconds(), attrs)
+	}
+}
+
 // Exists checks if a file exists in storage
 func (s *StorageServiceImpl) Exists(ctx context.Context, path string) (bool, error) {
 	if s.service != nil {
@@ -253,6 +351,7 @@ func (s *StorageServiceImpl) GetFileInfo(ctx context.Context, path string) (*ima
 			ContentType:  info.ContentType,
 			LastModified: info.LastModified,
 			ETag:         info.ETag,
+			Metadata:     info.Metadata,
 		}, nil
 	}
 	// Return minimal info for MinIOClient
@@ -3,6 +3,7 @@ package implementations
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,24 +31,24 @@ func (r *TagRepositoryImpl) Create(ctx context.Context, tag *image.Tag) error {
 		return fmt.Errorf("tag cannot be nil")
 	}
 
-	query := `
-		INSERT INTO tags (name, created_at)
-		VALUES ($1, $2)
-		RETURNING id
-	`
-
-	err := r.db.QueryRowContext(ctx, query, tag.Name, tag.CreatedAt).Scan(&tag.ID)
-	if err != nil {
+	dbTag := toDBTag(tag)
+	if err := r.dbTagRepo.Create(ctx, dbTag); err != nil {
 		return fmt.Errorf("failed to create tag: %w", err)
 	}
 
+	tag.ID = dbTag.ID
+	tag.CreatedAt = dbTag.CreatedAt
 	return nil
 }
 
 // GetByID retrieves a tag by its ID
 func (r *TagRepositoryImpl) GetByID(ctx context.Context, id int) (*image.Tag, error) {
-	// TODO: Implement actual database query
-	return nil, nil
+	dbTag, err := r.dbTagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapTagError(err)
+	}
+
+	return fromDBTag(dbTag), nil
 }
 
 // GetByName retrieves a tag by its name
@@ -56,33 +57,70 @@ func (r *TagRepositoryImpl) GetByName(ctx context.Context, name string) (*image.
 		return nil, fmt.Errorf("tag name cannot be empty")
 	}
 
-	query := `SELECT id, name, created_at FROM tags WHERE name = $1`
-	var tag image.Tag
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
-		&tag.ID,
-		&tag.Name,
-		&tag.CreatedAt,
-	)
-
+	dbTag, err := r.dbTagRepo.GetByName(ctx, name)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, database.ErrTagNotFound) {
 			return nil, nil // Tag not found, return nil (not an error for this method)
 		}
 		return nil, fmt.Errorf("failed to query tag by name: %w", err)
 	}
 
-	return &tag, nil
+	return fromDBTag(dbTag), nil
 }
 
 // List retrieves all tags or tags matching criteria
 func (r *TagRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*image.Tag, error) {
-	// TODO: Implement actual database query
-	return []*image.Tag{}, nil
+	dbTags, err := r.dbTagRepo.List(ctx, database.PaginationParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return fromDBTags(dbTags), nil
+}
+
+// Update modifies an existing tag
+func (r *TagRepositoryImpl) Update(ctx context.Context, tag *image.Tag) error {
+	if tag == nil {
+		return fmt.Errorf("tag cannot be nil")
+	}
+
+	if err := r.dbTagRepo.Update(ctx, toDBTag(tag)); err != nil {
+		return mapTagError(err)
+	}
+	return nil
 }
 
 // Delete removes a tag from the repository
 func (r *TagRepositoryImpl) Delete(ctx context.Context, id int) error {
-	// TODO: Implement actual database deletion
+	if err := r.dbTagRepo.Delete(ctx, id); err != nil {
+		return mapTagError(err)
+	}
+	return nil
+}
+
+// Merge moves all images from the source tag to the target tag and removes the source
+func (r *TagRepositoryImpl) Merge(ctx context.Context, sourceID, targetID int) error {
+	if err := r.dbTagRepo.Merge(ctx, sourceID, targetID); err != nil {
+		return mapTagError(err)
+	}
+	return nil
+}
+
+// GetAll returns every active tag, used to build the tag hierarchy
+func (r *TagRepositoryImpl) GetAll(ctx context.Context) ([]*image.Tag, error) {
+	dbTags, err := r.dbTagRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return fromDBTags(dbTags), nil
+}
+
+// SetParent moves a tag and its subtree under a new parent (nil for the root)
+func (r *TagRepositoryImpl) SetParent(ctx context.Context, tagID int, parentID *int) error {
+	if err := r.dbTagRepo.SetParent(ctx, tagID, parentID); err != nil {
+		return mapTagError(err)
+	}
 	return nil
 }
 
@@ -103,9 +141,19 @@ func (r *TagRepositoryImpl) GetOrCreate(ctx context.Context, name string) (*imag
 		return existing, nil
 	}
 
+	// Alternative spellings resolve to their canonical tag instead of creating a new one
+	canonical, err := r.ResolveAlias(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tag alias: %w", err)
+	}
+	if canonical != nil {
+		return canonical, nil
+	}
+
 	// Tag doesn't exist, create it
 	newTag := &image.Tag{
 		Name:      name,
+		IsActive:  true,
 		CreatedAt: time.Now(),
 	}
 
@@ -122,16 +170,127 @@ func (r *TagRepositoryImpl) GetOrCreate(ctx context.Context, name string) (*imag
 	return newTag, nil
 }
 
+// ResolveAlias returns the canonical tag for an alias, or nil if the alias is unknown
+func (r *TagRepositoryImpl) ResolveAlias(ctx context.Context, alias string) (*image.Tag, error) {
+	normalized := image.NormalizeAlias(alias)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	dbTag, err := r.dbTagRepo.ResolveAlias(ctx, normalized)
+	if err != nil {
+		if errors.Is(err, database.ErrAliasNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve alias: %w", err)
+	}
+
+	return fromDBTag(dbTag), nil
+}
+
+// ListAliases returns every alias, or only those of one tag when tagID is positive
+func (r *TagRepositoryImpl) ListAliases(ctx context.Context, tagID int) ([]*image.TagAlias, error) {
+	dbAliases, err := r.dbTagRepo.ListAliases(ctx, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+
+	aliases := make([]*image.TagAlias, len(dbAliases))
+	for i, dbAlias := range dbAliases {
+		aliases[i] = &image.TagAlias{
+			Alias:     dbAlias.Alias,
+			TagID:     dbAlias.TagID,
+			TagName:   dbAlias.TagName,
+			CreatedAt: dbAlias.CreatedAt,
+		}
+	}
+	return aliases, nil
+}
+
+// CreateAlias stores a new alias for a tag
+func (r *TagRepositoryImpl) CreateAlias(ctx context.Context, alias *image.TagAlias) error {
+	if alias == nil {
+		return fmt.Errorf("alias cannot be nil")
+	}
+
+	dbAlias := &database.TagAlias{Alias: alias.Alias, TagID: alias.TagID}
+	if err := r.dbTagRepo.CreateAlias(ctx, dbAlias); err != nil {
+		return mapTagError(err)
+	}
+
+	alias.CreatedAt = dbAlias.CreatedAt
+	return nil
+}
+
+// DeleteAlias removes an alias
+func (r *TagRepositoryImpl) DeleteAlias(ctx context.Context, alias string) error {
+	if err := r.dbTagRepo.DeleteAlias(ctx, image.NormalizeAlias(alias)); err != nil {
+		return mapTagError(err)
+	}
+	return nil
+}
+
+// AddPendingTag records that an image requested a tag that awaits approval
+func (r *TagRepositoryImpl) AddPendingTag(ctx context.Context, name string, imageID int) error {
+	if err := r.dbTagRepo.AddPending(ctx, name, imageID); err != nil {
+		return fmt.Errorf("failed to queue tag for approval: %w", err)
+	}
+	return nil
+}
+
+// ListPendingTags returns the tags awaiting approval, oldest request first
+func (r *TagRepositoryImpl) ListPendingTags(ctx context.Context) ([]*image.PendingTag, error) {
+	dbPending, err := r.dbTagRepo.ListPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending tags: %w", err)
+	}
+
+	pending := make([]*image.PendingTag, len(dbPending))
+	for i, p := range dbPending {
+		pending[i] = &image.PendingTag{
+			Name:             p.Name,
+			ImageIDs:         p.ImageIDs,
+			FirstRequestedAt: p.FirstRequestedAt,
+			LastRequestedAt:  p.LastRequestedAt,
+		}
+	}
+	return pending, nil
+}
+
+// ApprovePendingTag creates the tag as a predefined tag and attaches it to the requesting images
+func (r *TagRepositoryImpl) ApprovePendingTag(ctx context.Context, name string) (*image.Tag, []int, error) {
+	dbTag, imageIDs, err := r.dbTagRepo.ApprovePending(ctx, name, image.MaxTagsPerImage)
+	if err != nil {
+		return nil, nil, mapTagError(err)
+	}
+	return fromDBTag(dbTag), imageIDs, nil
+}
+
+// RejectPendingTag discards a pending tag
+func (r *TagRepositoryImpl) RejectPendingTag(ctx context.Context, name string) error {
+	if err := r.dbTagRepo.DeletePending(ctx, name); err != nil {
+		return mapTagError(err)
+	}
+	return nil
+}
+
 // GetPopularTags returns the most frequently used tags
 func (r *TagRepositoryImpl) GetPopularTags(ctx context.Context, limit int) ([]*image.Tag, error) {
-	// TODO: Implement popular tags query
-	return []*image.Tag{}, nil
+	dbTags, err := r.dbTagRepo.GetPopular(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular tags: %w", err)
+	}
+
+	return fromDBTags(dbTags), nil
 }
 
 // ExistsByName checks if a tag with the given name exists
 func (r *TagRepositoryImpl) ExistsByName(ctx context.Context, name string) (bool, error) {
-	// TODO: Implement existence check
-	return false, nil
+	tag, err := r.GetByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return tag != nil, nil
 }
 
 // GetPredefinedTags returns all predefined tags
@@ -142,15 +301,186 @@ func (r *TagRepositoryImpl) GetPredefinedTags(ctx context.Context) ([]*image.Tag
 		return nil, fmt.Errorf("failed to get predefined tags: %w", err)
 	}
 
-	// Convert database tags to domain tags
-	domainTags := make([]*image.Tag, len(dbTags))
-	for i, dbTag := range dbTags {
-		domainTags[i] = &image.Tag{
-			ID:        dbTag.ID,
-			Name:      dbTag.Name,
-			CreatedAt: dbTag.CreatedAt,
+	return fromDBTags(dbTags), nil
+}
+
+// GetCategories returns active predefined tags grouped by category
+func (r *TagRepositoryImpl) GetCategories(ctx context.Context) (map[string][]*image.Tag, error) {
+	dbCategories, err := r.dbTagRepo.GetPredefinedByCategory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag categories: %w", err)
+	}
+
+	categories := make(map[string][]*image.Tag, len(dbCategories))
+	for name, dbTags := range dbCategories {
+		categories[name] = fromDBTags(dbTags)
+	}
+	return categories, nil
+}
+
+// RenameCategory moves all tags from one category to another
+func (r *TagRepositoryImpl) RenameCategory(ctx context.Context, from, to string) (int, error) {
+	count, err := r.dbTagRepo.RenameCategory(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename category: %w", err)
+	}
+	return count, nil
+}
+
+// GetImageIDs returns the IDs of all images carrying a tag
+func (r *TagRepositoryImpl) GetImageIDs(ctx context.Context, tagID int) ([]int, error) {
+	ids, err := r.dbTagRepo.GetTagImageIDs(ctx, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag images: %w", err)
+	}
+	return ids, nil
+}
+
+// AddToImage attaches a tag to an image, reporting whether a new association was created.
+// The image row is locked for the duration of the transaction so that concurrent
+// attachments cannot push an image past MaxTagsPerImage.
+func (r *TagRepositoryImpl) AddToImage(ctx context.Context, imageID, tagID int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Transaction cleanup
+
+	var lockedID int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM images WHERE id = $1 FOR UPDATE`, imageID).Scan(&lockedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("%w: image with ID %d", image.ErrImageNotFound, imageID)
 		}
+		return false, fmt.Errorf("failed to lock image: %w", err)
+	}
+
+	var attached bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM image_tags WHERE image_id = $1 AND tag_id = $2)`,
+		imageID, tagID,
+	).Scan(&attached)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tag association: %w", err)
+	}
+	if attached {
+		return false, nil
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM image_tags WHERE image_id = $1`, imageID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to count image tags: %w", err)
+	}
+	if count >= image.MaxTagsPerImage {
+		return false, fmt.Errorf("%w: image already has %d tags (max %d)", image.ErrTagLimitExceeded, count, image.MaxTagsPerImage)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO image_tags (image_id, tag_id) VALUES ($1, $2)`, imageID, tagID)
+	if err != nil {
+		return false, fmt.Errorf("failed to attach tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit tag attachment: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveFromImage detaches a tag from an image, reporting whether an association was removed
+func (r *TagRepositoryImpl) RemoveFromImage(ctx context.Context, imageID, tagID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM image_tags WHERE image_id = $1 AND tag_id = $2`, imageID, tagID)
+	if err != nil {
+		return false, fmt.Errorf("failed to detach tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check detached rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetImageTags returns all tags attached to an image
+func (r *TagRepositoryImpl) GetImageTags(ctx context.Context, imageID int) ([]*image.Tag, error) {
+	dbTags, err := r.dbTagRepo.GetImageTags(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image tags: %w", err)
 	}
 
-	return domainTags, nil
+	return fromDBTags(dbTags), nil
+}
+
+// mapTagError translates database tag errors into domain errors
+func mapTagError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrTagNotFound):
+		return fmt.Errorf("%w: %v", image.ErrTagNotFound, err)
+	case errors.Is(err, database.ErrTagCycle):
+		return fmt.Errorf("%w: %v", image.ErrInvalidTagData, err)
+	case errors.Is(err, database.ErrAliasNotFound):
+		return fmt.Errorf("%w: %v", image.ErrAliasNotFound, err)
+	case errors.Is(err, database.ErrAliasConflict):
+		return fmt.Errorf("%w: %v", image.ErrAliasConflict, err)
+	case errors.Is(err, database.ErrPendingTagNotFound):
+		return fmt.Errorf("%w: %v", image.ErrPendingTagNotFound, err)
+	}
+	return err
+}
+
+// fromDBTag converts a database tag to a domain tag
+func fromDBTag(dbTag *database.Tag) *image.Tag {
+	tag := &image.Tag{
+		ID:           dbTag.ID,
+		Name:         dbTag.Name,
+		IsPredefined: dbTag.IsPredefined,
+		IsActive:     dbTag.IsActive,
+		DisplayOrder: dbTag.DisplayOrder,
+		ParentID:     dbTag.ParentID,
+		CreatedAt:    dbTag.CreatedAt,
+	}
+	if dbTag.Description != nil {
+		tag.Description = *dbTag.Description
+	}
+	if dbTag.Color != nil {
+		tag.Color = *dbTag.Color
+	}
+	if dbTag.Category != nil {
+		tag.Category = *dbTag.Category
+	}
+	return tag
+}
+
+// fromDBTags converts a slice of database tags to domain tags
+func fromDBTags(dbTags []*database.Tag) []*image.Tag {
+	tags := make([]*image.Tag, len(dbTags))
+	for i, dbTag := range dbTags {
+		tags[i] = fromDBTag(dbTag)
+	}
+	return tags
+}
+
+// toDBTag converts a domain tag to a database tag, storing empty strings as NULL
+func toDBTag(tag *image.Tag) *database.Tag {
+	return &database.Tag{
+		ID:           tag.ID,
+		Name:         tag.Name,
+		Description:  nullableString(tag.Description),
+		Color:        nullableString(tag.Color),
+		Category:     nullableString(tag.Category),
+		IsPredefined: tag.IsPredefined,
+		IsActive:     tag.IsActive,
+		DisplayOrder: tag.DisplayOrder,
+		ParentID:     tag.ParentID,
+		CreatedAt:    tag.CreatedAt,
+	}
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
@@ -2,6 +2,8 @@ package implementations
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"image-gallery/internal/domain/image"
 )
@@ -11,6 +13,7 @@ type TagServiceImpl struct {
 	tagRepo   image.TagRepository
 	validator image.ValidationService
 	eventPub  image.EventPublisher // can be nil
+	cache     image.CacheService   // can be nil
 }
 
 // NewTagService creates a new tag service implementation
@@ -18,22 +21,55 @@ func NewTagService(
 	tagRepo image.TagRepository,
 	validator image.ValidationService,
 	eventPub image.EventPublisher,
+	cache image.CacheService,
 ) image.TagService {
 	return &TagServiceImpl{
 		tagRepo:   tagRepo,
 		validator: validator,
 		eventPub:  eventPub,
+		cache:     cache,
 	}
 }
 
 // CreateTag creates a new tag
-func (s *TagServiceImpl) CreateTag(ctx context.Context, name string) (*image.Tag, error) {
-	// TODO: Implement tag creation workflow
-	// 1. Validate tag name
-	// 2. Check if tag already exists
-	// 3. Create tag
-	// 4. Publish events
-	return nil, nil
+func (s *TagServiceImpl) CreateTag(ctx context.Context, req *image.CreateTagRequest) (*image.Tag, error) {
+	if req == nil {
+		return nil, fmt.Errorf("create request cannot be nil")
+	}
+
+	tag, err := req.ToTag()
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.ParentID != nil {
+		if _, err := s.tagRepo.GetByID(ctx, *tag.ParentID); err != nil {
+			return nil, fmt.Errorf("parent tag: %w", err)
+		}
+	}
+
+	exists, err := s.tagRepo.ExistsByName(ctx, tag.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing tag: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", image.ErrDuplicateTag, tag.Name)
+	}
+	if err := s.checkNotAlias(ctx, tag.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	if s.eventPub != nil {
+		if err := s.eventPub.PublishTagCreated(ctx, tag); err != nil {
+			_ = err
+		}
+	}
+
+	return tag, nil
 }
 
 // GetTag retrieves a tag by ID
@@ -46,6 +82,204 @@ func (s *TagServiceImpl) ListTags(ctx context.Context, limit, offset int) ([]*im
 	return s.tagRepo.List(ctx, limit, offset)
 }
 
+// UpdateTag renames or re-describes an existing tag
+func (s *TagServiceImpl) UpdateTag(ctx context.Context, id int, req *image.UpdateTagRequest) (*image.Tag, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update request cannot be nil")
+	}
+
+	tag, err := s.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousName := tag.Name
+
+	if err := req.ApplyTo(tag); err != nil {
+		return nil, err
+	}
+
+	if tag.Name != previousName {
+		existing, err := s.tagRepo.GetByName(ctx, tag.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for existing tag: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: %s (merge the tags instead)", image.ErrDuplicateTag, tag.Name)
+		}
+		if err := s.checkNotAlias(ctx, tag.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	s.invalidateTaggedImages(ctx, id)
+
+	return tag, nil
+}
+
+// MergeTags folds the source tag into the target tag
+func (s *TagServiceImpl) MergeTags(ctx context.Context, sourceID, targetID int) (*image.Tag, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", image.ErrInvalidTagData)
+	}
+
+	target, err := s.tagRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect affected images before the source tag disappears
+	imageIDs, err := s.tagRepo.GetImageIDs(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.Merge(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	s.invalidateImages(ctx, imageIDs)
+
+	if s.eventPub != nil {
+		if err := s.eventPub.PublishTagDeleted(ctx, sourceID); err != nil {
+			_ = err
+		}
+	}
+
+	return target, nil
+}
+
+// MoveTag re-parents a tag and its subtree (nil parent moves it to the root)
+func (s *TagServiceImpl) MoveTag(ctx context.Context, id int, parentID *int) (*image.Tag, error) {
+	if parentID != nil && *parentID == id {
+		return nil, fmt.Errorf("%w: a tag cannot be its own parent", image.ErrInvalidTagData)
+	}
+
+	if err := s.tagRepo.SetParent(ctx, id, parentID); err != nil {
+		return nil, err
+	}
+
+	// Parent filters now match a different set of images
+	s.invalidateImages(ctx, nil)
+
+	return s.tagRepo.GetByID(ctx, id)
+}
+
+// GetTagTree returns the active tags arranged as a hierarchy
+func (s *TagServiceImpl) GetTagTree(ctx context.Context) ([]*image.TagNode, error) {
+	tags, err := s.tagRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return image.BuildTagTree(tags), nil
+}
+
+// ResolveTagNames replaces aliases with canonical tag names and drops duplicates
+func (s *TagServiceImpl) ResolveTagNames(ctx context.Context, names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	resolved := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		canonical, err := s.tagRepo.ResolveAlias(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if canonical != nil {
+			name = canonical.Name
+		}
+
+		key := strings.ToLower(name)
+		if !seen[key] {
+			seen[key] = true
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+// ListAliases returns every alias, or only those of one tag when tagID is positive
+func (s *TagServiceImpl) ListAliases(ctx context.Context, tagID int) ([]*image.TagAlias, error) {
+	if tagID > 0 {
+		if _, err := s.tagRepo.GetByID(ctx, tagID); err != nil {
+			return nil, err
+		}
+	}
+	return s.tagRepo.ListAliases(ctx, tagID)
+}
+
+// AddAlias makes alias resolve to the given tag
+func (s *TagServiceImpl) AddAlias(ctx context.Context, tagID int, alias string) (*image.TagAlias, error) {
+	tagAlias, err := image.NewTagAlias(alias, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.GetByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.CreateAlias(ctx, tagAlias); err != nil {
+		return nil, err
+	}
+	tagAlias.TagName = tag.Name
+
+	// Filters using the alias now match the tag's images
+	s.invalidateImages(ctx, nil)
+
+	return tagAlias, nil
+}
+
+// RemoveAlias deletes an alias
+func (s *TagServiceImpl) RemoveAlias(ctx context.Context, alias string) error {
+	if err := s.tagRepo.DeleteAlias(ctx, alias); err != nil {
+		return err
+	}
+
+	s.invalidateImages(ctx, nil)
+	return nil
+}
+
+// ListPendingTags returns the tags awaiting approval
+func (s *TagServiceImpl) ListPendingTags(ctx context.Context) ([]*image.PendingTag, error) {
+	return s.tagRepo.ListPendingTags(ctx)
+}
+
+// ApprovePendingTag turns a pending tag into a predefined tag on the requesting images
+func (s *TagServiceImpl) ApprovePendingTag(ctx context.Context, name string) (*image.Tag, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if err := s.checkNotAlias(ctx, name); err != nil {
+		return nil, err
+	}
+
+	tag, imageIDs, err := s.tagRepo.ApprovePendingTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateImages(ctx, imageIDs)
+
+	if s.eventPub != nil {
+		if err := s.eventPub.PublishTagCreated(ctx, tag); err != nil {
+			_ = err
+		}
+	}
+
+	return tag, nil
+}
+
+// RejectPendingTag discards a pending tag
+func (s *TagServiceImpl) RejectPendingTag(ctx context.Context, name string) error {
+	return s.tagRepo.RejectPendingTag(ctx, strings.TrimSpace(strings.ToLower(name)))
+}
+
 // GetPopularTags returns frequently used tags
 func (s *TagServiceImpl) GetPopularTags(ctx context.Context, limit int) ([]*image.Tag, error) {
 	return s.tagRepo.GetPopularTags(ctx, limit)
@@ -58,14 +292,49 @@ func (s *TagServiceImpl) GetPredefinedTags(ctx context.Context) ([]*image.Tag, e
 
 // DeleteTag removes a tag
 func (s *TagServiceImpl) DeleteTag(ctx context.Context, id int) error {
-	// TODO: Implement tag deletion workflow
-	// 1. Validate deletion is allowed
-	// 2. Remove tag associations
-	// 3. Delete tag
-	// 4. Publish events
+	if err := s.validator.ValidateTagOperation(ctx, "delete", id); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	imageIDs, err := s.tagRepo.GetImageIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Tag associations are removed by the ON DELETE CASCADE on image_tags
+	if err := s.tagRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidateImages(ctx, imageIDs)
+
+	if s.eventPub != nil {
+		if err := s.eventPub.PublishTagDeleted(ctx, id); err != nil {
+			_ = err
+		}
+	}
+
 	return nil
 }
 
+// GetCategories returns predefined tags grouped by category
+func (s *TagServiceImpl) GetCategories(ctx context.Context) (map[string][]*image.Tag, error) {
+	return s.tagRepo.GetCategories(ctx)
+}
+
+// RenameCategory moves all tags from one category to another
+func (s *TagServiceImpl) RenameCategory(ctx context.Context, from, to string) (int, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return 0, fmt.Errorf("%w: category name cannot be empty", image.ErrInvalidTagData)
+	}
+	if len(to) > image.MaxCategoryLen {
+		return 0, fmt.Errorf("%w: category too long (max %d characters)", image.ErrInvalidTagData, image.MaxCategoryLen)
+	}
+
+	return s.tagRepo.RenameCategory(ctx, from, to)
+}
+
 // GetTagStats returns statistics about tags
 func (s *TagServiceImpl) GetTagStats(ctx context.Context) (*image.TagStats, error) {
 	// TODO: Implement tag stats calculation
@@ -73,3 +342,42 @@ func (s *TagServiceImpl) GetTagStats(ctx context.Context) (*image.TagStats, erro
 		TotalTags: 0,
 	}, nil
 }
+
+// checkNotAlias rejects tag names that are already taken by an alias
+func (s *TagServiceImpl) checkNotAlias(ctx context.Context, name string) error {
+	canonical, err := s.tagRepo.ResolveAlias(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to check tag aliases: %w", err)
+	}
+	if canonical != nil {
+		return fmt.Errorf("%w: %s is an alias of %s", image.ErrAliasConflict, name, canonical.Name)
+	}
+	return nil
+}
+
+// invalidateTaggedImages drops cached copies of every image carrying the tag
+func (s *TagServiceImpl) invalidateTaggedImages(ctx context.Context, tagID int) {
+	if s.cache == nil {
+		return
+	}
+	imageIDs, err := s.tagRepo.GetImageIDs(ctx, tagID)
+	if err != nil {
+		_ = err
+	}
+	s.invalidateImages(ctx, imageIDs)
+}
+
+// invalidateImages drops cached copies of the given images and all cached lists
+func (s *TagServiceImpl) invalidateImages(ctx context.Context, imageIDs []int) {
+	if s.cache == nil {
+		return
+	}
+	for _, id := range imageIDs {
+		if err := s.cache.DeleteImage(ctx, id); err != nil {
+			_ = err
+		}
+	}
+	if err := s.cache.InvalidateImageLists(ctx); err != nil {
+		_ = err
+	}
+}
@@ -59,7 +59,8 @@ func (v *ValidationServiceImpl) validateBasicFields(req *image.CreateImageReques
 func (v *ValidationServiceImpl) validateContentType(contentType string) error {
 	supportedTypes := []string{
 		"image/jpeg", "image/jpg", "image/png", "image/gif",
-		"image/webp", "image/bmp", "image/tiff",
+		"image/webp", "image/bmp", "image/tiff", "image/heic", "image/heif",
+		image.ContentTypeSVG,
 	}
 	for _, supportedType := range supportedTypes {
 		if contentType == supportedType {
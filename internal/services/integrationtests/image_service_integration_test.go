@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -238,6 +239,60 @@ func (s *ImageServiceIntegrationTestSuite) TestImageWithTags_Integration() {
 	assert.NotContains(s.T(), tagNames, "lifecycle")
 }
 
+// TestAttachTag_ConcurrentAttachmentsStayWithinLimit tests that the tag limit holds
+// when tags are attached to an image at the same time
+func (s *ImageServiceIntegrationTestSuite) TestAttachTag_ConcurrentAttachmentsStayWithinLimit() {
+	// Given: An image two tags short of the limit
+	tags := make([]string, image.MaxTagsPerImage-2)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("existing-%d", i)
+	}
+	req := &image.CreateImageRequest{
+		OriginalFilename: "nearly-full.jpg",
+		ContentType:      "image/jpeg",
+		FileSize:         2048,
+		Tags:             tags,
+	}
+	createdImage, err := s.imageService.CreateImage(s.ctx, req, strings.NewReader(string(testutils.GenerateTestImageData(100, 100))))
+	require.NoError(s.T(), err, "Image creation with tags should succeed")
+
+	// When: Attaching more new tags than fit, all at once
+	const attempts = 8
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.imageService.AttachTag(s.ctx, createdImage.ID, fmt.Sprintf("concurrent-%d", i))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Then: Only the tags that fit are attached; the rest are refused
+	attached := 0
+	for err := range errs {
+		if err == nil {
+			attached++
+			continue
+		}
+		assert.ErrorIs(s.T(), err, image.ErrTagLimitExceeded)
+	}
+	assert.Equal(s.T(), 2, attached, "Only two more tags fit")
+
+	retrievedImage, err := s.imageService.GetImage(s.ctx, createdImage.ID)
+	require.NoError(s.T(), err, "Image retrieval should succeed")
+	assert.Len(s.T(), retrievedImage.Tags, image.MaxTagsPerImage, "Image should be exactly at the tag limit")
+
+	// When: Attaching one of its tags again
+	_, err = s.imageService.AttachTag(s.ctx, createdImage.ID, "existing-0")
+
+	// Then: Re-attaching is not refused by the limit
+	assert.NoError(s.T(), err, "Re-attaching a tag should succeed at the limit")
+}
+
 // TestImageStats_Integration tests image statistics functionality
 func (s *ImageServiceIntegrationTestSuite) TestImageStats_Integration() {
 	// Given: Multiple images with different properties
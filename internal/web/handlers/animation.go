@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// imageThumbnailHandler serves the thumbnail stored for an animated GIF at upload
+// (GET /api/images/{id}/thumbnail)
+func (h *Handler) imageThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ImageThumbnailHandler",
+		attribute.String("handler", "image_thumbnail"),
+	)
+	defer h.endSpan(span)
+
+	imageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int("image.id", imageID))
+
+	if h.imageService == nil || h.storageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	img, err := h.imageService.GetImage(ctx, imageID)
+	if err != nil {
+		h.handleError(ctx, span, err, "", "image_not_found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if img.ThumbnailPath == nil {
+		http.Error(w, "Thumbnail not found", http.StatusNotFound)
+		return
+	}
+
+	reader, err := h.storageService.Retrieve(ctx, *img.ThumbnailPath)
+	if err != nil {
+		h.handleError(ctx, span, err, "Failed to retrieve thumbnail", "thumbnail_retrieve_failed", "")
+		http.Error(w, "Failed to retrieve thumbnail", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = reader.Close() }() //nolint:errcheck // Resource cleanup
+
+	// Thumbnails are written once at upload and image IDs are not reused
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if _, err := io.Copy(w, reader); err != nil {
+		h.handleError(ctx, span, err, "Failed to serve thumbnail", "", "")
+	}
+}
+
+// thumbnailURL returns the URL of the animated thumbnail stored for an image, or ""
+func thumbnailURL(img *image.Image) string {
+	if img.ThumbnailPath == nil || *img.ThumbnailPath != image.AnimatedThumbnailKey(img.ID) {
+		return ""
+	}
+	return fmt.Sprintf("/api/images/%d/thumbnail", img.ID)
+}
+
+// cardImageURL returns the image a gallery card shows: the animated thumbnail when there
+// is one, so GIFs keep moving, and the card rendition otherwise
+func cardImageURL(img ImageResponse) string {
+	if img.ThumbnailURL != "" {
+		return img.ThumbnailURL
+	}
+	return renditionURL(img, "card")
+}
+
+// lightboxImageURL returns the image the lightbox opens. Animations open as the original,
+// as the lightbox rendition would be a still of the first frame.
+func lightboxImageURL(img ImageResponse) string {
+	if img.Animation != nil {
+		return img.URL
+	}
+	return renditionURL(img, "lightbox")
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimatedCardImages(t *testing.T) {
+	img := &image.Image{ID: 5, Variants: []image.ImageVariant{{Width: 320, Format: image.RenderFormatJPEG}}}
+	require.NoError(t, img.SetAnimation(&image.Animation{FrameCount: 8, DurationMS: 800}))
+	key := image.AnimatedThumbnailKey(img.ID)
+	img.ThumbnailPath = &key
+
+	resp := ImageResponse{
+		URL:          "/api/images/5/view",
+		Renditions:   renditionURLs(img.ID),
+		Variants:     variantResponses(img),
+		Animation:    img.Animation(),
+		ThumbnailURL: thumbnailURL(img),
+	}
+	assert.Equal(t, "/api/images/5/thumbnail", cardImageURL(resp))
+	assert.Empty(t, srcsetAttributes(resp), "still variants would replace the animated thumbnail")
+	assert.Equal(t, "/api/images/5/view", lightboxImageURL(resp))
+
+	still := ImageResponse{URL: "/api/images/6/view", Renditions: renditionURLs(6)}
+	assert.Equal(t, "/api/images/6/render?preset=card", cardImageURL(still))
+	assert.Equal(t, "/api/images/6/render?preset=lightbox", lightboxImageURL(still))
+
+	other := "thumbnails/6.jpg"
+	assert.Empty(t, thumbnailURL(&image.Image{ID: 6, ThumbnailPath: &other}),
+		"only thumbnails stored for animations are served")
+}
+
+func TestBuildAnimatedBadge(t *testing.T) {
+	h := &Handler{}
+
+	badge := h.buildAnimatedBadge(ImageResponse{Animation: &image.Animation{FrameCount: 24, DurationMS: 2400}})
+	assert.Contains(t, badge, ">animated<")
+	assert.Contains(t, badge, `title="24 frames, 2.4s"`)
+
+	assert.Empty(t, h.buildAnimatedBadge(ImageResponse{}))
+}
@@ -37,6 +37,11 @@ func (h *Handler) listImagesHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse tag filters from query parameters
 	tagFilters := r.URL.Query()["tags"]
 	matchAll := r.URL.Query().Get("match_all") == queryParamTrue
+	photo, err := parsePhotoFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Create child span for this handler
 	var span trace.Span
@@ -55,8 +60,14 @@ func (h *Handler) listImagesHandler(w http.ResponseWriter, r *http.Request) {
 	if len(tagFilters) > 0 && span != nil {
 		span.SetAttributes(attribute.StringSlice("tag_filters.tags", tagFilters))
 	}
+	if span != nil && (photo.Camera != "" || photo.Lens != "") {
+		span.SetAttributes(
+			attribute.String("photo_filters.camera", photo.Camera),
+			attribute.String("photo_filters.lens", photo.Lens),
+		)
+	}
 
-	images, err := h.getStorageImages(ctx, tagFilters, matchAll)
+	images, err := h.getStorageImages(ctx, tagFilters, matchAll, photo)
 	if err != nil {
 		if span != nil {
 			span.RecordError(err)
@@ -95,8 +106,54 @@ func (h *Handler) listImagesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// photoFilters narrows and orders the image list by the capture details extracted on upload
+type photoFilters struct {
+	Camera      string     // Substring of the camera make and model
+	Lens        string     // Substring of the lens name
+	SortBy      string     // image.SortByUploadedAt (default) or image.SortByTakenAt
+	TakenAfter  *time.Time // Inclusive lower bound on the capture time
+	TakenBefore *time.Time // Exclusive upper bound on the capture time
+}
+
+// parsePhotoFilters reads ?camera=, ?lens=, ?sort= and the RFC 3339 or YYYY-MM-DD
+// ?taken_after= and ?taken_before= bounds
+func parsePhotoFilters(r *http.Request) (photoFilters, error) {
+	query := r.URL.Query()
+	filters := photoFilters{
+		Camera: strings.TrimSpace(query.Get("camera")),
+		Lens:   strings.TrimSpace(query.Get("lens")),
+		SortBy: query.Get("sort"),
+	}
+	if filters.SortBy != "" && filters.SortBy != image.SortByUploadedAt && filters.SortBy != image.SortByTakenAt {
+		return filters, fmt.Errorf("sort must be %s or %s", image.SortByUploadedAt, image.SortByTakenAt)
+	}
+
+	var err error
+	if filters.TakenAfter, err = parseTimeParam(query.Get("taken_after")); err != nil {
+		return filters, fmt.Errorf("invalid taken_after: %w", err)
+	}
+	if filters.TakenBefore, err = parseTimeParam(query.Get("taken_before")); err != nil {
+		return filters, fmt.Errorf("invalid taken_before: %w", err)
+	}
+	return filters, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 time or UTC date
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, value); err != nil {
+			return nil, fmt.Errorf("expected RFC 3339 time or YYYY-MM-DD date")
+		}
+	}
+	return &t, nil
+}
+
 // getStorageImages fetches images from database with metadata
-func (h *Handler) getStorageImages(ctx context.Context, tagFilters []string, matchAll bool) ([]ImageResponse, error) {
+func (h *Handler) getStorageImages(ctx context.Context, tagFilters []string, matchAll bool, photo photoFilters) ([]ImageResponse, error) {
 	ctx, span := h.startSpan(ctx, "getStorageImages",
 		attribute.Int("tag_filters.count", len(tagFilters)),
 		attribute.Bool("tag_filters.match_all", matchAll),
@@ -105,7 +162,7 @@ func (h *Handler) getStorageImages(ctx context.Context, tagFilters []string, mat
 
 	// Try ImageService first (preferred path with full metadata)
 	if h.imageService != nil {
-		return h.getImagesFromService(ctx, span, tagFilters, matchAll)
+		return h.getImagesFromService(ctx, span, tagFilters, matchAll, photo)
 	}
 
 	// Fallback to storage-only approach
@@ -113,12 +170,17 @@ func (h *Handler) getStorageImages(ctx context.Context, tagFilters []string, mat
 }
 
 // getImagesFromService fetches images using the ImageService
-func (h *Handler) getImagesFromService(ctx context.Context, span trace.Span, tagFilters []string, matchAll bool) ([]ImageResponse, error) {
+func (h *Handler) getImagesFromService(ctx context.Context, span trace.Span, tagFilters []string, matchAll bool, photo photoFilters) ([]ImageResponse, error) {
 	listReq := &image.ListImagesRequest{
-		Page:     1,
-		PageSize: 100,
-		Tags:     tagFilters,
-		MatchAll: matchAll,
+		Page:        1,
+		PageSize:    100,
+		Tags:        tagFilters,
+		MatchAll:    matchAll,
+		Camera:      photo.Camera,
+		Lens:        photo.Lens,
+		SortBy:      photo.SortBy,
+		TakenAfter:  photo.TakenAfter,
+		TakenBefore: photo.TakenBefore,
 	}
 
 	listResp, err := h.imageService.ListImages(ctx, listReq)
@@ -147,27 +209,54 @@ func (h *Handler) convertDomainImagesToResponse(domainImages []image.Image) []Im
 		// Use proxy endpoint for reliable access from browser
 		url := fmt.Sprintf("/api/images/%d/view", img.ID)
 
-		// Extract tag names
+		// Extract tag names and any colors set by an admin
 		var tagNames []string
+		var tagColors map[string]string
 		for _, tag := range img.Tags {
 			tagNames = append(tagNames, tag.Name)
+			if tag.Color != "" {
+				if tagColors == nil {
+					tagColors = make(map[string]string)
+				}
+				tagColors[tag.Name] = tag.Color
+			}
 		}
 
+		processingStatus, processingError := img.ProcessingStatus()
 		images = append(images, ImageResponse{
-			ID:          fmt.Sprintf("%d", img.ID),
-			Name:        img.OriginalFilename,
-			URL:         url,
-			Size:        img.FileSize,
-			UploadTime:  img.UploadedAt.Format("2006-01-02 15:04:05"),
-			ContentType: img.ContentType,
-			Width:       img.Width,
-			Height:      img.Height,
-			Tags:        tagNames,
+			ID:             fmt.Sprintf("%d", img.ID),
+			Name:           img.OriginalFilename,
+			URL:            url,
+			Size:           img.FileSize,
+			UploadTime:     img.UploadedAt.Format("2006-01-02 15:04:05"),
+			ContentType:    img.ContentType,
+			Width:          img.Width,
+			Height:         img.Height,
+			Tags:           tagNames,
+			TagColors:      tagColors,
+			Photo:          img.Photo(),
+			MetadataPolicy: img.MetadataPolicy(),
+			TakenAt:        formatTakenAt(img.TakenAt),
+			Renditions:     imageRenditionURLs(img),
+			Variants:       variantResponses(img),
+			Animation:      img.Animation(),
+			ThumbnailURL:   thumbnailURL(img),
+
+			ProcessingStatus: processingStatus,
+			ProcessingError:  processingError,
 		})
 	}
 	return images
 }
 
+// formatTakenAt formats a capture time like the upload time, or "" for images without one
+func formatTakenAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
 // getImagesFromStorage fetches images from storage service (fallback)
 func (h *Handler) getImagesFromStorage(ctx context.Context, span trace.Span) ([]ImageResponse, error) {
 	storageServiceImpl, ok := h.storageService.(*implementations.StorageServiceImpl)
@@ -262,7 +351,7 @@ func (h *Handler) renderGalleryHTML(images []ImageResponse, tagFilters []string)
 
 // renderImageCard generates HTML for a single image card
 func (h *Handler) renderImageCard(img ImageResponse) string {
-	metadataBadges := h.buildDimensionsBadge(img) + h.buildContentTypeBadge(img)
+	metadataBadges := h.buildDimensionsBadge(img) + h.buildContentTypeBadge(img) + h.buildAnimatedBadge(img)
 	tagsBadges := h.buildTagsBadges(img)
 
 	return fmt.Sprintf(`
@@ -279,7 +368,7 @@ func (h *Handler) renderImageCard(img ImageResponse) string {
 					</button>
 				</div>
 			</div>
-			<img src="%s" alt="%s" class="gallery-image cursor-pointer"
+			<img src="%s"%s alt="%s" class="gallery-image cursor-pointer"
 				 onclick="openModal('%s', '%s', %d)">
 			<div class="p-3">
 				<h3 class="font-medium text-gray-900 truncate mb-2">%s</h3>
@@ -292,7 +381,7 @@ func (h *Handler) renderImageCard(img ImageResponse) string {
 			</div>
 		</div>`,
 		img.ID, img.ID, img.ID, img.Name,
-		img.URL, img.Name, img.URL, img.Name, img.Size,
+		cardImageURL(img), srcsetAttributes(img), img.Name, lightboxImageURL(img), img.Name, img.Size,
 		img.Name,
 		metadataBadges,
 		formatFileSize(img.Size),
@@ -318,6 +407,15 @@ func (h *Handler) buildContentTypeBadge(img ImageResponse) string {
 	return fmt.Sprintf(`<span class="inline-block bg-purple-100 text-purple-800 text-xs px-2 py-1 rounded mr-1">%s</span>`, formatLabel)
 }
 
+// buildAnimatedBadge creates the badge of animated images, with their frame count and duration as its tooltip
+func (h *Handler) buildAnimatedBadge(img ImageResponse) string {
+	if img.Animation == nil {
+		return ""
+	}
+	return fmt.Sprintf(`<span class="inline-block bg-pink-100 text-pink-800 text-xs px-2 py-1 rounded mr-1" title="%d frames, %.1fs">animated</span>`,
+		img.Animation.FrameCount, float64(img.Animation.DurationMS)/1000)
+}
+
 // getFormatLabel returns human-readable format label for content type
 func (h *Handler) getFormatLabel(contentType string) string {
 	switch contentType {
@@ -329,6 +427,14 @@ func (h *Handler) getFormatLabel(contentType string) string {
 		return "GIF"
 	case "image/webp":
 		return "WebP"
+	case "image/bmp":
+		return "BMP"
+	case "image/tiff":
+		return "TIFF"
+	case "image/heic", "image/heif":
+		return "HEIC"
+	case image.ContentTypeSVG:
+		return "SVG"
 	default:
 		return "Image"
 	}
@@ -342,9 +448,9 @@ func (h *Handler) buildTagsBadges(img ImageResponse) string {
 
 	var badges strings.Builder
 	for _, tag := range img.Tags {
-		colorClass := settings.GetLightTagColorClass(tag)
-		badges.WriteString(fmt.Sprintf(`<button onclick="filterByTag('%s')" class="inline-block %s text-xs px-2 py-1 rounded mr-1 mb-1 cursor-pointer hover:opacity-80 transition-opacity">%s</button>`,
-			tag, colorClass, tag))
+		colorClass, style := settings.GetTagBadgeStyle(tag, img.TagColors[tag])
+		badges.WriteString(fmt.Sprintf(`<button onclick="filterByTag('%s')" class="inline-block %s text-xs px-2 py-1 rounded mr-1 mb-1 cursor-pointer hover:opacity-80 transition-opacity" style="%s">%s</button>`,
+			tag, colorClass, style, tag))
 	}
 
 	return fmt.Sprintf(`<div class="mt-2">%s</div>`, badges.String())
@@ -454,6 +560,12 @@ func (h *Handler) getImageHandler(w http.ResponseWriter, r *http.Request) {
 	)
 	defer h.endSpan(span)
 
+	// Numeric IDs are database images, returned with their tags and photo metadata
+	if imageID, err := strconv.Atoi(imagePath); err == nil && h.imageService != nil {
+		h.writeImageDetail(ctx, span, w, imageID)
+		return
+	}
+
 	// Check if image exists
 	exists, err := h.storageService.Exists(ctx, imagePath)
 	if err != nil {
@@ -506,6 +618,30 @@ func (h *Handler) getImageHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeImageDetail responds with a database image including its photo metadata
+func (h *Handler) writeImageDetail(ctx context.Context, span trace.Span, w http.ResponseWriter, imageID int) {
+	img, err := h.imageService.GetImage(ctx, imageID)
+	if err != nil {
+		// The repository reports a missing row as a plain error, so treat any failure as not found
+		h.handleError(ctx, span, err, "Failed to get image", "image not found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
+	response := h.convertDomainImagesToResponse([]image.Image{*img})[0]
+	h.setSpanAttributes(span,
+		attribute.Bool("image.found", true),
+		attribute.Bool("image.has_photo_metadata", response.Photo != nil),
+	)
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 // Helper methods to reduce cyclomatic complexity in handlers
 
 func (h *Handler) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
@@ -559,24 +695,40 @@ func (h *Handler) handleError(ctx context.Context, span trace.Span, err error, l
 }
 
 type ImageResponse struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name,omitempty"`
-	URL         string   `json:"url"`
-	Size        int64    `json:"size"`
-	UploadTime  string   `json:"upload_time"`
-	ContentType string   `json:"content_type,omitempty"`
-	Width       *int     `json:"width,omitempty"`
-	Height      *int     `json:"height,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name,omitempty"`
+	URL            string                 `json:"url"`
+	Size           int64                  `json:"size"`
+	UploadTime     string                 `json:"upload_time"`
+	ContentType    string                 `json:"content_type,omitempty"`
+	Width          *int                   `json:"width,omitempty"`
+	Height         *int                   `json:"height,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+	TagColors      map[string]string      `json:"tag_colors,omitempty"`      // Admin-assigned colors by tag name
+	Photo          *image.PhotoMetadata   `json:"photo,omitempty"`           // Camera, exposure, capture time and GPS from EXIF/XMP/IPTC
+	TakenAt        string                 `json:"taken_at,omitempty"`        // Capture time, or the upload time when unknown
+	MetadataPolicy image.MetadataPolicy   `json:"metadata_policy,omitempty"` // Metadata removed from the stored file at upload
+	Renditions     map[string]string      `json:"renditions,omitempty"`      // Resized derivative URLs by preset (thumb, card, lightbox, social)
+	Variants       []ImageVariantResponse `json:"variants,omitempty"`        // Responsive widths generated at upload, narrowest first
+	Animation      *image.Animation       `json:"animation,omitempty"`       // Frame count and duration of animated GIFs and WebPs
+	ThumbnailURL   string                 `json:"thumbnail_url,omitempty"`   // Animated GIFs: thumbnail that keeps the animation
+
+	ProcessingStatus image.ProcessingStatus `json:"processing_status,omitempty"` // pending, processing, ready or failed
+	ProcessingError  string                 `json:"processing_error,omitempty"`  // The failed step and its error
 }
 
 func isImageContentType(contentType string) bool {
 	supportedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/gif":  true,
-		"image/webp": true,
+		"image/jpeg":         true,
+		"image/jpg":          true,
+		"image/png":          true,
+		"image/gif":          true,
+		"image/webp":         true,
+		"image/bmp":          true,
+		"image/tiff":         true,
+		"image/heic":         true,
+		"image/heif":         true,
+		image.ContentTypeSVG: true,
 	}
 	return supportedTypes[contentType]
 }
@@ -589,17 +741,25 @@ func isImageFile(filename, contentType string) bool {
 
 	// If content type is missing or not recognized, check file extension
 	ext := strings.ToLower(filepath.Ext(filename))
-	supportedExtensions := map[string]string{
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".png":  "image/png",
-		".gif":  "image/gif",
-		".webp": "image/webp",
-	}
-	_, isSupported := supportedExtensions[ext]
+	_, isSupported := imageExtensions[ext]
 	return isSupported
 }
 
+// imageExtensions maps the file extensions of supported formats to their content types
+var imageExtensions = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".heic": "image/heic",
+	".heif": "image/heif",
+	".svg":  image.ContentTypeSVG,
+}
+
 func extractOriginalFilename(metadata map[string]string, objectKey string) string {
 	if filename, exists := metadata["original-filename"]; exists {
 		return filename
@@ -877,6 +1037,7 @@ func (h *Handler) getPredefinedTagsHandler(w http.ResponseWriter, r *http.Reques
 		Description string `json:"description,omitempty"`
 		Color       string `json:"color"`
 		ColorClass  string `json:"color_class"`
+		Style       string `json:"style,omitempty"`
 	}
 
 	response := make([]TagResponse, len(tags))
@@ -885,12 +1046,18 @@ func (h *Handler) getPredefinedTagsHandler(w http.ResponseWriter, r *http.Reques
 		if tag.Description != nil {
 			desc = *tag.Description
 		}
+		storedColor := ""
+		if tag.Color != nil {
+			storedColor = *tag.Color
+		}
+		colorClass, style := settings.GetTagBadgeStyle(tag.Name, storedColor)
 		response[i] = TagResponse{
 			ID:          tag.ID,
 			Name:        tag.Name,
 			Description: desc,
-			Color:       settings.GetTagColor(tag.Name),
-			ColorClass:  settings.GetLightTagColorClass(tag.Name),
+			Color:       settings.ResolveTagColor(tag.Name, storedColor),
+			ColorClass:  colorClass,
+			Style:       style,
 		}
 	}
 
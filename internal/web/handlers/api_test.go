@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePhotoFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		sortBy      string
+		takenAfter  string
+		takenBefore string
+		wantErr     bool
+	}{
+		{name: "no filters", query: ""},
+		{name: "capture order", query: "sort=taken_at", sortBy: "taken_at"},
+		{
+			name:        "date bounds",
+			query:       "taken_after=2024-05-01&taken_before=2024-06-01T00:00:00Z",
+			takenAfter:  "2024-05-01T00:00:00Z",
+			takenBefore: "2024-06-01T00:00:00Z",
+		},
+		{name: "unknown sort", query: "sort=name", wantErr: true},
+		{name: "malformed date", query: "taken_after=May+2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/images?"+tt.query, nil)
+			filters, err := parsePhotoFilters(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.sortBy, filters.SortBy)
+			assertTimeParam(t, tt.takenAfter, filters.TakenAfter)
+			assertTimeParam(t, tt.takenBefore, filters.TakenBefore)
+		})
+	}
+}
+
+func assertTimeParam(t *testing.T, expected string, actual *time.Time) {
+	t.Helper()
+	if expected == "" {
+		assert.Nil(t, actual)
+		return
+	}
+	require.NotNil(t, actual)
+	assert.Equal(t, expected, actual.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// backfillProgressHandler reports how far the derived data backfill has got
+// (GET /api/admin/backfill)
+func (h *Handler) backfillProgressHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "BackfillProgressHandler",
+		attribute.String("handler", "backfill_progress"),
+	)
+	defer h.endSpan(span)
+
+	if h.backfill == nil {
+		http.Error(w, "Backfill not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	progress, err := h.backfill.Progress(ctx)
+	if err != nil {
+		h.handleError(ctx, span, err, "Failed to get backfill progress", "backfill_progress_failed", "")
+		http.Error(w, "Failed to get backfill progress", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSpanAttributes(span,
+		attribute.Bool("backfill.running", progress.Running),
+		attribute.Int("backfill.remaining", progress.Remaining),
+	)
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(progress); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// startBackfillHandler starts the derived data backfill in the background, from its
+// checkpoint or, with ?restart=true, from the first image (POST /api/admin/backfill)
+func (h *Handler) startBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	restart, _ := strconv.ParseBool(r.URL.Query().Get("restart")) //nolint:errcheck // Anything else means false
+
+	ctx, span := h.startSpan(r.Context(), "StartBackfillHandler",
+		attribute.String("handler", "start_backfill"),
+		attribute.Bool("backfill.restart", restart),
+	)
+	defer h.endSpan(span)
+
+	if h.backfill == nil {
+		http.Error(w, "Backfill not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !h.backfill.Start(restart) {
+		h.setSpanStatus(span, codes.Error, "backfill_already_running")
+		http.Error(w, "Backfill is already running", http.StatusConflict)
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	if h.logger != nil {
+		h.logger.Info(ctx).Bool("restart", restart).Msg("Backfill started")
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
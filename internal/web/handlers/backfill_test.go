@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackfillRunner struct {
+	running bool
+	restart bool
+}
+
+func (f *fakeBackfillRunner) Start(restart bool) bool {
+	if f.running {
+		return false
+	}
+	f.running, f.restart = true, restart
+	return true
+}
+
+func (f *fakeBackfillRunner) Progress(context.Context) (*image.BackfillProgress, error) {
+	return &image.BackfillProgress{
+		BackfillCheckpoint: image.BackfillCheckpoint{Job: image.BackfillJobName, LastImageID: 42, Processed: 40, Failed: 2},
+		Running:            f.running,
+		Remaining:          7,
+	}, nil
+}
+
+func TestBackfillHandlers(t *testing.T) {
+	runner := &fakeBackfillRunner{}
+	h := &Handler{backfill: runner}
+
+	rec := httptest.NewRecorder()
+	h.startBackfillHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/backfill?restart=true", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.True(t, runner.restart)
+
+	rec = httptest.NewRecorder()
+	h.startBackfillHandler(rec, httptest.NewRequest(http.MethodPost, "/api/admin/backfill", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.backfillProgressHandler(rec, httptest.NewRequest(http.MethodGet, "/api/admin/backfill", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var progress map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &progress))
+	assert.Equal(t, float64(42), progress["last_image_id"])
+	assert.Equal(t, float64(7), progress["remaining"])
+	assert.Equal(t, true, progress["running"])
+
+	rec = httptest.NewRecorder()
+	(&Handler{}).backfillProgressHandler(rec, httptest.NewRequest(http.MethodGet, "/api/admin/backfill", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// maxPresignRequestSize caps the JSON body of a presign request, which carries no file
+const maxPresignRequestSize = 64 * 1024
+
+// presignUploadHandler returns a policy for the browser to upload one image straight to
+// storage. The body names the file, and optionally its content type, tags and
+// metadata_policy as for form uploads (POST /api/uploads/presign).
+func (h *Handler) presignUploadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "PresignUploadHandler",
+		attribute.String("handler", "presign_upload"),
+	)
+	defer h.endSpan(span)
+
+	if h.directUploads == nil {
+		http.Error(w, "Direct uploads not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req image.PresignUploadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPresignRequestSize)).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid request body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ContentType == "" || req.ContentType == "application/octet-stream" {
+		req.ContentType = imageExtensions[strings.ToLower(filepath.Ext(req.Filename))]
+	}
+	req.MetadataPolicy = image.MetadataPolicy(strings.ToLower(strings.TrimSpace(string(req.MetadataPolicy))))
+	req.Tags = h.resolveTagAliases(ctx, req.Tags)
+
+	h.setSpanAttributes(span,
+		attribute.String("upload.filename", req.Filename),
+		attribute.String("upload.content_type", req.ContentType),
+	)
+
+	upload, err := h.directUploads.PresignUpload(ctx, &req)
+	if err != nil {
+		if isInvalidImageError(err) {
+			h.handleError(ctx, span, err, "", "invalid_upload", "")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to presign upload", "presign_failed", "")
+		http.Error(w, "Failed to presign upload", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.String("upload.key", upload.Key))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(upload); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// completeUploadHandler registers the image a browser uploaded with a presigned policy,
+// once it has been checked to be the image the policy was for
+// (POST /api/uploads/{key}/complete)
+func (h *Handler) completeUploadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "CompleteUploadHandler",
+		attribute.String("handler", "complete_upload"),
+	)
+	defer h.endSpan(span)
+
+	if h.directUploads == nil {
+		http.Error(w, "Direct uploads not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	key := chi.URLParam(r, "key")
+	h.setSpanAttributes(span, attribute.String("upload.key", key))
+
+	img, err := h.directUploads.CompleteUpload(ctx, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrUploadNotFound):
+			h.handleError(ctx, span, err, "", "upload_not_found", "")
+			http.Error(w, "Upload not found", http.StatusNotFound)
+		case errors.Is(err, image.ErrUploadLocked):
+			h.handleError(ctx, span, err, "", "upload_locked", "")
+			http.Error(w, "Upload is being completed by another request", http.StatusConflict)
+		case errors.Is(err, image.ErrUploadTooLarge):
+			h.handleError(ctx, span, err, "", "upload_too_large", "")
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case isInvalidImageError(err):
+			h.handleError(ctx, span, err, "", "invalid_image", "")
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			h.handleError(ctx, span, err, "Failed to complete upload", "complete_failed", "")
+			http.Error(w, "Failed to complete upload", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var imageURL string
+	if h.storageService != nil {
+		if url, err := h.storageService.GenerateURL(ctx, img.StoragePath, 3600); err == nil { // 1 hour expiry
+			imageURL = url
+		}
+	}
+
+	h.setSpanAttributes(span, attribute.Int("image.id", img.ID))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(newUploadedImageInfo(img, imageURL)); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
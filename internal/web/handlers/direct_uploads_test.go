@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectUploads presigns key abc123 and completes it into image 42
+type fakeDirectUploads struct {
+	presigned   *image.PresignUploadRequest
+	completeErr error
+}
+
+func (f *fakeDirectUploads) PresignUpload(_ context.Context, req *image.PresignUploadRequest) (*image.PresignedUpload, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	f.presigned = req
+	return &image.PresignedUpload{
+		Key:       "abc123",
+		URL:       "http://storage.test/images",
+		Fields:    map[string]string{"key": image.DirectUploadPrefix + "abc123"},
+		MaxSize:   1 << 20,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil
+}
+
+func (f *fakeDirectUploads) CompleteUpload(_ context.Context, key string) (*image.Image, error) {
+	if key != "abc123" {
+		return nil, image.ErrUploadNotFound
+	}
+	if f.completeErr != nil {
+		return nil, f.completeErr
+	}
+	return &image.Image{ID: 42, OriginalFilename: "beach.png", ContentType: "image/png", FileSize: 10}, nil
+}
+
+func TestDirectUploadHandlers(t *testing.T) {
+	uploads := &fakeDirectUploads{}
+	h := &Handler{directUploads: uploads}
+	r := chi.NewRouter()
+	r.Route("/api/uploads", func(r chi.Router) {
+		r.Post("/presign", h.presignUploadHandler)
+		r.Post("/{key}/complete", h.completeUploadHandler)
+	})
+	serve := func(target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/api/uploads/presign", `{"filename": "beach.png", "tags": ["sea"], "metadata_policy": "Strip_GPS"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	var presigned image.PresignedUpload
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &presigned))
+	assert.Equal(t, "abc123", presigned.Key)
+	assert.Equal(t, "http://storage.test/images", presigned.URL)
+	assert.Equal(t, image.DirectUploadPrefix+"abc123", presigned.Fields["key"])
+	assert.Equal(t, "image/png", uploads.presigned.ContentType, "the content type follows the extension")
+	assert.Equal(t, image.MetadataPolicyStripGPS, uploads.presigned.MetadataPolicy)
+
+	rec = serve("/api/uploads/abc123/complete", "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created UploadedImageInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, 42, created.ID)
+	assert.Equal(t, "beach.png", created.OriginalFilename)
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			target      string
+			body        string
+			completeErr error
+			want        int
+		}{
+			{"malformed body", "/api/uploads/presign", `{"filename":`, nil, http.StatusBadRequest},
+			{"not an image", "/api/uploads/presign", `{"filename": "notes.txt"}`, nil, http.StatusBadRequest},
+			{"unknown upload", "/api/uploads/missing/complete", "", nil, http.StatusNotFound},
+			{"completed by another request", "/api/uploads/abc123/complete", "", image.ErrUploadLocked, http.StatusConflict},
+			{"over the size limit", "/api/uploads/abc123/complete", "", image.ErrUploadTooLarge, http.StatusRequestEntityTooLarge},
+			{"not the image presigned", "/api/uploads/abc123/complete", "", fmt.Errorf("%w: file is gif, not image/png", image.ErrInvalidContentType), http.StatusUnprocessableEntity},
+			{"storage failure", "/api/uploads/abc123/complete", "", fmt.Errorf("storage unavailable"), http.StatusInternalServerError},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				uploads.completeErr = tt.completeErr
+				rec := serve(tt.target, tt.body)
+				assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			})
+		}
+	})
+
+	t.Run("reports direct uploads unavailable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/uploads/presign", strings.NewReader(`{"filename": "a.png"}`))
+		rec := httptest.NewRecorder()
+		(&Handler{}).presignUploadHandler(rec, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
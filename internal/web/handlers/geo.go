@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"image-gallery/internal/domain/image"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// defaultGeoZoom clusters as for a whole-world map when no zoom is given
+const defaultGeoZoom = 2
+
+// GeoFeatureCollection is a GeoJSON (RFC 7946) FeatureCollection of image clusters
+type GeoFeatureCollection struct {
+	Type     string       `json:"type"`
+	BBox     []float64    `json:"bbox"`
+	Features []GeoFeature `json:"features"`
+}
+
+// GeoFeature is a GeoJSON Point feature for one image or a cluster of nearby images
+type GeoFeature struct {
+	Type       string               `json:"type"`
+	Geometry   GeoPoint             `json:"geometry"`
+	Properties GeoFeatureProperties `json:"properties"`
+}
+
+// GeoPoint is a GeoJSON Point; coordinates are [longitude, latitude]
+type GeoPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// GeoFeatureProperties describes the images behind a map point
+type GeoFeatureProperties struct {
+	Cluster  bool   `json:"cluster"`
+	Count    int    `json:"count"`
+	ImageID  int    `json:"image_id"` // The image itself, or the newest one in a cluster
+	Name     string `json:"name"`
+	ImageURL string `json:"image_url"`
+}
+
+// geoImagesHandler returns geotagged images inside a bounding box as GeoJSON, clustered by
+// zoom level (GET /api/images/geo?bbox=minLon,minLat,maxLon,maxLat&zoom=N)
+func (h *Handler) geoImagesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "GeoImagesHandler",
+		attribute.String("handler", "geo_images"),
+	)
+	defer h.endSpan(span)
+
+	if h.imageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	bounds, zoom, err := parseGeoQuery(r)
+	if err != nil {
+		h.handleError(ctx, span, err, "", "invalid_geo_query", "")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int("geo.zoom", zoom))
+
+	clusters, err := h.imageService.GetGeoClusters(ctx, bounds, zoom)
+	if err != nil {
+		if errors.Is(err, image.ErrInvalidListFilter) {
+			h.handleError(ctx, span, err, "", "invalid_geo_query", "")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to get geotagged images", "geo_query_failed", "")
+		http.Error(w, "Failed to get geotagged images", http.StatusInternalServerError)
+		return
+	}
+
+	response := GeoFeatureCollection{
+		Type:     "FeatureCollection",
+		BBox:     []float64{bounds.MinLon, bounds.MinLat, bounds.MaxLon, bounds.MaxLat},
+		Features: make([]GeoFeature, len(clusters)),
+	}
+	for i, cluster := range clusters {
+		response.Features[i] = GeoFeature{
+			Type: "Feature",
+			Geometry: GeoPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{cluster.Longitude, cluster.Latitude},
+			},
+			Properties: GeoFeatureProperties{
+				Cluster:  cluster.Count > 1,
+				Count:    cluster.Count,
+				ImageID:  cluster.ImageID,
+				Name:     cluster.Filename,
+				ImageURL: fmt.Sprintf("/api/images/%d/view", cluster.ImageID),
+			},
+		}
+	}
+
+	h.setSpanAttributes(span, attribute.Int("geo.features", len(response.Features)))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// parseGeoQuery reads the bbox (GeoJSON order, default the whole world) and zoom parameters
+func parseGeoQuery(r *http.Request) (image.GeoBounds, int, error) {
+	query := r.URL.Query()
+	bounds := image.GeoBounds{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		parts := strings.Split(bbox, ",")
+		if len(parts) != 4 {
+			return bounds, 0, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		var values [4]float64
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return bounds, 0, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+			}
+			values[i] = v
+		}
+		bounds = image.GeoBounds{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	}
+
+	zoom := defaultGeoZoom
+	if z := query.Get("zoom"); z != "" {
+		var err error
+		if zoom, err = strconv.Atoi(z); err != nil {
+			return bounds, 0, fmt.Errorf("zoom must be an integer between 0 and %d", image.MaxGeoZoom)
+		}
+	}
+	return bounds, zoom, nil
+}
+
+// mapPageHandler serves the map of geotagged images
+func (h *Handler) mapPageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	if _, err := w.Write([]byte(mapPage)); err != nil {
+		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+const mapPage = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Map - Image Gallery</title>
+    <link href="https://cdn.jsdelivr.net/npm/tailwindcss@2.2.19/dist/tailwind.min.css" rel="stylesheet">
+    <link href="https://unpkg.com/leaflet@1.9.4/dist/leaflet.css" rel="stylesheet">
+    <script src="https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"></script>
+    <style>
+        #map {
+            height: 75vh;
+            border-radius: 0.5rem;
+        }
+        .cluster-icon {
+            background: #2563eb;
+            color: white;
+            border: 2px solid white;
+            border-radius: 9999px;
+            font-weight: bold;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            box-shadow: 0 1px 4px rgba(0, 0, 0, 0.4);
+        }
+    </style>
+</head>
+<body class="bg-gray-50">
+    <div class="container mx-auto px-4 py-8">
+        <div class="flex justify-between items-center mb-8">
+            <h1 class="text-4xl font-bold text-gray-800">Map</h1>
+            <div class="flex gap-2">
+                <a href="/timeline" class="bg-white hover:bg-gray-100 text-gray-800 font-bold py-2 px-4 rounded-lg shadow-md">Timeline</a>
+                <a href="/gallery" class="bg-white hover:bg-gray-100 text-gray-800 font-bold py-2 px-4 rounded-lg shadow-md">Gallery</a>
+            </div>
+        </div>
+        <div id="map" class="shadow-md"></div>
+        <p id="status" class="mt-2 text-sm text-gray-500"></p>
+    </div>
+
+    <script>
+        const map = L.map('map', { worldCopyJump: true }).setView([20, 0], 2);
+        L.tileLayer('https://tile.openstreetmap.org/{z}/{x}/{y}.png', {
+            maxZoom: 19,
+            attribution: '&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a> contributors'
+        }).addTo(map);
+        const markers = L.layerGroup().addTo(map);
+        let request = 0;
+
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+
+        function clamp(value, min, max) {
+            return Math.min(Math.max(value, min), max);
+        }
+
+        function marker(feature) {
+            const [lon, lat] = feature.geometry.coordinates;
+            const p = feature.properties;
+            if (!p.cluster) {
+                return L.marker([lat, lon]).bindPopup(
+                    '<a href="' + p.image_url + '" target="_blank"><img src="/api/images/' + p.image_id + '/render?preset=thumb" width="200" height="200"></a>' +
+                    '<div class="mt-1">' + escapeHtml(p.name) + '</div>');
+            }
+            const size = 28 + Math.min(4 * Math.log2(p.count), 24);
+            const icon = L.divIcon({
+                className: '',
+                html: '<div class="cluster-icon" style="width:' + size + 'px;height:' + size + 'px">' + p.count + '</div>',
+                iconSize: [size, size]
+            });
+            // Zooming in splits a cluster into smaller clusters and single images
+            return L.marker([lat, lon], { icon: icon }).on('click', () => map.setView([lat, lon], map.getZoom() + 2));
+        }
+
+        async function load() {
+            const id = ++request;
+            const b = map.getBounds();
+            // The API does not wrap around the antimeridian, so clamp to a single world copy
+            const bbox = [
+                clamp(b.getWest(), -180, 180), clamp(b.getSouth(), -90, 90),
+                clamp(b.getEast(), -180, 180), clamp(b.getNorth(), -90, 90)
+            ].join(',');
+            const response = await fetch('/api/images/geo?bbox=' + bbox + '&zoom=' + map.getZoom());
+            if (id !== request) {
+                return;
+            }
+            if (!response.ok) {
+                document.getElementById('status').textContent = 'Failed to load geotagged images';
+                return;
+            }
+            const data = await response.json();
+            markers.clearLayers();
+            let total = 0;
+            data.features.forEach(f => {
+                markers.addLayer(marker(f));
+                total += f.properties.count;
+            });
+            document.getElementById('status').textContent = total + ' geotagged image(s) in view';
+        }
+
+        map.on('moveend', load);
+        load();
+    </script>
+</body>
+</html>
+`
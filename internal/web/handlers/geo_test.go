@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGeoQuery(t *testing.T) {
+	t.Run("defaults to the whole world", func(t *testing.T) {
+		bounds, zoom, err := parseGeoQuery(httptest.NewRequest("GET", "/api/images/geo", nil))
+		require.NoError(t, err)
+		assert.Equal(t, image.GeoBounds{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}, bounds)
+		assert.Equal(t, defaultGeoZoom, zoom)
+	})
+
+	t.Run("bbox in GeoJSON order", func(t *testing.T) {
+		bounds, zoom, err := parseGeoQuery(httptest.NewRequest("GET", "/api/images/geo?bbox=2.2,48.8,2.4,48.9&zoom=12", nil))
+		require.NoError(t, err)
+		assert.Equal(t, image.GeoBounds{MinLon: 2.2, MinLat: 48.8, MaxLon: 2.4, MaxLat: 48.9}, bounds)
+		assert.Equal(t, 12, zoom)
+	})
+
+	for _, query := range []string{"bbox=1,2,3", "bbox=a,b,c,d", "zoom=far"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			_, _, err := parseGeoQuery(httptest.NewRequest("GET", "/api/images/geo?"+query, nil))
+			assert.Error(t, err)
+		})
+	}
+}
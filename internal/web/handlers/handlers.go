@@ -31,6 +31,16 @@ type Handler struct {
 	imageService   image.ImageService
 	tagService     image.TagService
 	storageService image.StorageService
+	backfill       image.BackfillRunner
+	jobs           image.JobQueue
+	uploads        image.ResumableUploadService
+	directUploads  image.DirectUploadService
+
+	// Form uploads: files of one request processed at once, and the bytes being
+	// processed across requests
+	uploadConcurrency int
+	uploadBytes       *byteBudget
+	progress          *progressHub // Progress of the uploads made with an upload session
 
 	// Observability
 	tracer      trace.Tracer
@@ -70,6 +80,14 @@ func NewWithContainer(container *services.Container) *Handler {
 		imageService:   container.ImageService(),
 		tagService:     container.TagService(),
 		storageService: container.StorageService(),
+		backfill:       container.BackfillJob(),
+		jobs:           container.JobQueue(),
+		uploads:        container.ResumableUploadService(),
+		directUploads:  container.DirectUploadService(),
+
+		uploadConcurrency: container.Config().Storage.UploadConcurrency,
+		uploadBytes:       newByteBudget(container.Config().Storage.UploadBytesInFlight),
+		progress:          newProgressHub(),
 
 		// Observability
 		tracer:      tracer,
@@ -106,15 +124,38 @@ func (h *Handler) Routes() http.Handler {
 	// Web routes
 	r.Get("/", h.indexHandler)
 	r.Get("/gallery", h.galleryHandler)
+	r.Get("/timeline", h.timelinePageHandler)
+	r.Get("/map", h.mapPageHandler)
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		r.Route("/images", func(r chi.Router) {
 			r.Get("/", h.listImagesHandler)
 			r.Post("/", h.uploadImagesHandler) // Upload images endpoint
+			r.Get("/geo", h.geoImagesHandler)  // GeoJSON clusters of geotagged images
 			r.Get("/{id}", h.getImageHandler)
-			r.Get("/{id}/view", h.viewImageHandler) // Proxy endpoint for viewing images
-			r.Delete("/{id}", h.deleteImageHandler) // Delete image endpoint
+			r.Get("/{id}/view", h.viewImageHandler)                // Proxy endpoint for viewing images
+			r.Get("/{id}/render", h.renderImageHandler)            // Resized derivative by preset
+			r.Get("/{id}/variants/{width}", h.imageVariantHandler) // Responsive variant generated at upload
+			r.Get("/{id}/thumbnail", h.imageThumbnailHandler)      // Animated GIF thumbnail generated at upload
+			r.Post("/{id}/reprocess", h.reprocessImageHandler)     // Queue the image's derived data to be regenerated
+			r.Get("/{id}/processing", h.processingStatusHandler)   // Status of each background processing step
+			r.Delete("/{id}", h.deleteImageHandler)                // Delete image endpoint
+			r.Post("/{id}/tags/{name}", h.attachTagHandler)        // Attach a single tag
+			r.Delete("/{id}/tags/{name}", h.detachTagHandler)      // Detach a single tag
+		})
+		// Upload endpoints
+		r.Route("/uploads", func(r chi.Router) {
+			r.Post("/presign", h.presignUploadHandler)         // Policy to upload straight to storage
+			r.Post("/{key}/complete", h.completeUploadHandler) // Register an image uploaded with a policy
+			r.Get("/{session}/events", h.uploadEventsHandler)  // Progress of uploads made with a session, as SSE
+			r.Route("/tus", func(r chi.Router) {
+				r.Options("/", h.tusOptionsHandler) // Protocol version, extensions and size limit
+				r.Post("/", h.tusCreateHandler)     // Start a resumable upload
+				r.Head("/{id}", h.tusHeadHandler)   // Offset to resume from
+				r.Patch("/{id}", h.tusPatchHandler) // Append a chunk; the last one creates the image
+				r.Delete("/{id}", h.tusDeleteHandler)
+			})
 		})
 		// Settings endpoints
 		r.Route("/settings", func(r chi.Router) {
@@ -124,8 +165,34 @@ func (h *Handler) Routes() http.Handler {
 		})
 		// Tags endpoints
 		r.Route("/tags", func(r chi.Router) {
+			r.Get("/", h.listTagsHandler)
+			r.Post("/", h.createTagHandler)
 			r.Get("/predefined", h.getPredefinedTagsHandler) // Get predefined tags
+			r.Get("/tree", h.getTagTreeHandler)              // Tag hierarchy for the filter panel
+			r.Get("/categories", h.getTagCategoriesHandler)
+			r.Put("/categories/{name}", h.renameTagCategoryHandler)
+			r.Get("/aliases", h.listTagAliasesHandler)
+			r.Delete("/aliases/{alias}", h.deleteTagAliasHandler)
+			r.Get("/pending", h.listPendingTagsHandler) // Approval queue (TAG_POLICY=approval)
+			r.Post("/pending/{name}/approve", h.approvePendingTagHandler)
+			r.Delete("/pending/{name}", h.rejectPendingTagHandler)
+			r.Get("/{id}", h.getTagHandler)
+			r.Patch("/{id}", h.updateTagHandler)
+			r.Delete("/{id}", h.deleteTagHandler)
+			r.Post("/{id}/merge", h.mergeTagHandler) // Merge tag {id} into target_id
+			r.Put("/{id}/parent", h.moveTagHandler)  // Move tag {id} and its subtree
+			r.Get("/{id}/aliases", h.listTagAliasesHandler)
+			r.Post("/{id}/aliases", h.createTagAliasHandler) // Alternative names resolving to tag {id}
 		})
+		r.Get("/timeline", h.timelineHandler) // Image counts per capture year, month or day
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Get("/backfill", h.backfillProgressHandler)  // Progress of the derived data backfill
+			r.Post("/backfill", h.startBackfillHandler)    // Start it, ?restart=true from the first image
+			r.Get("/jobs/failed", h.listFailedJobsHandler) // Processing jobs that failed on every attempt
+			r.Post("/jobs/{id}/retry", h.retryJobHandler)  // Queue a failed job again
+		})
+
 		// Test endpoint for observability validation (generates traces + logs)
 		r.Get("/test-db", h.testDatabaseHandler)
 	})
@@ -139,6 +206,10 @@ func (h *Handler) indexHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/gallery", http.StatusFound)
 }
 
+// svgContentSecurityPolicy is sent with SVG originals: inline styles are allowed, as SVGs
+// use them for presentation, and so are embedded raster images
+const svgContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; img-src data:; sandbox"
+
 // viewImageHandler serves images directly from storage (proxy endpoint)
 //
 //nolint:gocyclo // Handler with error handling and content type detection
@@ -188,11 +259,25 @@ func (h *Handler) viewImageHandler(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "image/gif")
 		case ".webp":
 			w.Header().Set("Content-Type", "image/webp")
+		case ".bmp":
+			w.Header().Set("Content-Type", "image/bmp")
+		case ".tif", ".tiff":
+			w.Header().Set("Content-Type", "image/tiff")
+		case ".heic", ".heif":
+			w.Header().Set("Content-Type", "image/heic")
+		case ".svg":
+			w.Header().Set("Content-Type", image.ContentTypeSVG)
 		default:
 			w.Header().Set("Content-Type", "application/octet-stream")
 		}
 	}
 
+	// SVGs are sanitized at upload; the policy also stops scripts and external loads
+	// when one is opened directly rather than through an <img> tag
+	if w.Header().Get("Content-Type") == image.ContentTypeSVG {
+		w.Header().Set("Content-Security-Policy", svgContentSecurityPolicy)
+	}
+
 	// Set cache headers
 	w.Header().Set("Cache-Control", "public, max-age=3600")
 
@@ -239,6 +324,8 @@ func (h *Handler) galleryHandler(w http.ResponseWriter, r *http.Request) {
         <div class="flex justify-between items-center mb-8">
             <h1 class="text-4xl font-bold text-gray-800">Image Gallery</h1>
             <div class="flex gap-3">
+                <a href="/timeline" class="bg-white hover:bg-gray-100 text-gray-800 font-bold py-2 px-4 rounded-lg shadow-md flex items-center">Timeline</a>
+                <a href="/map" class="bg-white hover:bg-gray-100 text-gray-800 font-bold py-2 px-4 rounded-lg shadow-md flex items-center">Map</a>
                 <button onclick="openUploadModal()" class="bg-blue-500 hover:bg-blue-700 text-white font-bold py-2 px-4 rounded-lg shadow-md flex items-center gap-2">
                     <svg class="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                         <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M7 16a4 4 0 01-.88-7.903A5 5 0 1115.9 6L16 6a5 5 0 011 9.9M15 13l-3-3m0 0l-3 3m3-3v12"></path>
@@ -255,6 +342,12 @@ func (h *Handler) galleryHandler(w http.ResponseWriter, r *http.Request) {
             </div>
         </div>
 
+        <!-- Tag Tree Panel (collapsible hierarchy; clicking a parent also matches its descendants) -->
+        <details id="tagTreePanel" class="mb-6 bg-white rounded-lg shadow-md p-4">
+            <summary class="cursor-pointer font-semibold text-gray-700">Browse tags</summary>
+            <div id="tagTree" class="mt-3 text-sm text-gray-700">Loading tags...</div>
+        </details>
+
         <!-- Active Filters Panel -->
         <div id="activeFilters"></div>
 
@@ -608,7 +701,8 @@ func (h *Handler) galleryHandler(w http.ResponseWriter, r *http.Request) {
             let html = '';
             tags.forEach(tag => {
                 const desc = tag.description || tag.name;
-                html += '<label class="flex items-center gap-2 ' + tag.color_class + ' px-3 py-2 rounded cursor-pointer hover:opacity-80 transition-opacity">' +
+                const style = tag.style ? ' style="' + tag.style + '"' : '';
+                html += '<label class="flex items-center gap-2 ' + tag.color_class + ' px-3 py-2 rounded cursor-pointer hover:opacity-80 transition-opacity"' + style + '>' +
                     '<input type="checkbox" class="tag-checkbox" value="' + tag.name + '" onchange="updateSelectedTags()" title="' + desc + '">' +
                     '<span class="text-sm font-medium">' + tag.name + '</span>' +
                     '</label>';
@@ -876,6 +970,40 @@ func (h *Handler) galleryHandler(w http.ResponseWriter, r *http.Request) {
             });
         }
 
+        // Load the tag hierarchy into the filter panel
+        async function loadTagTree() {
+            const treeElement = document.getElementById('tagTree');
+            try {
+                const response = await fetch('/api/tags/tree');
+                if (!response.ok) {
+                    throw new Error('Failed to load tag tree');
+                }
+                const nodes = await response.json();
+                treeElement.innerHTML = nodes.length > 0 ?
+                    renderTagTreeNodes(nodes) :
+                    '<div class="text-gray-500">No tags yet</div>';
+            } catch (error) {
+                console.error('Failed to load tag tree:', error);
+                treeElement.innerHTML = '<div class="text-red-500">Failed to load tags</div>';
+            }
+        }
+
+        // Render tree nodes as nested collapsible lists
+        function renderTagTreeNodes(nodes) {
+            let html = '<ul class="pl-4 space-y-1">';
+            nodes.forEach(node => {
+                const link = '<button type="button" onclick="filterByTag(\'' + node.name + '\')" ' +
+                    'class="hover:text-blue-600 hover:underline" title="' + node.path + '">' + node.name + '</button>';
+                if (node.children && node.children.length > 0) {
+                    html += '<li><details><summary class="cursor-pointer">' + link + '</summary>' +
+                        renderTagTreeNodes(node.children) + '</details></li>';
+                } else {
+                    html += '<li class="pl-3">' + link + '</li>';
+                }
+            });
+            return html + '</ul>';
+        }
+
         // Load filters from URL on page load
         function loadFiltersFromURL() {
             const params = new URLSearchParams(window.location.search);
@@ -890,6 +1018,7 @@ func (h *Handler) galleryHandler(w http.ResponseWriter, r *http.Request) {
         // Load settings and filters on page load
         loadSettings();
         loadFiltersFromURL();
+        loadTagTree();
     </script>
 </body>
 </html>
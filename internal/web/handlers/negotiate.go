@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"image-gallery/internal/domain/image"
+)
+
+// negotiateFormat returns the preferred format for a derivative of an image of the
+// content type that the Accept header explicitly allows, or "" when the client did not
+// ask for any. Browsers list image/* for every image request, so only formats named
+// explicitly are chosen.
+func negotiateFormat(accept, contentType string) image.RenderFormat {
+	for _, format := range image.NegotiableFormats(contentType) {
+		if acceptsMediaType(accept, format.ContentType()) {
+			return format
+		}
+	}
+	return ""
+}
+
+// acceptsMediaType reports whether the Accept header lists the media type with a non-zero quality
+func acceptsMediaType(accept, mediaType string) bool {
+	for _, entry := range strings.Split(accept, ",") {
+		params := strings.Split(entry, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), mediaType) {
+			continue
+		}
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
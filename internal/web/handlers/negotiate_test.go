@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		expected    image.RenderFormat
+	}{
+		{name: "no header", accept: "", contentType: "image/png", expected: ""},
+		{name: "browser image request", accept: "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8", contentType: "image/png", expected: image.RenderFormatWebP},
+		{name: "webp with a quality", accept: "image/jpeg, image/webp;q=0.9", contentType: "image/gif", expected: image.RenderFormatWebP},
+		{name: "webp refused", accept: "image/webp;q=0, image/*", contentType: "image/png", expected: ""},
+		{name: "wildcards only", accept: "image/*,*/*;q=0.8", contentType: "image/png", expected: ""},
+		{name: "avif only", accept: "image/avif", contentType: "image/png", expected: ""},
+		{name: "photo stays jpeg", accept: "image/avif,image/webp,image/*", contentType: "image/jpeg", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateFormat(tt.accept, tt.contentType))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// reprocessImageHandler queues an image to have its dimensions, content hash, capture
+// details, variants and thumbnails regenerated from its stored file
+// (POST /api/images/{id}/reprocess)
+func (h *Handler) reprocessImageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ReprocessImageHandler",
+		attribute.String("handler", "reprocess_image"),
+	)
+	defer h.endSpan(span)
+
+	imageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int("image.id", imageID))
+
+	if h.imageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.imageService.ReprocessImage(ctx, imageID); err != nil {
+		if errors.Is(err, image.ErrImageNotFound) {
+			h.handleError(ctx, span, err, "", "image_not_found", "")
+			http.Error(w, "Image not found", http.StatusNotFound)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to reprocess image", "reprocess_failed", "")
+		http.Error(w, "Failed to reprocess image", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ProcessingStatusResponse reports where the background processing of an image has got
+type ProcessingStatusResponse struct {
+	ImageID int                    `json:"image_id"`
+	Status  image.ProcessingStatus `json:"status"`          // pending, processing, ready or failed
+	Error   string                 `json:"error,omitempty"` // The failed step and its error
+	Steps   []image.ProcessingJob  `json:"steps"`           // Latest job of each step queued for the image
+}
+
+// FailedJobsResponse is a page of dead-lettered processing jobs
+type FailedJobsResponse struct {
+	Jobs  []*image.ProcessingJob `json:"jobs"`
+	Total int                    `json:"total"`
+}
+
+// processingStatusHandler returns the processing status of an image and of each of its
+// steps (GET /api/images/{id}/processing)
+func (h *Handler) processingStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ProcessingStatusHandler",
+		attribute.String("handler", "processing_status"),
+	)
+	defer h.endSpan(span)
+
+	imageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int("image.id", imageID))
+
+	if h.imageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	img, err := h.imageService.GetImage(ctx, imageID)
+	if err != nil {
+		// The repository reports a missing row as a plain error, so treat any failure as not found
+		h.handleError(ctx, span, err, "", "image_not_found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
+	status, statusErr := img.ProcessingStatus()
+	response := ProcessingStatusResponse{
+		ImageID: img.ID,
+		Status:  status,
+		Error:   statusErr,
+		Steps:   img.Processing,
+	}
+	if response.Steps == nil {
+		response.Steps = []image.ProcessingJob{}
+	}
+
+	h.setSpanAttributes(span, attribute.String("processing.status", string(status)))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// listFailedJobsHandler lists the processing jobs that failed on every attempt, most
+// recently failed first (GET /api/admin/jobs/failed?limit=&offset=)
+func (h *Handler) listFailedJobsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ListFailedJobsHandler",
+		attribute.String("handler", "list_failed_jobs"),
+	)
+	defer h.endSpan(span)
+
+	if h.jobs == nil {
+		http.Error(w, "Job queue not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))   //nolint:errcheck // Invalid values fall back to the queue default
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset")) //nolint:errcheck // Invalid values fall back to zero
+
+	jobs, total, err := h.jobs.ListFailed(ctx, limit, offset)
+	if err != nil {
+		h.handleError(ctx, span, err, "Failed to list failed jobs", "list_failed_jobs_failed", "")
+		http.Error(w, "Failed to list failed jobs", http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []*image.ProcessingJob{}
+	}
+
+	h.setSpanAttributes(span, attribute.Int("jobs.count", len(jobs)), attribute.Int("jobs.total", total))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(FailedJobsResponse{Jobs: jobs, Total: total}); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// retryJobHandler queues a failed processing job again with all its attempts
+// (POST /api/admin/jobs/{id}/retry)
+func (h *Handler) retryJobHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "RetryJobHandler",
+		attribute.String("handler", "retry_job"),
+	)
+	defer h.endSpan(span)
+
+	jobID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int64("job.id", jobID))
+
+	if h.jobs == nil {
+		http.Error(w, "Job queue not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.jobs.Requeue(ctx, jobID); err != nil {
+		if errors.Is(err, image.ErrJobNotFound) {
+			h.handleError(ctx, span, err, "", "job_not_found", "")
+			http.Error(w, "Failed job not found", http.StatusNotFound)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to retry job", "retry_job_failed", "")
+		http.Error(w, "Failed to retry job", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	if h.logger != nil {
+		h.logger.Info(ctx).Int64("job_id", jobID).Msg("Failed processing job queued again")
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
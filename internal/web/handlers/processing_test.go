@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFailedJobs serves one failed job and requeues it once
+type fakeFailedJobs struct {
+	image.JobQueue
+	requeued []int64
+}
+
+func (f *fakeFailedJobs) ListFailed(_ context.Context, limit, offset int) ([]*image.ProcessingJob, int, error) {
+	if offset > 0 {
+		return nil, 1, nil
+	}
+	return []*image.ProcessingJob{
+		{ID: 4, ImageID: 7, Type: image.JobVariants, Status: image.JobDead, LastError: "corrupt image", OriginalFilename: "photo.jpg"},
+	}, 1, nil
+}
+
+func (f *fakeFailedJobs) Requeue(_ context.Context, id int64) error {
+	if id != 4 || len(f.requeued) > 0 {
+		return image.ErrJobNotFound
+	}
+	f.requeued = append(f.requeued, id)
+	return nil
+}
+
+func TestFailedJobHandlers(t *testing.T) {
+	jobs := &fakeFailedJobs{}
+	h := &Handler{jobs: jobs}
+	r := chi.NewRouter()
+	r.Get("/api/admin/jobs/failed", h.listFailedJobsHandler)
+	r.Post("/api/admin/jobs/{id}/retry", h.retryJobHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page FailedJobsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, "corrupt image", page.Jobs[0].LastError)
+	assert.Equal(t, "photo.jpg", page.Jobs[0].OriginalFilename)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed?offset=50", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"jobs":[],"total":1}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/jobs/4/retry", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, []int64{4}, jobs.requeued)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/jobs/4/retry", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "a requeued job is no longer failed")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/jobs/abc/retry", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	(&Handler{}).listFailedJobsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// renditionPresets are the preset URLs listed on each image for the frontend
+var renditionPresets = []string{"thumb", "card", "lightbox", "social"}
+
+// renderImageHandler serves a resized derivative of an image
+// (GET /api/images/{id}/render?preset=card, or ?w=400&h=300&fit=cover&fmt=jpeg&q=80).
+// Without fmt, clients that accept image/webp get WebP for PNG and GIF sources.
+func (h *Handler) renderImageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "RenderImageHandler",
+		attribute.String("handler", "render_image"),
+	)
+	defer h.endSpan(span)
+
+	imageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.imageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	opts, err := parseRenderOptions(r)
+	if err == nil {
+		err = opts.Validate()
+	}
+	if err != nil {
+		h.handleError(ctx, span, err, "", "invalid_render_options", "")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	img, err := h.imageService.GetImage(ctx, imageID)
+	if err != nil {
+		h.handleError(ctx, span, err, "", "image_not_found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("fmt") == "" && len(image.NegotiableFormats(img.ContentType)) > 0 {
+		// Without an explicit format the encoding follows the Accept header
+		w.Header().Add("Vary", "Accept")
+		if format := negotiateFormat(r.Header.Get("Accept"), img.ContentType); format != "" {
+			opts.Format = format
+		}
+	}
+	h.setSpanAttributes(span,
+		attribute.Int("image.id", imageID),
+		attribute.Int("render.width", opts.Width),
+		attribute.Int("render.height", opts.Height),
+		attribute.String("render.format", string(opts.Format)),
+	)
+	if img.IsVector() {
+		// Vector images scale in the browser, so every rendition is the original
+		http.Redirect(w, r, fmt.Sprintf("/api/images/%d/view", imageID), http.StatusFound)
+		return
+	}
+
+	reader, err := h.imageService.RenderImage(ctx, imageID, opts)
+	if err != nil {
+		if errors.Is(err, image.ErrInvalidRenderOptions) {
+			h.handleError(ctx, span, err, "", "invalid_render_options", "")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to render image", "render_failed", "")
+		http.Error(w, "Failed to render image", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = reader.Close() }() //nolint:errcheck // Resource cleanup
+
+	// A derivative never changes once rendered: image IDs are not reused
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if _, err := io.Copy(w, reader); err != nil {
+		h.handleError(ctx, span, err, "Failed to serve rendered image", "", "")
+	}
+}
+
+// parseRenderOptions starts from ?preset= (default card) and applies any w, h, fit, fmt and q
+// overrides; the result still has to pass image.RenderOptions.Validate
+func parseRenderOptions(r *http.Request) (image.RenderOptions, error) {
+	query := r.URL.Query()
+
+	name := query.Get("preset")
+	if name == "" {
+		name = "card"
+	}
+	opts, ok := image.RenderPresets[name]
+	if !ok {
+		return opts, fmt.Errorf("%w: unknown preset %q", image.ErrInvalidRenderOptions, name)
+	}
+
+	for param, target := range map[string]*int{"w": &opts.Width, "h": &opts.Height, "q": &opts.Quality} {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return opts, fmt.Errorf("%w: %s must be an integer", image.ErrInvalidRenderOptions, param)
+			}
+			*target = n
+		}
+	}
+	if fit := query.Get("fit"); fit != "" {
+		opts.Fit = image.RenderFit(fit)
+	}
+	switch format := query.Get("fmt"); format {
+	case "":
+	case "jpg":
+		opts.Format = image.RenderFormatJPEG
+	default:
+		opts.Format = image.RenderFormat(format)
+	}
+	return opts, nil
+}
+
+// renditionURLs returns the render URL of each preset for an image
+func renditionURLs(id int) map[string]string {
+	urls := make(map[string]string, len(renditionPresets))
+	for _, name := range renditionPresets {
+		urls[name] = fmt.Sprintf("/api/images/%d/render?preset=%s", id, name)
+	}
+	return urls
+}
+
+// imageRenditionURLs returns the rendition URLs of an image, or nil for vector images,
+// which are shown from the original at every size
+func imageRenditionURLs(img *image.Image) map[string]string {
+	if img.IsVector() {
+		return nil
+	}
+	return renditionURLs(img.ID)
+}
+
+// renditionURL returns an image's URL for a preset, or the original when it has no renditions
+func renditionURL(img ImageResponse, preset string) string {
+	if url, ok := img.Renditions[preset]; ok {
+		return url
+	}
+	return img.URL
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRenderOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected image.RenderOptions
+		wantErr  bool
+	}{
+		{name: "defaults to the card preset", query: "", expected: image.RenderPresets["card"]},
+		{name: "named preset", query: "preset=lightbox", expected: image.RenderPresets["lightbox"]},
+		{
+			name:     "explicit parameters",
+			query:    "w=1200&h=630&fit=cover&fmt=jpg&q=60",
+			expected: image.RenderOptions{Width: 1200, Height: 630, Fit: image.RenderFitCover, Format: image.RenderFormatJPEG, Quality: 60},
+		},
+		{
+			name:     "webp",
+			query:    "preset=card&fmt=webp",
+			expected: image.RenderOptions{Width: 400, Height: 300, Fit: image.RenderFitCover, Format: image.RenderFormatWebP, Quality: image.DefaultRenderQuality},
+		},
+		{
+			name:     "preset with another format",
+			query:    "preset=thumb&fmt=png",
+			expected: image.RenderOptions{Width: 200, Height: 200, Fit: image.RenderFitCover, Format: image.RenderFormatPNG, Quality: image.DefaultRenderQuality},
+		},
+		{name: "unknown preset", query: "preset=poster", wantErr: true},
+		{name: "non-numeric width", query: "w=wide", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseRenderOptions(httptest.NewRequest("GET", "/api/images/1/render?"+tt.query, nil))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, image.ErrInvalidRenderOptions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, opts)
+			assert.NoError(t, opts.Validate())
+		})
+	}
+}
@@ -0,0 +1,742 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/domain/settings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ImageTagsResponse is returned after an incremental tag edit
+type ImageTagsResponse struct {
+	ImageID     int      `json:"image_id"`
+	Tags        []string `json:"tags"`
+	PendingTags []string `json:"pending_tags,omitempty"` // Tags queued for approval by the tag policy
+}
+
+// attachTagHandler adds a single tag to an image (POST /api/images/{id}/tags/{name})
+func (h *Handler) attachTagHandler(w http.ResponseWriter, r *http.Request) {
+	h.editImageTag(w, r, "AttachTagHandler", "attach_tag", h.imageService.AttachTag)
+}
+
+// detachTagHandler removes a single tag from an image (DELETE /api/images/{id}/tags/{name})
+func (h *Handler) detachTagHandler(w http.ResponseWriter, r *http.Request) {
+	h.editImageTag(w, r, "DetachTagHandler", "detach_tag", h.imageService.DetachTag)
+}
+
+// editImageTag runs a single-tag edit and renders the resulting tag list
+func (h *Handler) editImageTag(
+	w http.ResponseWriter,
+	r *http.Request,
+	spanName, handlerName string,
+	edit func(ctx context.Context, id int, tagName string) (*image.Image, error),
+) {
+	ctx := r.Context()
+
+	ctx, span := h.startSpan(ctx, spanName,
+		attribute.String("handler", handlerName),
+	)
+	defer h.endSpan(span)
+
+	imageIDStr := chi.URLParam(r, "id")
+	imageID, err := strconv.Atoi(imageIDStr)
+	if err != nil {
+		h.handleError(ctx, span, err, "Invalid image ID", "invalid_id", "")
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	tagName := chi.URLParam(r, "name")
+
+	h.setSpanAttributes(span,
+		attribute.Int("image.id", imageID),
+		attribute.String("tag.name", tagName),
+	)
+
+	img, err := edit(ctx, imageID, tagName)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to update image tags")
+		return
+	}
+
+	tags := make([]string, len(img.Tags))
+	for i, tag := range img.Tags {
+		tags[i] = tag.Name
+	}
+
+	h.setSpanAttributes(span, attribute.Int("tags.count", len(tags)))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).
+			Int("image_id", imageID).
+			Str("tag", tagName).
+			Str("operation", handlerName).
+			Msg("Image tags updated")
+	}
+
+	// A tag parked for approval has been accepted but not applied yet
+	status := http.StatusOK
+	if len(img.PendingTags) > 0 {
+		status = http.StatusAccepted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(ImageTagsResponse{
+		ImageID:     imageID,
+		Tags:        tags,
+		PendingTags: img.PendingTags,
+	}); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeTagError maps domain errors from tag operations to HTTP status codes
+func (h *Handler) writeTagError(ctx context.Context, span trace.Span, w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, image.ErrImageNotFound):
+		h.handleError(ctx, span, err, "", "image_not_found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+	case errors.Is(err, image.ErrTagNotFound):
+		h.handleError(ctx, span, err, "", "tag_not_found", "")
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case errors.Is(err, image.ErrAliasNotFound):
+		h.handleError(ctx, span, err, "", "alias_not_found", "")
+		http.Error(w, "Tag alias not found", http.StatusNotFound)
+	case errors.Is(err, image.ErrPendingTagNotFound):
+		h.handleError(ctx, span, err, "", "pending_tag_not_found", "")
+		http.Error(w, "Pending tag not found", http.StatusNotFound)
+	case errors.Is(err, image.ErrTagNotAllowed):
+		h.handleError(ctx, span, err, "", "tag_not_allowed", "")
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, image.ErrInvalidTagName), errors.Is(err, image.ErrInvalidTagData):
+		h.handleError(ctx, span, err, "", "invalid_tag", "")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, image.ErrTagLimitExceeded):
+		h.handleError(ctx, span, err, "", "tag_limit_exceeded", "")
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, image.ErrDuplicateTag), errors.Is(err, image.ErrAliasConflict):
+		h.handleError(ctx, span, err, "", "duplicate_tag", "")
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.handleError(ctx, span, err, logMsg, "tag_operation_failed", "")
+		http.Error(w, logMsg, http.StatusInternalServerError)
+	}
+}
+
+// TagDetailResponse is the admin view of a tag
+type TagDetailResponse struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Color        string `json:"color,omitempty"`
+	DisplayColor string `json:"display_color"` // Stored color, or the hashed palette color when unset
+	TextClass    string `json:"text_class"`    // Text class with the better WCAG contrast on DisplayColor
+	Category     string `json:"category,omitempty"`
+	IsPredefined bool   `json:"is_predefined"`
+	IsActive     bool   `json:"is_active"`
+	DisplayOrder int    `json:"display_order"`
+	ParentID     *int   `json:"parent_id"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func newTagDetailResponse(tag *image.Tag) TagDetailResponse {
+	displayColor := settings.ResolveTagColor(tag.Name, tag.Color)
+	return TagDetailResponse{
+		ID:           tag.ID,
+		Name:         tag.Name,
+		Description:  tag.Description,
+		Color:        tag.Color,
+		DisplayColor: displayColor,
+		TextClass:    settings.TextClassForColor(displayColor),
+		Category:     tag.Category,
+		IsPredefined: tag.IsPredefined,
+		IsActive:     tag.IsActive,
+		DisplayOrder: tag.DisplayOrder,
+		ParentID:     tag.ParentID,
+		CreatedAt:    tag.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// writeTagJSON encodes a response body for the tag endpoints
+func (h *Handler) writeTagJSON(ctx context.Context, span trace.Span, w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// parseTagID reads the {id} URL parameter, writing a 400 response when it is invalid
+func (h *Handler) parseTagID(ctx context.Context, span trace.Span, w http.ResponseWriter, r *http.Request) (int, bool) {
+	tagID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleError(ctx, span, err, "", "invalid_id", "")
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return 0, false
+	}
+	h.setSpanAttributes(span, attribute.Int("tag.id", tagID))
+	return tagID, true
+}
+
+// listTagsHandler returns all tags (GET /api/tags?limit=&offset=)
+func (h *Handler) listTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ListTagsHandler",
+		attribute.String("handler", "list_tags"),
+	)
+	defer h.endSpan(span)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))   //nolint:errcheck // Invalid values fall back to the repository default
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset")) //nolint:errcheck // Invalid values fall back to zero
+
+	tags, err := h.tagService.ListTags(ctx, limit, offset)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to list tags")
+		return
+	}
+
+	response := make([]TagDetailResponse, len(tags))
+	for i, tag := range tags {
+		response[i] = newTagDetailResponse(tag)
+	}
+
+	h.setSpanAttributes(span, attribute.Int("tags.count", len(tags)))
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, response)
+}
+
+// createTagHandler creates a tag (POST /api/tags)
+func (h *Handler) createTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "CreateTagHandler",
+		attribute.String("handler", "create_tag"),
+	)
+	defer h.endSpan(span)
+
+	var req image.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid_request_body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(ctx, &req)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to create tag")
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.Int("tag.id", tag.ID), attribute.String("tag.name", tag.Name))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Int("tag_id", tag.ID).Str("tag", tag.Name).Msg("Tag created")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusCreated, newTagDetailResponse(tag))
+}
+
+// getTagHandler returns a single tag (GET /api/tags/{id})
+func (h *Handler) getTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "GetTagHandler",
+		attribute.String("handler", "get_tag"),
+	)
+	defer h.endSpan(span)
+
+	tagID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	tag, err := h.tagService.GetTag(ctx, tagID)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to get tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagDetailResponse(tag))
+}
+
+// updateTagHandler renames or re-describes a tag (PATCH /api/tags/{id})
+func (h *Handler) updateTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "UpdateTagHandler",
+		attribute.String("handler", "update_tag"),
+	)
+	defer h.endSpan(span)
+
+	tagID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	var req image.UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid_request_body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(ctx, tagID, &req)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to update tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Int("tag_id", tag.ID).Str("tag", tag.Name).Msg("Tag updated")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagDetailResponse(tag))
+}
+
+// deleteTagHandler removes a tag and its image associations (DELETE /api/tags/{id})
+func (h *Handler) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "DeleteTagHandler",
+		attribute.String("handler", "delete_tag"),
+	)
+	defer h.endSpan(span)
+
+	tagID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.DeleteTag(ctx, tagID); err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to delete tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Int("tag_id", tagID).Msg("Tag deleted")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, map[string]any{
+		"status":  statusSuccess,
+		"message": "Tag deleted successfully",
+		"tag_id":  tagID,
+	})
+}
+
+// MergeTagRequest is the body of POST /api/tags/{id}/merge
+type MergeTagRequest struct {
+	TargetID int `json:"target_id"`
+}
+
+// mergeTagHandler folds tag {id} into the target tag (POST /api/tags/{id}/merge)
+func (h *Handler) mergeTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "MergeTagHandler",
+		attribute.String("handler", "merge_tag"),
+	)
+	defer h.endSpan(span)
+
+	sourceID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	var req MergeTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TargetID <= 0 {
+		h.addSpanEvent(span, "invalid_request_body")
+		http.Error(w, "Request body must contain a positive target_id", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.Int("tag.target_id", req.TargetID))
+
+	target, err := h.tagService.MergeTags(ctx, sourceID, req.TargetID)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to merge tags")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).
+			Int("source_tag_id", sourceID).
+			Int("target_tag_id", target.ID).
+			Str("target_tag", target.Name).
+			Msg("Tags merged")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagDetailResponse(target))
+}
+
+// getTagCategoriesHandler returns predefined tags grouped by category (GET /api/tags/categories)
+func (h *Handler) getTagCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "GetTagCategoriesHandler",
+		attribute.String("handler", "get_tag_categories"),
+	)
+	defer h.endSpan(span)
+
+	categories, err := h.tagService.GetCategories(ctx)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to get tag categories")
+		return
+	}
+
+	response := make(map[string][]TagDetailResponse, len(categories))
+	for name, tags := range categories {
+		items := make([]TagDetailResponse, len(tags))
+		for i, tag := range tags {
+			items[i] = newTagDetailResponse(tag)
+		}
+		response[name] = items
+	}
+
+	h.setSpanAttributes(span, attribute.Int("categories.count", len(categories)))
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, response)
+}
+
+// RenameCategoryRequest is the body of PUT /api/tags/categories/{name}
+type RenameCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// renameTagCategoryHandler moves every tag of a category to a new name (PUT /api/tags/categories/{name})
+func (h *Handler) renameTagCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "RenameTagCategoryHandler",
+		attribute.String("handler", "rename_tag_category"),
+	)
+	defer h.endSpan(span)
+
+	from := chi.URLParam(r, "name")
+	var req RenameCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid_request_body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.tagService.RenameCategory(ctx, from, req.Name)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to rename category")
+		return
+	}
+
+	h.setSpanAttributes(span,
+		attribute.String("category.from", from),
+		attribute.String("category.to", req.Name),
+		attribute.Int("tags.updated", count),
+	)
+	h.setSpanStatus(span, codes.Ok, "")
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, map[string]any{
+		"status":       statusSuccess,
+		"category":     req.Name,
+		"tags_updated": count,
+	})
+}
+
+// TagTreeNodeResponse is a node of the tag hierarchy returned by GET /api/tags/tree
+type TagTreeNodeResponse struct {
+	ID       int                   `json:"id"`
+	Name     string                `json:"name"`
+	Path     string                `json:"path"`
+	Children []TagTreeNodeResponse `json:"children"`
+}
+
+func newTagTreeResponse(nodes []*image.TagNode) []TagTreeNodeResponse {
+	response := make([]TagTreeNodeResponse, len(nodes))
+	for i, node := range nodes {
+		response[i] = TagTreeNodeResponse{
+			ID:       node.Tag.ID,
+			Name:     node.Tag.Name,
+			Path:     node.Path,
+			Children: newTagTreeResponse(node.Children),
+		}
+	}
+	return response
+}
+
+// getTagTreeHandler returns active tags as a hierarchy (GET /api/tags/tree)
+func (h *Handler) getTagTreeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "GetTagTreeHandler",
+		attribute.String("handler", "get_tag_tree"),
+	)
+	defer h.endSpan(span)
+
+	tree, err := h.tagService.GetTagTree(ctx)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to get tag tree")
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.Int("tags.roots", len(tree)))
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagTreeResponse(tree))
+}
+
+// MoveTagRequest is the body of PUT /api/tags/{id}/parent; a null parent_id moves the tag to the root
+type MoveTagRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+// moveTagHandler re-parents a tag together with its subtree (PUT /api/tags/{id}/parent)
+func (h *Handler) moveTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "MoveTagHandler",
+		attribute.String("handler", "move_tag"),
+	)
+	defer h.endSpan(span)
+
+	tagID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	var req MoveTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid_request_body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ParentID != nil {
+		h.setSpanAttributes(span, attribute.Int("tag.parent_id", *req.ParentID))
+	}
+
+	tag, err := h.tagService.MoveTag(ctx, tagID, req.ParentID)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to move tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Int("tag_id", tag.ID).Str("tag", tag.Name).Msg("Tag moved")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagDetailResponse(tag))
+}
+
+// TagAliasRequest is the body of POST /api/tags/{id}/aliases
+type TagAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// TagAliasResponse describes an alias and the canonical tag it resolves to
+type TagAliasResponse struct {
+	Alias     string `json:"alias"`
+	TagID     int    `json:"tag_id"`
+	TagName   string `json:"tag_name"`
+	CreatedAt string `json:"created_at"`
+}
+
+func newTagAliasResponses(aliases []*image.TagAlias) []TagAliasResponse {
+	response := make([]TagAliasResponse, len(aliases))
+	for i, alias := range aliases {
+		response[i] = TagAliasResponse{
+			Alias:     alias.Alias,
+			TagID:     alias.TagID,
+			TagName:   alias.TagName,
+			CreatedAt: alias.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return response
+}
+
+// listTagAliasesHandler returns all aliases (GET /api/tags/aliases)
+// or the aliases of a single tag (GET /api/tags/{id}/aliases)
+func (h *Handler) listTagAliasesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ListTagAliasesHandler",
+		attribute.String("handler", "list_tag_aliases"),
+	)
+	defer h.endSpan(span)
+
+	tagID := 0
+	if chi.URLParam(r, "id") != "" {
+		var ok bool
+		if tagID, ok = h.parseTagID(ctx, span, w, r); !ok {
+			return
+		}
+	}
+
+	aliases, err := h.tagService.ListAliases(ctx, tagID)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to list tag aliases")
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.Int("aliases.count", len(aliases)))
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagAliasResponses(aliases))
+}
+
+// createTagAliasHandler adds an alternative name for a tag (POST /api/tags/{id}/aliases)
+func (h *Handler) createTagAliasHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "CreateTagAliasHandler",
+		attribute.String("handler", "create_tag_alias"),
+	)
+	defer h.endSpan(span)
+
+	tagID, ok := h.parseTagID(ctx, span, w, r)
+	if !ok {
+		return
+	}
+
+	var req TagAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(ctx, span, err, "", "invalid_request_body", "")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span, attribute.String("tag.alias", req.Alias))
+
+	alias, err := h.tagService.AddAlias(ctx, tagID, req.Alias)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to create tag alias")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).
+			Int("tag_id", alias.TagID).
+			Str("tag", alias.TagName).
+			Str("alias", alias.Alias).
+			Msg("Tag alias created")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusCreated, newTagAliasResponses([]*image.TagAlias{alias})[0])
+}
+
+// deleteTagAliasHandler removes an alias (DELETE /api/tags/aliases/{alias})
+func (h *Handler) deleteTagAliasHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "DeleteTagAliasHandler",
+		attribute.String("handler", "delete_tag_alias"),
+	)
+	defer h.endSpan(span)
+
+	alias := chi.URLParam(r, "alias")
+	if unescaped, err := url.PathUnescape(alias); err == nil {
+		alias = unescaped
+	}
+	h.setSpanAttributes(span, attribute.String("tag.alias", alias))
+
+	if err := h.tagService.RemoveAlias(ctx, alias); err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to delete tag alias")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Str("alias", alias).Msg("Tag alias deleted")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, map[string]any{
+		"status":  statusSuccess,
+		"message": "Tag alias deleted successfully",
+		"alias":   alias,
+	})
+}
+
+// PendingTagResponse describes a tag awaiting approval
+type PendingTagResponse struct {
+	Name             string `json:"name"`
+	ImageIDs         []int  `json:"image_ids"`
+	ImageCount       int    `json:"image_count"`
+	FirstRequestedAt string `json:"first_requested_at"`
+	LastRequestedAt  string `json:"last_requested_at"`
+}
+
+// listPendingTagsHandler returns the tags awaiting approval (GET /api/tags/pending)
+func (h *Handler) listPendingTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ListPendingTagsHandler",
+		attribute.String("handler", "list_pending_tags"),
+	)
+	defer h.endSpan(span)
+
+	pending, err := h.tagService.ListPendingTags(ctx)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to list pending tags")
+		return
+	}
+
+	response := make([]PendingTagResponse, len(pending))
+	for i, p := range pending {
+		response[i] = PendingTagResponse{
+			Name:             p.Name,
+			ImageIDs:         p.ImageIDs,
+			ImageCount:       len(p.ImageIDs),
+			FirstRequestedAt: p.FirstRequestedAt.Format(time.RFC3339),
+			LastRequestedAt:  p.LastRequestedAt.Format(time.RFC3339),
+		}
+	}
+
+	h.setSpanAttributes(span, attribute.Int("pending_tags.count", len(response)))
+	h.setSpanStatus(span, codes.Ok, "")
+	h.writeTagJSON(ctx, span, w, http.StatusOK, response)
+}
+
+// approvePendingTagHandler creates a pending tag as a predefined tag and applies it
+// to the images that requested it (POST /api/tags/pending/{name}/approve)
+func (h *Handler) approvePendingTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ApprovePendingTagHandler",
+		attribute.String("handler", "approve_pending_tag"),
+	)
+	defer h.endSpan(span)
+
+	name := chi.URLParam(r, "name")
+	h.setSpanAttributes(span, attribute.String("tag.name", name))
+
+	tag, err := h.tagService.ApprovePendingTag(ctx, name)
+	if err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to approve pending tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Int("tag_id", tag.ID).Str("tag", tag.Name).Msg("Pending tag approved")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, newTagDetailResponse(tag))
+}
+
+// rejectPendingTagHandler discards a pending tag (DELETE /api/tags/pending/{name})
+func (h *Handler) rejectPendingTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "RejectPendingTagHandler",
+		attribute.String("handler", "reject_pending_tag"),
+	)
+	defer h.endSpan(span)
+
+	name := chi.URLParam(r, "name")
+	h.setSpanAttributes(span, attribute.String("tag.name", name))
+
+	if err := h.tagService.RejectPendingTag(ctx, name); err != nil {
+		h.writeTagError(ctx, span, w, err, "Failed to reject pending tag")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if h.logger != nil {
+		h.logger.Info(ctx).Str("tag", name).Msg("Pending tag rejected")
+	}
+
+	h.writeTagJSON(ctx, span, w, http.StatusOK, map[string]any{
+		"status":  statusSuccess,
+		"message": "Pending tag rejected",
+		"name":    name,
+	})
+}
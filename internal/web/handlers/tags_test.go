@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTagError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"image not found", fmt.Errorf("%w: image with ID 1", image.ErrImageNotFound), http.StatusNotFound},
+		{"invalid tag", fmt.Errorf("%w: tag name cannot be empty", image.ErrInvalidTagName), http.StatusBadRequest},
+		{"tag limit", fmt.Errorf("%w: image already has 20 tags", image.ErrTagLimitExceeded), http.StatusConflict},
+		{"tag not found", fmt.Errorf("%w: tag with ID 7", image.ErrTagNotFound), http.StatusNotFound},
+		{"duplicate tag", fmt.Errorf("%w: sunset", image.ErrDuplicateTag), http.StatusConflict},
+		{"invalid tag data", fmt.Errorf("%w: bad color", image.ErrInvalidTagData), http.StatusBadRequest},
+		{"alias not found", fmt.Errorf("%w: bw", image.ErrAliasNotFound), http.StatusNotFound},
+		{"alias conflict", fmt.Errorf("%w: bw", image.ErrAliasConflict), http.StatusConflict},
+		{"tag not allowed", fmt.Errorf("%w: selfie", image.ErrTagNotAllowed), http.StatusForbidden},
+		{"pending tag not found", fmt.Errorf("%w: selfie", image.ErrPendingTagNotFound), http.StatusNotFound},
+		{"unexpected error", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	h := &Handler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.writeTagError(context.Background(), nil, rec, tt.err, "Failed")
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
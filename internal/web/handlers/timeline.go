@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"image-gallery/internal/domain/image"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TimelineResponse lists image counts per capture period, newest first
+type TimelineResponse struct {
+	Granularity image.TimelineGranularity `json:"granularity"`
+	Buckets     []image.TimelineBucket    `json:"buckets"`
+	TotalCount  int                       `json:"total_count"`
+}
+
+// timelineHandler returns image counts per capture year, month or day
+// (GET /api/timeline?granularity=year|month|day, default month)
+func (h *Handler) timelineHandler(w http.ResponseWriter, r *http.Request) {
+	granularity := image.TimelineGranularity(r.URL.Query().Get("granularity"))
+	if granularity == "" {
+		granularity = image.TimelineMonth
+	}
+
+	ctx, span := h.startSpan(r.Context(), "TimelineHandler",
+		attribute.String("handler", "timeline"),
+		attribute.String("timeline.granularity", string(granularity)),
+	)
+	defer h.endSpan(span)
+
+	if h.imageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	buckets, err := h.imageService.GetTimeline(ctx, granularity)
+	if err != nil {
+		if errors.Is(err, image.ErrInvalidListFilter) {
+			h.handleError(ctx, span, err, "", "invalid_granularity", "")
+			http.Error(w, "granularity must be one of: year, month, day", http.StatusBadRequest)
+			return
+		}
+		h.handleError(ctx, span, err, "Failed to get timeline", "timeline_failed", "")
+		http.Error(w, "Failed to get timeline", http.StatusInternalServerError)
+		return
+	}
+
+	response := TimelineResponse{
+		Granularity: granularity,
+		Buckets:     buckets,
+	}
+	if response.Buckets == nil {
+		response.Buckets = []image.TimelineBucket{}
+	}
+	for _, bucket := range buckets {
+		response.TotalCount += bucket.Count
+	}
+
+	h.setSpanAttributes(span, attribute.Int("timeline.buckets", len(buckets)))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.handleError(ctx, span, err, "Failed to encode response", "", "")
+	}
+}
+
+// timelinePageHandler serves the capture-date gallery, one month at a time
+func (h *Handler) timelinePageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	if _, err := w.Write([]byte(timelinePage)); err != nil {
+		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+const timelinePage = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Timeline - Image Gallery</title>
+    <link href="https://cdn.jsdelivr.net/npm/tailwindcss@2.2.19/dist/tailwind.min.css" rel="stylesheet">
+    <style>
+        .gallery-image {
+            width: 100%;
+            height: 200px;
+            object-fit: cover;
+            border-radius: 0.5rem;
+        }
+    </style>
+</head>
+<body class="bg-gray-50">
+    <div class="container mx-auto px-4 py-8">
+        <div class="flex justify-between items-center mb-8">
+            <h1 class="text-4xl font-bold text-gray-800">Timeline</h1>
+            <a href="/gallery" class="bg-white hover:bg-gray-100 text-gray-800 font-bold py-2 px-4 rounded-lg shadow-md">Gallery</a>
+        </div>
+
+        <div class="flex flex-col md:flex-row gap-6">
+            <!-- Months with images, grouped by year -->
+            <nav id="months" class="md:w-48 flex-shrink-0 bg-white rounded-lg shadow-md p-4 text-sm text-gray-700">Loading timeline...</nav>
+
+            <main class="flex-1">
+                <div class="flex justify-between items-center mb-4">
+                    <button id="newer" class="bg-gray-200 hover:bg-gray-300 text-gray-800 py-1 px-3 rounded disabled:opacity-50">&larr; Newer</button>
+                    <h2 id="monthTitle" class="text-2xl font-semibold text-gray-800"></h2>
+                    <button id="older" class="bg-gray-200 hover:bg-gray-300 text-gray-800 py-1 px-3 rounded disabled:opacity-50">Older &rarr;</button>
+                </div>
+                <div id="gallery" class="grid grid-cols-1 sm:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 gap-6"></div>
+            </main>
+        </div>
+    </div>
+
+    <script>
+        let buckets = [];
+        let current = -1;
+
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+
+        function monthName(bucket) {
+            return new Date(bucket.start).toLocaleDateString(undefined, { year: 'numeric', month: 'long', timeZone: 'UTC' });
+        }
+
+        function renderMonths() {
+            const nav = document.getElementById('months');
+            if (buckets.length === 0) {
+                nav.textContent = 'No images yet.';
+                return;
+            }
+            let html = '';
+            let year = '';
+            buckets.forEach((bucket, i) => {
+                const y = bucket.period.slice(0, 4);
+                if (y !== year) {
+                    year = y;
+                    html += '<div class="font-bold mt-2">' + y + '</div>';
+                }
+                const active = i === current ? ' bg-blue-100 font-semibold' : '';
+                html += '<a href="#' + bucket.period + '" class="flex justify-between px-2 py-1 rounded hover:bg-gray-100' + active + '">' +
+                    '<span>' + new Date(bucket.start).toLocaleDateString(undefined, { month: 'short', timeZone: 'UTC' }) + '</span>' +
+                    '<span class="text-gray-500">' + bucket.count + '</span></a>';
+            });
+            nav.innerHTML = html;
+        }
+
+        async function showMonth(index) {
+            if (index < 0 || index >= buckets.length) {
+                return;
+            }
+            current = index;
+            const bucket = buckets[index];
+            document.getElementById('monthTitle').textContent = monthName(bucket);
+            document.getElementById('newer').disabled = index === 0;
+            document.getElementById('older').disabled = index === buckets.length - 1;
+            renderMonths();
+
+            const gallery = document.getElementById('gallery');
+            gallery.innerHTML = '<div class="col-span-full text-center text-gray-500">Loading images...</div>';
+            const params = new URLSearchParams({ sort: 'taken_at', taken_after: bucket.start, taken_before: bucket.end });
+            const response = await fetch('/api/images?' + params);
+            if (!response.ok) {
+                gallery.innerHTML = '<div class="col-span-full text-center text-red-500">Failed to load images</div>';
+                return;
+            }
+            const data = await response.json();
+            gallery.innerHTML = (data.images || []).map(img =>
+                '<div class="bg-white rounded-lg shadow-md p-2">' +
+                '<img class="gallery-image" loading="lazy" src="' + escapeHtml(img.renditions ? img.renditions.card : img.url) + '" alt="' + escapeHtml(img.name || '') + '">' +
+                '<div class="mt-2 text-sm text-gray-700 truncate">' + escapeHtml(img.name || '') + '</div>' +
+                '<div class="text-xs text-gray-500">' + escapeHtml(img.taken_at || img.upload_time) + '</div>' +
+                '</div>'
+            ).join('');
+        }
+
+        function showHash() {
+            const index = buckets.findIndex(b => b.period === location.hash.slice(1));
+            showMonth(index >= 0 ? index : 0);
+        }
+
+        document.getElementById('newer').addEventListener('click', () => {
+            if (current > 0) location.hash = buckets[current - 1].period;
+        });
+        document.getElementById('older').addEventListener('click', () => {
+            if (current < buckets.length - 1) location.hash = buckets[current + 1].period;
+        });
+        window.addEventListener('hashchange', showHash);
+
+        fetch('/api/timeline?granularity=month')
+            .then(response => response.json())
+            .then(data => {
+                buckets = data.buckets || [];
+                renderMonths();
+                showHash();
+            })
+            .catch(() => {
+                document.getElementById('months').textContent = 'Failed to load timeline.';
+            });
+    </script>
+</body>
+</html>
+`
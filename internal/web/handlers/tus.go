@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tus protocol constants (https://tus.io/protocols/resumable-upload)
+const (
+	tusVersion        = "1.0.0"
+	tusExtensions     = "creation,termination,expiration"
+	tusChunkMediaType = "application/offset+octet-stream"
+)
+
+// tusOptionsHandler reports the protocol version, extensions and largest upload the
+// server supports (OPTIONS /api/uploads/tus)
+func (h *Handler) tusOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	if h.uploads != nil {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.uploads.MaxSize(), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tusCreateHandler starts a resumable upload of Upload-Length bytes. Upload-Metadata
+// carries the filename and filetype, and optionally comma-separated tags and a
+// metadata_policy, as for form uploads (POST /api/uploads/tus).
+func (h *Handler) tusCreateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "TusCreateHandler",
+		attribute.String("handler", "tus_create"),
+	)
+	defer h.endSpan(span)
+
+	if !h.checkTusRequest(w, r) {
+		return
+	}
+
+	if r.Header.Get("Upload-Defer-Length") != "" {
+		http.Error(w, "Upload-Defer-Length is not supported", http.StatusBadRequest)
+		return
+	}
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "Invalid Upload-Length", http.StatusBadRequest)
+		return
+	}
+
+	metadata, err := parseTusMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		http.Error(w, "Invalid Upload-Metadata", http.StatusBadRequest)
+		return
+	}
+	filename := metadata["filename"]
+	contentType := metadata["filetype"]
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = imageExtensions[strings.ToLower(filepath.Ext(filename))]
+	}
+
+	metadataPolicy := image.MetadataPolicy(strings.ToLower(strings.TrimSpace(metadata["metadata_policy"])))
+	if metadataPolicy != "" && !metadataPolicy.IsValid() {
+		http.Error(w, "metadata_policy must be one of: keep, strip_gps, strip_all", http.StatusBadRequest)
+		return
+	}
+
+	h.setSpanAttributes(span,
+		attribute.Int64("upload.length", length),
+		attribute.String("upload.filename", filename),
+		attribute.String("upload.content_type", contentType),
+	)
+
+	upload, err := h.uploads.CreateUpload(ctx, &image.CreateUploadRequest{
+		Length:         length,
+		Filename:       filename,
+		ContentType:    contentType,
+		Tags:           h.resolveTagAliases(ctx, parseTags(metadata["tags"])),
+		MetadataPolicy: metadataPolicy,
+	})
+	if err != nil {
+		h.writeTusError(ctx, w, r, span, err, "Failed to create upload")
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.String("upload.id", upload.ID))
+	h.setSpanStatus(span, codes.Ok, "")
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+upload.ID)
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// tusHeadHandler reports how much of an upload has been received, so a client can
+// resume it (HEAD /api/uploads/tus/{id})
+func (h *Handler) tusHeadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "TusHeadHandler",
+		attribute.String("handler", "tus_head"),
+	)
+	defer h.endSpan(span)
+
+	w.Header().Set("Cache-Control", "no-store")
+	if !h.checkTusRequest(w, r) {
+		return
+	}
+
+	upload, err := h.uploads.GetUpload(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeTusError(ctx, w, r, span, err, "Failed to get upload")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	writeTusUploadHeaders(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// tusPatchHandler appends the request body to an upload at Upload-Offset. The chunk
+// completing the upload hands it off to be stored as an image, whose ID is returned in
+// X-Image-Id (PATCH /api/uploads/tus/{id}).
+func (h *Handler) tusPatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "TusPatchHandler",
+		attribute.String("handler", "tus_patch"),
+	)
+	defer h.endSpan(span)
+
+	if !h.checkTusRequest(w, r) {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != tusChunkMediaType {
+		http.Error(w, "Content-Type must be "+tusChunkMediaType, http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	h.setSpanAttributes(span,
+		attribute.String("upload.id", id),
+		attribute.Int64("upload.offset", offset),
+	)
+
+	upload, err := h.uploads.WriteChunk(ctx, id, offset, r.Body)
+	if err != nil {
+		h.writeTusError(ctx, w, r, span, err, "Failed to write upload chunk")
+		return
+	}
+
+	h.setSpanAttributes(span, attribute.Int64("upload.new_offset", upload.Offset))
+	h.setSpanStatus(span, codes.Ok, "")
+	writeTusUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tusDeleteHandler stops an upload and discards what was received
+// (DELETE /api/uploads/tus/{id})
+func (h *Handler) tusDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "TusDeleteHandler",
+		attribute.String("handler", "tus_delete"),
+	)
+	defer h.endSpan(span)
+
+	if !h.checkTusRequest(w, r) {
+		return
+	}
+
+	if err := h.uploads.TerminateUpload(ctx, chi.URLParam(r, "id")); err != nil {
+		h.writeTusError(ctx, w, r, span, err, "Failed to terminate upload")
+		return
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkTusRequest sets the protocol version on the response and rejects requests for
+// another version, or made while resumable uploads are unavailable
+func (h *Handler) checkTusRequest(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		http.Error(w, "Unsupported tus version", http.StatusPreconditionFailed)
+		return false
+	}
+	if h.uploads == nil {
+		http.Error(w, "Resumable uploads not available", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// writeTusUploadHeaders sets the progress of an upload on a response
+func writeTusUploadHeaders(w http.ResponseWriter, upload *image.ResumableUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.ImageID != nil {
+		w.Header().Set("X-Image-Id", strconv.Itoa(*upload.ImageID))
+	}
+}
+
+// writeTusError maps an upload error to its response. An error rejecting the file is a
+// bad request when the upload is created and unprocessable once it is complete.
+func (h *Handler) writeTusError(ctx context.Context, w http.ResponseWriter, r *http.Request, span trace.Span, err error, logMsg string) {
+	switch {
+	case errors.Is(err, image.ErrUploadNotFound):
+		h.handleError(ctx, span, err, "", "upload_not_found", "")
+		http.Error(w, "Upload not found", http.StatusNotFound)
+	case errors.Is(err, image.ErrUploadOffsetMismatch):
+		h.handleError(ctx, span, err, "", "offset_mismatch", "")
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, image.ErrUploadLocked):
+		h.handleError(ctx, span, err, "", "upload_locked", "")
+		http.Error(w, "Upload is being written by another request", http.StatusLocked)
+	case errors.Is(err, image.ErrUploadTooLarge):
+		h.handleError(ctx, span, err, "", "upload_too_large", "")
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case r.Method == http.MethodPost && isInvalidImageError(err):
+		h.handleError(ctx, span, err, "", "invalid_upload", "")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case isInvalidImageError(err):
+		h.handleError(ctx, span, err, "", "invalid_image", "")
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		h.handleError(ctx, span, err, logMsg, "upload_failed", "")
+		http.Error(w, logMsg, http.StatusInternalServerError)
+	}
+}
+
+// isInvalidImageError reports whether an error rejects the image itself rather than
+// reporting a failure to store it
+func isInvalidImageError(err error) bool {
+	for _, target := range []error{
+		image.ErrInvalidImageData,
+		image.ErrInvalidContentType,
+		image.ErrInvalidFileSize,
+		image.ErrInvalidFilename,
+		image.ErrInvalidDimensions,
+		image.ErrInvalidTagName,
+		image.ErrTagLimitExceeded,
+		image.ErrTagNotAllowed,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTusMetadata parses an Upload-Metadata header: comma-separated pairs of a key and
+// its base64 value, which may be left out
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			metadata[fields[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", fields[0], err)
+			}
+			metadata[fields[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("invalid metadata pair %q", pair)
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResumableUploads keeps one upload, created image 42 once complete
+type fakeResumableUploads struct {
+	upload  *image.ResumableUpload
+	created *image.CreateUploadRequest
+}
+
+func (f *fakeResumableUploads) CreateUpload(_ context.Context, req *image.CreateUploadRequest) (*image.ResumableUpload, error) {
+	if req.Length > f.MaxSize() {
+		return nil, image.ErrUploadTooLarge
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	f.created = req
+	f.upload = &image.ResumableUpload{ID: "abc123", Length: req.Length, ExpiresAt: time.Now().Add(time.Hour)}
+	return f.upload, nil
+}
+
+func (f *fakeResumableUploads) GetUpload(_ context.Context, id string) (*image.ResumableUpload, error) {
+	if f.upload == nil || f.upload.ID != id {
+		return nil, image.ErrUploadNotFound
+	}
+	return f.upload, nil
+}
+
+func (f *fakeResumableUploads) WriteChunk(ctx context.Context, id string, offset int64, data io.Reader) (*image.ResumableUpload, error) {
+	upload, err := f.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return nil, image.ErrUploadOffsetMismatch
+	}
+	n, err := io.Copy(io.Discard, data)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Offset+n > upload.Length {
+		return nil, image.ErrUploadTooLarge
+	}
+	upload.Offset += n
+	if upload.IsComplete() {
+		imageID := 42
+		upload.ImageID = &imageID
+	}
+	return upload, nil
+}
+
+func (f *fakeResumableUploads) TerminateUpload(ctx context.Context, id string) error {
+	if _, err := f.GetUpload(ctx, id); err != nil {
+		return err
+	}
+	f.upload = nil
+	return nil
+}
+
+func (f *fakeResumableUploads) MaxSize() int64 {
+	return 1 << 20
+}
+
+func tusMetadata(pairs ...string) string {
+	var encoded []string
+	for i := 0; i < len(pairs); i += 2 {
+		encoded = append(encoded, pairs[i]+" "+base64.StdEncoding.EncodeToString([]byte(pairs[i+1])))
+	}
+	return strings.Join(encoded, ",")
+}
+
+func TestTusHandlers(t *testing.T) {
+	uploads := &fakeResumableUploads{}
+	h := &Handler{uploads: uploads}
+	r := chi.NewRouter()
+	r.Route("/api/uploads/tus", func(r chi.Router) {
+		r.Options("/", h.tusOptionsHandler)
+		r.Post("/", h.tusCreateHandler)
+		r.Head("/{id}", h.tusHeadHandler)
+		r.Patch("/{id}", h.tusPatchHandler)
+		r.Delete("/{id}", h.tusDeleteHandler)
+	})
+	serve := func(method, target string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, body)
+		req.Header.Set("Tus-Resumable", tusVersion)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodOptions, "/api/uploads/tus/", nil, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, tusVersion, rec.Header().Get("Tus-Version"))
+	assert.Equal(t, "creation,termination,expiration", rec.Header().Get("Tus-Extension"))
+	assert.Equal(t, "1048576", rec.Header().Get("Tus-Max-Size"))
+
+	// Creation
+	rec = serve(http.MethodPost, "/api/uploads/tus/", nil, map[string]string{
+		"Upload-Length":   "10",
+		"Upload-Metadata": tusMetadata("filename", "beach.png", "tags", "Sea, sand"),
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/api/uploads/tus/abc123", rec.Header().Get("Location"))
+	assert.NotEmpty(t, rec.Header().Get("Upload-Expires"))
+	assert.Equal(t, "beach.png", uploads.created.Filename)
+	assert.Equal(t, "image/png", uploads.created.ContentType, "the content type follows the extension")
+	assert.Equal(t, []string{"Sea", "sand"}, uploads.created.Tags)
+
+	// Chunks
+	patch := func(offset string, body string) *httptest.ResponseRecorder {
+		return serve(http.MethodPatch, "/api/uploads/tus/abc123", strings.NewReader(body), map[string]string{
+			"Content-Type":  tusChunkMediaType,
+			"Upload-Offset": offset,
+		})
+	}
+	rec = patch("0", "hello")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+	assert.Empty(t, rec.Header().Get("X-Image-Id"))
+
+	rec = serve(http.MethodHead, "/api/uploads/tus/abc123", nil, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "10", rec.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusConflict, patch("0", "hello").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, patch("5", "world, again").Code)
+
+	rec = patch("5", "world")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "42", rec.Header().Get("X-Image-Id"))
+
+	// Termination
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/api/uploads/tus/abc123", nil, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodHead, "/api/uploads/tus/abc123", nil, nil).Code)
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			method  string
+			headers map[string]string
+			want    int
+		}{
+			{"missing length", http.MethodPost, map[string]string{"Upload-Metadata": tusMetadata("filename", "a.png")}, http.StatusBadRequest},
+			{"deferred length", http.MethodPost, map[string]string{"Upload-Defer-Length": "1"}, http.StatusBadRequest},
+			{"over the size limit", http.MethodPost, map[string]string{"Upload-Length": fmt.Sprint(2 << 20), "Upload-Metadata": tusMetadata("filename", "a.png")}, http.StatusRequestEntityTooLarge},
+			{"not an image", http.MethodPost, map[string]string{"Upload-Length": "10", "Upload-Metadata": tusMetadata("filename", "notes.txt")}, http.StatusBadRequest},
+			{"unknown metadata policy", http.MethodPost, map[string]string{"Upload-Length": "10", "Upload-Metadata": tusMetadata("filename", "a.png", "metadata_policy", "blur")}, http.StatusBadRequest},
+			{"malformed metadata", http.MethodPost, map[string]string{"Upload-Length": "10", "Upload-Metadata": "filename !!"}, http.StatusBadRequest},
+			{"wrong chunk content type", http.MethodPatch, map[string]string{"Content-Type": "image/png", "Upload-Offset": "0"}, http.StatusUnsupportedMediaType},
+			{"missing offset", http.MethodPatch, map[string]string{"Content-Type": tusChunkMediaType}, http.StatusBadRequest},
+			{"unknown upload", http.MethodPatch, map[string]string{"Content-Type": tusChunkMediaType, "Upload-Offset": "0"}, http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				target := "/api/uploads/tus/"
+				if tt.method == http.MethodPatch {
+					target += "missing"
+				}
+				rec := serve(tt.method, target, strings.NewReader("data"), tt.headers)
+				assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			})
+		}
+	})
+
+	t.Run("requires the protocol version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "/api/uploads/tus/abc123", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Equal(t, tusVersion, rec.Header().Get("Tus-Version"))
+	})
+
+	t.Run("reports resumable uploads unavailable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/uploads/tus/", nil)
+		req.Header.Set("Tus-Resumable", tusVersion)
+		rec := httptest.NewRecorder()
+		(&Handler{}).tusCreateHandler(rec, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
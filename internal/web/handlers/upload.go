@@ -1,16 +1,17 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"image-gallery/internal/domain/image"
+	"image-gallery/internal/services/implementations"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -40,7 +41,10 @@ type UploadedImageInfo struct {
 	Width            *int     `json:"width,omitempty"`
 	Height           *int     `json:"height,omitempty"`
 	Tags             []string `json:"tags,omitempty"`
+	PendingTags      []string `json:"pending_tags,omitempty"` // Tags queued for approval by the tag policy
+	MetadataPolicy   string   `json:"metadata_policy,omitempty"`
 	URL              string   `json:"url,omitempty"`
+	ProcessingStatus string   `json:"processing_status,omitempty"` // pending while variants and thumbnails are queued
 }
 
 // UploadError represents an error that occurred during upload
@@ -70,8 +74,18 @@ func (h *Handler) uploadImagesHandler(w http.ResponseWriter, r *http.Request) {
 		Str("content_type", r.Header.Get("Content-Type")).
 		Msg("Starting image upload request")
 
+	// Progress is published to the upload session, when the client gave one
+	progress, err := h.uploadProgress(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid upload session")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer progress.finish()
+
 	// Limit request body size
-	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	r.Body = progress.body(http.MaxBytesReader(w, r.Body, maxUploadSize), r.ContentLength)
 
 	// Parse multipart form with limited in-memory buffer
 	// maxMemoryPerUpload (1MB) is buffered in RAM per request
@@ -81,6 +95,7 @@ func (h *Handler) uploadImagesHandler(w http.ResponseWriter, r *http.Request) {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to parse multipart form")
 		h.logger.Error(ctx).Err(err).Msg("Failed to parse multipart form")
+		progress.fail(err)
 		http.Error(w, fmt.Sprintf("Failed to parse form: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -100,13 +115,26 @@ func (h *Handler) uploadImagesHandler(w http.ResponseWriter, r *http.Request) {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "no files in request")
 		h.logger.Warn(ctx).Msg("Upload request with no files")
+		progress.fail(err)
 		http.Error(w, "No files provided", http.StatusBadRequest)
 		return
 	}
+	progress.filesReceived(files)
 
 	// Get tags (comma-separated)
 	tagsStr := r.FormValue("tags")
-	tags := parseTags(tagsStr)
+	tags := h.resolveTagAliases(ctx, parseTags(tagsStr))
+
+	// Optional per-upload metadata policy; it can only tighten the server policy
+	metadataPolicy := image.MetadataPolicy(strings.ToLower(strings.TrimSpace(r.FormValue("metadata_policy"))))
+	if metadataPolicy != "" && !metadataPolicy.IsValid() {
+		err := fmt.Errorf("unknown metadata policy: %s", metadataPolicy)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid metadata policy")
+		progress.fail(err)
+		http.Error(w, "metadata_policy must be one of: keep, strip_gps, strip_all", http.StatusBadRequest)
+		return
+	}
 
 	// Add span attributes
 	span.SetAttributes(
@@ -120,14 +148,12 @@ func (h *Handler) uploadImagesHandler(w http.ResponseWriter, r *http.Request) {
 		Interface("tags", tags).
 		Msg("Processing uploaded files")
 
-	// Process each file
+	// Process the files concurrently, collecting the results in upload order
 	uploadedImages := make([]UploadedImageInfo, 0, len(files))
 	var uploadErrors []UploadError
 	var totalBytes int64
 
-	for _, fileHeader := range files {
-		result := h.processUploadedFile(ctx, fileHeader, tags)
-
+	for _, result := range h.processUploadedFiles(ctx, files, tags, metadataPolicy, progress) {
 		if result.uploadedImage != nil {
 			uploadedImages = append(uploadedImages, *result.uploadedImage)
 			totalBytes += result.bytesUploaded
@@ -182,7 +208,7 @@ func (h *Handler) uploadImagesHandler(w http.ResponseWriter, r *http.Request) {
 // detectContentType detects the content type of a file by reading its header
 func (h *Handler) detectContentType(ctx context.Context, file multipart.File, fileHeader *multipart.FileHeader, fileSpan trace.Span) (string, error) {
 	contentType := fileHeader.Header.Get("Content-Type")
-	if contentType != "" {
+	if contentType != "" && contentType != "application/octet-stream" {
 		return contentType, nil
 	}
 
@@ -196,6 +222,13 @@ func (h *Handler) detectContentType(ctx context.Context, file multipart.File, fi
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 	contentType = http.DetectContentType(buffer[:n])
+	if !isSupportedImageType(contentType) {
+		// Sniffing knows neither TIFF nor HEIC and reports SVG as XML or text;
+		// the storage layer still checks the file against its magic number
+		if byExtension, ok := imageExtensions[strings.ToLower(filepath.Ext(fileHeader.Filename))]; ok {
+			contentType = byExtension
+		}
+	}
 
 	// Seek back to start for upload (multipart.File supports seeking)
 	if seeker, ok := file.(io.Seeker); ok {
@@ -211,7 +244,12 @@ func (h *Handler) detectContentType(ctx context.Context, file multipart.File, fi
 }
 
 // processUploadedFile processes a single uploaded file and returns the result
-func (h *Handler) processUploadedFile(ctx context.Context, fileHeader *multipart.FileHeader, tags []string) processedFileResult {
+func (h *Handler) processUploadedFile(
+	ctx context.Context,
+	fileHeader *multipart.FileHeader,
+	tags []string,
+	metadataPolicy image.MetadataPolicy,
+) processedFileResult {
 	// Create child span for each file
 	_, fileSpan := h.tracer.Start(ctx, "ProcessUploadedFile",
 		trace.WithAttributes(
@@ -280,9 +318,13 @@ func (h *Handler) processUploadedFile(ctx context.Context, fileHeader *multipart
 		}
 	}
 
-	// Extract image dimensions (currently a stub - TODO: implement without buffering entire file)
-	// For now, pass nil to extractImageDimensions to indicate streaming mode
-	width, height := h.extractImageDimensions(ctx, nil, fileHeader.Filename, fileSpan)
+	// Extract image dimensions from the header; the body still streams to storage whole.
+	// SVGs have no pixel dimensions to read.
+	var body io.Reader = file
+	var width, height *int
+	if contentType != image.ContentTypeSVG {
+		body, width, height = h.extractImageDimensions(ctx, file, fileHeader.Filename, fileSpan)
+	}
 
 	// Create upload request
 	createReq := &image.CreateImageRequest{
@@ -292,11 +334,12 @@ func (h *Handler) processUploadedFile(ctx context.Context, fileHeader *multipart
 		Width:            width,
 		Height:           height,
 		Tags:             tags,
+		MetadataPolicy:   metadataPolicy,
 	}
 
 	// Upload image via ImageService (streaming upload - no buffering)
 	// File is already open and seeked to start position
-	img, err := h.imageService.CreateImage(ctx, createReq, file)
+	img, err := h.imageService.CreateImage(ctx, createReq, body)
 	_ = file.Close() //nolint:errcheck // Close after upload completes
 	if err != nil {
 		fileSpan.RecordError(err)
@@ -321,12 +364,6 @@ func (h *Handler) processUploadedFile(ctx context.Context, fileHeader *multipart
 		}
 	}
 
-	// Extract tag names
-	tagNames := make([]string, 0, len(img.Tags))
-	for _, tag := range img.Tags {
-		tagNames = append(tagNames, tag.Name)
-	}
-
 	fileSpan.SetStatus(codes.Ok, "file processed successfully")
 
 	h.logger.Info(ctx).
@@ -336,33 +373,48 @@ func (h *Handler) processUploadedFile(ctx context.Context, fileHeader *multipart
 		Msg("Successfully uploaded image")
 
 	return processedFileResult{
-		uploadedImage: &UploadedImageInfo{
-			ID:               img.ID,
-			Filename:         img.Filename,
-			OriginalFilename: img.OriginalFilename,
-			Size:             img.FileSize,
-			ContentType:      img.ContentType,
-			Width:            img.Width,
-			Height:           img.Height,
-			Tags:             tagNames,
-			URL:              imageURL,
-		},
+		uploadedImage: newUploadedImageInfo(img, imageURL),
 		bytesUploaded: fileHeader.Size,
 	}
 }
 
-// extractImageDimensions extracts width and height from image data
-func (h *Handler) extractImageDimensions(ctx context.Context, fileData []byte, filename string, span trace.Span) (width *int, height *int) {
-	if h.container == nil || fileData == nil {
-		// Return nil dimensions if processor unavailable or streaming mode (fileData == nil)
-		return nil, nil
+// newUploadedImageInfo describes an image just created from an upload
+func newUploadedImageInfo(img *image.Image, imageURL string) *UploadedImageInfo {
+	tagNames := make([]string, 0, len(img.Tags))
+	for _, tag := range img.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	processingStatus, _ := img.ProcessingStatus()
+	return &UploadedImageInfo{
+		ID:               img.ID,
+		Filename:         img.Filename,
+		OriginalFilename: img.OriginalFilename,
+		Size:             img.FileSize,
+		ContentType:      img.ContentType,
+		Width:            img.Width,
+		Height:           img.Height,
+		Tags:             tagNames,
+		PendingTags:      img.PendingTags,
+		MetadataPolicy:   string(img.MetadataPolicy()),
+		URL:              imageURL,
+		ProcessingStatus: string(processingStatus),
+	}
+}
+
+// extractImageDimensions extracts width and height, as displayed, from the header of an
+// image, returning a reader over the whole upload. It returns nil dimensions when the
+// processor is unavailable or the header cannot be read.
+func (h *Handler) extractImageDimensions(ctx context.Context, file io.Reader, filename string, span trace.Span) (body io.Reader, width *int, height *int) {
+	if h.container == nil || h.container.ImageProcessor() == nil {
+		return file, nil, nil
 	}
 
-	imageInfo, err := h.container.ImageProcessor().GetImageInfo(ctx, bytes.NewReader(fileData))
+	body, imageInfo, err := implementations.DecodeUploadHeader(ctx, h.container.ImageProcessor(), file)
 	if err != nil {
 		// Log but don't fail - dimensions are optional
 		h.logger.Warn(ctx).Err(err).Str("filename", filename).Msg("Failed to extract image dimensions")
-		return nil, nil
+		return body, nil, nil
 	}
 
 	span.SetAttributes(
@@ -378,7 +430,7 @@ func (h *Handler) extractImageDimensions(ctx context.Context, fileData []byte, f
 		Str("format", imageInfo.Format).
 		Msg("Extracted image dimensions")
 
-	return &imageInfo.Width, &imageInfo.Height
+	return body, &imageInfo.Width, &imageInfo.Height
 }
 
 // parseTags parses comma-separated tags and returns cleaned, deduplicated tag names
@@ -406,14 +458,34 @@ func parseTags(tagsStr string) []string {
 	return tags
 }
 
+// resolveTagAliases replaces alias spellings (e.g. "b&w") with their canonical tag names.
+// On lookup failure the parsed names are kept; the image service resolves aliases again.
+func (h *Handler) resolveTagAliases(ctx context.Context, tags []string) []string {
+	if len(tags) == 0 || h.tagService == nil {
+		return tags
+	}
+
+	resolved, err := h.tagService.ResolveTagNames(ctx, tags)
+	if err != nil {
+		h.logger.Warn(ctx).Err(err).Msg("Failed to resolve tag aliases")
+		return tags
+	}
+	return resolved
+}
+
 // isSupportedImageType checks if the content type is a supported image format
 func isSupportedImageType(contentType string) bool {
 	supportedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/gif":  true,
-		"image/webp": true,
+		"image/jpeg":         true,
+		"image/jpg":          true,
+		"image/png":          true,
+		"image/gif":          true,
+		"image/webp":         true,
+		"image/bmp":          true,
+		"image/tiff":         true,
+		"image/heic":         true,
+		"image/heif":         true,
+		image.ContentTypeSVG: true,
 	}
 	return supportedTypes[contentType]
 }
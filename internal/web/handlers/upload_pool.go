@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"sync"
+
+	"image-gallery/internal/domain/image"
+)
+
+const (
+	defaultUploadConcurrency   = 4        // Files of one upload request processed at once
+	defaultUploadBytesInFlight = 64 << 20 // Total size of the files being processed across requests
+)
+
+// processUploadedFiles processes the files of one upload request on up to
+// uploadConcurrency workers, returning their results in the order of files. Files not
+// started when the request is canceled fail with the cancellation. The progress of
+// each file is published as it goes.
+func (h *Handler) processUploadedFiles(
+	ctx context.Context,
+	files []*multipart.FileHeader,
+	tags []string,
+	metadataPolicy image.MetadataPolicy,
+	progress *uploadProgress,
+) []processedFileResult {
+	results := make([]processedFileResult, len(files))
+
+	workers := h.uploadConcurrency
+	if workers <= 0 {
+		workers = defaultUploadConcurrency
+	}
+	workers = min(workers, len(files))
+
+	next := make(chan int, len(files))
+	for i := range files {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fileCtx := progress.file(ctx, i, files[i].Filename)
+				results[i] = h.processUploadedFileInBudget(fileCtx, files[i], tags, metadataPolicy)
+				progress.fileResult(i, files[i].Filename, results[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// processUploadedFileInBudget waits until the file fits in the bytes being processed
+// across requests, then processes it
+func (h *Handler) processUploadedFileInBudget(
+	ctx context.Context,
+	fileHeader *multipart.FileHeader,
+	tags []string,
+	metadataPolicy image.MetadataPolicy,
+) processedFileResult {
+	release, err := h.uploadBytes.acquire(ctx, fileHeader.Size)
+	if err != nil {
+		h.logger.Warn(ctx).Err(err).Str("filename", fileHeader.Filename).Msg("Upload canceled before the file was processed")
+		return processedFileResult{
+			uploadError: &UploadError{
+				Filename: fileHeader.Filename,
+				Error:    fmt.Sprintf("Upload canceled: %v", err),
+			},
+		}
+	}
+	defer release()
+
+	return h.processUploadedFile(ctx, fileHeader, tags, metadataPolicy)
+}
+
+// byteBudget limits the total size of the files being processed at once. Files are
+// admitted in the order they asked; one larger than the whole budget is admitted once
+// nothing else is in flight.
+type byteBudget struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	waiters []*budgetWaiter
+}
+
+// budgetWaiter is a file waiting for room in a byteBudget; ready is closed once it has
+// been admitted
+type budgetWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// newByteBudget creates a budget of size bytes
+func newByteBudget(size int64) *byteBudget {
+	if size <= 0 {
+		size = defaultUploadBytesInFlight
+	}
+	return &byteBudget{size: size}
+}
+
+// acquire waits until n bytes fit in the budget, or ctx is done. The returned function
+// gives the bytes back. A nil budget admits every file that is not canceled.
+func (b *byteBudget) acquire(ctx context.Context, n int64) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return func() {}, nil
+	}
+	n = min(max(n, 0), b.size)
+
+	b.mu.Lock()
+	if len(b.waiters) == 0 && b.used+n <= b.size {
+		b.used += n
+		b.mu.Unlock()
+		return func() { b.release(n) }, nil
+	}
+	waiter := &budgetWaiter{n: n, ready: make(chan struct{})}
+	b.waiters = append(b.waiters, waiter)
+	b.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return func() { b.release(n) }, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		select {
+		case <-waiter.ready:
+			// Admitted while giving up; hand the bytes on to the next in line
+			b.used -= n
+		default:
+			for i, w := range b.waiters {
+				if w == waiter {
+					b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		b.admit()
+		return nil, ctx.Err()
+	}
+}
+
+// release gives n bytes back and admits the files now fitting
+func (b *byteBudget) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	b.admit()
+}
+
+// admit lets in the waiting files that fit, in order. It must be called with mu held.
+func (b *byteBudget) admit() {
+	for len(b.waiters) > 0 && b.used+b.waiters[0].n <= b.size {
+		waiter := b.waiters[0]
+		b.waiters = b.waiters[1:]
+		b.used += waiter.n
+		close(waiter.ready)
+	}
+}
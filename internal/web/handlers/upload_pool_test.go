@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sync"
+	"testing"
+	"time"
+
+	"image-gallery/internal/domain/image"
+	"image-gallery/internal/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// slowImageCreator creates images after a delay, the first files taking longest, and
+// records how many it was creating at once
+type slowImageCreator struct {
+	image.ImageService
+
+	mu      sync.Mutex
+	running int
+	peak    int
+	started chan struct{}
+}
+
+func (c *slowImageCreator) CreateImage(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error) {
+	c.mu.Lock()
+	c.running++
+	c.peak = max(c.peak, c.running)
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+	if c.started != nil {
+		c.started <- struct{}{}
+	}
+
+	var index int
+	_, _ = fmt.Sscanf(req.OriginalFilename, "photo-%d.png", &index) //nolint:errcheck // Test files are named for it
+	select {
+	case <-time.After(time.Duration(10-index) * 5 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if _, err := io.Copy(io.Discard, data); err != nil {
+		return nil, err
+	}
+	return &image.Image{ID: index + 1, OriginalFilename: req.OriginalFilename, ContentType: req.ContentType}, nil
+}
+
+func newTestUploadHandler(t *testing.T, images image.ImageService, concurrency int, budget *byteBudget) *Handler {
+	t.Helper()
+	return &Handler{
+		imageService:      images,
+		uploadConcurrency: concurrency,
+		uploadBytes:       budget,
+		tracer:            noop.NewTracerProvider().Tracer("test"),
+		logger:            observability.NewLogger(observability.Config{LogLevel: "fatal"}),
+	}
+}
+
+func testUploadFiles(t *testing.T, count int) []*multipart.FileHeader {
+	t.Helper()
+	files := make([]*multipart.FileHeader, count)
+	for i := range files {
+		files[i] = multipartFileHeader(t, fmt.Sprintf("photo-%d.png", i), "image/png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"))
+	}
+	return files
+}
+
+func TestProcessUploadedFiles(t *testing.T) {
+	t.Run("keeps the upload order", func(t *testing.T) {
+		images := &slowImageCreator{}
+		h := newTestUploadHandler(t, images, 3, newByteBudget(1<<20))
+
+		results := h.processUploadedFiles(context.Background(), testUploadFiles(t, 8), nil, "", nil)
+
+		require.Len(t, results, 8)
+		for i, result := range results {
+			require.NotNil(t, result.uploadedImage, "file %d: %+v", i, result.uploadError)
+			assert.Equal(t, fmt.Sprintf("photo-%d.png", i), result.uploadedImage.OriginalFilename)
+		}
+		assert.Equal(t, 3, images.peak, "files are processed concurrently, up to the configured concurrency")
+	})
+
+	t.Run("limits the bytes in flight", func(t *testing.T) {
+		files := testUploadFiles(t, 4)
+		images := &slowImageCreator{}
+		h := newTestUploadHandler(t, images, 4, newByteBudget(2*files[0].Size))
+
+		results := h.processUploadedFiles(context.Background(), files, nil, "", nil)
+
+		for _, result := range results {
+			require.NotNil(t, result.uploadedImage)
+		}
+		assert.Equal(t, 2, images.peak, "only two files fit in the budget at once")
+	})
+
+	t.Run("stops when the request is canceled", func(t *testing.T) {
+		images := &slowImageCreator{started: make(chan struct{})}
+		h := newTestUploadHandler(t, images, 2, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan []processedFileResult)
+		go func() { done <- h.processUploadedFiles(ctx, testUploadFiles(t, 6), nil, "", nil) }()
+		<-images.started
+		<-images.started
+		cancel()
+
+		results := <-done
+		require.Len(t, results, 6)
+		for i, result := range results {
+			assert.Nil(t, result.uploadedImage, "file %d", i)
+			require.NotNil(t, result.uploadError, "file %d", i)
+			assert.Equal(t, fmt.Sprintf("photo-%d.png", i), result.uploadError.Filename)
+		}
+		assert.Equal(t, 2, images.peak, "no file is started once the request is canceled")
+	})
+}
+
+func TestByteBudget(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues files behind those waiting before them", func(t *testing.T) {
+		budget := newByteBudget(10)
+		release, err := budget.acquire(ctx, 8)
+		require.NoError(t, err)
+
+		admitted := make(chan int, 2)
+		for i, n := range []int64{5, 1} {
+			go func() {
+				release, err := budget.acquire(ctx, n)
+				if err == nil {
+					admitted <- i
+					release()
+				}
+			}()
+			// Queue the waiters one after the other
+			require.Eventually(t, func() bool {
+				budget.mu.Lock()
+				defer budget.mu.Unlock()
+				return len(budget.waiters) == i+1
+			}, time.Second, time.Millisecond)
+		}
+
+		// The small file would fit, but waits behind the one queued before it
+		select {
+		case i := <-admitted:
+			t.Fatalf("file %d admitted past the budget", i)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		release()
+		assert.ElementsMatch(t, []int{0, 1}, []int{<-admitted, <-admitted})
+	})
+
+	t.Run("admits a file larger than the whole budget alone", func(t *testing.T) {
+		budget := newByteBudget(10)
+		release, err := budget.acquire(ctx, 50)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), budget.used)
+		release()
+		assert.Equal(t, int64(0), budget.used)
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		budget := newByteBudget(10)
+		release, err := budget.acquire(ctx, 10)
+		require.NoError(t, err)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = budget.acquire(waitCtx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, budget.waiters)
+
+		release()
+		assert.Equal(t, int64(0), budget.used)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = budget.acquire(canceled, 1)
+		assert.ErrorIs(t, err, context.Canceled, "a canceled request is not admitted even with room")
+	})
+}
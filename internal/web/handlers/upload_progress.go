@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	progressHistoryLimit     = 1000             // Events replayed to a subscriber joining late
+	progressSubscriberBuffer = 64               // Events held for a subscriber before it is dropped as too slow
+	progressSessionTTL       = 5 * time.Minute  // How long a session nobody follows is kept after its last event
+	progressKeepAlive        = 15 * time.Second // Comment sent on idle event streams, so proxies keep them open
+	progressReceivedInterval = 256 << 10        // Bytes received between two received events
+)
+
+// uploadSessionPattern matches the upload session IDs clients may choose
+var uploadSessionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// progressHub passes the progress of uploads to the clients following their upload
+// session. Sessions live in memory, so the events of an upload reach the clients
+// connected to the same server instance.
+type progressHub struct {
+	mu       sync.Mutex
+	sessions map[string]*progressSession
+}
+
+// progressSession is the progress of the latest upload of a session
+type progressSession struct {
+	history     []image.UploadProgress
+	subscribers map[*progressSubscriber]struct{}
+	finished    bool
+	touched     time.Time
+}
+
+// progressSubscriber receives the events of a session as they are published
+type progressSubscriber struct {
+	events  chan image.UploadProgress
+	dropped bool // Closed for falling behind rather than because the upload finished
+}
+
+func newProgressHub() *progressHub {
+	return &progressHub{sessions: make(map[string]*progressSession)}
+}
+
+// session returns a session, creating it, and removes the sessions nobody has
+// followed or published to for a while. It must be called with mu held.
+func (p *progressHub) session(id string) *progressSession {
+	now := time.Now()
+	for other, s := range p.sessions {
+		if other != id && len(s.subscribers) == 0 && now.Sub(s.touched) > progressSessionTTL {
+			delete(p.sessions, other)
+		}
+	}
+
+	s, ok := p.sessions[id]
+	if !ok {
+		s = &progressSession{subscribers: make(map[*progressSubscriber]struct{})}
+		p.sessions[id] = s
+	}
+	s.touched = now
+	return s
+}
+
+// start begins a new upload in a session, forgetting the events of the previous one
+func (p *progressHub) start(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.session(id)
+	s.history = nil
+	s.finished = false
+}
+
+// publish records an event and sends it to the session's subscribers. A subscriber
+// whose buffer is full is dropped; it reconnects and is replayed the history.
+func (p *progressHub) publish(id string, event image.UploadProgress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.session(id)
+	if len(s.history) == progressHistoryLimit {
+		s.history = s.history[1:]
+	}
+	s.history = append(s.history, event)
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped = true
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// finish marks a session's upload as complete and ends its subscriptions
+func (p *progressHub) finish(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.session(id)
+	s.finished = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// subscribe returns the events of a session so far and, unless its upload is
+// finished, a subscriber receiving those to come. Its events are closed when the
+// upload finishes or it falls behind; cancel must be called once done with it.
+func (p *progressHub) subscribe(id string) (history []image.UploadProgress, sub *progressSubscriber, cancel func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.session(id)
+	history = append([]image.UploadProgress(nil), s.history...)
+	if s.finished {
+		return history, nil, func() {}
+	}
+
+	sub = &progressSubscriber{events: make(chan image.UploadProgress, progressSubscriberBuffer)}
+	s.subscribers[sub] = struct{}{}
+	return history, sub, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := s.subscribers[sub]; ok {
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+		s.touched = time.Now()
+	}
+}
+
+// dropped reports whether a subscriber's events were closed for falling behind
+func (p *progressHub) dropped(sub *progressSubscriber) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return sub.dropped
+}
+
+// uploadProgress publishes the progress of one upload request to its session. A nil
+// uploadProgress, for requests without a session, publishes nothing.
+type uploadProgress struct {
+	hub     *progressHub
+	session string
+	request *progressBody
+}
+
+// uploadProgress starts publishing the progress of an upload request to the session
+// given in its X-Upload-Session header or session query parameter
+func (h *Handler) uploadProgress(r *http.Request) (*uploadProgress, error) {
+	session := r.Header.Get("X-Upload-Session")
+	if session == "" {
+		session = r.URL.Query().Get("session")
+	}
+	if session == "" || h.progress == nil {
+		return nil, nil
+	}
+	if !uploadSessionPattern.MatchString(session) {
+		return nil, fmt.Errorf("invalid upload session %q", session)
+	}
+
+	h.progress.start(session)
+	return &uploadProgress{hub: h.progress, session: session}, nil
+}
+
+func (p *uploadProgress) publish(event image.UploadProgress) {
+	if p != nil {
+		p.hub.publish(p.session, event)
+	}
+}
+
+// fail reports an error rejecting the whole request
+func (p *uploadProgress) fail(err error) {
+	p.publish(image.UploadProgress{Stage: image.UploadStageError, Error: err.Error()})
+}
+
+func (p *uploadProgress) finish() {
+	if p != nil {
+		p.hub.finish(p.session)
+	}
+}
+
+// body reports the bytes of a request body as they are received
+func (p *uploadProgress) body(body io.ReadCloser, total int64) io.ReadCloser {
+	if p == nil {
+		return body
+	}
+	p.request = &progressBody{ReadCloser: body, progress: p, total: max(total, 0)}
+	return p.request
+}
+
+// received reports the bytes of the request body read since the last received event,
+// as the form parser stops at the closing boundary rather than at the end of the body
+func (p *uploadProgress) received() {
+	if p != nil && p.request != nil {
+		p.request.report()
+	}
+}
+
+// filesReceived reports the rest of the request body received, then each file in it
+func (p *uploadProgress) filesReceived(files []*multipart.FileHeader) {
+	if p == nil {
+		return
+	}
+	p.received()
+	for i, file := range files {
+		p.publish(image.UploadProgress{File: &i, Filename: file.Filename, Stage: image.UploadStageReceived, Bytes: file.Size, Total: file.Size})
+	}
+}
+
+// file returns the context to create the image of the file at index in, reporting the
+// stages it reaches
+func (p *uploadProgress) file(ctx context.Context, index int, filename string) context.Context {
+	if p == nil {
+		return ctx
+	}
+	var last image.UploadStage
+	return image.WithUploadStageReporter(ctx, func(stage image.UploadStage) {
+		if stage != last {
+			last = stage
+			p.publish(image.UploadProgress{File: &index, Filename: filename, Stage: stage})
+		}
+	})
+}
+
+// fileResult reports how processing the file at index ended
+func (p *uploadProgress) fileResult(index int, filename string, result processedFileResult) {
+	event := image.UploadProgress{File: &index, Filename: filename, Stage: image.UploadStageDone}
+	if result.uploadedImage != nil {
+		event.ImageID = result.uploadedImage.ID
+	} else if result.uploadError != nil {
+		event.Stage = image.UploadStageError
+		event.Error = result.uploadError.Error
+	}
+	p.publish(event)
+}
+
+// progressBody is a request body publishing received events as it is read
+type progressBody struct {
+	io.ReadCloser
+	progress *uploadProgress
+	total    int64
+	read     int64
+	reported int64
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read-b.reported >= progressReceivedInterval || err == io.EOF {
+		b.report()
+	}
+	return n, err
+}
+
+// report publishes the bytes read, unless they have been already
+func (b *progressBody) report() {
+	if b.read > b.reported {
+		b.reported = b.read
+		b.progress.publish(image.UploadProgress{Stage: image.UploadStageReceived, Bytes: b.read, Total: b.total})
+	}
+}
+
+// uploadEventsHandler streams the progress of the uploads made with an upload session
+// as server-sent events: a progress event for each image.UploadProgress, starting with
+// those already published, then an end event once the upload is complete. The stream
+// can be opened before the upload starts (GET /api/uploads/{session}/events).
+func (h *Handler) uploadEventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "UploadEventsHandler",
+		attribute.String("handler", "upload_events"),
+	)
+	defer h.endSpan(span)
+
+	session := chi.URLParam(r, "session")
+	if !uploadSessionPattern.MatchString(session) {
+		http.Error(w, "Invalid upload session", http.StatusBadRequest)
+		return
+	}
+	if h.progress == nil {
+		http.Error(w, "Upload progress not available", http.StatusServiceUnavailable)
+		return
+	}
+	h.setSpanAttributes(span, attribute.String("upload.session", session))
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && h.logger != nil {
+		h.logger.Warn(ctx).Err(err).Msg("Failed to clear write deadline of event stream")
+	}
+
+	history, sub, cancel := h.progress.subscribe(session)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data any) bool {
+		payload, err := json.Marshal(data)
+		if err == nil {
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		return err == nil
+	}
+
+	for _, event := range history {
+		if !send("progress", event) {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(progressKeepAlive)
+	defer keepAlive.Stop()
+	for sub != nil {
+		select {
+		case event, ok := <-sub.events:
+			if !ok {
+				if h.progress.dropped(sub) {
+					// Fallen behind; the client reconnects and is replayed the history
+					return
+				}
+				sub = nil
+				continue
+			}
+			if !send("progress", event) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	h.setSpanStatus(span, codes.Ok, "")
+	send("end", struct{}{})
+}
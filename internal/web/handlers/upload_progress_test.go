@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stagedImageCreator reports the stages CreateImage goes through, rejecting files named
+// bad-*
+type stagedImageCreator struct {
+	image.ImageService
+}
+
+func (stagedImageCreator) CreateImage(ctx context.Context, req *image.CreateImageRequest, data io.Reader) (*image.Image, error) {
+	image.ReportUploadStage(ctx, image.UploadStageValidating)
+	if strings.HasPrefix(req.OriginalFilename, "bad-") {
+		return nil, image.ErrInvalidImageData
+	}
+	image.ReportUploadStage(ctx, image.UploadStageStoring)
+	if _, err := io.Copy(io.Discard, data); err != nil {
+		return nil, err
+	}
+	image.ReportUploadStage(ctx, image.UploadStageProcessing)
+	return &image.Image{ID: 7, OriginalFilename: req.OriginalFilename}, nil
+}
+
+// sseEvent is an event read from a server-sent event stream
+type sseEvent struct {
+	name     string
+	progress image.UploadProgress
+}
+
+// readSSE reads events from a stream until its end event
+func readSSE(t *testing.T, stream io.Reader) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var name string
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event := sseEvent{name: name}
+			if name == "progress" {
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.progress))
+			}
+			events = append(events, event)
+			if name == "end" {
+				return events
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+// fileStages groups the stages of the progress events by file
+func fileStages(events []sseEvent) map[int][]image.UploadStage {
+	stages := make(map[int][]image.UploadStage)
+	for _, event := range events {
+		if event.progress.File != nil {
+			stages[*event.progress.File] = append(stages[*event.progress.File], event.progress.Stage)
+		}
+	}
+	return stages
+}
+
+func newUploadForm(t *testing.T, filenames ...string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, filename := range filenames {
+		part, err := writer.CreateFormFile("files", filename)
+		require.NoError(t, err)
+		_, err = part.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+func newProgressRouter(t *testing.T) (*Handler, chi.Router) {
+	t.Helper()
+	h := newTestUploadHandler(t, stagedImageCreator{}, 2, nil)
+	h.progress = newProgressHub()
+	r := chi.NewRouter()
+	r.Post("/api/images", h.uploadImagesHandler)
+	r.Get("/api/uploads/{session}/events", h.uploadEventsHandler)
+	return h, r
+}
+
+func TestUploadProgress(t *testing.T) {
+	t.Run("replays the progress of a finished upload", func(t *testing.T) {
+		_, r := newProgressRouter(t)
+
+		body, contentType := newUploadForm(t, "a.png", "bad-b.png")
+		size := int64(body.Len())
+		req := httptest.NewRequest(http.MethodPost, "/api/images?session=batch-1", body)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusMultiStatus, rec.Code, rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/uploads/batch-1/events", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+		events := readSSE(t, rec.Body)
+		require.NotEmpty(t, events)
+		assert.Equal(t, "end", events[len(events)-1].name)
+
+		request := events[0].progress
+		assert.Nil(t, request.File)
+		assert.Equal(t, image.UploadStageReceived, request.Stage)
+		assert.Equal(t, size, request.Bytes, "the request body is reported received in full")
+
+		assert.Equal(t, map[int][]image.UploadStage{
+			0: {image.UploadStageReceived, image.UploadStageValidating, image.UploadStageStoring, image.UploadStageProcessing, image.UploadStageDone},
+			1: {image.UploadStageReceived, image.UploadStageValidating, image.UploadStageError},
+		}, fileStages(events))
+		for _, event := range events {
+			switch {
+			case event.progress.Stage == image.UploadStageDone:
+				assert.Equal(t, 7, event.progress.ImageID)
+			case event.progress.Stage == image.UploadStageError:
+				assert.Equal(t, "bad-b.png", event.progress.Filename)
+				assert.NotEmpty(t, event.progress.Error)
+			}
+		}
+	})
+
+	t.Run("streams the progress of an upload as it goes", func(t *testing.T) {
+		h, r := newProgressRouter(t)
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/api/uploads/live/events")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }() //nolint:errcheck // Test cleanup
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Wait for the stream to subscribe before uploading
+		require.Eventually(t, func() bool {
+			h.progress.mu.Lock()
+			defer h.progress.mu.Unlock()
+			s, ok := h.progress.sessions["live"]
+			return ok && len(s.subscribers) == 1
+		}, time.Second, time.Millisecond)
+
+		body, contentType := newUploadForm(t, "a.png")
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/images", body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Upload-Session", "live")
+		upload, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = upload.Body.Close() //nolint:errcheck // Test cleanup
+		require.Equal(t, http.StatusCreated, upload.StatusCode)
+
+		events := readSSE(t, resp.Body)
+		require.NotEmpty(t, events)
+		assert.Equal(t, "end", events[len(events)-1].name)
+		assert.Equal(t, []image.UploadStage{
+			image.UploadStageReceived, image.UploadStageValidating, image.UploadStageStoring, image.UploadStageProcessing, image.UploadStageDone,
+		}, fileStages(events)[0])
+	})
+
+	t.Run("rejects invalid sessions", func(t *testing.T) {
+		_, r := newProgressRouter(t)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/uploads/"+strings.Repeat("x", 65)+"/events", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		body, contentType := newUploadForm(t, "a.png")
+		req := httptest.NewRequest(http.MethodPost, "/api/images", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Upload-Session", "not/valid")
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestProgressHub(t *testing.T) {
+	t.Run("drops a subscriber that falls behind", func(t *testing.T) {
+		hub := newProgressHub()
+		hub.start("s")
+		_, sub, cancel := hub.subscribe("s")
+		defer cancel()
+
+		for i := range progressSubscriberBuffer + 1 {
+			hub.publish("s", image.UploadProgress{Stage: image.UploadStageReceived, Bytes: int64(i)})
+		}
+
+		assert.True(t, hub.dropped(sub))
+		received := 0
+		for range sub.events {
+			received++
+		}
+		assert.Equal(t, progressSubscriberBuffer, received)
+
+		history, _, _ := hub.subscribe("s")
+		assert.Len(t, history, progressSubscriberBuffer+1, "a reconnecting subscriber is replayed every event")
+	})
+
+	t.Run("starts each upload afresh", func(t *testing.T) {
+		hub := newProgressHub()
+		hub.start("s")
+		hub.publish("s", image.UploadProgress{Stage: image.UploadStageReceived})
+		hub.finish("s")
+
+		history, sub, _ := hub.subscribe("s")
+		assert.Len(t, history, 1)
+		assert.Nil(t, sub, "a finished upload has nothing more to send")
+
+		hub.start("s")
+		history, sub, cancel := hub.subscribe("s")
+		defer cancel()
+		assert.Empty(t, history)
+		assert.NotNil(t, sub)
+	})
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/textproto"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func TestParseTags(t *testing.T) {
@@ -57,7 +63,11 @@ func TestIsSupportedImageType(t *testing.T) {
 		{"image/png", true},
 		{"image/gif", true},
 		{"image/webp", true},
-		{"image/bmp", false},
+		{"image/bmp", true},
+		{"image/tiff", true},
+		{"image/heic", true},
+		{"image/svg+xml", true},
+		{"image/x-icon", false},
 		{"text/plain", false},
 		{"application/pdf", false},
 		{"", false},
@@ -70,3 +80,60 @@ func TestIsSupportedImageType(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		header   string
+		data     []byte
+		expected string
+	}{
+		{"declared type wins", "photo.jpg", "image/png", []byte("anything"), "image/png"},
+		{"sniffed when missing", "photo", "", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), "image/png"},
+		{"sniffed over octet-stream", "photo", "application/octet-stream", []byte("GIF89a\x01\x00\x01\x00"), "image/gif"},
+		{"HEIC from extension", "IMG_0001.HEIC", "application/octet-stream", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"), "image/heic"},
+		{"TIFF from extension", "scan.tif", "", []byte("II*\x00\x08\x00\x00\x00"), "image/tiff"},
+		{"SVG from extension", "logo.svg", "", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), "image/svg+xml"},
+		{"unknown extension keeps sniffed type", "notes.txt", "", []byte("hello"), "text/plain; charset=utf-8"},
+	}
+
+	h := &Handler{}
+	_, span := noop.NewTracerProvider().Tracer("test").Start(context.Background(), "test")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileHeader := multipartFileHeader(t, tt.filename, tt.header, tt.data)
+			file, err := fileHeader.Open()
+			require.NoError(t, err)
+			defer func() { _ = file.Close() }() //nolint:errcheck // Test cleanup
+
+			contentType, err := h.detectContentType(context.Background(), file, fileHeader, span)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, contentType)
+		})
+	}
+}
+
+// multipartFileHeader builds the header of an uploaded file as the form parser does
+func multipartFileHeader(t *testing.T, filename, contentType string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", `form-data; name="images"; filename="`+filename+`"`)
+	if contentType != "" {
+		partHeader.Set("Content-Type", contentType)
+	}
+	part, err := writer.CreatePart(partHeader)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() }) //nolint:errcheck // Test cleanup
+	return form.File["images"][0]
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// cardSizes is the sizes attribute of gallery cards, matching the default five-column grid
+const cardSizes = "(min-width: 1280px) 20vw, (min-width: 1024px) 25vw, (min-width: 768px) 33vw, (min-width: 640px) 50vw, 100vw"
+
+// ImageVariantResponse is a responsive variant of an image as listed by the API
+type ImageVariantResponse struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// imageVariantHandler serves a responsive variant generated at upload
+// (GET /api/images/{id}/variants/{width})
+func (h *Handler) imageVariantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r.Context(), "ImageVariantHandler",
+		attribute.String("handler", "image_variant"),
+	)
+	defer h.endSpan(span)
+
+	imageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	width, err := strconv.Atoi(chi.URLParam(r, "width"))
+	if err != nil {
+		http.Error(w, "Invalid variant width", http.StatusBadRequest)
+		return
+	}
+	h.setSpanAttributes(span,
+		attribute.Int("image.id", imageID),
+		attribute.Int("variant.width", width),
+	)
+
+	if h.imageService == nil || h.storageService == nil {
+		http.Error(w, "Image service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	img, err := h.imageService.GetImage(ctx, imageID)
+	if err != nil {
+		h.handleError(ctx, span, err, "", "image_not_found", "")
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
+	variant, ok := findVariant(img.Variants, width)
+	if !ok {
+		h.handleError(ctx, span, fmt.Errorf("no %dpx variant", width), "", "variant_not_found", "")
+		http.Error(w, "Variant not found", http.StatusNotFound)
+		return
+	}
+
+	reader, err := h.storageService.Retrieve(ctx, variant.StoragePath)
+	if err != nil {
+		h.handleError(ctx, span, err, "Failed to retrieve variant", "variant_retrieve_failed", "")
+		http.Error(w, "Failed to retrieve variant", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = reader.Close() }() //nolint:errcheck // Resource cleanup
+
+	// Variants are written once at upload and image IDs are not reused
+	w.Header().Set("Content-Type", variant.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.setSpanStatus(span, codes.Ok, "")
+
+	if _, err := io.Copy(w, reader); err != nil {
+		h.handleError(ctx, span, err, "Failed to serve variant", "", "")
+	}
+}
+
+// findVariant returns the variant of the given width
+func findVariant(variants []image.ImageVariant, width int) (image.ImageVariant, bool) {
+	for _, v := range variants {
+		if v.Width == width {
+			return v, true
+		}
+	}
+	return image.ImageVariant{}, false
+}
+
+// variantResponses lists an image's variants with the URLs they are served from
+func variantResponses(img *image.Image) []ImageVariantResponse {
+	if len(img.Variants) == 0 {
+		return nil
+	}
+	variants := make([]ImageVariantResponse, len(img.Variants))
+	for i, v := range img.Variants {
+		variants[i] = ImageVariantResponse{
+			Width:  v.Width,
+			Height: v.Height,
+			URL:    fmt.Sprintf("/api/images/%d/variants/%d", img.ID, v.Width),
+		}
+	}
+	return variants
+}
+
+// srcsetAttributes returns the srcset and sizes attributes of a card image, or "" for
+// images without variants, which keep the single card rendition, and for animated GIFs,
+// whose still variants would replace the animated thumbnail
+func srcsetAttributes(img ImageResponse) string {
+	if len(img.Variants) == 0 || img.ThumbnailURL != "" {
+		return ""
+	}
+	candidates := make([]string, len(img.Variants))
+	for i, v := range img.Variants {
+		candidates[i] = fmt.Sprintf("%s %dw", v.URL, v.Width)
+	}
+	return fmt.Sprintf(` srcset="%s" sizes="%s"`, strings.Join(candidates, ", "), cardSizes)
+}
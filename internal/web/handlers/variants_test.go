@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"image-gallery/internal/domain/image"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSrcsetAttributes(t *testing.T) {
+	img := &image.Image{
+		ID: 7,
+		Variants: []image.ImageVariant{
+			{Width: 320, Height: 240, Format: image.RenderFormatJPEG},
+			{Width: 640, Height: 480, Format: image.RenderFormatJPEG},
+		},
+	}
+
+	attrs := srcsetAttributes(ImageResponse{Variants: variantResponses(img)})
+	assert.Contains(t, attrs, `srcset="/api/images/7/variants/320 320w, /api/images/7/variants/640 640w"`)
+	assert.Contains(t, attrs, `sizes="`+cardSizes+`"`)
+
+	assert.Empty(t, srcsetAttributes(ImageResponse{Variants: variantResponses(&image.Image{ID: 8})}),
+		"images without variants keep the single card rendition")
+}
+
+func TestFindVariant(t *testing.T) {
+	variants := []image.ImageVariant{{Width: 320}, {Width: 640}}
+
+	v, ok := findVariant(variants, 640)
+	assert.True(t, ok)
+	assert.Equal(t, 640, v.Width)
+
+	_, ok = findVariant(variants, 1280)
+	assert.False(t, ok)
+}